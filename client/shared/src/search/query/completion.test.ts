@@ -74,6 +74,8 @@ describe('getCompletionItems()', () => {
             'rev',
             'select',
             'timeout',
+            'trailer',
+            '-trailer',
             'type',
             'visibility',
         ])
@@ -134,6 +136,8 @@ describe('getCompletionItems()', () => {
             'rev',
             'select',
             'timeout',
+            'trailer',
+            '-trailer',
             'type',
             'visibility',
             'github.com/sourcegraph/jsonrpc2',
@@ -176,6 +180,8 @@ describe('getCompletionItems()', () => {
             'rev',
             'select',
             'timeout',
+            'trailer',
+            '-trailer',
             'type',
             'visibility',
         ])
@@ -226,6 +232,8 @@ describe('getCompletionItems()', () => {
             'rev',
             'select',
             'timeout',
+            'trailer',
+            '-trailer',
             'type',
             'visibility',
             'github.com/sourcegraph/jsonrpc2',
@@ -267,6 +275,8 @@ describe('getCompletionItems()', () => {
             'rev',
             'select',
             'timeout',
+            'trailer',
+            '-trailer',
             'type',
             'visibility',
         ])
@@ -778,6 +778,7 @@ export const hasRegexpValue = (field: string): boolean => {
         case 'message':
         case 'msg':
         case 'm':
+        case 'trailer':
         case 'commiter':
         case 'author':
             return true
@@ -95,7 +95,7 @@ const rules: PatternOf<Token[], PatternData>[] = [
     allOf(
         not(some({ field: { value: 'type' }, value: { value: oneOf('diff', 'commit') } })),
         each({
-            field: { value: oneOf('author', 'before', 'until', 'after', 'since', 'message', 'msg', 'm') },
+            field: { value: oneOf('author', 'before', 'until', 'after', 'since', 'message', 'msg', 'm', 'trailer') },
             $data: addFilterDiagnostic('Error: this filter requires `type:commit` or `type:diff` in the query'),
         })
     ),
@@ -30,6 +30,7 @@ export enum FilterType {
     rev = 'rev',
     select = 'select',
     timeout = 'timeout',
+    trailer = 'trailer',
     type = 'type',
     visibility = 'visibility',
 }
@@ -85,6 +86,7 @@ export enum NegatedFilters {
     r = '-r',
     repo = '-repo',
     repohasfile = '-repohasfile',
+    trailer = '-trailer',
 }
 
 /** The list of filters that are able to be negated. */
@@ -97,6 +99,7 @@ export type NegatableFilter =
     | FilterType.committer
     | FilterType.author
     | FilterType.message
+    | FilterType.trailer
 
 export const isNegatableFilter = (filter: FilterType): filter is NegatableFilter =>
     Object.keys(NegatedFilters).includes(filter)
@@ -119,6 +122,7 @@ const negatedFilterToNegatableFilter: { [key: string]: NegatableFilter } = {
     '-r': FilterType.repo,
     '-repo': FilterType.repo,
     '-repohasfile': FilterType.repohasfile,
+    '-trailer': FilterType.trailer,
 }
 
 export const resolveNegatedFilter = (filter: NegatedFilters): NegatableFilter => negatedFilterToNegatableFilter[filter]
@@ -286,6 +290,11 @@ export const FILTERS: Record<NegatableFilter, NegatableFilterDefinition> &
         description: 'Duration before timeout',
         singular: true,
     },
+    [FilterType.trailer]: {
+        negatable: true,
+        description: negated =>
+            `${negated ? 'Exclude' : 'Include only'} Commits with message trailers (like Signed-off-by:) matching a certain string`,
+    },
     [FilterType.type]: {
         description: 'Limit results to the specified type.',
         discreteValues: () => ['diff', 'commit', 'symbol', 'repo', 'path', 'file'].map(value => ({ label: value })),
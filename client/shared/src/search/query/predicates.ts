@@ -26,6 +26,10 @@ export const PREDICATES: Access[] = [
                     },
                 ],
             },
+            {
+                name: 'has',
+                fields: [{ name: 'topic' }, { name: 'meta' }],
+            },
         ],
     },
     {
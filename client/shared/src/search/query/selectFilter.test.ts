@@ -56,7 +56,10 @@ describe('selectorCompletion', () => {
             commit,
             commit.diff,
             commit.diff.added,
-            commit.diff.removed
+            commit.diff.removed,
+            commit.author,
+            commit.message,
+            commit.date
         `)
     })
 })
@@ -49,7 +49,12 @@ export const SELECTORS: Access[] = [
     },
     {
         name: 'commit',
-        fields: [{ name: 'diff', fields: [{ name: 'added' }, { name: 'removed' }] }],
+        fields: [
+            { name: 'diff', fields: [{ name: 'added' }, { name: 'removed' }] },
+            { name: 'author' },
+            { name: 'message' },
+            { name: 'date' },
+        ],
     },
 ]
 
@@ -108,6 +108,8 @@ export interface CommitMatch {
 
     content: MarkdownText
     ranges: number[][]
+    /** The number of commits the match stands for, when selecting a commit field such as select:commit.date. */
+    count?: number
 }
 
 export interface RepositoryMatch {
@@ -6,6 +6,9 @@ import GithubIcon from 'mdi-react/GithubIcon'
 import GitIcon from 'mdi-react/GitIcon'
 import GitLabIcon from 'mdi-react/GitlabIcon'
 import LanguageJavaIcon from 'mdi-react/LanguageJavaIcon'
+import LanguageJavascriptIcon from 'mdi-react/LanguageJavascriptIcon'
+import LanguagePythonIcon from 'mdi-react/LanguagePythonIcon'
+import SourceRepositoryIcon from 'mdi-react/SourceRepositoryIcon'
 import React from 'react'
 
 import { PhabricatorIcon } from '@sourcegraph/shared/src/components/icons'
@@ -17,9 +20,13 @@ import githubSchemaJSON from '../../../../../schema/github.schema.json'
 import gitlabSchemaJSON from '../../../../../schema/gitlab.schema.json'
 import gitoliteSchemaJSON from '../../../../../schema/gitolite.schema.json'
 import jvmPackagesSchemaJSON from '../../../../../schema/jvm-packages.schema.json'
+import mercurialSchemaJSON from '../../../../../schema/mercurial.schema.json'
+import npmPackagesSchemaJSON from '../../../../../schema/npm-packages.schema.json'
 import otherExternalServiceSchemaJSON from '../../../../../schema/other_external_service.schema.json'
 import perforceSchemaJSON from '../../../../../schema/perforce.schema.json'
 import phabricatorSchemaJSON from '../../../../../schema/phabricator.schema.json'
+import pythonPackagesSchemaJSON from '../../../../../schema/python-packages.schema.json'
+import subversionSchemaJSON from '../../../../../schema/subversion.schema.json'
 import { ExternalServiceKind } from '../../graphql-operations'
 import { EditorAction } from '../../site-admin/configHelpers'
 import { PerforceIcon } from '../PerforceIcon'
@@ -1179,6 +1186,76 @@ const PERFORCE: AddExternalServiceOptions = {
         },
     ],
 }
+const MERCURIAL: AddExternalServiceOptions = {
+    kind: ExternalServiceKind.MERCURIAL,
+    title: 'Mercurial',
+    icon: SourceRepositoryIcon,
+    jsonSchema: mercurialSchemaJSON,
+    defaultDisplayName: 'Mercurial repositories',
+    defaultConfig: `{
+  "url": "https://hg.example.com",
+  "repos": []
+}`,
+    instructions: (
+        <div>
+            <ol>
+                <li>
+                    In the configuration below, set <Field>url</Field> to be the URL of your Mercurial server.
+                </li>
+                <li>
+                    Add the paths of the repositories you wish to index to the <Field>repos</Field> field. These will be
+                    appended to the server URL to obtain the repository URLs.
+                </li>
+            </ol>
+            <p>
+                Sourcegraph converts Mercurial repositories to Git repositories when cloning them. See{' '}
+                <a rel="noopener noreferrer" target="_blank" href="https://docs.sourcegraph.com/admin/repo/mercurial">
+                    the docs
+                </a>{' '}
+                for more information.
+            </p>
+        </div>
+    ),
+    editorActions: [],
+}
+const SUBVERSION: AddExternalServiceOptions = {
+    kind: ExternalServiceKind.SUBVERSION,
+    title: 'Subversion',
+    icon: SourceRepositoryIcon,
+    jsonSchema: subversionSchemaJSON,
+    defaultDisplayName: 'Subversion repositories',
+    defaultConfig: `{
+  "url": "https://svn.example.com",
+  "username": "<username>",
+  "password": "<password>",
+  "repos": []
+}`,
+    instructions: (
+        <div>
+            <ol>
+                <li>
+                    In the configuration below, set <Field>url</Field> to be the URL of your Subversion server.
+                </li>
+                <li>
+                    Set <Field>username</Field> and <Field>password</Field> to the credentials of a user with read
+                    access to the repositories, or remove them if the repositories are public.
+                </li>
+                <li>
+                    Add the paths of the repositories you wish to index to the <Field>repos</Field> field. These will be
+                    appended to the server URL to obtain the repository URLs.
+                </li>
+            </ol>
+            <p>
+                Sourcegraph converts Subversion repositories to Git repositories when cloning them. See{' '}
+                <a rel="noopener noreferrer" target="_blank" href="https://docs.sourcegraph.com/admin/repo/subversion">
+                    the docs
+                </a>{' '}
+                for more information.
+            </p>
+        </div>
+    ),
+    editorActions: [],
+}
 const JVM_PACKAGES: AddExternalServiceOptions = {
     kind: ExternalServiceKind.JVMPACKAGES,
     title: 'JVM Dependencies',
@@ -1210,6 +1287,58 @@ const JVM_PACKAGES: AddExternalServiceOptions = {
     ),
     editorActions: [],
 }
+const NPM_PACKAGES: AddExternalServiceOptions = {
+    kind: ExternalServiceKind.NPMPACKAGES,
+    title: 'npm Dependencies',
+    icon: LanguageJavascriptIcon,
+    jsonSchema: npmPackagesSchemaJSON,
+    defaultDisplayName: 'npm Dependencies',
+    defaultConfig: `{
+  "registry": "https://registry.npmjs.org",
+  "dependencies": []
+}`,
+    instructions: (
+        <div>
+            <ol>
+                <li>
+                    In the configuration below, set <Field>registry</Field> to the URL of the npm registry. For
+                    example, <code>"https://registry.npmjs.org"</code>.
+                </li>
+                <li>
+                    In the configuration below, set <Field>dependencies</Field> to the list of packages that you want
+                    to manually add. For example, <code>"react@17.0.2"</code> or <code>"@types/node@16.0.0"</code>.
+                </li>
+            </ol>
+        </div>
+    ),
+    editorActions: [],
+}
+const PYTHON_PACKAGES: AddExternalServiceOptions = {
+    kind: ExternalServiceKind.PYTHONPACKAGES,
+    title: 'Python Dependencies',
+    icon: LanguagePythonIcon,
+    jsonSchema: pythonPackagesSchemaJSON,
+    defaultDisplayName: 'Python Dependencies',
+    defaultConfig: `{
+  "urls": ["https://pypi.org/simple"],
+  "dependencies": []
+}`,
+    instructions: (
+        <div>
+            <ol>
+                <li>
+                    In the configuration below, set <Field>urls</Field> to the list of Python package indexes. For
+                    example, <code>"https://pypi.org/simple"</code>.
+                </li>
+                <li>
+                    In the configuration below, set <Field>dependencies</Field> to the list of packages that you want
+                    to manually add. For example, <code>"requests==2.26.0"</code>.
+                </li>
+            </ol>
+        </div>
+    ),
+    editorActions: [],
+}
 
 export const codeHostExternalServices: Record<string, AddExternalServiceOptions> = {
     github: GITHUB_DOTCOM,
@@ -1223,7 +1352,11 @@ export const codeHostExternalServices: Record<string, AddExternalServiceOptions>
     gitolite: GITOLITE,
     git: GENERIC_GIT,
     ...(window.context?.experimentalFeatures?.perforce === 'enabled' ? { perforce: PERFORCE } : {}),
+    ...(window.context?.experimentalFeatures?.mercurial === 'enabled' ? { mercurial: MERCURIAL } : {}),
+    ...(window.context?.experimentalFeatures?.subversion === 'enabled' ? { subversion: SUBVERSION } : {}),
     ...(window.context?.experimentalFeatures?.jvmPackages === 'enabled' ? { jvmPackages: JVM_PACKAGES } : {}),
+    ...(window.context?.experimentalFeatures?.npmPackages === 'enabled' ? { npmPackages: NPM_PACKAGES } : {}),
+    ...(window.context?.experimentalFeatures?.pythonPackages === 'enabled' ? { pythonPackages: PYTHON_PACKAGES } : {}),
 }
 
 export const nonCodeHostExternalServices: Record<string, AddExternalServiceOptions> = {
@@ -1245,5 +1378,9 @@ export const defaultExternalServices: Record<ExternalServiceKind, AddExternalSer
     [ExternalServiceKind.OTHER]: GENERIC_GIT,
     [ExternalServiceKind.AWSCODECOMMIT]: AWS_CODE_COMMIT,
     [ExternalServiceKind.PERFORCE]: PERFORCE,
+    [ExternalServiceKind.MERCURIAL]: MERCURIAL,
+    [ExternalServiceKind.SUBVERSION]: SUBVERSION,
     [ExternalServiceKind.JVMPACKAGES]: JVM_PACKAGES,
+    [ExternalServiceKind.NPMPACKAGES]: NPM_PACKAGES,
+    [ExternalServiceKind.PYTHONPACKAGES]: PYTHON_PACKAGES,
 }
@@ -15,6 +15,10 @@ const configInstructionLinks: Record<ExternalServiceKind, string> = {
     [ExternalServiceKind.BITBUCKETCLOUD]: 'unsupported',
     [ExternalServiceKind.GITOLITE]: 'unsupported',
     [ExternalServiceKind.JVMPACKAGES]: 'unsupported',
+    [ExternalServiceKind.MERCURIAL]: 'unsupported',
+    [ExternalServiceKind.NPMPACKAGES]: 'unsupported',
+    [ExternalServiceKind.PYTHONPACKAGES]: 'unsupported',
+    [ExternalServiceKind.SUBVERSION]: 'unsupported',
     [ExternalServiceKind.OTHER]: 'unsupported',
     [ExternalServiceKind.PERFORCE]: 'unsupported',
     [ExternalServiceKind.PHABRICATOR]: 'unsupported',
@@ -15,9 +15,13 @@ import githubSchemaJSON from '../../../../schema/github.schema.json'
 import gitlabSchemaJSON from '../../../../schema/gitlab.schema.json'
 import gitoliteSchemaJSON from '../../../../schema/gitolite.schema.json'
 import jvmPackagesSchemaJSON from '../../../../schema/jvm-packages.schema.json'
+import mercurialSchemaJSON from '../../../../schema/mercurial.schema.json'
+import npmPackagesSchemaJSON from '../../../../schema/npm-packages.schema.json'
 import otherExternalServiceSchemaJSON from '../../../../schema/other_external_service.schema.json'
 import perforceSchemaJSON from '../../../../schema/perforce.schema.json'
 import phabricatorSchemaJSON from '../../../../schema/phabricator.schema.json'
+import pythonPackagesSchemaJSON from '../../../../schema/python-packages.schema.json'
+import subversionSchemaJSON from '../../../../schema/subversion.schema.json'
 import settingsSchemaJSON from '../../../../schema/settings.schema.json'
 import siteSchemaJSON from '../../../../schema/site.schema.json'
 import { PageTitle } from '../components/PageTitle'
@@ -42,9 +46,13 @@ const externalServices: Record<ExternalServiceKind, JSONSchema> = {
     GITLAB: gitlabSchemaJSON,
     GITOLITE: gitoliteSchemaJSON,
     JVMPACKAGES: jvmPackagesSchemaJSON,
+    MERCURIAL: mercurialSchemaJSON,
+    NPMPACKAGES: npmPackagesSchemaJSON,
     OTHER: otherExternalServiceSchemaJSON,
     PERFORCE: perforceSchemaJSON,
     PHABRICATOR: phabricatorSchemaJSON,
+    PYTHONPACKAGES: pythonPackagesSchemaJSON,
+    SUBVERSION: subversionSchemaJSON,
 }
 
 const allConfigSchema = {
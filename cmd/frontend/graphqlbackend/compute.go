@@ -23,7 +23,7 @@ type ComputeResolver interface {
 // A dummy type to express the union of compute results. This how its done by the GQL library we use.
 // https://github.com/graph-gophers/graphql-go/blob/af5bb93e114f0cd4cc095dd8eae0b67070ae8f20/example/starwars/starwars.go#L485-L487
 //
-// union ComputeResult = ComputeMatchContext | ComputeText
+// union ComputeResult = ComputeMatchContext | ComputeText | ComputeHistogram | ComputeGroups
 type computeResultResolver struct {
 	result interface{}
 }
@@ -132,6 +132,69 @@ func (c *computeTextResolver) Kind() *string {
 }
 func (c *computeTextResolver) Value() string { return c.t.Value }
 
+// ComputeHistogram GQL result resolver definitions.
+
+type computeHistogramResolver struct {
+	h *compute.Histogram
+}
+
+func (c *computeHistogramResolver) Buckets() []*computeHistogramBucketResolver {
+	buckets := c.h.Buckets()
+	result := make([]*computeHistogramBucketResolver, 0, len(buckets))
+	for _, b := range buckets {
+		result = append(result, &computeHistogramBucketResolver{b: b})
+	}
+	return result
+}
+
+type computeHistogramBucketResolver struct {
+	b compute.Bucket
+}
+
+func (r *computeHistogramBucketResolver) Value() string { return r.b.Value }
+func (r *computeHistogramBucketResolver) Count() int32  { return int32(r.b.Count) }
+
+// ComputeGroups GQL result resolver definitions.
+
+type computeGroupsResolver struct {
+	g               *compute.Groups
+	getRepoResolver func(types.MinimalRepo) *RepositoryResolver
+}
+
+func (c *computeGroupsResolver) Groups() []*computeGroupResolver {
+	values := c.g.Values()
+	result := make([]*computeGroupResolver, 0, len(values))
+	for _, v := range values {
+		result = append(result, &computeGroupResolver{v: v, getRepoResolver: c.getRepoResolver})
+	}
+	return result
+}
+
+type computeGroupResolver struct {
+	v               compute.GroupValue
+	getRepoResolver func(types.MinimalRepo) *RepositoryResolver
+}
+
+func (r *computeGroupResolver) Value() string { return r.v.Value }
+func (r *computeGroupResolver) Count() int32  { return int32(r.v.Count) }
+
+func (r *computeGroupResolver) Files() []*computeGroupFileResolver {
+	result := make([]*computeGroupFileResolver, 0, len(r.v.Files))
+	for _, f := range r.v.Files {
+		result = append(result, &computeGroupFileResolver{repository: r.getRepoResolver(f.Repo), f: f})
+	}
+	return result
+}
+
+type computeGroupFileResolver struct {
+	repository *RepositoryResolver
+	f          compute.GroupFile
+}
+
+func (r *computeGroupFileResolver) Repository() *RepositoryResolver { return r.repository }
+func (r *computeGroupFileResolver) Commit() string                  { return r.f.Commit }
+func (r *computeGroupFileResolver) Path() string                    { return r.f.Path }
+
 // Definitions required by https://github.com/graph-gophers/graphql-go to resolve
 // a union type in GraphQL.
 
@@ -145,6 +208,16 @@ func (r *computeResultResolver) ToComputeText() (*computeTextResolver, bool) {
 	return res, ok
 }
 
+func (r *computeResultResolver) ToComputeHistogram() (*computeHistogramResolver, bool) {
+	res, ok := r.result.(*computeHistogramResolver)
+	return res, ok
+}
+
+func (r *computeResultResolver) ToComputeGroups() (*computeGroupsResolver, bool) {
+	res, ok := r.result.(*computeGroupsResolver)
+	return res, ok
+}
+
 func toComputeMatchContextResolver(fm *result.FileMatch, mc *compute.MatchContext, repository *RepositoryResolver) *computeMatchContextResolver {
 	var computeMatches []*computeMatchResolver
 	for _, m := range mc.Matches {
@@ -195,6 +268,11 @@ func toResultResolverList(ctx context.Context, cmd compute.Command, matches []re
 		return resolver
 	}
 
+	// Histograms and groups are not per file: values for all matches are
+	// merged into a single result.
+	var histogram *compute.Histogram
+	var groups *compute.Groups
+
 	results := make([]*computeResultResolver, 0, len(matches))
 	for _, m := range matches {
 		if fm, ok := m.(*result.FileMatch); ok {
@@ -202,10 +280,33 @@ func toResultResolverList(ctx context.Context, cmd compute.Command, matches []re
 			if err != nil {
 				return nil, err
 			}
+			if h, ok := result.(*compute.Histogram); ok {
+				if histogram == nil {
+					histogram = &compute.Histogram{}
+				}
+				histogram.Merge(h)
+				continue
+			}
+			if g, ok := result.(*compute.Groups); ok {
+				if groups == nil {
+					groups = &compute.Groups{}
+				}
+				groups.Merge(g)
+				continue
+			}
 			repoResolver := getRepoResolver(fm.Repo, "")
 			results = append(results, toComputeResultResolver(fm, result, repoResolver))
 		}
 	}
+	if histogram != nil {
+		results = append(results, &computeResultResolver{result: &computeHistogramResolver{h: histogram}})
+	}
+	if groups != nil {
+		results = append(results, &computeResultResolver{result: &computeGroupsResolver{
+			g:               groups,
+			getRepoResolver: func(repo types.MinimalRepo) *RepositoryResolver { return getRepoResolver(repo, "") },
+		}})
+	}
 	return results, nil
 }
 
@@ -0,0 +1,204 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+type gitserverExecAuditLogsArgs struct {
+	graphqlutil.ConnectionArgs
+	After          *string
+	RepositoryName *string
+	Actor          *string
+	Since          *time.Time
+	Until          *time.Time
+}
+
+// toListOpts transforms the GraphQL gitserverExecAuditLogsArgs into options
+// that can be provided to the GitserverExecAuditLogStore's Count and List
+// methods.
+func (args *gitserverExecAuditLogsArgs) toListOpts() (database.GitserverExecAuditLogListOpts, error) {
+	opts := database.GitserverExecAuditLogListOpts{
+		Since: args.Since,
+		Until: args.Until,
+	}
+
+	if args.First != nil {
+		opts.Limit = int(*args.First)
+	} else {
+		opts.Limit = 50
+	}
+
+	if args.After != nil {
+		var err error
+		opts.Cursor, err = strconv.ParseInt(*args.After, 10, 64)
+		if err != nil {
+			return opts, errors.Wrap(err, "parsing the after cursor")
+		}
+	}
+
+	if args.RepositoryName != nil {
+		opts.Repo = api.RepoName(*args.RepositoryName)
+	}
+	if args.Actor != nil {
+		opts.Actor = *args.Actor
+	}
+
+	return opts, nil
+}
+
+// GitserverExecAuditLogs returns the audit log of git commands run by
+// gitserver.
+func (r *schemaResolver) GitserverExecAuditLogs(ctx context.Context, args *gitserverExecAuditLogsArgs) (*gitserverExecAuditLogConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins can access the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	return &gitserverExecAuditLogConnectionResolver{
+		db:    r.db,
+		args:  args,
+		store: database.GitserverExecAuditLogs(r.db),
+	}, nil
+}
+
+type gitserverExecAuditLogConnectionResolver struct {
+	db    database.DB
+	args  *gitserverExecAuditLogsArgs
+	store *database.GitserverExecAuditLogStore
+
+	once sync.Once
+	logs []*types.GitserverExecAuditLog
+	next int64
+	err  error
+}
+
+func (r *gitserverExecAuditLogConnectionResolver) Nodes(ctx context.Context) ([]*gitserverExecAuditLogResolver, error) {
+	logs, _, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*gitserverExecAuditLogResolver, len(logs))
+	for i, log := range logs {
+		nodes[i] = &gitserverExecAuditLogResolver{db: r.db, log: log}
+	}
+	return nodes, nil
+}
+
+func (r *gitserverExecAuditLogConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	opts, err := r.args.toListOpts()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.store.Count(ctx, opts)
+	return int32(count), err
+}
+
+func (r *gitserverExecAuditLogConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	_, next, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if next == 0 {
+		return graphqlutil.HasNextPage(false), nil
+	}
+	return graphqlutil.NextPageCursor(fmt.Sprint(next)), nil
+}
+
+func (r *gitserverExecAuditLogConnectionResolver) compute(ctx context.Context) ([]*types.GitserverExecAuditLog, int64, error) {
+	r.once.Do(func() {
+		r.err = func() error {
+			opts, err := r.args.toListOpts()
+			if err != nil {
+				return err
+			}
+
+			r.logs, r.next, err = r.store.List(ctx, opts)
+			return err
+		}()
+	})
+
+	return r.logs, r.next, r.err
+}
+
+type gitserverExecAuditLogResolver struct {
+	db  database.DB
+	log *types.GitserverExecAuditLog
+}
+
+func (r *gitserverExecAuditLogResolver) Actor() string {
+	return r.log.Actor
+}
+
+func (r *gitserverExecAuditLogResolver) User(ctx context.Context) (*UserResolver, error) {
+	id, err := strconv.ParseInt(r.log.Actor, 10, 32)
+	if err != nil || id == 0 {
+		// Internal or anonymous actor.
+		return nil, nil
+	}
+
+	user, err := UserByIDInt32(ctx, r.db, int32(id))
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *gitserverExecAuditLogResolver) Client() string {
+	return r.log.Client
+}
+
+func (r *gitserverExecAuditLogResolver) Gitserver() string {
+	return r.log.Gitserver
+}
+
+func (r *gitserverExecAuditLogResolver) RepositoryName() string {
+	return string(r.log.Repo)
+}
+
+func (r *gitserverExecAuditLogResolver) Command() string {
+	return r.log.Command
+}
+
+func (r *gitserverExecAuditLogResolver) Arguments() []string {
+	return r.log.Args
+}
+
+func (r *gitserverExecAuditLogResolver) Status() string {
+	return r.log.Status
+}
+
+func (r *gitserverExecAuditLogResolver) ExitStatus() *int32 {
+	if r.log.ExitStatus == nil {
+		return nil
+	}
+	exitStatus := int32(*r.log.ExitStatus)
+	return &exitStatus
+}
+
+func (r *gitserverExecAuditLogResolver) Error() *string {
+	return r.log.Error
+}
+
+func (r *gitserverExecAuditLogResolver) DurationMilliseconds() int32 {
+	return int32(r.log.Duration.Milliseconds())
+}
+
+func (r *gitserverExecAuditLogResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.log.CreatedAt}
+}
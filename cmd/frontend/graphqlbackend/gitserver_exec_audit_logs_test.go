@@ -0,0 +1,116 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbmock"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestGitserverExecAuditLogsArgs(t *testing.T) {
+	var (
+		now   = time.Date(2021, 11, 1, 18, 25, 10, 0, time.UTC)
+		later = now.Add(1 * time.Hour)
+	)
+
+	t.Run("success", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			input gitserverExecAuditLogsArgs
+			want  database.GitserverExecAuditLogListOpts
+		}{
+			"no arguments": {
+				input: gitserverExecAuditLogsArgs{},
+				want: database.GitserverExecAuditLogListOpts{
+					Limit: 50,
+				},
+			},
+			"all arguments": {
+				input: gitserverExecAuditLogsArgs{
+					ConnectionArgs: graphqlutil.ConnectionArgs{
+						First: int32Ptr(25),
+					},
+					After:          stringPtr("40"),
+					RepositoryName: stringPtr("github.com/foo/bar"),
+					Actor:          stringPtr("internal"),
+					Since:          timePtr(now),
+					Until:          timePtr(later),
+				},
+				want: database.GitserverExecAuditLogListOpts{
+					Limit:  25,
+					Cursor: 40,
+					Repo:   "github.com/foo/bar",
+					Actor:  "internal",
+					Since:  timePtr(now),
+					Until:  timePtr(later),
+				},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				have, err := tc.input.toListOpts()
+				assert.Nil(t, err)
+				assert.Equal(t, tc.want, have)
+			})
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, input := range []string{"", "-", "foo"} {
+			t.Run(input, func(t *testing.T) {
+				_, err := (&gitserverExecAuditLogsArgs{After: &input}).toListOpts()
+				assert.NotNil(t, err)
+			})
+		}
+	})
+}
+
+func TestGitserverExecAuditLogs(t *testing.T) {
+	for name, tc := range map[string]struct {
+		user *types.User
+		want error
+	}{
+		"unauthenticated user": {nil, backend.ErrNotAuthenticated},
+		"regular user":         {&types.User{}, backend.ErrMustBeSiteAdmin},
+		"admin user":           {&types.User{SiteAdmin: true}, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			users := dbmock.NewMockUserStore()
+			users.GetByCurrentAuthUserFunc.SetDefaultReturn(tc.user, nil)
+
+			db := dbmock.NewMockDB()
+			db.UsersFunc.SetDefaultReturn(users)
+
+			_, err := newSchemaResolver(db).GitserverExecAuditLogs(context.Background(), &gitserverExecAuditLogsArgs{})
+			if tc.want == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGitserverExecAuditLogResolver(t *testing.T) {
+	exitStatus := 128
+	r := &gitserverExecAuditLogResolver{log: &types.GitserverExecAuditLog{
+		Actor:      "internal",
+		Command:    "log",
+		Args:       []string{"log", "-n", "1"},
+		Status:     "128",
+		ExitStatus: &exitStatus,
+		Duration:   1500 * time.Millisecond,
+	}}
+
+	user, err := r.User(context.Background())
+	assert.Nil(t, err)
+	assert.Nil(t, user)
+	assert.Equal(t, int32(128), *r.ExitStatus())
+	assert.Equal(t, int32(1500), r.DurationMilliseconds())
+	assert.Equal(t, []string{"log", "-n", "1"}, r.Arguments())
+}
@@ -2,6 +2,7 @@ package graphqlbackend
 
 import (
 	"context"
+	"net/url"
 
 	"github.com/cockroachdb/errors"
 	"github.com/graph-gophers/graphql-go"
@@ -56,8 +57,11 @@ func (r *schemaResolver) savedSearchByID(ctx context.Context, id graphql.ID) (*s
 			Query:           ss.Config.Query,
 			Notify:          ss.Config.Notify,
 			NotifySlack:     ss.Config.NotifySlack,
+			NotifyWebhook:   ss.Config.NotifyWebhook,
 			UserID:          ss.Config.UserID,
 			SlackWebhookURL: ss.Config.SlackWebhookURL,
+			WebhookURL:      ss.Config.WebhookURL,
+			WebhookSecret:   ss.Config.WebhookSecret,
 		},
 	}
 	return savedSearch, nil
@@ -89,6 +93,60 @@ func (r savedSearchResolver) Namespace(ctx context.Context) (*NamespaceResolver,
 
 func (r savedSearchResolver) SlackWebhookURL() *string { return r.s.SlackWebhookURL }
 
+func (r savedSearchResolver) NotifyWebhook() bool { return r.s.NotifyWebhook }
+
+func (r savedSearchResolver) WebhookURL() *string { return r.s.WebhookURL }
+
+func (r savedSearchResolver) Deliveries(ctx context.Context, args *struct{ First int32 }) ([]*savedSearchDeliveryResolver, error) {
+	// 🚨 SECURITY: Only the owner of the saved search may view its delivery log.
+	if r.s.UserID != actor.FromContext(ctx).UID {
+		return nil, &backend.InsufficientAuthorizationError{
+			Message: "current user has insufficient privileges to view saved search deliveries",
+		}
+	}
+
+	limit := int(args.First)
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	deliveries, err := database.SavedSearchDeliveries(r.db).List(ctx, r.s.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*savedSearchDeliveryResolver, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resolvers = append(resolvers, &savedSearchDeliveryResolver{d: delivery})
+	}
+	return resolvers, nil
+}
+
+type savedSearchDeliveryResolver struct {
+	d *types.SavedSearchDelivery
+}
+
+func (r *savedSearchDeliveryResolver) Kind() string { return r.d.Kind }
+
+func (r *savedSearchDeliveryResolver) Event() string { return r.d.Event }
+
+func (r *savedSearchDeliveryResolver) Attempts() int32 { return int32(r.d.Attempts) }
+
+func (r *savedSearchDeliveryResolver) StatusCode() *int32 {
+	if r.d.StatusCode == nil {
+		return nil
+	}
+	statusCode := int32(*r.d.StatusCode)
+	return &statusCode
+}
+
+func (r *savedSearchDeliveryResolver) Error() *string { return r.d.Error }
+
+func (r *savedSearchDeliveryResolver) DurationMilliseconds() int32 {
+	return int32(r.d.Duration.Milliseconds())
+}
+
+func (r *savedSearchDeliveryResolver) CreatedAt() DateTime { return DateTime{Time: r.d.CreatedAt} }
+
 func (r *schemaResolver) toSavedSearchResolver(entry types.SavedSearch) *savedSearchResolver {
 	return &savedSearchResolver{db: r.db, s: entry}
 }
@@ -133,12 +191,15 @@ func (r *schemaResolver) SendSavedSearchTestNotification(ctx context.Context, ar
 }
 
 func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
-	Description string
-	Query       string
-	NotifyOwner bool
-	NotifySlack bool
-	OrgID       *graphql.ID
-	UserID      *graphql.ID
+	Description   string
+	Query         string
+	NotifyOwner   bool
+	NotifySlack   bool
+	NotifyWebhook bool
+	WebhookURL    *string
+	WebhookSecret *string
+	OrgID         *graphql.ID
+	UserID        *graphql.ID
 }) (*savedSearchResolver, error) {
 	if args.UserID == nil {
 		return nil, errors.New("a saved search must have a user owner")
@@ -157,12 +218,19 @@ func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
 		return nil, errMissingPatternType
 	}
 
+	if err := validateSavedSearchWebhook(args.NotifyWebhook, args.WebhookURL); err != nil {
+		return nil, err
+	}
+
 	ss, err := r.db.SavedSearches().Create(ctx, &types.SavedSearch{
-		Description: args.Description,
-		Query:       args.Query,
-		Notify:      args.NotifyOwner,
-		NotifySlack: args.NotifySlack,
-		UserID:      uid,
+		Description:   args.Description,
+		Query:         args.Query,
+		Notify:        args.NotifyOwner,
+		NotifySlack:   args.NotifySlack,
+		NotifyWebhook: args.NotifyWebhook,
+		UserID:        uid,
+		WebhookURL:    nonEmptyStringPtr(args.WebhookURL),
+		WebhookSecret: nonEmptyStringPtr(args.WebhookSecret),
 	})
 	if err != nil {
 		return nil, err
@@ -172,13 +240,16 @@ func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
 }
 
 func (r *schemaResolver) UpdateSavedSearch(ctx context.Context, args *struct {
-	ID          graphql.ID
-	Description string
-	Query       string
-	NotifyOwner bool
-	NotifySlack bool
-	OrgID       *graphql.ID
-	UserID      *graphql.ID
+	ID            graphql.ID
+	Description   string
+	Query         string
+	NotifyOwner   bool
+	NotifySlack   bool
+	NotifyWebhook bool
+	WebhookURL    *string
+	WebhookSecret *string
+	OrgID         *graphql.ID
+	UserID        *graphql.ID
 }) (*savedSearchResolver, error) {
 	if args.UserID == nil {
 		return nil, errors.New("a saved search must have a user owner")
@@ -202,13 +273,32 @@ func (r *schemaResolver) UpdateSavedSearch(ctx context.Context, args *struct {
 		return nil, errMissingPatternType
 	}
 
+	if err := validateSavedSearchWebhook(args.NotifyWebhook, args.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	webhookURL := nonEmptyStringPtr(args.WebhookURL)
+	webhookSecret := nonEmptyStringPtr(args.WebhookSecret)
+	if webhookURL != nil && args.WebhookSecret == nil {
+		// The secret is never returned to clients, so keep the existing one
+		// unless a new one is supplied. An empty string removes it.
+		existing, err := r.db.SavedSearches().GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		webhookSecret = existing.Config.WebhookSecret
+	}
+
 	ss, err := r.db.SavedSearches().Update(ctx, &types.SavedSearch{
-		ID:          id,
-		Description: args.Description,
-		Query:       args.Query,
-		Notify:      args.NotifyOwner,
-		NotifySlack: args.NotifySlack,
-		UserID:      uid,
+		ID:            id,
+		Description:   args.Description,
+		Query:         args.Query,
+		Notify:        args.NotifyOwner,
+		NotifySlack:   args.NotifySlack,
+		NotifyWebhook: args.NotifyWebhook,
+		UserID:        uid,
+		WebhookURL:    webhookURL,
+		WebhookSecret: webhookSecret,
 	})
 	if err != nil {
 		return nil, err
@@ -245,4 +335,28 @@ func queryHasPatternType(query string) bool {
 	return patternType.Match([]byte(query))
 }
 
+// validateSavedSearchWebhook checks that a usable webhook URL is supplied when
+// webhook notifications are enabled.
+func validateSavedSearchWebhook(notifyWebhook bool, webhookURL *string) error {
+	if webhookURL == nil || *webhookURL == "" {
+		if notifyWebhook {
+			return errors.New("a webhook URL is required to enable webhook notifications")
+		}
+		return nil
+	}
+
+	u, err := url.Parse(*webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid webhook URL %q: must be an absolute http or https URL", *webhookURL)
+	}
+	return nil
+}
+
+func nonEmptyStringPtr(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
 var errMissingPatternType = errors.New("a `patternType:` filter is required in the query for all saved searches. `patternType` can be \"literal\", \"regexp\" or \"structural\"")
@@ -163,12 +163,15 @@ func TestCreateSavedSearch(t *testing.T) {
 
 	userID := MarshalUserID(key)
 	savedSearches, err := (&schemaResolver{db: db}).CreateSavedSearch(ctx, &struct {
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
 	}{Description: "test query", Query: "test type:diff patternType:regexp", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err != nil {
 		t.Fatal(err)
@@ -190,12 +193,15 @@ func TestCreateSavedSearch(t *testing.T) {
 
 	// Ensure create saved search errors when patternType is not provided in the query.
 	_, err = (&schemaResolver{db: db}).CreateSavedSearch(ctx, &struct {
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
 	}{Description: "test query", Query: "test type:diff", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err == nil {
 		t.Error("Expected error for createSavedSearch when query does not provide a patternType: field.")
@@ -227,13 +233,16 @@ func TestUpdateSavedSearch(t *testing.T) {
 
 	userID := MarshalUserID(key)
 	savedSearches, err := (&schemaResolver{db: db}).UpdateSavedSearch(ctx, &struct {
-		ID          graphql.ID
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		ID            graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
 	}{ID: marshalSavedSearchID(key), Description: "updated query description", Query: "test type:diff patternType:regexp", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err != nil {
 		t.Fatal(err)
@@ -256,13 +265,16 @@ func TestUpdateSavedSearch(t *testing.T) {
 
 	// Ensure update saved search errors when patternType is not provided in the query.
 	_, err = (&schemaResolver{db: db}).UpdateSavedSearch(ctx, &struct {
-		ID          graphql.ID
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		ID            graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
 	}{ID: marshalSavedSearchID(key), Description: "updated query description", Query: "test type:diff", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err == nil {
 		t.Error("Expected error for updateSavedSearch when query does not provide a patternType: field.")
@@ -308,3 +320,69 @@ func TestDeleteSavedSearch(t *testing.T) {
 
 	mockrequire.Called(t, ss.DeleteFunc)
 }
+
+func TestUpdateSavedSearchWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	key := int32(1)
+	users := dbmock.NewMockUserStore()
+	users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{SiteAdmin: true, ID: key}, nil)
+
+	existingSecret := "s3cr3t"
+	ss := dbmock.NewMockSavedSearchStore()
+	ss.GetByIDFunc.SetDefaultReturn(&api.SavedQuerySpecAndConfig{
+		Config: api.ConfigSavedQuery{UserID: key, WebhookSecret: &existingSecret},
+	}, nil)
+	ss.UpdateFunc.SetDefaultHook(func(ctx context.Context, savedSearch *types.SavedSearch) (*types.SavedSearch, error) {
+		return savedSearch, nil
+	})
+
+	db := dbmock.NewMockDB()
+	db.UsersFunc.SetDefaultReturn(users)
+	db.SavedSearchesFunc.SetDefaultReturn(ss)
+
+	update := func(notifyWebhook bool, webhookURL, webhookSecret *string) (*savedSearchResolver, error) {
+		userID := MarshalUserID(key)
+		return (&schemaResolver{db: db}).UpdateSavedSearch(ctx, &struct {
+			ID            graphql.ID
+			Description   string
+			Query         string
+			NotifyOwner   bool
+			NotifySlack   bool
+			NotifyWebhook bool
+			WebhookURL    *string
+			WebhookSecret *string
+			OrgID         *graphql.ID
+			UserID        *graphql.ID
+		}{ID: marshalSavedSearchID(key), Description: "test", Query: "test type:diff patternType:regexp", NotifyWebhook: notifyWebhook, WebhookURL: webhookURL, WebhookSecret: webhookSecret, UserID: &userID})
+	}
+
+	webhookURL := "https://example.com/hook"
+	savedSearch, err := update(true, &webhookURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !savedSearch.NotifyWebhook() || *savedSearch.WebhookURL() != webhookURL {
+		t.Errorf("unexpected webhook settings %+v", savedSearch.s)
+	}
+	if secret := savedSearch.s.WebhookSecret; secret == nil || *secret != existingSecret {
+		t.Errorf("expected existing secret to be kept, got %v", secret)
+	}
+
+	empty := ""
+	savedSearch, err = update(true, &webhookURL, &empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if savedSearch.s.WebhookSecret != nil {
+		t.Errorf("expected secret to be removed, got %v", *savedSearch.s.WebhookSecret)
+	}
+
+	if _, err := update(true, nil, nil); err == nil {
+		t.Error("expected error enabling webhook notifications without a URL")
+	}
+	invalidURL := "ftp://example.com/hook"
+	if _, err := update(true, &invalidURL, nil); err == nil {
+		t.Error("expected error for non-http webhook URL")
+	}
+}
@@ -442,7 +442,8 @@ func LogSearchLatency(ctx context.Context, db database.DB, si *run.SearchInputs,
 		} else {
 			// No search pattern or file: is specified, assume repo.
 			// This includes accounting for searches of fields that
-			// specify repohasfile: and repohascommitafter:.
+			// specify repohasfile:, repohascommitafter:, repohastopic: and
+			// repohasmeta:.
 			types = append(types, "repo")
 		}
 	}
@@ -504,6 +505,8 @@ func (r *searchResolver) toRepoOptions(q query.Q, opts resolveRepositoriesOpts)
 	visibility := query.ParseVisibility(visibilityStr)
 
 	commitAfter, _ := q.StringValue(query.FieldRepoHasCommitAfter)
+	hasTopics, _ := q.StringValues(query.FieldRepoHasTopic)
+	hasMeta, _ := q.StringValues(query.FieldRepoHasMeta)
 	searchContextSpec, _ := q.StringValue(query.FieldContext)
 
 	var CacheLookup bool
@@ -523,6 +526,8 @@ func (r *searchResolver) toRepoOptions(q query.Q, opts resolveRepositoriesOpts)
 		NoArchived:        archived == query.No,
 		Visibility:        visibility,
 		CommitAfter:       commitAfter,
+		HasTopics:         hasTopics,
+		HasMeta:           hasMeta,
 		Query:             q,
 		Limit:             opts.limit,
 		CacheLookup:       CacheLookup,
@@ -1089,7 +1094,9 @@ func (r *searchResolver) resultsStreaming(ctx context.Context) (*SearchResultsRe
 	if sp, _ := r.Plan.ToParseTree().StringValue(query.FieldSelect); sp != "" {
 		// Ensure downstream events sent on the stream are processed by `select:`.
 		selectPath, _ := filter.SelectPathFromString(sp) // Invariant: error already checked
-		r.stream = streaming.WithSelect(r.stream, selectPath)
+		var flush func()
+		r.stream, flush = streaming.WithSelect(r.stream, selectPath)
+		defer flush()
 	}
 	sr, err := r.resultsRecursive(ctx, r.Plan)
 	srr := r.resultsToResolver(sr)
@@ -22,6 +22,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 	"github.com/sourcegraph/sourcegraph/internal/txemail"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
@@ -237,6 +238,35 @@ func serveSavedQueriesDeleteInfo(db database.DB) func(w http.ResponseWriter, r *
 	}
 }
 
+func serveSavedQueriesLogDelivery(db database.DB) func(w http.ResponseWriter, r *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var delivery *api.SavedQueryDelivery
+		err := json.NewDecoder(r.Body).Decode(&delivery)
+		if err != nil {
+			return errors.Wrap(err, "Decode")
+		}
+		entry := &types.SavedSearchDelivery{
+			SavedSearchID: delivery.SavedSearchID,
+			Kind:          delivery.Kind,
+			Event:         delivery.Event,
+			Attempts:      delivery.Attempts,
+			Duration:      delivery.Duration,
+		}
+		if delivery.StatusCode != 0 {
+			entry.StatusCode = &delivery.StatusCode
+		}
+		if delivery.Error != "" {
+			entry.Error = &delivery.Error
+		}
+		if err := database.SavedSearchDeliveries(db).Create(r.Context(), entry); err != nil {
+			return errors.Wrap(err, "SavedSearchDeliveries.Create")
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("OK"))
+		return nil
+	}
+}
+
 func serveSettingsGetForSubject(db database.DB) func(w http.ResponseWriter, r *http.Request) error {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		var subject api.SettingsSubject
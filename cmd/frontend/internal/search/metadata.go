@@ -8,6 +8,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
 	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
@@ -30,3 +31,12 @@ func getEventRepoMetadata(ctx context.Context, db dbutil.DB, event streaming.Sea
 	}
 	return repoMetadata, nil
 }
+
+// hasRepoMetadata returns true if repoMetadata contains the repo of match. A
+// missing repo is a sign that we have searched repos that the actor shouldn't
+// have access to.
+func hasRepoMetadata(match result.Match, repoMetadata map[api.RepoID]*types.SearchedRepo) bool {
+	repo := match.RepoName()
+	md, ok := repoMetadata[repo.ID]
+	return ok && md.Name == repo.Name
+}
@@ -115,6 +115,29 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Globbing: false, // TODO
 	}
 
+	var aggregator *streaming.Aggregator
+	if args.Aggregate != "" {
+		aggregator = &streaming.Aggregator{Mode: args.Aggregate}
+	}
+	sendAggregate := func() {
+		if aggregator == nil {
+			return
+		}
+		buckets, other := aggregator.Compute()
+		event := streamhttp.EventAggregate{
+			Mode:       string(aggregator.Mode),
+			Buckets:    make([]streamhttp.EventAggregateBucket, 0, len(buckets)),
+			OtherCount: other,
+		}
+		for _, b := range buckets {
+			event.Buckets = append(event.Buckets, streamhttp.EventAggregateBucket{
+				Label: b.Label,
+				Count: b.Count,
+			})
+		}
+		_ = eventWriter.Event("aggregate", event)
+	}
+
 	// Store marshalled matches and flush periodically or when we go over
 	// 32kb. 32kb chosen to be smaller than bufio.MaxTokenSize. Note: we can
 	// still write more than that.
@@ -130,6 +153,10 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if progress.Dirty {
 			sendProgress()
 		}
+
+		if aggregator != nil && aggregator.Dirty {
+			sendAggregate()
+		}
 	}
 	flushTicker := time.NewTicker(h.flushTickerInternal)
 	defer flushTicker.Stop()
@@ -142,6 +169,27 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		progress.Update(event)
 		filters.Update(event)
 
+		var repoMetadata map[api.RepoID]*types.SearchedRepo
+		if aggregator != nil {
+			// Aggregate before truncating so that buckets count all matches.
+			// This needs the repo metadata of all matches, so that we only
+			// count the matches the actor has access to.
+			md, err := getEventRepoMetadata(ctx, h.db, event)
+			if err != nil {
+				log15.Error("failed to get repo metadata", "error", err)
+				return
+			}
+			repoMetadata = md
+
+			accessible := make([]result.Match, 0, len(event.Results))
+			for _, match := range event.Results {
+				if hasRepoMetadata(match, repoMetadata) {
+					accessible = append(accessible, match)
+				}
+			}
+			aggregator.Update(streaming.SearchEvent{Results: accessible})
+		}
+
 		// Truncate the event to the match limit before fetching repo metadata
 		for i, match := range event.Results {
 			if display <= 0 {
@@ -152,10 +200,13 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			display = match.Limit(display)
 		}
 
-		repoMetadata, err := getEventRepoMetadata(ctx, h.db, event)
-		if err != nil {
-			log15.Error("failed to get repo metadata", "error", err)
-			return
+		if aggregator == nil {
+			md, err := getEventRepoMetadata(ctx, h.db, event)
+			if err != nil {
+				log15.Error("failed to get repo metadata", "error", err)
+				return
+			}
+			repoMetadata = md
 		}
 
 		if progress.Stats.Repos == nil {
@@ -168,7 +219,7 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// Don't send matches which we cannot map to a repo the actor has access to. This
 			// check is expected to always pass. Missing metadata is a sign that we have
 			// searched repos that user shouldn't have access to.
-			if md, ok := repoMetadata[repo.ID]; !ok || md.Name != repo.Name {
+			if !hasRepoMetadata(match, repoMetadata) {
 				continue
 			}
 
@@ -212,6 +263,9 @@ LOOP:
 
 	matchesFlush()
 
+	// Send the final aggregate, even if unchanged, so clients can rely on it.
+	sendAggregate()
+
 	// Send dynamic filters once.
 	if filters := filters.Compute(); len(filters) > 0 {
 		buf := make([]streamhttp.EventFilter, 0, len(filters))
@@ -342,6 +396,10 @@ type args struct {
 	PatternType string
 	Display     int
 
+	// Aggregate, if set, requests bucketed counts of all matches to be
+	// streamed as "aggregate" events.
+	Aggregate streaming.AggregateMode
+
 	// Optional decoration parameters for server-side rendering a result set
 	// or subset. Decorations may specify, e.g., highlighting results with
 	// HTML markup up-front, and/or including context lines around file results.
@@ -376,6 +434,12 @@ func parseURLQuery(q url.Values) (*args, error) {
 		return nil, errors.Errorf("display must be an integer, got %q: %w", display, err)
 	}
 
+	if aggregate := get("aggregate", ""); aggregate != "" {
+		if a.Aggregate, err = streaming.ParseAggregateMode(aggregate); err != nil {
+			return nil, err
+		}
+	}
+
 	decorationLimit := get("dl", "0")
 	if a.DecorationLimit, err = strconv.Atoi(decorationLimit); err != nil {
 		return nil, errors.Errorf("decorationLimit must be an integer, got %q: %w", decorationLimit, err)
@@ -576,6 +640,7 @@ func fromCommit(commit *result.CommitMatch, repoCache map[api.RepoID]*types.Sear
 		Repository: string(commit.Repo.Name),
 		Content:    content,
 		Ranges:     ranges,
+		Count:      commit.ProjectionCount,
 	}
 
 	if r, ok := repoCache[commit.Repo.ID]; ok {
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
@@ -209,6 +210,80 @@ func TestDisplayLimit(t *testing.T) {
 	}
 }
 
+func TestAggregateRepoAccess(t *testing.T) {
+	mock := &mockSearchResolver{
+		done: make(chan struct{}),
+	}
+
+	// The actor only has access to repo1.
+	database.Mocks.Repos.Metadata = func(ctx context.Context, ids ...api2.RepoID) (_ []*types.SearchedRepo, err error) {
+		res := make([]*types.SearchedRepo, 0, len(ids))
+		for _, id := range ids {
+			if id == 1 {
+				res = append(res, &types.SearchedRepo{ID: id, Name: "repo1"})
+			}
+		}
+		return res, nil
+	}
+	defer func() { database.Mocks.Repos.Metadata = nil }()
+
+	ts := httptest.NewServer(&streamHandler{
+		flushTickerInternal: 1 * time.Millisecond,
+		pingTickerInterval:  1 * time.Millisecond,
+		newSearchResolver: func(_ context.Context, _ database.DB, args *graphqlbackend.SearchArgs) (searchResolver, error) {
+			mock.c = args.Stream
+			q, err := query.Parse("foo", query.Literal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mock.inputs = &run.SearchInputs{
+				Query: q,
+			}
+			return mock, nil
+		}})
+	defer ts.Close()
+
+	req, _ := streamhttp.NewRequest(ts.URL, "foo")
+	q := req.URL.Query()
+	q.Add("aggregate", "repo")
+	q.Add("display", "0")
+	req.URL.RawQuery = q.Encode()
+
+	var aggregate *streamhttp.EventAggregate
+	decoder := streamhttp.FrontendStreamDecoder{
+		OnAggregate: func(a *streamhttp.EventAggregate) {
+			aggregate = a
+		},
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	g := errgroup.Group{}
+	g.Go(func() error {
+		return decoder.ReadAll(resp.Body)
+	})
+
+	mock.c.Send(streaming.SearchEvent{
+		Results: []result.Match{mkRepoMatch(1), mkRepoMatch(2)},
+	})
+	mock.Close()
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &streamhttp.EventAggregate{
+		Mode:    "repo",
+		Buckets: []streamhttp.EventAggregateBucket{{Label: "repo1", Count: 1}},
+	}
+	if diff := cmp.Diff(want, aggregate); diff != "" {
+		t.Fatalf("unexpected aggregate (-want +got):\n%s", diff)
+	}
+}
+
 func mkRepoMatch(id int) *result.RepoMatch {
 	return &result.RepoMatch{
 		ID:   api2.RepoID(id),
@@ -2,13 +2,13 @@
 package main // import "github.com/sourcegraph/sourcegraph/cmd/gitserver"
 
 import (
-	"container/list"
 	"context"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
 	"syscall"
 	"time"
 
@@ -51,6 +51,8 @@ var (
 	syncRepoStateInterval        = env.MustGetDuration("SRC_REPOS_SYNC_STATE_INTERVAL", 10*time.Minute, "Interval between state syncs")
 	syncRepoStateBatchSize       = env.MustGetInt("SRC_REPOS_SYNC_STATE_BATCH_SIZE", 500, "Number of upserts to perform per batch")
 	syncRepoStateUpsertPerSecond = env.MustGetInt("SRC_REPOS_SYNC_STATE_UPSERT_PER_SEC", 500, "The number of upserted rows allowed per second across all gitserver instances")
+	reconcileReplicasInterval    = env.MustGetDuration("SRC_REPOS_RECONCILE_REPLICAS_INTERVAL", 5*time.Minute, "Interval between reconciliations of secondary replicas")
+	execAuditLogFlushInterval    = env.MustGetDuration("SRC_EXEC_AUDIT_LOG_FLUSH_INTERVAL", 5*time.Second, "Interval between writes of the exec audit log")
 )
 
 func main() {
@@ -125,7 +127,7 @@ func main() {
 		},
 		Hostname:   hostname.Get(),
 		DB:         db,
-		CloneQueue: server.NewCloneQueue(list.New()),
+		CloneQueue: server.NewCloneQueue(),
 	}
 	gitserver.RegisterMetrics()
 
@@ -148,6 +150,8 @@ func main() {
 	go debugserver.NewServerRoutine(ready).Start()
 	go gitserver.Janitor(janitorInterval)
 	go gitserver.SyncRepoState(syncRepoStateInterval, syncRepoStateBatchSize, syncRepoStateUpsertPerSecond)
+	go gitserver.ReconcileReplicas(reconcileReplicasInterval)
+	go gitserver.WriteExecAuditLogs(execAuditLogFlushInterval)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -303,12 +307,70 @@ func getVCSSyncer(ctx context.Context, externalServiceStore database.ExternalSer
 			Client:       c.P4Client,
 			FusionConfig: configureFusionClient(c),
 		}, nil
+	case extsvc.TypeMercurial:
+		return &server.HgRepoSyncer{}, nil
+	case extsvc.TypeSubversion:
+		var c schema.SubversionConnection
+		if err := extractOptions(&c); err != nil {
+			return nil, err
+		}
+		return &server.SVNRepoSyncer{
+			StandardLayout: c.Layout != "none",
+			AuthorsMap:     c.Authors,
+		}, nil
 	case extsvc.TypeJVMPackages:
 		var c schema.JVMPackagesConnection
 		if err := extractOptions(c); err != nil {
 			return nil, err
 		}
 		return &server.JVMPackagesSyncer{Config: &c, DBStore: codeintelDB}, nil
+	case extsvc.TypeNPMPackages:
+		var c schema.NPMPackagesConnection
+		if err := extractOptions(&c); err != nil {
+			return nil, err
+		}
+		return &server.NPMPackagesSyncer{Config: &c}, nil
+	case extsvc.TypePythonPackages:
+		var c schema.PythonPackagesConnection
+		if err := extractOptions(&c); err != nil {
+			return nil, err
+		}
+		return &server.PythonPackagesSyncer{Config: &c, DBStore: codeintelDB}, nil
+	case extsvc.TypeGitHub, extsvc.TypeGitLab, extsvc.TypeBitbucketServer, extsvc.TypeOther:
+		// partialClone has the same shape in the configuration of all these
+		// code hosts.
+		var c struct {
+			PartialClone []*schema.OtherExternalServicePartialClone `json:"partialClone"`
+		}
+		if err := extractOptions(&c); err != nil {
+			// Partial clones are an optimization, so don't fail syncing.
+			log15.Warn("failed to get partial clone options, cloning fully", "repo", repo, "error", err)
+			return &server.GitRepoSyncer{}, nil
+		}
+		opts, err := partialCloneOptions(repo, c.PartialClone)
+		if err != nil {
+			log15.Warn("failed to get partial clone options, cloning fully", "repo", repo, "error", err)
+			return &server.GitRepoSyncer{}, nil
+		}
+		return &server.GitRepoSyncer{PartialClone: opts}, nil
 	}
 	return &server.GitRepoSyncer{}, nil
 }
+
+// partialCloneOptions returns the partial clone options of the first entry
+// whose pattern matches repo, or nil if repo should be cloned fully.
+func partialCloneOptions(repo api.RepoName, partialClone []*schema.OtherExternalServicePartialClone) (*server.PartialCloneOptions, error) {
+	for _, pc := range partialClone {
+		pattern, err := regexp.Compile(pc.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid partialClone pattern %q", pc.Pattern)
+		}
+		if pattern.MatchString(string(repo)) {
+			return &server.PartialCloneOptions{
+				BlobSizeLimit: pc.BlobSizeLimit,
+				SparsePaths:   pc.SparsePaths,
+			}, nil
+		}
+	}
+	return nil, nil
+}
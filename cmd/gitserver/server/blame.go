@@ -0,0 +1,292 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// blameIgnoreRevsFile is the conventional name of the file listing the
+// revisions git blame should ignore.
+const blameIgnoreRevsFile = ".git-blame-ignore-revs"
+
+var (
+	blameRunning = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "src_gitserver_blame_running",
+		Help: "number of blames currently running",
+	})
+	blameDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "src_gitserver_blame_duration_seconds",
+		Help:    "gitserver blame duration",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"error"})
+)
+
+// handleBlame streams the blame of a file. Hunks are sent in "hunks" events
+// as git computes them, followed by a single "done" event.
+func (s *Server) handleBlame(w http.ResponseWriter, r *http.Request) {
+	var req protocol.BlameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.HasPrefix(string(req.Commit), "-") {
+		http.Error(w, fmt.Sprintf("invalid git revision spec %q (begins with '-')", req.Commit), http.StatusBadRequest)
+		return
+	}
+	req.Repo = protocol.NormalizeRepo(req.Repo)
+
+	tr, ctx := trace.New(r.Context(), "blame", string(req.Repo))
+	defer tr.Finish()
+	tr.LogFields(
+		otlog.String("commit", string(req.Commit)),
+		otlog.String("path", req.Path),
+		otlog.Bool("ignore_revs", req.IgnoreRevs),
+	)
+
+	dir := s.dir(req.Repo)
+	if !repoCloned(dir) {
+		payload := protocol.NotFoundPayload{}
+		if !conf.Get().DisableAutoGitUpdates {
+			payload.CloneProgress, payload.CloneInProgress = s.locker.Status(dir)
+			if !payload.CloneInProgress {
+				if cloneProgress, err := s.cloneRepo(ctx, req.Repo, &cloneOptions{Priority: clonePriorityInteractive}); err != nil {
+					log15.Debug("error starting repo clone", "repo", req.Repo, "err", err)
+				} else {
+					payload.CloneInProgress = true
+					payload.CloneProgress = cloneProgress
+				}
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(&payload)
+		return
+	}
+
+	if !conf.Get().DisableAutoGitUpdates {
+		_ = s.ensureRevision(ctx, req.Repo, string(req.Commit), dir)
+	}
+
+	blameStart := time.Now()
+	blameRunning.Inc()
+	defer blameRunning.Dec()
+
+	eventWriter, err := streamhttp.NewWriter(w)
+	if err != nil {
+		tr.SetError(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hunksBuf := streamhttp.NewJSONArrayBuf(8*1024, func(data []byte) error {
+		return eventWriter.EventBytes("hunks", data)
+	})
+
+	blameErr := blame(ctx, dir, &req, hunksBuf)
+	if writeErr := eventWriter.Event("done", protocol.NewBlameEventDone(blameErr)); writeErr != nil {
+		log15.Error("failed to send done event", "error", writeErr)
+	}
+	tr.SetError(blameErr)
+	blameDuration.
+		WithLabelValues(strconv.FormatBool(blameErr != nil)).
+		Observe(time.Since(blameStart).Seconds())
+}
+
+// blame runs git blame --incremental for req, appending hunks to hunksBuf as
+// git emits them. The first hunk is flushed immediately, later ones
+// periodically.
+func blame(ctx context.Context, dir GitDir, req *protocol.BlameRequest, hunksBuf *streamhttp.JSONArrayBuf) error {
+	args := []string{"blame", "--incremental", "-w"}
+	if req.StartLine != 0 || req.EndLine != 0 {
+		args = append(args, fmt.Sprintf("-L%d,%d", req.StartLine, req.EndLine))
+	}
+	if req.IgnoreRevs {
+		ignoreRevsPath, err := writeBlameIgnoreRevs(ctx, dir, req.Commit)
+		if err != nil {
+			return err
+		}
+		if ignoreRevsPath != "" {
+			defer os.Remove(ignoreRevsPath)
+			args = append(args, "--ignore-revs-file", ignoreRevsPath)
+		}
+	}
+	if req.Commit != "" {
+		args = append(args, string(req.Commit))
+	}
+	args = append(args, "--", req.Path)
+
+	g, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hunks := make(chan protocol.BlameHunk, 128)
+	g.Go(func() error {
+		defer close(hunks)
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", args...)
+		dir.Set(cmd)
+		cmd.Stderr = &stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+
+		parseErr := parseBlameIncremental(stdout, func(hunk protocol.BlameHunk) {
+			select {
+			case <-ctx.Done():
+			case hunks <- hunk:
+			}
+		})
+		if parseErr != nil {
+			// Drain stdout so git doesn't block writing to it.
+			_, _ = io.Copy(io.Discard, stdout)
+		}
+		if err := cmd.Wait(); err != nil {
+			return errors.Errorf("git command %v failed (stderr: %q): %s", args, stderr.Bytes(), err)
+		}
+		return parseErr
+	})
+
+	g.Go(func() error {
+		defer cancel()
+		defer hunksBuf.Flush()
+
+		flushTicker := time.NewTicker(50 * time.Millisecond)
+		defer flushTicker.Stop()
+
+		firstHunk := true
+		for {
+			select {
+			case hunk, ok := <-hunks:
+				if !ok {
+					return nil
+				}
+				_ = hunksBuf.Append(hunk) // EOF only
+
+				// Send immediately if this is the first hunk we've seen
+				if firstHunk {
+					_ = hunksBuf.Flush() // EOF only
+					firstHunk = false
+				}
+			case <-flushTicker.C:
+				_ = hunksBuf.Flush() // EOF only
+			}
+		}
+	})
+
+	return g.Wait()
+}
+
+// writeBlameIgnoreRevs writes the .git-blame-ignore-revs file of commit to a
+// temporary file, since git blame only reads it from the filesystem. It
+// returns an empty path if commit has no such file.
+func writeBlameIgnoreRevs(ctx context.Context, dir GitDir, commit api.CommitID) (string, error) {
+	rev := "HEAD"
+	if commit != "" {
+		rev = string(commit)
+	}
+	cmd := exec.CommandContext(ctx, "git", "show", rev+":"+blameIgnoreRevsFile)
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		// The file is optional.
+		return "", nil
+	}
+	return writeTempFile("blame-ignore-revs", out)
+}
+
+// parseBlameIncremental parses the output of git blame --incremental, calling
+// onHunk for each hunk. The commit details are only printed the first time a
+// commit is seen, so they are remembered for the following hunks.
+func parseBlameIncremental(r io.Reader, onHunk func(protocol.BlameHunk)) error {
+	commits := make(map[api.CommitID]protocol.BlameHunk)
+
+	var hunk *protocol.BlameHunk
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if hunk == nil {
+			// Hunk header: <sha> <orig-line> <final-line> <num-lines>
+			fields := strings.Split(line, " ")
+			if len(fields) != 4 {
+				return errors.Errorf("unexpected blame hunk header %q", line)
+			}
+			finalLine, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return errors.Errorf("unexpected blame hunk header %q", line)
+			}
+			nLines, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return errors.Errorf("unexpected blame hunk header %q", line)
+			}
+
+			hunk = &protocol.BlameHunk{}
+			if seen, ok := commits[api.CommitID(fields[0])]; ok {
+				*hunk = seen
+			}
+			hunk.CommitID = api.CommitID(fields[0])
+			hunk.StartLine = finalLine
+			hunk.EndLine = finalLine + nLines
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			key, value = line[:i], line[i+1:]
+		}
+		switch key {
+		case "author":
+			hunk.Author.Name = value
+		case "author-mail":
+			hunk.Author.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			authorTime, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.Errorf("failed to parse author-time %q", value)
+			}
+			hunk.Author.Date = time.Unix(authorTime, 0).UTC()
+		case "summary":
+			hunk.Message = value
+		case "filename":
+			// filename always ends a hunk.
+			hunk.Filename = value
+			commits[hunk.CommitID] = *hunk
+			onHunk(*hunk)
+			hunk = nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if hunk != nil {
+		return errors.Errorf("unexpected end of blame output in hunk for %s", hunk.CommitID)
+	}
+	return nil
+}
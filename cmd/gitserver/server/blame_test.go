@@ -0,0 +1,63 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestParseBlameIncremental(t *testing.T) {
+	// The details of commit a are only printed for its first hunk.
+	out := `aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 1 1 2
+author Alice
+author-mail <alice@example.com>
+author-time 1136214245
+author-tz +0000
+committer Alice
+committer-mail <alice@example.com>
+committer-time 1136214245
+committer-tz +0000
+summary first
+boundary
+filename old.txt
+bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb 3 3 1
+author Bob
+author-mail <bob@example.com>
+author-time 1136214246
+author-tz +0000
+committer Bob
+committer-mail <bob@example.com>
+committer-time 1136214246
+committer-tz +0000
+summary second
+previous aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa old.txt
+filename new.txt
+aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 3 4 1
+filename old.txt
+`
+	alice := protocol.Signature{Name: "Alice", Email: "alice@example.com", Date: time.Unix(1136214245, 0).UTC()}
+	bob := protocol.Signature{Name: "Bob", Email: "bob@example.com", Date: time.Unix(1136214246, 0).UTC()}
+	want := []protocol.BlameHunk{
+		{StartLine: 1, EndLine: 3, CommitID: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Author: alice, Message: "first", Filename: "old.txt"},
+		{StartLine: 3, EndLine: 4, CommitID: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", Author: bob, Message: "second", Filename: "new.txt"},
+		{StartLine: 4, EndLine: 5, CommitID: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Author: alice, Message: "first", Filename: "old.txt"},
+	}
+
+	var got []protocol.BlameHunk
+	if err := parseBlameIncremental(strings.NewReader(out), func(hunk protocol.BlameHunk) {
+		got = append(got, hunk)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected hunks (-want +got):\n%s", diff)
+	}
+
+	if err := parseBlameIncremental(strings.NewReader("aaaa 1 1 1\nauthor Alice\n"), func(protocol.BlameHunk) {}); err == nil {
+		t.Fatal("expected error for truncated output")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/env"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/search"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
@@ -80,9 +82,11 @@ const reposStatsName = "repos-stats.json"
 // 3. Remove stale lock files.
 // 4. Ensure correct git attributes
 // 5. Scrub remote URLs
-// 6. Perform garbage collection
-// 7. Re-clone repos after a while. (simulate git gc)
-// 8. Remove repos based on disk pressure.
+// 6. Optimize the repository (garbage collection or incremental maintenance)
+// 7. Check the integrity of repos with git fsck, within an IO budget.
+// 8. Build or remove commit indexes for commit search.
+// 9. Re-clone repos after a while or when they are corrupt. (simulate git gc)
+// 10. Remove repos based on disk pressure.
 func (s *Server) cleanupRepos() {
 	janitorRunning.Set(1)
 	defer janitorRunning.Set(0)
@@ -141,7 +145,7 @@ func (s *Server) cleanupRepos() {
 		// Add a jitter to spread out re-cloning of repos cloned at the same time.
 		var reason string
 		const maybeCorrupt = "maybeCorrupt"
-		if maybeCorrupt, _ := gitConfigGet(dir, gitConfigMaybeCorrupt); maybeCorrupt != "" {
+		if v, _ := gitConfigGet(dir, gitConfigMaybeCorrupt); v != "" {
 			reason = maybeCorrupt
 			// unset flag to stop constantly re-cloning if it fails.
 			_ = gitConfigUnset(dir, gitConfigMaybeCorrupt)
@@ -155,11 +159,15 @@ func (s *Server) cleanupRepos() {
 			}
 		}
 
-		// We believe converting a Perforce depot to a Git repository is generally a
-		// very expensive operation, therefore we do not try to re-clone/redo the
-		// conversion only because it is old or slow to do "git gc".
-		if repoType == "perforce" && reason != maybeCorrupt {
-			reason = ""
+		// We believe converting a Perforce depot, or a Mercurial or Subversion
+		// repository, to a Git repository is generally a very expensive operation,
+		// therefore we do not try to re-clone/redo the conversion only because it
+		// is old or slow to do "git gc".
+		switch repoType {
+		case "perforce", "mercurial", "subversion":
+			if reason != maybeCorrupt {
+				reason = ""
+			}
 		}
 
 		if reason == "" {
@@ -220,13 +228,116 @@ func (s *Server) cleanupRepos() {
 		return false, multi
 	}
 
-	performGC := func(dir GitDir) (done bool, err error) {
+	optimizeRepo := func(dir GitDir) (done bool, err error) {
+		if conf.GitRepoOptimizationStrategy() == "incremental" {
+			// Counting loose objects reads many directories, so we only
+			// count objects for the strategy which uses the counts.
+			objectStats, err := computeRepoObjectStats(dir)
+			if err != nil {
+				return false, err
+			}
+			stats.Packfiles += int64(objectStats.Packfiles)
+			stats.LooseObjects += int64(objectStats.LooseObjects)
+			if err := gitMaintenance(dir, maintenanceTasks(dir, objectStats)); err != nil {
+				return false, err
+			}
+			// The maintenance tasks never prune unreachable objects, so we
+			// occasionally fall back to git gc.
+			return false, maybeGitGC(dir)
+		}
+
 		if !enableGCAuto {
 			return false, nil
 		}
 		return false, gitGC(dir)
 	}
 
+	// fsckBudget is the number of bytes of repository data we may still check
+	// during this run.
+	fsckBudget := fsckMaxBytesPerRun
+
+	maybeFsck := func(dir GitDir) (done bool, err error) {
+		if fsckInterval <= 0 || fsckBudget <= 0 {
+			return false, nil
+		}
+
+		lastFsck, err := getLastFsck(dir)
+		if err != nil {
+			return false, err
+		}
+		// Add a jitter to spread out checking of repos checked at the same time.
+		if time.Since(lastFsck) < fsckInterval+jitterDuration(string(dir), fsckInterval/4) {
+			return false, nil
+		}
+
+		// A clone in progress replaces the repository anyway.
+		if _, cloning := s.locker.Status(dir); cloning {
+			return false, nil
+		}
+
+		fsckBudget -= dirSize(dir.Path("."))
+
+		ctx, cancel := context.WithTimeout(bCtx, fsckTimeout())
+		defer cancel()
+
+		corruptionLog, err := gitFsck(ctx, dir)
+		if errors.Is(err, context.DeadlineExceeded) {
+			// Record the check, so that a repo too large to check within
+			// the timeout is not checked again on every janitor run.
+			if err := setLastFsck(dir, time.Now()); err != nil {
+				return false, err
+			}
+			return false, errors.Wrap(err, "git fsck timed out")
+		}
+		if err != nil {
+			return false, errors.Wrap(err, "git fsck")
+		}
+		if err := setLastFsck(dir, time.Now()); err != nil {
+			return false, err
+		}
+
+		repo := s.name(dir)
+		s.setFsckResultNonFatal(ctx, repo, corruptionLog)
+		if corruptionLog == "" {
+			return false, nil
+		}
+
+		log15.Warn("marking repo for re-cloning due to git fsck reporting repo corruption", "repo", repo, "output", corruptionLog)
+		reposCorrupted.Inc()
+
+		// maybeReclone re-clones the repo next. The corrupt copy keeps
+		// serving reads until the new clone replaces it.
+		return false, gitConfigSet(dir, gitConfigMaybeCorrupt, strconv.FormatInt(time.Now().Unix(), 10))
+	}
+
+	maybeUpdateCommitIndex := func(dir GitDir) (done bool, err error) {
+		indexDir := commitIndexDir(dir)
+		if !conf.SearchIndexCommitsEnabled() {
+			// Free the disk space of indexes once commit indexing is disabled.
+			return false, os.RemoveAll(indexDir)
+		}
+
+		state, err := search.ReadCommitIndexState(indexDir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+		if err == nil {
+			stats.CommitIndexBytes += dirSize(indexDir)
+			// Indexes are updated after each fetch, so we only need to resume
+			// interrupted updates, and rebuild indexes in an old format.
+			if state.Head != "" && !state.Pending {
+				return false, nil
+			}
+		}
+		// Otherwise, the repo was not fetched since commit indexing was
+		// enabled.
+		if _, cloning := s.locker.Status(dir); cloning {
+			return false, nil
+		}
+		s.queueCommitIndexUpdate(dir, false)
+		return false, nil
+	}
+
 	type cleanupFn struct {
 		Name string
 		Do   func(GitDir) (bool, error)
@@ -249,7 +360,19 @@ func (s *Server) cleanupRepos() {
 		// removing unreachable objects which may have been created from prior
 		// invocations of git add, packing refs, pruning reflog, rerere metadata or stale
 		// working trees. May also update ancillary indexes such as the commit-graph.
-		{"garbage collect", performGC},
+		//
+		// With the incremental strategy we instead only run the git maintenance tasks
+		// the repository needs, which avoids long git gc runs on large repositories.
+		{"optimize", optimizeRepo},
+		// Regularly check that all objects reachable from the refs of the repository
+		// are present. A limited amount of repository data is checked per run, so
+		// that the checks do not compete with other IO.
+		{"maybe check integrity", maybeFsck},
+		// Build the commit index used by commit and diff search for repos which
+		// do not have one yet, resume interrupted builds, or remove the index if
+		// commit indexing is disabled. This also accounts for the disk space
+		// used by commit indexes.
+		{"maybe update commit index", maybeUpdateCommitIndex},
 	}
 
 	if !conf.Get().DisableAutoGitUpdates {
@@ -297,6 +420,9 @@ func (s *Server) cleanupRepos() {
 		log15.Error("cleanup: error iterating over repositories", "error", err)
 	}
 
+	atomic.StoreInt64(&s.commitIndexBytes, stats.CommitIndexBytes)
+	commitIndexBytes.Set(float64(stats.CommitIndexBytes))
+
 	if b, err := json.Marshal(stats); err != nil {
 		log15.Error("cleanup: failed to marshal periodic stats", "error", err)
 	} else if err = os.WriteFile(filepath.Join(s.ReposDir, reposStatsName), b, 0666); err != nil {
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	otlog "github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// handleCommitAncestry answers which candidate commits are ancestors or
+// descendants of a set of commits, and at which distance, in a single request.
+// If the request asks for the commit graph, it returns the graph instead.
+func (s *Server) handleCommitAncestry(w http.ResponseWriter, r *http.Request) {
+	var req protocol.CommitAncestryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Commits) == 0 && (req.Graph == nil || !req.Graph.AllRefs) {
+		http.Error(w, "no commits", http.StatusBadRequest)
+		return
+	}
+	for _, commits := range [][]api.CommitID{req.Commits, req.Candidates} {
+		for _, commit := range commits {
+			if commit == "" || strings.HasPrefix(string(commit), "-") || strings.ContainsAny(string(commit), " \n") {
+				http.Error(w, fmt.Sprintf("invalid git revision spec %q", commit), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	req.Repo = protocol.NormalizeRepo(req.Repo)
+
+	tr, ctx := trace.New(r.Context(), "commitAncestry", string(req.Repo))
+	defer tr.Finish()
+	tr.LogFields(
+		otlog.Int("commits", len(req.Commits)),
+		otlog.Int("candidates", len(req.Candidates)),
+		otlog.Int("max_distance", req.MaxDistance),
+	)
+
+	dir := s.dir(req.Repo)
+	if !repoCloned(dir) {
+		payload := protocol.NotFoundPayload{}
+		if !conf.Get().DisableAutoGitUpdates {
+			payload.CloneProgress, payload.CloneInProgress = s.locker.Status(dir)
+			if !payload.CloneInProgress {
+				if cloneProgress, err := s.cloneRepo(ctx, req.Repo, &cloneOptions{Priority: clonePriorityInteractive}); err != nil {
+					log15.Debug("error starting repo clone", "repo", req.Repo, "err", err)
+				} else {
+					payload.CloneInProgress = true
+					payload.CloneProgress = cloneProgress
+				}
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(&payload)
+		return
+	}
+
+	inputs := append(append([]api.CommitID{}, req.Commits...), req.Candidates...)
+	resolved, err := resolveCommits(ctx, dir, inputs)
+	if err == nil && !allResolved(inputs, resolved) && !conf.Get().DisableAutoGitUpdates {
+		// Some commits were not found, update before answering.
+		_ = s.doRepoUpdate(ctx, req.Repo)
+		resolved, err = resolveCommits(ctx, dir, inputs)
+	}
+	var resp *protocol.CommitAncestryResponse
+	if err == nil {
+		if req.Graph != nil {
+			resp, err = commitGraph(ctx, dir, &req, resolved)
+		} else {
+			resp, err = commitAncestry(ctx, dir, &req, resolved)
+		}
+	}
+	if err != nil {
+		tr.SetError(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log15.Error("handleCommitAncestry: sending response", "error", err)
+	}
+}
+
+// commitAncestry computes the response to req. resolved maps the commits and
+// candidates of req which exist in the repository to their full SHAs.
+//
+// Only the part of the commit graph which can contain paths between the
+// commits and the candidates is loaded: any such path lies between the
+// commits and the best common ancestors of all of them and the candidates,
+// so the ancestors of those are excluded. git walks the graph using the
+// commit-graph file the janitor maintains.
+func commitAncestry(ctx context.Context, dir GitDir, req *protocol.CommitAncestryRequest, resolved map[api.CommitID]string) (*protocol.CommitAncestryResponse, error) {
+	resp := &protocol.CommitAncestryResponse{
+		Ancestors:   map[api.CommitID][]protocol.CommitDistance{},
+		Descendants: map[api.CommitID][]protocol.CommitDistance{},
+	}
+
+	var commits, inputs []string
+	candidates := map[string][]api.CommitID{}
+	seen := map[api.CommitID]bool{}
+	for _, commit := range req.Commits {
+		sha, ok := resolved[commit]
+		if !ok {
+			if !seen[commit] {
+				resp.Missing = append(resp.Missing, commit)
+			}
+		} else {
+			commits = append(commits, sha)
+			inputs = append(inputs, sha)
+		}
+		seen[commit] = true
+	}
+	for _, candidate := range req.Candidates {
+		sha, ok := resolved[candidate]
+		if !ok {
+			if !seen[candidate] {
+				resp.Missing = append(resp.Missing, candidate)
+			}
+		} else {
+			candidates[sha] = append(candidates[sha], candidate)
+			inputs = append(inputs, sha)
+		}
+		seen[candidate] = true
+	}
+	if len(commits) == 0 {
+		return resp, nil
+	}
+
+	bases, err := mergeBases(ctx, dir, commits)
+	if err != nil {
+		return nil, err
+	}
+	for _, sha := range bases {
+		resp.MergeBases = append(resp.MergeBases, api.CommitID(sha))
+	}
+	if len(candidates) == 0 {
+		return resp, nil
+	}
+
+	if len(inputs) > len(commits) {
+		if bases, err = mergeBases(ctx, dir, inputs); err != nil {
+			return nil, err
+		}
+	}
+	parents, err := revListParents(ctx, dir, inputs, bases)
+	if err != nil {
+		return nil, err
+	}
+	children := map[string][]string{}
+	for commit, commitParents := range parents {
+		for _, parent := range commitParents {
+			children[parent] = append(children[parent], commit)
+		}
+	}
+
+	for _, commit := range req.Commits {
+		sha, ok := resolved[commit]
+		if !ok {
+			continue
+		}
+		resp.Ancestors[commit] = commitDistances(parents, sha, candidates, req.MaxDistance)
+		resp.Descendants[commit] = commitDistances(children, sha, candidates, req.MaxDistance)
+	}
+	return resp, nil
+}
+
+// commitGraph returns the commit graph reachable from the commits of req, as
+// narrowed by req.Graph. resolved maps the commits of req which exist in the
+// repository to their full SHAs. If none of the commits exist and req.Graph
+// does not include all refs, the graph is empty.
+func commitGraph(ctx context.Context, dir GitDir, req *protocol.CommitAncestryRequest, resolved map[api.CommitID]string) (*protocol.CommitAncestryResponse, error) {
+	resp := &protocol.CommitAncestryResponse{}
+
+	args := []string{"-c", "core.commitGraph=true", "rev-list", "--parents", "--topo-order"}
+	if req.Graph.AllRefs {
+		args = append(args, "--all")
+	}
+	if req.Graph.Since != nil {
+		args = append(args, "--since="+req.Graph.Since.Format(time.RFC3339))
+	}
+	if req.Graph.Limit > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(req.Graph.Limit))
+	}
+	var stdin strings.Builder
+	seen := map[api.CommitID]bool{}
+	for _, commit := range req.Commits {
+		if sha, ok := resolved[commit]; ok {
+			stdin.WriteString(sha + "\n")
+		} else if !seen[commit] {
+			resp.Missing = append(resp.Missing, commit)
+		}
+		seen[commit] = true
+	}
+	if stdin.Len() == 0 && !req.Graph.AllRefs {
+		return resp, nil
+	}
+	args = append(args, "--stdin")
+
+	out, err := gitOutput(ctx, dir, stdin.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		entry := protocol.CommitParents{Commit: api.CommitID(fields[0])}
+		for _, parent := range fields[1:] {
+			entry.Parents = append(entry.Parents, api.CommitID(parent))
+		}
+		resp.Graph = append(resp.Graph, entry)
+	}
+	return resp, scanner.Err()
+}
+
+// commitDistances returns the targets reachable from start by following
+// edges, nearest first, with their distance to start. If maxDistance is
+// non-zero, targets further than maxDistance are left out. targets maps each
+// target SHA to the commit IDs it is returned as.
+func commitDistances(edges map[string][]string, start string, targets map[string][]api.CommitID, maxDistance int) []protocol.CommitDistance {
+	distances := []protocol.CommitDistance{}
+	visited := map[string]bool{start: true}
+	frontier := []string{start}
+	found := 0
+	for distance := 0; len(frontier) > 0 && found < len(targets); distance++ {
+		if maxDistance > 0 && distance > maxDistance {
+			break
+		}
+
+		var next []string
+		for _, commit := range frontier {
+			if ids, ok := targets[commit]; ok {
+				found++
+				for _, id := range ids {
+					distances = append(distances, protocol.CommitDistance{Commit: id, Distance: distance})
+				}
+			}
+			for _, edge := range edges[commit] {
+				if !visited[edge] {
+					visited[edge] = true
+					next = append(next, edge)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	sort.SliceStable(distances, func(i, j int) bool {
+		if distances[i].Distance != distances[j].Distance {
+			return distances[i].Distance < distances[j].Distance
+		}
+		return distances[i].Commit < distances[j].Commit
+	})
+	return distances
+}
+
+// resolveCommits returns a map from the commits which exist in the repository
+// at dir to their full SHAs.
+func resolveCommits(ctx context.Context, dir GitDir, commits []api.CommitID) (map[api.CommitID]string, error) {
+	var stdin strings.Builder
+	for _, commit := range commits {
+		stdin.WriteString(string(commit) + "^{commit}\n")
+	}
+	out, err := gitOutput(ctx, dir, stdin.String(), "cat-file", "--batch-check=%(objectname)")
+	if err != nil {
+		return nil, err
+	}
+
+	// cat-file prints one line per input line, which is "<input> missing"
+	// (or ambiguous) if the commit does not exist.
+	resolved := make(map[api.CommitID]string, len(commits))
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	for i, line := range lines {
+		if i < len(commits) && isAbsoluteRevision(line) {
+			resolved[commits[i]] = line
+		}
+	}
+	return resolved, nil
+}
+
+func allResolved(commits []api.CommitID, resolved map[api.CommitID]string) bool {
+	for _, commit := range commits {
+		if _, ok := resolved[commit]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeBases returns the best common ancestors of commits, which is empty if
+// the commits have no common history.
+func mergeBases(ctx context.Context, dir GitDir, commits []string) ([]string, error) {
+	out, err := gitOutput(ctx, dir, "", append([]string{"merge-base", "--octopus"}, commits...)...)
+	if err != nil {
+		var exitErr *exec.ExitError
+		// merge-base exits with status 1 without output if there are no
+		// common ancestors.
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(bytes.TrimSpace(exitErr.Stderr)) == 0 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// revListParents returns a map from the commits reachable from commits, but
+// not from the parents of bases, to their parents.
+func revListParents(ctx context.Context, dir GitDir, commits, bases []string) (map[string][]string, error) {
+	var stdin strings.Builder
+	for _, commit := range commits {
+		stdin.WriteString(commit + "\n")
+	}
+	for _, base := range bases {
+		stdin.WriteString("^" + base + "^@\n")
+	}
+	out, err := gitOutput(ctx, dir, stdin.String(), "-c", "core.commitGraph=true", "rev-list", "--parents", "--stdin")
+	if err != nil {
+		return nil, err
+	}
+
+	parents := map[string][]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			parents[fields[0]] = fields[1:]
+		}
+	}
+	return parents, scanner.Err()
+}
+
+// gitOutput runs git with args in dir, writing stdin to its standard input,
+// and returns its standard output.
+func gitOutput(ctx context.Context, dir GitDir, stdin string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	dir.Set(cmd)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, errors.Wrapf(err, "git command %v failed (stderr: %q)", args, exitErr.Stderr)
+		}
+		return nil, err
+	}
+	return out, nil
+}
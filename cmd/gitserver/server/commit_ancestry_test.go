@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestCommitAncestry(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	git := func(arg ...string) string {
+		t.Helper()
+		return strings.TrimSpace(runCmd(t, root, "git", arg...))
+	}
+	commit := func(message string) api.CommitID {
+		t.Helper()
+		git("commit", "--allow-empty", "-m", message)
+		return api.CommitID(git("rev-parse", "HEAD"))
+	}
+
+	// a - b - c ----- e
+	//      \         /
+	//       d ------
+	//        \
+	//         f
+	git("init", "-b", "master")
+	a := commit("a")
+	b := commit("b")
+	c := commit("c")
+	git("checkout", "-b", "feature", string(b))
+	d := commit("d")
+	git("checkout", "-b", "other")
+	f := commit("f")
+	git("checkout", "master")
+	git("merge", "--no-ff", "-m", "e", "feature")
+	e := api.CommitID(git("rev-parse", "HEAD"))
+
+	dir := GitDir(filepath.Join(root, ".git"))
+	resolve := func(req *protocol.CommitAncestryRequest) map[api.CommitID]string {
+		t.Helper()
+		resolved, err := resolveCommits(ctx, dir, append(append([]api.CommitID{}, req.Commits...), req.Candidates...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resolved
+	}
+
+	tests := []struct {
+		name string
+		req  protocol.CommitAncestryRequest
+		want *protocol.CommitAncestryResponse
+	}{
+		{
+			name: "ancestors and descendants",
+			req: protocol.CommitAncestryRequest{
+				Commits:    []api.CommitID{c, d},
+				Candidates: []api.CommitID{a, d, e},
+			},
+			want: &protocol.CommitAncestryResponse{
+				Ancestors: map[api.CommitID][]protocol.CommitDistance{
+					c: {{Commit: a, Distance: 2}},
+					d: {{Commit: d, Distance: 0}, {Commit: a, Distance: 2}},
+				},
+				Descendants: map[api.CommitID][]protocol.CommitDistance{
+					c: {{Commit: e, Distance: 1}},
+					d: {{Commit: d, Distance: 0}, {Commit: e, Distance: 1}},
+				},
+				MergeBases: []api.CommitID{b},
+			},
+		},
+		{
+			name: "max distance",
+			req: protocol.CommitAncestryRequest{
+				Commits:     []api.CommitID{e},
+				Candidates:  []api.CommitID{a, b, c},
+				MaxDistance: 2,
+			},
+			want: &protocol.CommitAncestryResponse{
+				Ancestors: map[api.CommitID][]protocol.CommitDistance{
+					e: {{Commit: c, Distance: 1}, {Commit: b, Distance: 2}},
+				},
+				Descendants: map[api.CommitID][]protocol.CommitDistance{
+					e: {},
+				},
+				MergeBases: []api.CommitID{e},
+			},
+		},
+		{
+			name: "merge bases only",
+			req: protocol.CommitAncestryRequest{
+				Commits: []api.CommitID{c, f},
+			},
+			want: &protocol.CommitAncestryResponse{
+				Ancestors:   map[api.CommitID][]protocol.CommitDistance{},
+				Descendants: map[api.CommitID][]protocol.CommitDistance{},
+				MergeBases:  []api.CommitID{b},
+			},
+		},
+		{
+			name: "missing commits",
+			req: protocol.CommitAncestryRequest{
+				Commits:    []api.CommitID{"deadbeefdeadbeefdeadbeefdeadbeefdeadbeef", a},
+				Candidates: []api.CommitID{"unknown", a},
+			},
+			want: &protocol.CommitAncestryResponse{
+				Ancestors: map[api.CommitID][]protocol.CommitDistance{
+					a: {{Commit: a, Distance: 0}},
+				},
+				Descendants: map[api.CommitID][]protocol.CommitDistance{
+					a: {{Commit: a, Distance: 0}},
+				},
+				MergeBases: []api.CommitID{a},
+				Missing:    []api.CommitID{"deadbeefdeadbeefdeadbeefdeadbeefdeadbeef", "unknown"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := commitAncestry(ctx, dir, &test.req, resolve(&test.req))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("unexpected response (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	graphTests := []struct {
+		name string
+		req  protocol.CommitAncestryRequest
+		want []protocol.CommitParents
+	}{
+		{
+			name: "graph of a commit",
+			req: protocol.CommitAncestryRequest{
+				Commits: []api.CommitID{d},
+				Graph:   &protocol.CommitGraphOptions{},
+			},
+			want: []protocol.CommitParents{
+				{Commit: d, Parents: []api.CommitID{b}},
+				{Commit: b, Parents: []api.CommitID{a}},
+				{Commit: a},
+			},
+		},
+		{
+			name: "graph limit",
+			req: protocol.CommitAncestryRequest{
+				Commits: []api.CommitID{f},
+				Graph:   &protocol.CommitGraphOptions{Limit: 2},
+			},
+			want: []protocol.CommitParents{
+				{Commit: f, Parents: []api.CommitID{d}},
+				{Commit: d, Parents: []api.CommitID{b}},
+			},
+		},
+	}
+	for _, test := range graphTests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := commitGraph(ctx, dir, &test.req, resolve(&test.req))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got.Graph); diff != "" {
+				t.Fatalf("unexpected graph (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("graph of all refs", func(t *testing.T) {
+		req := protocol.CommitAncestryRequest{Graph: &protocol.CommitGraphOptions{AllRefs: true}}
+		got, err := commitGraph(ctx, dir, &req, resolve(&req))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Graph) != 6 {
+			t.Fatalf("expected all 6 commits, got %d", len(got.Graph))
+		}
+	})
+
+	t.Run("graph of a missing commit", func(t *testing.T) {
+		req := protocol.CommitAncestryRequest{Commits: []api.CommitID{"unknown"}, Graph: &protocol.CommitGraphOptions{}}
+		got, err := commitGraph(ctx, dir, &req, resolve(&req))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(&protocol.CommitAncestryResponse{Missing: []api.CommitID{"unknown"}}, got); diff != "" {
+			t.Fatalf("unexpected response (-want +got):\n%s", diff)
+		}
+	})
+}
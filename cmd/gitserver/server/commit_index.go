@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/search"
+)
+
+// commitIndexDirName is the name of the directory in $GIT_DIR holding the
+// commit index used by commit and diff search.
+const commitIndexDirName = "sg_commit_index"
+
+var (
+	// commitIndexConcurrency is the maximum number of commit indexes updated
+	// at the same time.
+	commitIndexConcurrency, _ = strconv.Atoi(env.Get("SRC_COMMIT_INDEX_CONCURRENCY", "1", "The maximum number of repositories gitserver updates the commit index of at the same time, if search.index.commits.enabled is set."))
+
+	// commitIndexMaxDiffBytesPerRepo is the maximum compressed size of the
+	// diffs stored in the commit index of a single repo.
+	commitIndexMaxDiffBytesPerRepo = func() int64 {
+		mb, _ := strconv.ParseInt(env.Get("SRC_COMMIT_INDEX_MAX_DIFF_MB_PER_REPO", "1024", "The maximum size in megabytes of the compressed diffs stored in the commit index of a repository. Diffs of older commits are computed by git when searched."), 10, 64)
+		return mb * 1024 * 1024
+	}()
+
+	// commitIndexMaxTotalBytes is the size of all commit indexes on this
+	// gitserver above which no new commit indexes are built.
+	commitIndexMaxTotalBytes = func() int64 {
+		mb, _ := strconv.ParseInt(env.Get("SRC_COMMIT_INDEX_MAX_TOTAL_MB", "102400", "The size in megabytes of all commit indexes on gitserver above which no new commit indexes are built. Existing commit indexes are still updated."), 10, 64)
+		return mb * 1024 * 1024
+	}()
+)
+
+var (
+	commitIndexUpdateDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "src_gitserver_commit_index_update_duration_seconds",
+		Help:    "time taken to update the commit index of a repo",
+		Buckets: prometheus.ExponentialBuckets(0.1, 4, 8),
+	})
+	commitIndexUpdateErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_gitserver_commit_index_update_errors_total",
+		Help: "number of commit index updates that failed",
+	})
+	commitIndexBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "src_gitserver_commit_index_bytes",
+		Help: "size of all commit indexes on disk as of the last janitor run",
+	})
+)
+
+// commitIndexDir returns the directory of the commit index of the repo in dir.
+func commitIndexDir(dir GitDir) string {
+	return dir.Path(commitIndexDirName)
+}
+
+// queueCommitIndexUpdate updates the commit index of the repo in dir in the
+// background, if commit indexing is enabled. If wait is false, the update is
+// skipped when the maximum number of concurrent updates are already running.
+// Only one update of a repo runs at a time; the commits fetched meanwhile are
+// indexed by the next update, and searched with git log until then.
+//
+// New indexes are not built once the commit indexes on this gitserver exceed
+// commitIndexMaxTotalBytes, as measured by the last janitor run.
+func (s *Server) queueCommitIndexUpdate(dir GitDir, wait bool) {
+	if !conf.SearchIndexCommitsEnabled() || isPartialClone(dir) {
+		return
+	}
+	if _, err := os.Stat(commitIndexDir(dir)); os.IsNotExist(err) && atomic.LoadInt64(&s.commitIndexBytes) >= commitIndexMaxTotalBytes {
+		return
+	}
+	if _, running := s.commitIndexUpdates.LoadOrStore(dir, struct{}{}); running {
+		return
+	}
+	if !wait {
+		select {
+		case s.commitIndexSem <- struct{}{}:
+		default:
+			s.commitIndexUpdates.Delete(dir)
+			return
+		}
+	}
+
+	ctx, cancel := s.serverContext()
+	go func() {
+		defer cancel()
+		defer s.commitIndexUpdates.Delete(dir)
+
+		if wait {
+			select {
+			case s.commitIndexSem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		defer func() { <-s.commitIndexSem }()
+
+		// Updates that time out are resumed by the next update, which is
+		// queued after the next fetch or by the janitor.
+		ctx, cancel := context.WithTimeout(ctx, conf.GitLongCommandTimeout())
+		defer cancel()
+
+		start := time.Now()
+		err := search.UpdateCommitIndex(ctx, dir.Path(), commitIndexDir(dir), search.CommitIndexOptions{
+			MaxDiffBytes: commitIndexMaxDiffBytesPerRepo,
+		})
+		commitIndexUpdateDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			commitIndexUpdateErrors.Inc()
+			log15.Warn("failed to update commit index", "repo", s.name(dir), "error", err)
+		}
+	}()
+}
+
+// commitIndexStatusConcurrency is the number of repos whose commit index
+// status is determined at the same time by handleCommitIndexStatus.
+const commitIndexStatusConcurrency = 8
+
+// handleCommitIndexStatus reports which of the requested repos have a commit
+// index covering all commits reachable from HEAD. The frontend uses it to
+// decide how many repos a commit or diff search may cover.
+func (s *Server) handleCommitIndexStatus(w http.ResponseWriter, r *http.Request) {
+	var req protocol.CommitIndexStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := protocol.CommitIndexStatusResponse{
+		Current: make(map[api.RepoName]bool, len(req.Repos)),
+	}
+	if conf.SearchIndexCommitsEnabled() {
+		var (
+			mu  sync.Mutex
+			wg  sync.WaitGroup
+			sem = make(chan struct{}, commitIndexStatusConcurrency)
+		)
+		for _, repo := range req.Repos {
+			repo := repo
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				dir := s.dir(repo)
+				current, err := search.CommitIndexIsCurrent(r.Context(), dir.Path(), commitIndexDir(dir))
+				if err != nil {
+					log15.Warn("failed to determine commit index status", "repo", repo, "error", err)
+				}
+				mu.Lock()
+				resp.Current[repo] = current
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
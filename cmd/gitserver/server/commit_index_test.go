@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/search"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestCleanupCommitIndex(t *testing.T) {
+	root := t.TempDir()
+	remote := makeRemoteRepo(t, filepath.Join(root, "remote"))
+	reposDir := filepath.Join(root, "repos")
+	dir := filepath.Join(reposDir, "repo", ".git")
+	runCmd(t, root, "git", "clone", "--bare", remote, dir)
+
+	s := &Server{ReposDir: reposDir}
+	s.Handler() // Handler as a side-effect sets up Server
+	t.Cleanup(s.Stop)
+
+	mockConf := func(enabled bool) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+			DisableAutoGitUpdates:     true,
+			SearchIndexCommitsEnabled: enabled,
+		}})
+	}
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	mockConf(true)
+	s.cleanupRepos()
+
+	// The index is built in the background.
+	indexDir := commitIndexDir(GitDir(dir))
+	var index *search.CommitIndex
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		var err error
+		if index, err = search.OpenCommitIndex(indexDir); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("expected commit index to be built: %s", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer index.Close()
+
+	head := strings.TrimSpace(runCmd(t, dir, "git", "rev-parse", "HEAD"))
+	if index.Head() != head {
+		t.Fatalf("unexpected index head: want=%s have=%s", head, index.Head())
+	}
+	if index.Len() != 1 {
+		t.Fatalf("expected 1 indexed commit, got %d", index.Len())
+	}
+
+	// The janitor accounts for the size of commit indexes.
+	s.cleanupRepos()
+	if size := atomic.LoadInt64(&s.commitIndexBytes); size == 0 {
+		t.Fatal("expected commit index size to be recorded")
+	}
+
+	mockConf(false)
+	s.cleanupRepos()
+	if _, err := os.Stat(indexDir); !os.IsNotExist(err) {
+		t.Fatalf("expected commit index to be removed once disabled, got %v", err)
+	}
+
+	// No new indexes are built once the total size budget is used up.
+	mockConf(true)
+	atomic.StoreInt64(&s.commitIndexBytes, commitIndexMaxTotalBytes)
+	s.queueCommitIndexUpdate(GitDir(dir), true)
+	if _, queued := s.commitIndexUpdates.Load(GitDir(dir)); queued {
+		t.Fatal("expected no commit index to be built over budget")
+	}
+}
+
+func TestHandleCommitIndexStatus(t *testing.T) {
+	root := t.TempDir()
+	remote := makeRemoteRepo(t, filepath.Join(root, "remote"))
+	reposDir := filepath.Join(root, "repos")
+	for _, name := range []string{"indexed", "unindexed"} {
+		runCmd(t, root, "git", "clone", "--bare", remote, filepath.Join(reposDir, name, ".git"))
+	}
+
+	s := &Server{ReposDir: reposDir}
+	h := s.Handler()
+	t.Cleanup(s.Stop)
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{SearchIndexCommitsEnabled: true}})
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	indexed := GitDir(filepath.Join(reposDir, "indexed", ".git"))
+	if err := search.UpdateCommitIndex(context.Background(), indexed.Path(), commitIndexDir(indexed), search.CommitIndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	getStatus := func() map[api.RepoName]bool {
+		body, err := json.Marshal(protocol.CommitIndexStatusRequest{Repos: []api.RepoName{"indexed", "unindexed"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", "/commit-index-status", bytes.NewReader(body)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("http non-200 status %d", rr.Code)
+		}
+		var resp protocol.CommitIndexStatusResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Current
+	}
+
+	want := map[api.RepoName]bool{"indexed": true, "unindexed": false}
+	if diff := cmp.Diff(want, getStatus()); diff != "" {
+		t.Fatalf("unexpected status (-want +got):\n%s", diff)
+	}
+
+	// An index is no longer current once HEAD moves.
+	commit := strings.TrimSpace(runCmd(t, indexed.Path(), "git", "commit-tree", "HEAD^{tree}", "-p", "HEAD", "-m", "new commit"))
+	runCmd(t, indexed.Path(), "git", "update-ref", "HEAD", commit)
+	want["indexed"] = false
+	if diff := cmp.Diff(want, getStatus()); diff != "" {
+		t.Fatalf("unexpected status (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+const (
+	// execAuditLogBufferSize is the number of audit log entries buffered
+	// before they are written. Entries recorded while the buffer is full are
+	// dropped, so that exec requests never wait on the database.
+	execAuditLogBufferSize = 10000
+
+	// execAuditLogBatchSize is the maximum number of entries written at once.
+	execAuditLogBatchSize = 500
+)
+
+var (
+	execAuditLogWritten = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_gitserver_exec_audit_log_written_total",
+		Help: "number of exec audit log entries written to the database.",
+	})
+	execAuditLogDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_exec_audit_log_dropped_total",
+		Help: "number of exec audit log entries dropped, because the buffer was full or writing them failed.",
+	}, []string{"reason"})
+)
+
+// recordExecAuditLog queues log to be written to the exec audit log, if it is
+// enabled. It never blocks.
+func (s *Server) recordExecAuditLog(log *types.GitserverExecAuditLog) {
+	if s.execAuditLogs == nil || !conf.GitserverExecAuditLogEnabled() {
+		return
+	}
+
+	select {
+	case s.execAuditLogs <- log:
+	default:
+		execAuditLogDropped.WithLabelValues("buffer-full").Inc()
+	}
+}
+
+// WriteExecAuditLogs writes the queued exec audit log entries to the database
+// in batches, at least once every interval. It is expected to run in a
+// background goroutine.
+func (s *Server) WriteExecAuditLogs(interval time.Duration) {
+	if s.DB == nil {
+		return
+	}
+
+	store := database.GitserverExecAuditLogs(s.DB)
+	writeExecAuditLogs(s.ctx, s.execAuditLogs, interval, execAuditLogBatchSize, func(logs []*types.GitserverExecAuditLog) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err := store.Create(ctx, logs...); err != nil {
+			log15.Error("writing exec audit log", "entries", len(logs), "error", err)
+			execAuditLogDropped.WithLabelValues("write-error").Add(float64(len(logs)))
+			return
+		}
+		execAuditLogWritten.Add(float64(len(logs)))
+	})
+}
+
+// writeExecAuditLogs reads entries from logs and calls write with batches of
+// at most batchSize entries. Batches are written when they are full, and
+// otherwise every interval. The remaining entries are written when ctx is
+// done.
+func writeExecAuditLogs(ctx context.Context, logs <-chan *types.GitserverExecAuditLog, interval time.Duration, batchSize int, write func([]*types.GitserverExecAuditLog)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]*types.GitserverExecAuditLog, 0, batchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			write(batch)
+			batch = make([]*types.GitserverExecAuditLog, 0, batchSize)
+		}
+	}
+
+	for {
+		select {
+		case log := <-logs:
+			batch = append(batch, log)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for {
+				select {
+				case log := <-logs:
+					batch = append(batch, log)
+					if len(batch) >= batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
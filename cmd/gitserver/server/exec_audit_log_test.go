@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestExecAuditLog(t *testing.T) {
+	s := &Server{
+		ReposDir:          "/testroot",
+		Hostname:          "gitserver-0",
+		skipCloneForTests: true,
+	}
+	h := s.Handler()
+
+	origRepoCloned := repoCloned
+	repoCloned = func(dir GitDir) bool {
+		return dir == s.dir("github.com/gorilla/mux")
+	}
+	t.Cleanup(func() { repoCloned = origRepoCloned })
+
+	runCommandMock = func(ctx context.Context, cmd *exec.Cmd) (int, error) {
+		if cmd.Args[1] == "testerror" {
+			return 1, errors.New("testerror")
+		}
+		return 0, nil
+	}
+	t.Cleanup(func() { runCommandMock = nil })
+
+	run := func(body string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/exec", strings.NewReader(body))
+		req.Header.Set("X-Sourcegraph-Actor", "42")
+		req.Header.Set("User-Agent", "frontend")
+		h.ServeHTTP(&httptest.ResponseRecorder{Body: new(bytes.Buffer)}, req)
+	}
+	recorded := func() []*types.GitserverExecAuditLog {
+		var logs []*types.GitserverExecAuditLog
+		for {
+			select {
+			case log := <-s.execAuditLogs:
+				logs = append(logs, log)
+			default:
+				return logs
+			}
+		}
+	}
+
+	run(`{"repo": "github.com/gorilla/mux", "args": ["log", "-n", "1"]}`)
+	if logs := recorded(); len(logs) != 0 {
+		t.Fatalf("expected no audit log entries while disabled, got %d", len(logs))
+	}
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		GitserverExecAuditLog: &schema.GitserverExecAuditLog{Enabled: true},
+		DisableAutoGitUpdates: true,
+	}})
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	run(`{"repo": "github.com/gorilla/mux", "args": ["testerror", "HEAD"]}`)
+	run(`{"repo": "github.com/gorilla/doesnotexist", "args": ["log"]}`)
+
+	exitStatus := 1
+	errorMessage := "testerror"
+	want := []*types.GitserverExecAuditLog{
+		{
+			Actor:      "42",
+			Client:     "frontend",
+			Gitserver:  "gitserver-0",
+			Repo:       "github.com/gorilla/mux",
+			Command:    "testerror",
+			Args:       []string{"testerror", "HEAD"},
+			Status:     "1",
+			ExitStatus: &exitStatus,
+			Error:      &errorMessage,
+		},
+		{
+			Actor:     "42",
+			Client:    "frontend",
+			Gitserver: "gitserver-0",
+			Repo:      "github.com/gorilla/doesnotexist",
+			Command:   "log",
+			Args:      []string{"log"},
+			Status:    "repo-not-found",
+		},
+	}
+	if diff := cmp.Diff(want, recorded(), cmpopts.IgnoreFields(types.GitserverExecAuditLog{}, "Duration")); diff != "" {
+		t.Fatalf("unexpected audit log entries (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteExecAuditLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	logs := make(chan *types.GitserverExecAuditLog, 10)
+	for i := 0; i < 5; i++ {
+		logs <- &types.GitserverExecAuditLog{ID: int64(i)}
+	}
+
+	var batches [][]int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writeExecAuditLogs(ctx, logs, time.Hour, 2, func(batch []*types.GitserverExecAuditLog) {
+			var ids []int64
+			for _, log := range batch {
+				ids = append(ids, log.ID)
+			}
+			batches = append(batches, ids)
+			if len(batches) == 2 {
+				// The remaining entry is written once writing stops.
+				cancel()
+			}
+		})
+	}()
+	<-done
+
+	if diff := cmp.Diff([][]int64{{0, 1}, {2, 3}, {4}}, batches); diff != "" {
+		t.Fatalf("unexpected batches (-want +got):\n%s", diff)
+	}
+}
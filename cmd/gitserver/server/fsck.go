@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+const (
+	// gitConfigLastFsck is a key we add to git config to record the last time
+	// we checked the integrity of a repo.
+	gitConfigLastFsck = "sourcegraph.lastFsck"
+
+	// maxCorruptionLogSize is the maximum number of bytes of git fsck output
+	// we keep for a corrupt repo.
+	maxCorruptionLogSize = 16 * 1024
+)
+
+var (
+	// fsckInterval is how often we check the integrity of a repository. A
+	// value of zero disables the checks.
+	fsckInterval, _ = time.ParseDuration(env.Get("SRC_REPOS_FSCK_INTERVAL", "168h", "How often to check the integrity of each repository with git fsck. Set to 0 to disable the checks."))
+
+	// fsckMaxBytesPerRun is the IO budget of a janitor run for integrity
+	// checks, in bytes of repository data read.
+	fsckMaxBytesPerRun = func() int64 {
+		mb, _ := strconv.ParseInt(env.Get("SRC_REPOS_FSCK_MAX_MB_PER_RUN", "1024", "The maximum size in megabytes of the repositories checked with git fsck during a single janitor run."), 10, 64)
+		return mb * 1024 * 1024
+	}()
+)
+
+// fsckTimeout returns how long git fsck may run on a repository.
+var fsckTimeout = conf.GitLongCommandTimeout
+
+var reposCorrupted = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "src_gitserver_repos_corrupted",
+	Help: "number of repos found to be corrupt by git fsck",
+})
+
+// gitFsck checks the connectivity of the objects reachable from the refs of
+// dir. If the repository is corrupt the output of git fsck is returned as
+// corruptionLog. err is only set if the check could not be run.
+func gitFsck(ctx context.Context, dir GitDir) (corruptionLog string, err error) {
+	cmd := exec.CommandContext(ctx, "git", "fsck", "--connectivity-only", "--no-dangling", "--no-progress")
+	dir.Set(cmd)
+	// Missing objects are reported on stdout, other errors on stderr.
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return "", nil
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	var e *exec.ExitError
+	if !errors.As(err, &e) {
+		return "", wrapCmdError(cmd, err)
+	}
+
+	corruptionLog = strings.TrimSpace(string(out))
+	if corruptionLog == "" {
+		corruptionLog = e.Error()
+	}
+	if len(corruptionLog) > maxCorruptionLogSize {
+		corruptionLog = corruptionLog[:maxCorruptionLogSize] + "\n..."
+	}
+	return corruptionLog, nil
+}
+
+// setLastFsck sets the time the integrity of a repository was checked.
+func setLastFsck(dir GitDir, now time.Time) error {
+	return gitConfigSet(dir, gitConfigLastFsck, strconv.FormatInt(now.Unix(), 10))
+}
+
+// getLastFsck returns the time the integrity of a repository was last
+// checked. If the repository has not been checked, the zero time is returned.
+func getLastFsck(dir GitDir) (time.Time, error) {
+	value, err := gitConfigGet(dir, gitConfigLastFsck)
+	if err != nil {
+		return time.Time{}, err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		// Check a repository with a bad value again.
+		return time.Time{}, nil
+	}
+	return time.Unix(sec, 0), nil
+}
+
+func (s *Server) setFsckResult(ctx context.Context, name api.RepoName, corruptionLog string) error {
+	if s.DB == nil || s.replicaOnly(name) {
+		return nil
+	}
+	return database.GitserverRepos(s.DB).SetFsckResult(ctx, name, corruptionLog, s.Hostname)
+}
+
+// setFsckResultNonFatal is the same as setFsckResult but only logs errors
+func (s *Server) setFsckResultNonFatal(ctx context.Context, name api.RepoName, corruptionLog string) {
+	if err := s.setFsckResult(ctx, name, corruptionLog); err != nil {
+		log15.Warn("Setting fsck result in DB", "error", err)
+	}
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// makeCorruptRepo creates a bare clone of remote at dir and removes the loose
+// object of file1 from it.
+func makeCorruptRepo(t *testing.T, remote, dir string) {
+	t.Helper()
+	runCmd(t, filepath.Dir(remote), "git", "clone", "--bare", remote, dir)
+	blob := strings.TrimSpace(runCmd(t, dir, "git", "rev-parse", "HEAD:file1"))
+	if err := os.Remove(filepath.Join(dir, "objects", blob[:2], blob[2:])); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// makeRemoteRepo creates a repository with a single commit in dir.
+func makeRemoteRepo(t *testing.T, dir string) string {
+	t.Helper()
+	runCmd(t, filepath.Dir(dir), "git", "init", dir)
+	runCmd(t, dir, "sh", "-c", "echo 1 > file1")
+	runCmd(t, dir, "git", "add", "file1")
+	runCmd(t, dir, "git", "commit", "-m", "file1")
+	return filepath.Join(dir, ".git")
+}
+
+func TestGitFsck(t *testing.T) {
+	root := t.TempDir()
+	remote := makeRemoteRepo(t, filepath.Join(root, "remote"))
+	corrupt := filepath.Join(root, "corrupt", ".git")
+	makeCorruptRepo(t, remote, corrupt)
+
+	ctx := context.Background()
+
+	corruptionLog, err := gitFsck(ctx, GitDir(remote))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corruptionLog != "" {
+		t.Fatalf("expected healthy repo to pass git fsck, got: %s", corruptionLog)
+	}
+
+	corruptionLog, err = gitFsck(ctx, GitDir(corrupt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(corruptionLog, "missing blob") {
+		t.Fatalf("expected git fsck to report the missing blob, got: %q", corruptionLog)
+	}
+}
+
+func TestGetLastFsck(t *testing.T) {
+	dir := GitDir(makeRemoteRepo(t, filepath.Join(t.TempDir(), "repo")))
+
+	lastFsck, err := getLastFsck(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastFsck.IsZero() {
+		t.Fatalf("expected zero time for unchecked repo, got %s", lastFsck)
+	}
+
+	now := time.Unix(time.Now().Unix(), 0)
+	if err := setLastFsck(dir, now); err != nil {
+		t.Fatal(err)
+	}
+	lastFsck, err = getLastFsck(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastFsck.Equal(now) {
+		t.Fatalf("unexpected last fsck time: want=%s have=%s", now, lastFsck)
+	}
+}
+
+func TestCleanupFsck(t *testing.T) {
+	remote := makeRemoteRepo(t, filepath.Join(t.TempDir(), "remote"))
+
+	newServer := func(root string) *Server {
+		s := &Server{
+			ReposDir: root,
+			GetRemoteURLFunc: func(ctx context.Context, name api.RepoName) (string, error) {
+				return remote, nil
+			},
+			GetVCSSyncer: func(ctx context.Context, name api.RepoName) (VCSSyncer, error) {
+				return &GitRepoSyncer{}, nil
+			},
+		}
+		s.Handler() // Handler as a side-effect sets up Server
+		return s
+	}
+
+	t.Run("marks corrupt repos", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{DisableAutoGitUpdates: true}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		root := t.TempDir()
+		healthy := filepath.Join(root, "healthy", ".git")
+		runCmd(t, root, "git", "clone", "--bare", remote, healthy)
+		corrupt := filepath.Join(root, "corrupt", ".git")
+		makeCorruptRepo(t, remote, corrupt)
+
+		newServer(root).cleanupRepos()
+
+		for _, dir := range []string{healthy, corrupt} {
+			if lastFsck, err := getLastFsck(GitDir(dir)); err != nil {
+				t.Fatal(err)
+			} else if lastFsck.IsZero() {
+				t.Errorf("expected %s to be checked", dir)
+			}
+		}
+		if maybeCorrupt, _ := gitConfigGet(GitDir(healthy), gitConfigMaybeCorrupt); maybeCorrupt != "" {
+			t.Error("expected healthy repo not to be marked as corrupt")
+		}
+		if maybeCorrupt, _ := gitConfigGet(GitDir(corrupt), gitConfigMaybeCorrupt); maybeCorrupt == "" {
+			t.Error("expected corrupt repo to be marked as corrupt")
+		}
+	})
+
+	t.Run("re-clones corrupt repos", func(t *testing.T) {
+		root := t.TempDir()
+		corrupt := filepath.Join(root, "corrupt", ".git")
+		makeCorruptRepo(t, remote, corrupt)
+
+		newServer(root).cleanupRepos()
+
+		corruptionLog, err := gitFsck(context.Background(), GitDir(corrupt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if corruptionLog != "" {
+			t.Fatalf("expected corrupt repo to be re-cloned, git fsck reported: %s", corruptionLog)
+		}
+	})
+
+	t.Run("records timed out checks", func(t *testing.T) {
+		orig := fsckTimeout
+		fsckTimeout = func() time.Duration { return time.Nanosecond }
+		t.Cleanup(func() { fsckTimeout = orig })
+
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{DisableAutoGitUpdates: true}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		root := t.TempDir()
+		dir := filepath.Join(root, "large", ".git")
+		runCmd(t, root, "git", "clone", "--bare", remote, dir)
+
+		newServer(root).cleanupRepos()
+
+		if lastFsck, err := getLastFsck(GitDir(dir)); err != nil {
+			t.Fatal(err)
+		} else if lastFsck.IsZero() {
+			t.Error("expected timed out check to be recorded")
+		}
+		if maybeCorrupt, _ := gitConfigGet(GitDir(dir), gitConfigMaybeCorrupt); maybeCorrupt != "" {
+			t.Error("expected timed out repo not to be marked as corrupt")
+		}
+	})
+
+	t.Run("respects budget", func(t *testing.T) {
+		orig := fsckMaxBytesPerRun
+		fsckMaxBytesPerRun = 1
+		t.Cleanup(func() { fsckMaxBytesPerRun = orig })
+
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{DisableAutoGitUpdates: true}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		root := t.TempDir()
+		var dirs []string
+		for _, name := range []string{"a", "b"} {
+			dir := filepath.Join(root, name, ".git")
+			runCmd(t, root, "git", "clone", "--bare", remote, dir)
+			dirs = append(dirs, dir)
+		}
+
+		s := newServer(root)
+		for i := range dirs {
+			s.cleanupRepos()
+
+			// Each run checks one more repo.
+			for j, dir := range dirs {
+				lastFsck, err := getLastFsck(GitDir(dir))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if checked := !lastFsck.IsZero(); checked != (j <= i) {
+					t.Errorf("after run %d: unexpected check state of %s: %t", i, dir, checked)
+				}
+			}
+		}
+	})
+}
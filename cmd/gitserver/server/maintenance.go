@@ -0,0 +1,235 @@
+package server
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+const (
+	// looseObjectsThreshold is the number of loose objects above which the
+	// incremental strategy packs them with the loose-objects task.
+	looseObjectsThreshold = 1024
+
+	// packfilesThreshold is the number of packfiles above which the
+	// incremental strategy combines them with the incremental-repack task.
+	packfilesThreshold = 16
+
+	// looseRefsThreshold is the number of loose refs above which the
+	// incremental strategy packs them with the pack-refs task.
+	looseRefsThreshold = 64
+
+	// gitConfigLastGC is a key we add to git config to record the last time
+	// the incremental strategy ran git gc on a repo.
+	gitConfigLastGC = "sourcegraph.lastGC"
+)
+
+// gcFallbackInterval is how often the incremental strategy runs git gc on a
+// repository, since the maintenance tasks never prune unreachable objects. A
+// value of zero disables it.
+var gcFallbackInterval, _ = time.ParseDuration(env.Get("SRC_REPOS_GC_FALLBACK_INTERVAL", "720h", "How often the incremental repository optimization strategy runs git gc on each repository. Set to 0 to disable."))
+
+var maintenanceTasksRun = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_gitserver_maintenance_tasks_total",
+	Help: "Number of git maintenance tasks run by the janitor",
+}, []string{"task"})
+
+// repoObjectStats are statistics about the objects stored in a repo.
+type repoObjectStats struct {
+	Packfiles    int
+	LooseObjects int
+	LooseRefs    int
+}
+
+// computeRepoObjectStats counts the packfiles, loose objects and loose refs of
+// the repo at dir.
+func computeRepoObjectStats(dir GitDir) (repoObjectStats, error) {
+	var stats repoObjectStats
+
+	packs, err := filepath.Glob(dir.Path("objects", "pack", "*.pack"))
+	if err != nil {
+		return stats, err
+	}
+	stats.Packfiles = len(packs)
+
+	// Loose objects are stored in objects/XX/, where XX are the first two hex
+	// digits of the object ID.
+	entries, err := os.ReadDir(dir.Path("objects"))
+	if err != nil {
+		return stats, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || len(entry.Name()) != 2 || !isHex(entry.Name()) {
+			continue
+		}
+		objects, err := os.ReadDir(dir.Path("objects", entry.Name()))
+		if err != nil {
+			return stats, err
+		}
+		stats.LooseObjects += len(objects)
+	}
+
+	err = filepath.WalkDir(dir.Path("refs"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			stats.LooseRefs++
+		}
+		return nil
+	})
+	return stats, err
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// maintenanceTasks returns the git maintenance tasks the incremental strategy
+// runs on the repo at dir:
+//
+// - commit-graph if the commit-graph is missing or older than the last fetch.
+// - loose-objects if there are more than looseObjectsThreshold loose objects.
+// - incremental-repack if there are more than packfilesThreshold packfiles.
+// - pack-refs if there are more than looseRefsThreshold loose refs.
+func maintenanceTasks(dir GitDir, stats repoObjectStats) []string {
+	var tasks []string
+	if commitGraphStale(dir) {
+		tasks = append(tasks, "commit-graph")
+	}
+	if stats.LooseObjects > looseObjectsThreshold {
+		tasks = append(tasks, "loose-objects")
+	}
+	if stats.Packfiles > packfilesThreshold {
+		tasks = append(tasks, "incremental-repack")
+	}
+	if stats.LooseRefs > looseRefsThreshold {
+		tasks = append(tasks, "pack-refs")
+	}
+	return tasks
+}
+
+// commitGraphStale returns true if the repo at dir has no commit-graph, or if
+// it was fetched after the commit-graph was last written.
+func commitGraphStale(dir GitDir) bool {
+	var written time.Time
+	for _, path := range []string{
+		dir.Path("objects", "info", "commit-graph"),
+		dir.Path("objects", "info", "commit-graphs", "commit-graph-chain"),
+	} {
+		if fi, err := os.Stat(path); err == nil && fi.ModTime().After(written) {
+			written = fi.ModTime()
+		}
+	}
+	if written.IsZero() {
+		return true
+	}
+
+	lastFetched, err := repoLastFetched(dir)
+	return err != nil || lastFetched.After(written)
+}
+
+// gitMaintenance runs the given git maintenance tasks on the repo at dir.
+func gitMaintenance(dir GitDir, tasks []string) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	var maintenanceTaskArgs []string
+	packRefs := false
+	for _, task := range tasks {
+		if task == "pack-refs" {
+			// git maintenance only has a pack-refs task since git 2.41, so
+			// we run the command of the task ourselves.
+			packRefs = true
+			continue
+		}
+		maintenanceTaskArgs = append(maintenanceTaskArgs, "--task="+task)
+	}
+
+	if len(maintenanceTaskArgs) > 0 {
+		args := append([]string{"-c", "core.multiPackIndex=true", "maintenance", "run"}, maintenanceTaskArgs...)
+		cmd := exec.Command("git", args...)
+		dir.Set(cmd)
+		if err := cmd.Run(); err != nil {
+			return errors.Wrapf(wrapCmdError(cmd, err), "failed to run git maintenance")
+		}
+	}
+	if packRefs {
+		cmd := exec.Command("git", "pack-refs", "--all", "--prune")
+		dir.Set(cmd)
+		if err := cmd.Run(); err != nil {
+			return errors.Wrapf(wrapCmdError(cmd, err), "failed to run git pack-refs")
+		}
+	}
+	for _, task := range tasks {
+		maintenanceTasksRun.WithLabelValues(task).Inc()
+	}
+	return nil
+}
+
+// maybeGitGC runs git gc on the repo at dir if gcFallbackInterval has passed
+// since it last ran. A repo on which git gc never ran starts its interval
+// now, so that switching to the incremental strategy doesn't run git gc on
+// every repo at once.
+func maybeGitGC(dir GitDir) error {
+	if !enableGCAuto || gcFallbackInterval <= 0 {
+		return nil
+	}
+
+	lastGC, err := getLastGC(dir)
+	if err != nil {
+		return err
+	}
+	if lastGC.IsZero() {
+		return setLastGC(dir, time.Now())
+	}
+	// Add a jitter to spread out the git gc of repos cloned at the same time.
+	if time.Since(lastGC) < gcFallbackInterval+jitterDuration(string(dir), gcFallbackInterval/4) {
+		return nil
+	}
+
+	if err := gitGC(dir); err != nil {
+		return err
+	}
+	return setLastGC(dir, time.Now())
+}
+
+func setLastGC(dir GitDir, now time.Time) error {
+	return gitConfigSet(dir, gitConfigLastGC, strconv.FormatInt(now.Unix(), 10))
+}
+
+// getLastGC returns the time the incremental strategy last ran git gc on a
+// repository. If it never ran, the zero time is returned.
+func getLastGC(dir GitDir) (time.Time, error) {
+	value, err := gitConfigGet(dir, gitConfigLastGC)
+	if err != nil {
+		return time.Time{}, err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		// Start the interval again for a repository with a bad value.
+		return time.Time{}, nil
+	}
+	return time.Unix(sec, 0), nil
+}
@@ -0,0 +1,324 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+// promisorRemote is the name of the remote partial clones fetch missing blobs
+// from. It is not called origin since the janitor removes origin, and its URL
+// is never stored: it is passed to each command that may fetch missing blobs.
+const promisorRemote = "promisor"
+
+// PartialCloneOptions configures which blobs of a repository are left out when
+// it is cloned. Missing blobs are fetched on demand.
+type PartialCloneOptions struct {
+	// BlobSizeLimit is the size in bytes above which blobs are not cloned.
+	BlobSizeLimit int
+	// SparsePaths, if set, are the paths of the default branch whose blobs
+	// are cloned. No other blobs are cloned.
+	SparsePaths []string
+}
+
+// filter returns the git object filter to clone with.
+func (o *PartialCloneOptions) filter() string {
+	if len(o.SparsePaths) > 0 || o.BlobSizeLimit <= 0 {
+		return "blob:none"
+	}
+	return fmt.Sprintf("blob:limit=%d", o.BlobSizeLimit)
+}
+
+// configurePartialClone turns the empty repo at dir into a partial clone, so
+// that it is fetched from promisorRemote with opts' filter.
+func configurePartialClone(dir GitDir, opts *PartialCloneOptions) error {
+	for _, kv := range [][2]string{
+		// extensions are only read in repository format version 1.
+		{"core.repositoryformatversion", "1"},
+		{"extensions.partialClone", promisorRemote},
+		{"remote." + promisorRemote + ".promisor", "true"},
+		{"remote." + promisorRemote + ".partialclonefilter", opts.filter()},
+	} {
+		if err := gitConfigSet(dir, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partialClones caches isPartialClone by the modification time of the repo
+// config, so that git config only runs after the config changed.
+var partialClones sync.Map // GitDir -> partialCloneState
+
+type partialCloneState struct {
+	configModTime time.Time
+	partial       bool
+}
+
+// isPartialClone returns true if the repo at dir is a partial clone.
+func isPartialClone(dir GitDir) bool {
+	fi, err := os.Stat(dir.Path("config"))
+	if err != nil {
+		return false
+	}
+	if v, ok := partialClones.Load(dir); ok {
+		if state := v.(partialCloneState); state.configModTime.Equal(fi.ModTime()) {
+			return state.partial
+		}
+	}
+
+	// Partial clones record the name of their promisor remote in
+	// extensions.partialClone.
+	promisor, err := gitConfigGet(dir, "extensions.partialClone")
+	if err != nil {
+		log15.Warn("failed to check for partial clone", "dir", dir, "error", err)
+		return false
+	}
+	partial := strings.TrimSpace(promisor) != ""
+	partialClones.Store(dir, partialCloneState{configModTime: fi.ModTime(), partial: partial})
+	return partial
+}
+
+// promisorURLTTL is how long the remote URL of a partial clone is cached for.
+const promisorURLTTL = 5 * time.Minute
+
+type cachedPromisorURL struct {
+	url     *vcs.URL
+	expires time.Time
+}
+
+// promisorURL returns the remote URL to fetch the missing blobs of the
+// partial clone of repo from. It is cached, since it is needed by every
+// command which may read blobs.
+func (s *Server) promisorURL(ctx context.Context, repo api.RepoName) (*vcs.URL, error) {
+	if v, ok := s.promisorURLs.Load(repo); ok {
+		if cached := v.(cachedPromisorURL); time.Now().Before(cached.expires) {
+			return cached.url, nil
+		}
+	}
+	remoteURL, err := s.getRemoteURL(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	s.promisorURLs.Store(repo, cachedPromisorURL{url: remoteURL, expires: time.Now().Add(promisorURLTTL)})
+	return remoteURL, nil
+}
+
+// withPromisorURL configures cmd to fetch missing blobs of a partial clone
+// from remoteURL.
+func withPromisorURL(cmd *exec.Cmd, remoteURL *vcs.URL) {
+	cmd.Args = append([]string{cmd.Args[0], "-c", "remote." + promisorRemote + ".url=" + remoteURL.String()}, cmd.Args[1:]...)
+}
+
+// partialCloneExec returns a function which configures the command of req to
+// run on the partial clone at dir, and a function to clean up after it ran.
+// git archive skips the files whose blobs are missing, and reports how many it
+// skipped in the X-Archive-Skipped-Files header. Other commands fetch missing
+// blobs from the code host when they read them.
+func (s *Server) partialCloneExec(ctx context.Context, w http.ResponseWriter, req *protocol.ExecRequest, dir GitDir) (configure func(*exec.Cmd), cleanup func(), err error) {
+	noop := func() {}
+	if len(req.Args) > 0 && req.Args[0] == "archive" {
+		treeish, pathspecs := archiveTreeishAndPathspecs(req.Args)
+		paths, err := missingBlobPaths(ctx, dir, treeish, pathspecs...)
+		if err != nil {
+			return nil, nil, err
+		}
+		w.Header().Set("X-Archive-Skipped-Files", strconv.Itoa(len(paths)))
+		if len(paths) == 0 {
+			return func(*exec.Cmd) {}, noop, nil
+		}
+
+		attributesFile, err := writeTempFile("archive-attributes", exportIgnoreAttributes(paths))
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(cmd *exec.Cmd) {
+			cmd.Args = append([]string{cmd.Args[0], "-c", "core.attributesFile=" + attributesFile}, cmd.Args[1:]...)
+		}, func() { os.Remove(attributesFile) }, nil
+	}
+
+	remoteURL, err := s.promisorURL(ctx, req.Repo)
+	if err != nil {
+		// Commands which don't read missing blobs still work.
+		log15.Warn("failed to get remote URL to fetch missing blobs", "repo", req.Repo, "error", err)
+		return func(*exec.Cmd) {}, noop, nil
+	}
+	return fetchMissingBlobs(remoteURL), noop, nil
+}
+
+// fetchMissingBlobs returns a function which configures a command on a
+// partial clone to fetch the blobs it reads which are missing from remoteURL.
+func fetchMissingBlobs(remoteURL *vcs.URL) func(*exec.Cmd) {
+	return func(cmd *exec.Cmd) {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		withPromisorURL(cmd, remoteURL)
+		configureRemoteGitCommand(cmd, tlsExternal().(*tlsConfig))
+	}
+}
+
+// archiveTreeishAndPathspecs returns the tree-ish of the git archive arguments
+// args, which is the last argument before the paths, and the pathspecs of the
+// paths.
+func archiveTreeishAndPathspecs(args []string) (treeish string, pathspecs []string) {
+	for i, arg := range args {
+		if arg == "--" && i > 0 {
+			return args[i-1], args[i+1:]
+		}
+	}
+	return args[len(args)-1], nil
+}
+
+// prefetchSparsePaths fetches the missing blobs under opts.SparsePaths at
+// HEAD of the partial clone at dir in a single batch, rather than one by one
+// when they are read.
+func prefetchSparsePaths(ctx context.Context, dir GitDir, remoteURL *vcs.URL, opts *PartialCloneOptions) error {
+	if opts == nil || len(opts.SparsePaths) == 0 || !isPartialClone(dir) {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"ls-tree", "-r", "-z", "HEAD", "--"}, opts.SparsePaths...)...)
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return errors.Wrap(wrapCmdError(cmd, err), "failed to list sparse paths")
+	}
+
+	missing, err := missingObjects(ctx, dir, "HEAD")
+	if err != nil {
+		return err
+	}
+	var oids bytes.Buffer
+	for _, entry := range parseLsTree(out) {
+		if missing[entry.oid] {
+			oids.WriteString(entry.oid + "\n")
+		}
+	}
+	if oids.Len() == 0 {
+		return nil
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "fetch", "--no-tags", "--no-write-fetch-head", "--recurse-submodules=no", "--filter=blob:none", "--stdin", promisorRemote)
+	withPromisorURL(cmd, remoteURL)
+	dir.Set(cmd)
+	cmd.Stdin = &oids
+	if output, err := runWith(ctx, cmd, true, nil); err != nil {
+		return errors.Wrapf(err, "failed to prefetch sparse paths with output %q", newURLRedactor(remoteURL).redact(string(output)))
+	}
+	return nil
+}
+
+// missingObjects returns the IDs of the objects reachable from treeish which
+// are missing from the partial clone at dir.
+func missingObjects(ctx context.Context, dir GitDir, treeish string) (map[string]bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--objects", "--no-walk", "--missing=print", treeish+"^{tree}")
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(wrapCmdError(cmd, err), "failed to list missing objects")
+	}
+
+	missing := map[string]bool{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if line := sc.Text(); strings.HasPrefix(line, "?") {
+			missing[line[1:]] = true
+		}
+	}
+	return missing, sc.Err()
+}
+
+// missingBlobPaths returns the paths of the files of treeish matching
+// pathspecs whose blobs are missing from the partial clone at dir. All files
+// match if there are no pathspecs.
+func missingBlobPaths(ctx context.Context, dir GitDir, treeish string, pathspecs ...string) ([]string, error) {
+	missing, err := missingObjects(ctx, dir, treeish)
+	if err != nil || len(missing) == 0 {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"ls-tree", "-r", "-z", treeish, "--"}, pathspecs...)...)
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(wrapCmdError(cmd, err), "failed to list files")
+	}
+
+	var paths []string
+	for _, entry := range parseLsTree(out) {
+		if missing[entry.oid] {
+			paths = append(paths, entry.path)
+		}
+	}
+	return paths, nil
+}
+
+type lsTreeEntry struct {
+	oid  string
+	path string
+}
+
+// parseLsTree parses the output of git ls-tree -z.
+func parseLsTree(out []byte) []lsTreeEntry {
+	var entries []lsTreeEntry
+	for _, line := range bytes.Split(out, []byte{0}) {
+		// <mode> SP <type> SP <object> TAB <file>
+		tab := bytes.IndexByte(line, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := strings.Fields(string(line[:tab]))
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		entries = append(entries, lsTreeEntry{oid: fields[2], path: string(line[tab+1:])})
+	}
+	return entries
+}
+
+// exportIgnoreAttributes returns a gitattributes file which excludes paths
+// from git archive.
+func exportIgnoreAttributes(paths []string) []byte {
+	var b bytes.Buffer
+	for _, path := range paths {
+		b.WriteString(quoteAttributesPattern(path))
+		b.WriteString(" export-ignore\n")
+	}
+	return b.Bytes()
+}
+
+// quoteAttributesPattern returns a gitattributes pattern which only matches
+// the file at path.
+func quoteAttributesPattern(path string) string {
+	var b strings.Builder
+	b.WriteByte('/')
+	for _, c := range path {
+		if strings.ContainsRune(`\*?[`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	pattern := b.String()
+	if !strings.ContainsAny(pattern, " \t\n\"") {
+		return pattern
+	}
+	// Patterns containing whitespace or quotes are quoted in C style.
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\t", `\t`, "\n", `\n`)
+	return `"` + r.Replace(pattern) + `"`
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+func TestPartialClone(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote")
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, name, arg...)
+	}
+	runCmd(t, root, "git", "init", "-b", "master", remote)
+	cmd("git", "config", "uploadpack.allowFilter", "true")
+	cmd("git", "config", "uploadpack.allowAnySHA1InWant", "true")
+	cmd("mkdir", "docs", "assets")
+	cmd("sh", "-c", "echo hello > docs/small.txt")
+	cmd("sh", "-c", "yes big | head -c 2000 > assets/big.bin")
+	cmd("sh", "-c", "echo tiny > assets/tiny.bin")
+	cmd("git", "add", ".")
+	cmd("git", "commit", "-m", "initial")
+
+	remoteURL, err := vcs.ParseURL(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remoteURLLookups int
+	s := &Server{
+		ReposDir: filepath.Join(root, "repos"),
+		GetRemoteURLFunc: func(ctx context.Context, name api.RepoName) (string, error) {
+			remoteURLLookups++
+			return remote, nil
+		},
+	}
+	clone := func(repo string, opts *PartialCloneOptions) GitDir {
+		t.Helper()
+		dir := s.dir(api.RepoName(repo))
+		syncer := &GitRepoSyncer{PartialClone: opts}
+		cloneCmd, err := syncer.CloneCommand(ctx, remoteURL, dir.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out, err := runWithRemoteOpts(ctx, cloneCmd, nil); err != nil {
+			t.Fatalf("clone failed: %s\n%s", err, out)
+		}
+		if err := prefetchSparsePaths(ctx, dir, remoteURL, opts); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	t.Run("blob size limit", func(t *testing.T) {
+		dir := clone("limit", &PartialCloneOptions{BlobSizeLimit: 1000})
+		if !isPartialClone(dir) {
+			t.Fatal("expected a partial clone")
+		}
+		paths, err := missingBlobPaths(ctx, dir, "HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"assets/big.bin"}, paths); diff != "" {
+			t.Fatalf("unexpected missing blobs (-want +got):\n%s", diff)
+		}
+
+		// Archives skip missing blobs.
+		w := httptest.NewRecorder()
+		s.handleArchive(w, httptest.NewRequest("GET", "/archive?repo=limit&treeish=HEAD&format=tar", nil))
+		if got := w.Header().Get("X-Archive-Skipped-Files"); got != "1" {
+			t.Fatalf("expected 1 skipped file, got %q", got)
+		}
+		var files []string
+		tr := tar.NewReader(w.Body)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hdr.Typeflag == tar.TypeReg {
+				files = append(files, hdr.Name)
+			}
+		}
+		if diff := cmp.Diff([]string{"assets/tiny.bin", "docs/small.txt"}, files); diff != "" {
+			t.Fatalf("unexpected archived files (-want +got):\n%s", diff)
+		}
+
+		// Only the missing blobs of the archived paths are skipped.
+		w = httptest.NewRecorder()
+		s.handleArchive(w, httptest.NewRequest("GET", "/archive?repo=limit&treeish=HEAD&format=tar&path=docs", nil))
+		if got := w.Header().Get("X-Archive-Skipped-Files"); got != "0" {
+			t.Fatalf("expected 0 skipped files, got %q", got)
+		}
+
+		// Other commands fetch missing blobs.
+		w = httptest.NewRecorder()
+		s.handleExec(w, httptest.NewRequest("POST", "/exec", strings.NewReader(`{"repo": "limit", "args": ["show", "HEAD:assets/big.bin"]}`)))
+		if w.Body.Len() != 2000 {
+			t.Fatalf("expected missing blob to be fetched, got %d bytes (stderr: %s)", w.Body.Len(), w.Header().Get("X-Exec-Stderr"))
+		}
+
+		// The remote URL is only looked up once.
+		w = httptest.NewRecorder()
+		s.handleExec(w, httptest.NewRequest("POST", "/exec", strings.NewReader(`{"repo": "limit", "args": ["show", "HEAD:assets/big.bin"]}`)))
+		if remoteURLLookups != 1 {
+			t.Fatalf("expected 1 remote URL lookup, got %d", remoteURLLookups)
+		}
+
+		// Fetches keep leaving out large blobs.
+		cmd("sh", "-c", "yes bigger | head -c 3000 > assets/bigger.bin")
+		cmd("git", "add", ".")
+		cmd("git", "commit", "-m", "bigger")
+		if err := (&GitRepoSyncer{}).Fetch(ctx, remoteURL, dir); err != nil {
+			t.Fatal(err)
+		}
+		paths, err = missingBlobPaths(ctx, dir, "HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"assets/bigger.bin"}, paths); diff != "" {
+			t.Fatalf("unexpected missing blobs after fetch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("full clone", func(t *testing.T) {
+		dir := clone("full", nil)
+		if isPartialClone(dir) {
+			t.Fatal("expected a full clone")
+		}
+	})
+
+	t.Run("sparse paths", func(t *testing.T) {
+		dir := clone("sparse", &PartialCloneOptions{SparsePaths: []string{"docs"}})
+		paths, err := missingBlobPaths(ctx, dir, "HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"assets/big.bin", "assets/bigger.bin", "assets/tiny.bin"}, paths); diff != "" {
+			t.Fatalf("unexpected missing blobs (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestArchivePathsAreLiteral(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote")
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, name, arg...)
+	}
+	runCmd(t, root, "git", "init", "-b", "master", remote)
+	cmd("sh", "-c", "echo star > 'a*.txt'")
+	cmd("sh", "-c", "echo other > ab.txt")
+	cmd("git", "add", ".")
+	cmd("git", "commit", "-m", "initial")
+
+	s := &Server{ReposDir: root}
+	w := httptest.NewRecorder()
+	s.handleArchive(w, httptest.NewRequest("GET", "/archive?repo=remote&treeish=HEAD&format=tar&path=a%2A.txt", nil))
+
+	var files []string
+	tr := tar.NewReader(w.Body)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			files = append(files, hdr.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{"a*.txt"}, files); diff != "" {
+		t.Fatalf("unexpected archived files (-want +got):\n%s", diff)
+	}
+}
+
+func TestQuoteAttributesPattern(t *testing.T) {
+	for path, want := range map[string]string{
+		"a/b.txt":     "/a/b.txt",
+		"a/*.txt":     `/a/\*.txt`,
+		"with space":  `"/with space"`,
+		`quote"[x]`:   `"/quote\"\\[x]"`,
+		"tab\there?":  `"/tab\there\\?"`,
+		`back\slash`:  `/back\\slash`,
+		"dir/üñí.txt": "/dir/üñí.txt",
+	} {
+		if got := quoteAttributesPattern(path); got != want {
+			t.Errorf("quoteAttributesPattern(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+var replicaReconcileCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_gitserver_replica_reconcile_total",
+	Help: "Incremented each time the replica reconciler clones or updates a secondary replica",
+}, []string{"action", "success"})
+
+// ReconcileReplicas makes sure that this gitserver holds an up to date copy of
+// every repo it is a secondary replica of, and is expected to run in a
+// background goroutine. Missing replicas are cloned, and replicas which were
+// last fetched before their primary are updated.
+func (s *Server) ReconcileReplicas(interval time.Duration) {
+	for {
+		addrs := conf.Get().ServiceConnections().GitServers
+		if err := s.reconcileReplicas(addrs, conf.GitServerReplicationFactor()); err != nil {
+			log15.Error("Reconciling replicas", "error", err)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func (s *Server) reconcileReplicas(addrs []string, factor int) error {
+	if s.DB == nil || factor <= 1 || len(addrs) <= 1 {
+		return nil
+	}
+
+	ctx, cancel := s.serverContext()
+	defer cancel()
+	ctx = actor.WithInternalActor(ctx)
+
+	// We only collect the repos to clone or update while iterating, since
+	// cloning and fetching are slow and iterating keeps a database connection
+	// busy.
+	var clone, update []api.RepoName
+	err := database.GitserverRepos(s.DB).IterateRepoGitserverStatus(ctx, database.IterateRepoGitserverStatusOptions{}, func(repo types.RepoGitserverStatus) error {
+		if !s.isSecondaryReplica(repo.Name, addrs, factor) {
+			return nil
+		}
+
+		dir := s.dir(repo.Name)
+		if _, cloning := s.locker.Status(dir); cloning {
+			return nil
+		}
+		if !repoCloned(dir) {
+			clone = append(clone, repo.Name)
+			return nil
+		}
+
+		if repo.GitserverRepo == nil || repo.LastFetched.IsZero() {
+			return nil
+		}
+		lastFetched, err := repoLastFetched(dir)
+		if err != nil || lastFetched.Before(repo.LastFetched) {
+			update = append(update, repo.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range clone {
+		_, err := s.cloneRepo(ctx, repo, &cloneOptions{Priority: clonePriorityBackfill})
+		if err != nil {
+			log15.Warn("Cloning replica", "repo", repo, "error", err)
+		}
+		replicaReconcileCounter.WithLabelValues("clone", strconv.FormatBool(err == nil)).Inc()
+	}
+	for _, repo := range update {
+		err := s.doRepoUpdate(ctx, repo)
+		if err != nil {
+			log15.Warn("Updating replica", "repo", repo, "error", err)
+		}
+		replicaReconcileCounter.WithLabelValues("update", strconv.FormatBool(err == nil)).Inc()
+	}
+	return ctx.Err()
+}
+
+// isSecondaryReplica returns true if this gitserver holds a secondary replica
+// of repo, rather than being its primary gitserver.
+func (s *Server) isSecondaryReplica(repo api.RepoName, addrs []string, factor int) bool {
+	if factor <= 1 || len(addrs) == 0 {
+		return false
+	}
+	for _, addr := range gitserver.ReplicaAddrsForRepo(repo, addrs, factor)[1:] {
+		if s.hostnameMatch(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// replicaOnly returns true if this gitserver only holds a secondary replica of
+// repo. The state of a repo in the database is owned by its primary gitserver,
+// so secondary replicas do not record it.
+func (s *Server) replicaOnly(repo api.RepoName) bool {
+	return s.isSecondaryReplica(repo, conf.Get().ServiceConnections().GitServers, conf.GitServerReplicationFactor())
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+func TestIsSecondaryReplica(t *testing.T) {
+	addrs := []string{"gitserver-0:3178", "gitserver-1:3178", "gitserver-2:3178"}
+	repo := api.RepoName("github.com/sourcegraph/sourcegraph")
+	replicas := gitserver.ReplicaAddrsForRepo(repo, addrs, 2)
+
+	hostname := func(addr string) string { return addr[:len("gitserver-0")] }
+	primary := &Server{Hostname: hostname(replicas[0])}
+	secondary := &Server{Hostname: hostname(replicas[1])}
+
+	if primary.isSecondaryReplica(repo, addrs, 2) {
+		t.Error("primary should not be a secondary replica")
+	}
+	if !secondary.isSecondaryReplica(repo, addrs, 2) {
+		t.Error("expected secondary replica")
+	}
+	if secondary.isSecondaryReplica(repo, addrs, 1) {
+		t.Error("repos are not replicated with a replication factor of 1")
+	}
+}
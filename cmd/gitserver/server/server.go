@@ -117,6 +117,43 @@ func runCommand(ctx context.Context, cmd *exec.Cmd) (exitCode int, err error) {
 	return exitStatus, err
 }
 
+// clonePriority is the class of a clone request. The clone queue schedules
+// clones of higher priority classes more often.
+type clonePriority int
+
+const (
+	// clonePriorityScheduled is used for clones requested by repo-updater's
+	// update scheduler.
+	clonePriorityScheduled clonePriority = iota
+	// clonePriorityInteractive is used for clones triggered by a user request
+	// for a repo that is not cloned yet.
+	clonePriorityInteractive
+	// clonePriorityBackfill is used for bulk background clones, such as
+	// re-clones by the janitor and clones of secondary replicas.
+	clonePriorityBackfill
+
+	numClonePriorities = iota
+)
+
+func (p clonePriority) String() string {
+	switch p {
+	case clonePriorityInteractive:
+		return "interactive"
+	case clonePriorityBackfill:
+		return "backfill"
+	default:
+		return "scheduled"
+	}
+}
+
+// clonePriorityWeights are the relative shares of the clone pipeline each
+// priority class gets while several of them have queued jobs.
+var clonePriorityWeights = [numClonePriorities]int{
+	clonePriorityScheduled:   3,
+	clonePriorityInteractive: 12,
+	clonePriorityBackfill:    1,
+}
+
 // cloneJob abstracts away a repo and necessary metadata to clone it. In the future it may be
 // possible to simplify this, but to do that, doClone will need to do a lot less than it does at the
 // moment.
@@ -132,23 +169,38 @@ type cloneJob struct {
 
 	remoteURL *vcs.URL
 	options   *cloneOptions
+
+	queuedAt time.Time
+}
+
+func (j *cloneJob) priority() clonePriority {
+	if j.options == nil {
+		return clonePriorityScheduled
+	}
+	return j.options.Priority
 }
 
-// cloneQueue is a threadsafe list.List of cloneJobs that functions as a queue in practice.
+// cloneQueue is a threadsafe queue of cloneJobs. It keeps a FIFO list of jobs
+// per priority class, and pops from the classes using weighted round robin so
+// that higher priority classes are served more often without starving the
+// others.
 type cloneQueue struct {
-	mu   sync.Mutex
-	jobs *list.List
+	mu      sync.Mutex
+	jobs    [numClonePriorities]*list.List
+	credits [numClonePriorities]int
 
 	cmu  sync.Mutex
 	cond *sync.Cond
 }
 
-// push will queue the cloneJob to the end of the queue.
+// push will queue the cloneJob to the end of the list of its priority class.
 func (c *cloneQueue) push(cj *cloneJob) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.jobs.PushBack(cj)
+	cj.queuedAt = time.Now()
+	c.jobs[cj.priority()].PushBack(cj)
+	cloneQueueEnqueued.WithLabelValues(cj.priority().String()).Inc()
 	c.cond.Signal()
 }
 
@@ -157,24 +209,64 @@ func (c *cloneQueue) pop() *cloneJob {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	next := c.jobs.Front()
-	if next == nil {
+	// Smooth weighted round robin: every class with queued jobs earns its
+	// weight in credits, the class with the most credits is picked and pays
+	// for it with the total weight of the classes that took part.
+	next, total := -1, 0
+	for p, jobs := range c.jobs {
+		if jobs.Len() == 0 {
+			continue
+		}
+		c.credits[p] += clonePriorityWeights[p]
+		total += clonePriorityWeights[p]
+		if next == -1 || c.credits[p] > c.credits[next] {
+			next = p
+		}
+	}
+	if next == -1 {
 		return nil
 	}
+	c.credits[next] -= total
 
-	return c.jobs.Remove(next).(*cloneJob)
+	job := c.jobs[next].Remove(c.jobs[next].Front()).(*cloneJob)
+	if c.jobs[next].Len() == 0 {
+		// Classes don't keep credits while they are idle, so that a class
+		// can't build up a burst.
+		c.credits[next] = 0
+	}
+	return job
 }
 
 func (c *cloneQueue) empty() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	return c.jobs.Len() == 0
+	return c.len() == 0
+}
+
+// lenByPriority returns the number of queued jobs of the given priority class.
+func (c *cloneQueue) lenByPriority(p clonePriority) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.jobs[p].Len()
+}
+
+// len returns the number of queued jobs. c.mu must be held.
+func (c *cloneQueue) len() int {
+	n := 0
+	for _, jobs := range c.jobs {
+		n += jobs.Len()
+	}
+	return n
 }
 
 // NewCloneQueue initializes a new cloneQueue.
-func NewCloneQueue(jobs *list.List) *cloneQueue {
-	cq := cloneQueue{jobs: jobs}
+func NewCloneQueue() *cloneQueue {
+	var cq cloneQueue
+	for p := range cq.jobs {
+		cq.jobs[p] = list.New()
+	}
 	cq.cond = sync.NewCond(&cq.cmu)
 
 	return &cq
@@ -244,6 +336,24 @@ type Server struct {
 
 	repoUpdateLocksMu sync.Mutex // protects the map below and also updates to locks.once
 	repoUpdateLocks   map[api.RepoName]*locks
+
+	// execAuditLogs buffers the exec audit log entries written by
+	// WriteExecAuditLogs.
+	execAuditLogs chan *types.GitserverExecAuditLog
+
+	// commitIndexSem limits the number of concurrent commit index updates,
+	// and commitIndexUpdates tracks the repos being updated. Use
+	// s.queueCommitIndexUpdate() instead of using these directly.
+	commitIndexSem     chan struct{}
+	commitIndexUpdates sync.Map // GitDir -> struct{}
+
+	// commitIndexBytes is the size of all commit indexes as of the last
+	// janitor run. It must be accessed atomically.
+	commitIndexBytes int64
+
+	// promisorURLs caches the remote URLs partial clones fetch missing blobs
+	// from. Use s.promisorURL() instead of using it directly.
+	promisorURLs sync.Map // api.RepoName -> cachedPromisorURL
 }
 
 type locks struct {
@@ -300,6 +410,8 @@ func (s *Server) Handler() http.Handler {
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.locker = &RepositoryLocker{}
 	s.repoUpdateLocks = make(map[api.RepoName]*locks)
+	s.execAuditLogs = make(chan *types.GitserverExecAuditLog, execAuditLogBufferSize)
+	s.commitIndexSem = make(chan struct{}, commitIndexConcurrency)
 
 	// GitMaxConcurrentClones controls the maximum number of clones that
 	// can happen at once on a single gitserver.
@@ -343,6 +455,8 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/archive", s.handleArchive)
 	mux.HandleFunc("/exec", s.handleExec)
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/blame", s.handleBlame)
+	mux.HandleFunc("/commit-ancestry", s.handleCommitAncestry)
 	mux.HandleFunc("/p4-exec", s.handleP4Exec)
 	mux.HandleFunc("/list", s.handleList)
 	mux.HandleFunc("/list-gitolite", s.handleListGitolite)
@@ -351,6 +465,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/repos", s.handleRepoInfo)
 	mux.HandleFunc("/repos-stats", s.handleReposStats)
 	mux.HandleFunc("/repo-clone-progress", s.handleRepoCloneProgress)
+	mux.HandleFunc("/commit-index-status", s.handleCommitIndexStatus)
 	mux.HandleFunc("/delete", s.handleRepoDelete)
 	mux.HandleFunc("/repo-update", s.handleRepoUpdate)
 	mux.HandleFunc("/getGitolitePhabricatorMetadata", s.handleGetGitolitePhabricatorMetadata)
@@ -464,16 +579,18 @@ func (s *Server) cloneJobConsumer(ctx context.Context, jobs <-chan *cloneJob) {
 		default:
 		}
 
-		ctx, cancel, err := s.acquireCloneLimiter(ctx)
+		cloneCtx, cancel, err := s.acquireCloneLimiter(ctx)
 		if err != nil {
 			log15.Error("cloneJobConsumer: ", "error", err)
+			j.lock.Release()
 			continue
 		}
+		cloneQueueWaitDuration.WithLabelValues(j.priority().String()).Observe(time.Since(j.queuedAt).Seconds())
 
 		go func(job *cloneJob) {
 			defer cancel()
 
-			err := s.doClone(ctx, job.repo, job.dir, job.syncer, job.lock, job.remoteURL, job.options)
+			err := s.doClone(cloneCtx, job.repo, job.dir, job.syncer, job.lock, job.remoteURL, job.options)
 			if err != nil {
 				log15.Error("failed to clone repo", "repo", job.repo, "error", err)
 			}
@@ -905,7 +1022,10 @@ func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req.Args = append(req.Args, treeish, "--")
-	req.Args = append(req.Args, paths...)
+	for _, path := range paths {
+		// Paths are file paths, not patterns.
+		req.Args = append(req.Args, ":(literal)"+path)
+	}
 
 	s.exec(w, r, req)
 }
@@ -1012,7 +1132,7 @@ func (s *Server) search(ctx context.Context, args *protocol.SearchRequest, match
 			}
 		}
 
-		cloneProgress, err := s.cloneRepo(ctx, args.Repo, nil)
+		cloneProgress, err := s.cloneRepo(ctx, args.Repo, &cloneOptions{Priority: clonePriorityInteractive})
 		if err != nil {
 			log15.Debug("error starting repo clone", "repo", args.Repo, "err", err)
 			return false, &gitdomain.RepoNotExistError{
@@ -1060,6 +1180,23 @@ func (s *Server) search(ctx context.Context, args *protocol.SearchRequest, match
 			Query:       mt,
 			IncludeDiff: args.IncludeDiff,
 		}
+		if isPartialClone(dir) {
+			// Diffs read blobs, which may be missing from partial clones.
+			remoteURL, err := s.promisorURL(ctx, args.Repo)
+			if err != nil {
+				return err
+			}
+			searcher.ConfigureDiffCmd = fetchMissingBlobs(remoteURL)
+		}
+		if conf.SearchIndexCommitsEnabled() && protocol.RevisionsAreHEAD(args.Revisions) {
+			index, err := search.OpenCommitIndex(commitIndexDir(dir))
+			if err == nil {
+				defer index.Close()
+				searcher.Index = index
+			} else if !errors.Is(err, os.ErrNotExist) {
+				log15.Warn("failed to open commit index", "repo", args.Repo, "error", err)
+			}
+		}
 
 		return searcher.Search(ctx, func(match *protocol.CommitMatch) {
 			select {
@@ -1183,6 +1320,25 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 			execRunning.WithLabelValues(cmd, repo).Dec()
 			execDuration.WithLabelValues(cmd, repo, status).Observe(duration.Seconds())
 
+			auditLog := &types.GitserverExecAuditLog{
+				Actor:     r.Header.Get("X-Sourcegraph-Actor"),
+				Client:    r.UserAgent(),
+				Gitserver: s.Hostname,
+				Repo:      req.Repo,
+				Command:   cmd,
+				Args:      req.Args,
+				Status:    status,
+				Duration:  duration,
+			}
+			if exitStatus != -10810 {
+				auditLog.ExitStatus = &exitStatus
+			}
+			if execErr != nil {
+				errorMessage := execErr.Error()
+				auditLog.Error = &errorMessage
+			}
+			s.recordExecAuditLog(auditLog)
+
 			var cmdDuration time.Duration
 			var fetchDuration time.Duration
 			if !cmdStart.IsZero() {
@@ -1258,7 +1414,7 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 			return
 		}
 
-		cloneProgress, err := s.cloneRepo(ctx, req.Repo, nil)
+		cloneProgress, err := s.cloneRepo(ctx, req.Repo, &cloneOptions{Priority: clonePriorityInteractive})
 		if err != nil {
 			log15.Debug("error starting repo clone", "repo", req.Repo, "err", err)
 			status = "repo-not-found"
@@ -1283,6 +1439,19 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 		}
 	}
 
+	// Partial clones are missing some blobs, which need special handling.
+	configureCmd := func(*exec.Cmd) {}
+	if isPartialClone(dir) {
+		var cleanup func()
+		configureCmd, cleanup, execErr = s.partialCloneExec(ctx, w, req, dir)
+		if execErr != nil {
+			status = "partial-clone-error"
+			http.Error(w, execErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer cleanup()
+	}
+
 	w.Header().Set("Content-Type", "application/octet-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 
@@ -1323,6 +1492,7 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	cmdStart = time.Now()
 	cmd := exec.CommandContext(ctx, "git", req.Args...)
 	dir.Set(cmd)
+	configureCmd(cmd)
 	cmd.Stdout = stdoutW
 	cmd.Stderr = stderrW
 
@@ -1502,14 +1672,14 @@ func (s *Server) p4exec(w http.ResponseWriter, r *http.Request, req *protocol.P4
 }
 
 func (s *Server) setLastError(ctx context.Context, name api.RepoName, error string) (err error) {
-	if s.DB == nil {
+	if s.DB == nil || s.replicaOnly(name) {
 		return nil
 	}
 	return database.GitserverRepos(s.DB).SetLastError(ctx, name, error, s.Hostname)
 }
 
 func (s *Server) setLastFetched(ctx context.Context, name api.RepoName) error {
-	if s.DB == nil {
+	if s.DB == nil || s.replicaOnly(name) {
 		return nil
 	}
 
@@ -1544,7 +1714,7 @@ func (s *Server) setLastErrorNonFatal(ctx context.Context, name api.RepoName, er
 }
 
 func (s *Server) setCloneStatus(ctx context.Context, name api.RepoName, status types.CloneStatus) (err error) {
-	if s.DB == nil {
+	if s.DB == nil || s.replicaOnly(name) {
 		return nil
 	}
 	return database.GitserverRepos(s.DB).SetCloneStatus(ctx, name, status, s.Hostname)
@@ -1601,6 +1771,10 @@ type cloneOptions struct {
 	// Once migration is complete for all repos in Sourcegraph, there is no need for this attribute
 	// and it should be removed.
 	MigrateFrom string
+
+	// Priority is the class the clone is scheduled with in the clone queue.
+	// Blocking clones are not queued.
+	Priority clonePriority
 }
 
 // cloneRepo performs a clone operation for the given repository. It is
@@ -1634,19 +1808,22 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 	// checks being blocked by a few slow clones will lead to poor feedback to
 	// users. We can defer since the rest of the function does not block this
 	// goroutine.
-	ctx, cancel, err := s.acquireCloneableLimiter(ctx)
+	cloneableCtx, cancel, err := s.acquireCloneableLimiter(ctx)
 	if err != nil {
 		return "", err // err will be a context error
 	}
-	defer cancel()
-
-	if err = s.rpsLimiter.Wait(ctx); err != nil {
-		return "", err
+	err = s.rpsLimiter.Wait(cloneableCtx)
+	if err == nil {
+		if err = syncer.IsCloneable(cloneableCtx, remoteURL); err != nil {
+			redactedErr := newURLRedactor(remoteURL).redact(err.Error())
+			err = errors.Errorf("error cloning repo: repo %s not cloneable: %s", repo, redactedErr)
+		}
 	}
-
-	if err := syncer.IsCloneable(ctx, remoteURL); err != nil {
-		redactedErr := newURLRedactor(remoteURL).redact(err.Error())
-		return "", errors.Errorf("error cloning repo: repo %s not cloneable: %s", repo, redactedErr)
+	// The cloneable limiter is only held for the check, so that blocking clones
+	// waiting in the clone queue don't hold up the checks of other clones.
+	cancel()
+	if err != nil {
+		return "", err
 	}
 
 	// Mark this repo as currently being cloned. We have to check again if someone else isn't already
@@ -1668,13 +1845,15 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 	// clones in the repo tree. This also avoids leaving behind corrupt clones
 	// if the clone is interrupted.
 	if opts != nil && opts.Block {
+		// We are blocking, so use the passed in context. Blocking clones
+		// bypass the clone queue: their callers, such as the janitor and
+		// repo updates, must not wait behind bulk clones.
 		ctx, cancel, err := s.acquireCloneLimiter(ctx)
 		if err != nil {
 			return "", err
 		}
 		defer cancel()
 
-		// We are blocking, so use the passed in context.
 		err = s.doClone(ctx, repo, dir, syncer, lock, remoteURL, opts)
 		err = errors.Wrapf(err, "failed to clone %s", repo)
 		// Use a background context to ensure we still update the DB even if we time out
@@ -1765,11 +1944,25 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 
 	removeBadRefs(ctx, tmp)
 
+	if svnSyncer, ok := syncer.(*SVNRepoSyncer); ok {
+		// git svn fetches into remote-tracking refs, which must be mapped to
+		// branches before HEAD can point to one.
+		if err := svnSyncer.updateRefs(ctx, tmp); err != nil {
+			return err
+		}
+	}
+
 	if err := setHEAD(ctx, tmp, syncer, repo, remoteURL); err != nil {
 		log15.Error("Failed to ensure HEAD exists", "repo", repo, "error", err)
 		return errors.Wrap(err, "failed to ensure HEAD exists")
 	}
 
+	if gitSyncer, ok := syncer.(*GitRepoSyncer); ok {
+		if err := prefetchSparsePaths(ctx, tmp, remoteURL, gitSyncer.PartialClone); err != nil {
+			return err
+		}
+	}
+
 	if err := setRepositoryType(tmp, syncer.Type()); err != nil {
 		return errors.Wrap(err, `git config set "sourcegraph.type"`)
 	}
@@ -1805,6 +1998,8 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 		log15.Warn("failed setting last fetch in DB", "repo", repo, "error", err)
 	}
 
+	s.queueCommitIndexUpdate(dir, true)
+
 	log15.Info("repo cloned", "repo", repo)
 	repoClonedCounter.Inc()
 
@@ -2122,6 +2317,8 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 		log15.Warn("failed setting last fetch in DB", "repo", repo, "error", err)
 	}
 
+	s.queueCommitIndexUpdate(dir, true)
+
 	return nil
 }
 
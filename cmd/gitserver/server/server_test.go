@@ -2,7 +2,6 @@ package server
 
 import (
 	"bytes"
-	"container/list"
 	"context"
 	"encoding/json"
 	"flag"
@@ -473,7 +472,7 @@ func makeTestServer(ctx context.Context, repoDir, remote string, db dbutil.DB) *
 			return &GitRepoSyncer{}, nil
 		},
 		DB:               db,
-		CloneQueue:       NewCloneQueue(list.New()),
+		CloneQueue:       NewCloneQueue(),
 		ctx:              ctx,
 		locker:           &RepositoryLocker{},
 		cloneLimiter:     mutablelimiter.New(1),
@@ -837,6 +836,91 @@ func TestCloneRepo_EnsureValidity(t *testing.T) {
 	})
 }
 
+func TestCloneQueue_Priority(t *testing.T) {
+	q := NewCloneQueue()
+	push := func(p clonePriority, n int) {
+		for i := 0; i < n; i++ {
+			q.push(&cloneJob{
+				repo:    api.RepoName(fmt.Sprintf("%s-%d", p, i)),
+				options: &cloneOptions{Priority: p},
+			})
+		}
+	}
+	push(clonePriorityBackfill, 20)
+	push(clonePriorityScheduled, 20)
+	push(clonePriorityInteractive, 2)
+
+	var got []string
+	for i := 0; i < 8; i++ {
+		got = append(got, string(q.pop().repo))
+	}
+	// Interactive clones jump the queue, and the other classes are served
+	// according to their weights.
+	want := []string{
+		"interactive-0", "interactive-1",
+		"scheduled-0", "scheduled-1", "scheduled-2", "scheduled-3",
+		"backfill-0", "scheduled-4",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected order (-want +got):\n%s", diff)
+	}
+
+	for !q.empty() {
+		q.pop()
+	}
+	if job := q.pop(); job != nil {
+		t.Fatalf("expected empty queue, got %v", job.repo)
+	}
+}
+
+func TestCloneRepo_BlockingSkipsQueue(t *testing.T) {
+	remote := t.TempDir()
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, name, arg...)
+	}
+	wantCommit := makeSingleCommitRepo(cmd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The clone pipeline is not started, so queued clones never run.
+	s := &Server{
+		ReposDir:         t.TempDir(),
+		GetRemoteURLFunc: staticGetRemoteURL(remote),
+		GetVCSSyncer: func(ctx context.Context, name api.RepoName) (VCSSyncer, error) {
+			return &GitRepoSyncer{}, nil
+		},
+		CloneQueue:       NewCloneQueue(),
+		ctx:              ctx,
+		locker:           &RepositoryLocker{},
+		cloneLimiter:     mutablelimiter.New(1),
+		cloneableLimiter: mutablelimiter.New(1),
+		rpsLimiter:       rate.NewLimiter(rate.Inf, 10),
+	}
+	for i := 0; i < 10; i++ {
+		s.CloneQueue.push(&cloneJob{
+			repo:    api.RepoName(fmt.Sprintf("scheduled-%d", i)),
+			options: &cloneOptions{Priority: clonePriorityScheduled},
+		})
+	}
+
+	ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	repoName := api.RepoName("example.com/foo/bar")
+	if _, err := s.cloneRepo(ctx, repoName, &cloneOptions{Block: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	gotCommit := runCmd(t, string(s.dir(repoName)), "git", "rev-parse", "HEAD")
+	if gotCommit != wantCommit {
+		t.Fatalf("got commit %q, want %q", gotCommit, wantCommit)
+	}
+	if n := s.CloneQueue.lenByPriority(clonePriorityScheduled); n != 10 {
+		t.Fatalf("expected the queue to be untouched, got %d jobs", n)
+	}
+}
+
 func TestHostnameMatch(t *testing.T) {
 	testCases := []struct {
 		hostname    string
@@ -7,10 +7,23 @@ import (
 
 	"github.com/inconshreveable/log15"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
 )
 
+var (
+	cloneQueueEnqueued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_clone_queue_enqueued_total",
+		Help: "Number of clone jobs added to the clone queue, by priority class.",
+	}, []string{"priority"})
+	cloneQueueWaitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "src_gitserver_clone_queue_wait_duration_seconds",
+		Help:    "Time clone jobs spend in the clone queue before they start cloning, by priority class.",
+		Buckets: []float64{0.1, 1, 5, 30, 60, 300, 900, 1800, 3600, 7200},
+	}, []string{"priority"})
+)
+
 func (s *Server) RegisterMetrics() {
 	// test the latency of exec, which may increase under certain memory
 	// conditions
@@ -31,6 +44,20 @@ func (s *Server) RegisterMetrics() {
 		}
 	}()
 
+	// report the length of the clone queue per priority class
+	if s.CloneQueue != nil {
+		for p := clonePriority(0); p < numClonePriorities; p++ {
+			p := p
+			prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name:        "src_gitserver_clone_queue_length",
+				Help:        "Number of clone jobs waiting in the clone queue, by priority class.",
+				ConstLabels: prometheus.Labels{"priority": p.String()},
+			}, func() float64 {
+				return float64(s.CloneQueue.lenByPriority(p))
+			}))
+		}
+	}
+
 	// report the size of the repos dir
 	if s.ReposDir == "" {
 		log15.Error("ReposDir is not set, cannot export disk_space_available metric.")
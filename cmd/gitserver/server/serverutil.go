@@ -175,6 +175,18 @@ func runWith(ctx context.Context, cmd *exec.Cmd, configRemoteOpts bool, progress
 	return b.Bytes(), err
 }
 
+// hasAskpass returns true if env sets GIT_ASKPASS to a program other than the
+// one inherited from the gitserver environment.
+func hasAskpass(env []string) bool {
+	inherited := "GIT_ASKPASS=" + os.Getenv("GIT_ASKPASS")
+	for _, e := range env {
+		if strings.HasPrefix(e, "GIT_ASKPASS=") && e != inherited {
+			return true
+		}
+	}
+	return false
+}
+
 func configureRemoteGitCommand(cmd *exec.Cmd, tlsConf *tlsConfig) {
 	// As a special case we also support the experimental p4-fusion client which is
 	// not run as a subcommand of git.
@@ -182,7 +194,11 @@ func configureRemoteGitCommand(cmd *exec.Cmd, tlsConf *tlsConfig) {
 		panic("Only git or p4-fusion commands are supported")
 	}
 
-	cmd.Env = append(cmd.Env, "GIT_ASKPASS=true") // disable password prompt
+	// Disable password prompt, unless the command supplies the password with
+	// its own askpass program.
+	if !hasAskpass(cmd.Env) {
+		cmd.Env = append(cmd.Env, "GIT_ASKPASS=true")
+	}
 
 	// Suppress asking to add SSH host key to known_hosts (which will hang because
 	// the command is non-interactive).
@@ -32,7 +32,10 @@ type VCSSyncer interface {
 }
 
 // GitRepoSyncer is a syncer for Git repositories.
-type GitRepoSyncer struct{}
+type GitRepoSyncer struct {
+	// PartialClone, if set, clones repositories partially.
+	PartialClone *PartialCloneOptions
+}
 
 func (s *GitRepoSyncer) Type() string {
 	return "git"
@@ -77,13 +80,26 @@ func (s *GitRepoSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, tm
 		return nil, errors.Wrapf(err, "clone setup failed")
 	}
 
-	cmd, _ = s.fetchCommand(ctx, remoteURL)
+	if s.PartialClone != nil {
+		if err := configurePartialClone(GitDir(tmpPath), s.PartialClone); err != nil {
+			return nil, errors.Wrapf(err, "clone setup failed")
+		}
+	}
+
+	cmd, _ = s.fetchCommand(ctx, remoteURL, s.PartialClone != nil)
 	cmd.Dir = tmpPath
 	return cmd, nil
 }
 
-func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, configRemoteOpts bool) {
+// fetchCommand returns the command to fetch from remoteURL. Partial clones
+// fetch from their promisor remote, so that the fetched objects are filtered
+// the way they were at clone time.
+func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL, partialClone bool) (cmd *exec.Cmd, configRemoteOpts bool) {
 	configRemoteOpts = true
+	remote := remoteURL.String()
+	if partialClone {
+		remote = promisorRemote
+	}
 	if customCmd := customFetchCmd(ctx, remoteURL); customCmd != nil {
 		cmd = customCmd
 		configRemoteOpts = false
@@ -91,7 +107,7 @@ func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (c
 		cmd = refspecOverridesFetchCmd(ctx, remoteURL)
 	} else {
 		cmd = exec.CommandContext(ctx, "git", "fetch",
-			"--progress", "--prune", remoteURL.String(),
+			"--progress", "--prune", remote,
 			// Normal git refs
 			"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*",
 			// GitHub pull requests
@@ -104,17 +120,24 @@ func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (c
 			"+refs/changes/*:refs/changes/*",
 			// Possibly deprecated refs for sourcegraph zap experiment?
 			"+refs/sourcegraph/*:refs/sourcegraph/*")
+		if partialClone {
+			withPromisorURL(cmd, remoteURL)
+		}
 	}
 	return cmd, configRemoteOpts
 }
 
 // Fetch tries to fetch updates of a Git repository.
 func (s *GitRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
-	cmd, configRemoteOpts := s.fetchCommand(ctx, remoteURL)
+	partialClone := isPartialClone(dir)
+	cmd, configRemoteOpts := s.fetchCommand(ctx, remoteURL, partialClone)
 	dir.Set(cmd)
 	if output, err := runWith(ctx, cmd, configRemoteOpts, nil); err != nil {
 		return errors.Wrapf(err, "failed to update with output %q", newURLRedactor(remoteURL).redact(string(output)))
 	}
+	if partialClone {
+		return prefetchSparsePaths(ctx, dir, remoteURL, s.PartialClone)
+	}
 	return nil
 }
 
@@ -468,7 +468,12 @@ func roundJVMVersionToNearestStableVersion(javaVersion int) int {
 }
 
 func runCommandInDirectory(ctx context.Context, cmd *exec.Cmd, workingDirectory string, dependency reposource.MavenDependency) (string, error) {
-	gitName := dependency.MavenModule.CoursierSyntax() + " authors"
+	return runCommandInDirectoryAs(ctx, cmd, workingDirectory, dependency.MavenModule.CoursierSyntax()+" authors")
+}
+
+// runCommandInDirectoryAs runs a git command with a stable author, committer
+// and date so that package repos always produce the same git revhashes.
+func runCommandInDirectoryAs(ctx context.Context, cmd *exec.Cmd, workingDirectory, gitName string) (string, error) {
 	gitEmail := "code-intel@sourcegraph.com"
 	cmd.Dir = workingDirectory
 	cmd.Env = append(cmd.Env, "EMAIL="+gitEmail)
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+// hgRemote is the name of the remote Mercurial repositories are fetched from
+// by git-remote-hg. It is not called origin since the janitor removes origin,
+// and its URL is never stored: it is passed to each fetch. git-remote-hg keeps
+// its copy of the Mercurial repository and the marks mapping Mercurial
+// changesets to Git commits in the hg/<remote> directory of the Git
+// repository, so the remote name must not change between fetches for them to
+// be incremental.
+const hgRemote = "hg"
+
+// HgRepoSyncer is a syncer for Mercurial repositories. It converts them into
+// Git repositories with the git-remote-hg remote helper, which imports
+// Mercurial changesets with git fast-import.
+type HgRepoSyncer struct{}
+
+func (s *HgRepoSyncer) Type() string {
+	return "mercurial"
+}
+
+// IsCloneable checks to see if the Mercurial remote URL is cloneable.
+func (s *HgRepoSyncer) IsCloneable(ctx context.Context, remoteURL *vcs.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "hg", "identify", "--rev", "tip", remoteURL.String())
+	cmd.Env = hgEnv()
+	out, err := runWith(ctx, cmd, false, nil)
+	if err != nil {
+		if ctxerr := ctx.Err(); ctxerr != nil {
+			err = ctxerr
+		}
+		if len(out) > 0 {
+			err = errors.Errorf("%s (output follows)\n\n%s", err, newURLRedactor(remoteURL).redact(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+// CloneCommand returns the command to be executed for cloning a Mercurial
+// repository as a Git repository.
+func (s *HgRepoSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, tmpPath string) (cmd *exec.Cmd, err error) {
+	if err := os.MkdirAll(tmpPath, os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "clone failed to create tmp dir")
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "init", "--bare", ".")
+	cmd.Dir = tmpPath
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "clone setup failed")
+	}
+
+	cmd = hgFetchCommand(ctx, remoteURL)
+	cmd.Dir = tmpPath
+	return cmd, nil
+}
+
+// Fetch tries to fetch updates of a Mercurial repository as a Git repository.
+// Only the changesets added since the last fetch are converted.
+func (s *HgRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	cmd := hgFetchCommand(ctx, remoteURL)
+	dir.Set(cmd)
+	if output, err := runWith(ctx, cmd, true, nil); err != nil {
+		return errors.Wrapf(err, "failed to update with output %q", newURLRedactor(remoteURL).redact(string(output)))
+	}
+	return nil
+}
+
+// RemoteShowCommand returns the command to be executed for showing Git remote
+// of a Mercurial repository.
+func (s *HgRepoSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
+	// git-remote-hg maps the default Mercurial branch to master, which is
+	// what HEAD points to in the converted repository.
+	return exec.CommandContext(ctx, "git", "remote", "show", "./"), nil
+}
+
+// hgFetchCommand returns the command to fetch the Mercurial repository at
+// remoteURL into the Git repository the command is run in.
+func hgFetchCommand(ctx context.Context, remoteURL *vcs.URL) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git",
+		"-c", "remote."+hgRemote+".url=hg::"+remoteURL.String(),
+		"fetch", "--progress", "--prune", hgRemote,
+		// Mercurial bookmarks and branches are mapped to Git branches.
+		"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")
+	cmd.Env = hgEnv()
+	return cmd
+}
+
+// hgEnv returns the environment to run hg, or git-remote-hg, in.
+func hgEnv() []string {
+	// HGPLAIN disables user configuration which changes hg's output, such
+	// as localization.
+	return append(os.Environ(), "HGPLAIN=1")
+}
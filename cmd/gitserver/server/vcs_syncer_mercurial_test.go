@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+func TestHgRepoSyncer(t *testing.T) {
+	for _, command := range []string{"hg", "git-remote-hg"} {
+		if _, err := exec.LookPath(command); err != nil {
+			t.Skip("command not in PATH: " + command)
+		}
+	}
+
+	ctx := context.Background()
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote")
+	hg := func(arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, "hg", append([]string{"--config", "ui.username=a <a@a.com>"}, arg...)...)
+	}
+	runCmd(t, root, "hg", "init", remote)
+	runCmd(t, remote, "sh", "-c", "echo hello > hello.txt")
+	hg("add", "hello.txt")
+	hg("commit", "-m", "initial")
+
+	remoteURL, err := vcs.ParseURL(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncer := &HgRepoSyncer{}
+	if err := syncer.IsCloneable(ctx, remoteURL); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := GitDir(filepath.Join(root, "repo", ".git"))
+	cmd, err := syncer.CloneCommand(ctx, remoteURL, string(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := runWith(ctx, cmd, false, nil); err != nil {
+		t.Fatalf("clone failed: %s\n%s", err, out)
+	}
+	gitLog := func() string {
+		t.Helper()
+		return strings.TrimSpace(runCmd(t, string(dir), "git", "log", "--format=%s", "master"))
+	}
+	if got, want := gitLog(), "initial"; got != want {
+		t.Fatalf("unexpected log after clone: got %q, want %q", got, want)
+	}
+
+	runCmd(t, remote, "sh", "-c", "echo world >> hello.txt")
+	hg("commit", "-m", "second")
+	if err := syncer.Fetch(ctx, remoteURL, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Split(gitLog(), "\n"), []string{"second", "initial"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected log after fetch: got %q, want %q", got, want)
+	}
+
+	// The remote URL is not stored in the repository.
+	if out, _ := exec.Command("git", "-C", string(dir), "config", "--get-regexp", `^remote\.`).Output(); len(out) > 0 {
+		t.Fatalf("expected no remotes to be configured, got %q", out)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/npmpackages/npm"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// placeholderNPMDependency is used to set GIT_AUTHOR_NAME for git commands
+// that don't create commits or tags. The name of this dependency should never
+// be publicly visible so it can have any random value.
+var placeholderNPMDependency = &reposource.NPMDependency{
+	NPMPackage: reposource.NPMPackage{
+		Scope: "sourcegraph",
+		Name:  "sourcegraph",
+	},
+	Version: "1.0.0",
+}
+
+type NPMPackagesSyncer struct {
+	Config *schema.NPMPackagesConnection
+}
+
+var _ VCSSyncer = &NPMPackagesSyncer{}
+
+func (s *NPMPackagesSyncer) Type() string {
+	return "npm_packages"
+}
+
+// IsCloneable checks to see if the VCS remote URL is cloneable. Any non-nil
+// error indicates there is a problem.
+func (s *NPMPackagesSyncer) IsCloneable(ctx context.Context, remoteURL *vcs.URL) error {
+	return s.packagesSyncer().IsCloneable(ctx, remoteURL)
+}
+
+// CloneCommand returns the command to be executed for cloning from remote.
+func (s *NPMPackagesSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, bareGitDirectory string) (*exec.Cmd, error) {
+	return s.packagesSyncer().CloneCommand(ctx, remoteURL, bareGitDirectory)
+}
+
+// Fetch adds git tags for newly added dependency versions and removes git tags
+// for deleted versions.
+func (s *NPMPackagesSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	return s.packagesSyncer().Fetch(ctx, remoteURL, dir)
+}
+
+// RemoteShowCommand returns the command to be executed for showing remote.
+func (s *NPMPackagesSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
+	return s.packagesSyncer().RemoteShowCommand(ctx, remoteURL)
+}
+
+func (s *NPMPackagesSyncer) packagesSyncer() *packagesSyncer {
+	return &packagesSyncer{source: s, placeholder: placeholderNPMDependency}
+}
+
+// packageDependencies returns the list of npm dependencies that belong to the
+// given URL path. The returned package dependencies are sorted by semantic
+// versioning. A URL maps to a single npm package, which may contain multiple
+// versions (one git tag per version).
+func (s *NPMPackagesSyncer) packageDependencies(ctx context.Context, repoUrlPath string) ([]packageDependency, error) {
+	pkg, err := reposource.ParseNPMPackageFromRepoURL(repoUrlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var npmDependencies []reposource.NPMDependency
+	for _, dependency := range s.Config.Dependencies {
+		if !pkg.MatchesDependencyString(dependency) {
+			continue
+		}
+		dependency, err := reposource.ParseNPMDependency(dependency)
+		if err != nil {
+			return nil, err
+		}
+		npmDependencies = append(npmDependencies, dependency)
+	}
+
+	if len(npmDependencies) == 0 {
+		return nil, errors.Errorf("no npm dependencies for URL path %s", repoUrlPath)
+	}
+
+	reposource.SortNPMDependencies(npmDependencies)
+	dependencies := make([]packageDependency, 0, len(npmDependencies))
+	for i := range npmDependencies {
+		dependencies = append(dependencies, &npmDependencies[i])
+	}
+	return dependencies, nil
+}
+
+func (s *NPMPackagesSyncer) exists(ctx context.Context, dependency packageDependency) error {
+	_, err := npm.Exists(ctx, s.Config, *dependency.(*reposource.NPMDependency))
+	return err
+}
+
+// download fetches the tarball of the given dependency and extracts it into
+// workingDirectory.
+func (s *NPMPackagesSyncer) download(ctx context.Context, dependency packageDependency, workingDirectory string) error {
+	tarball, err := npm.FetchSources(ctx, s.Config, *dependency.(*reposource.NPMDependency))
+	if err != nil {
+		return err
+	}
+	defer tarball.Close()
+
+	if err := decompressTgz(tarball, workingDirectory); err != nil {
+		return errors.Wrapf(err, "failed to decompress tarball for %s", dependency.PackageManagerSyntax())
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/npmpackages/npm/npmtest"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	exampleNPMFilePath           = "index.js"
+	exampleNPMFileContents       = "module.exports = 1;\n"
+	exampleNPMFileContents2      = "module.exports = 2;\n"
+	exampleNPMPackageVersion     = "1.0.0"
+	exampleNPMPackageVersion2    = "2.0.0"
+	exampleNPMPackageDependency  = "@example/example@1.0.0"
+	exampleNPMPackageDependency2 = "@example/example@2.0.0"
+	exampleNPMPackageUrl         = "npm/example/example"
+)
+
+func (s NPMPackagesSyncer) runCloneCommand(t *testing.T, bareGitDirectory string, dependencies []string) {
+	url := vcs.URL{
+		URL: url.URL{Path: exampleNPMPackageUrl},
+	}
+	s.Config.Dependencies = dependencies
+	cmd, err := s.CloneCommand(context.Background(), &url, bareGitDirectory)
+	assert.Nil(t, err)
+	assert.Nil(t, cmd.Run())
+}
+
+func TestNPMCloneCommand(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	registry := npmtest.NewRegistry(t, map[string][]byte{
+		exampleNPMPackageDependency:  npmtest.Tarball(t, map[string]string{exampleNPMFilePath: exampleNPMFileContents}),
+		exampleNPMPackageDependency2: npmtest.Tarball(t, map[string]string{exampleNPMFilePath: exampleNPMFileContents2}),
+	})
+
+	s := NPMPackagesSyncer{
+		Config: &schema.NPMPackagesConnection{Registry: registry.URL},
+	}
+	bareGitDirectory := path.Join(dir, "git")
+
+	s.runCloneCommand(t, bareGitDirectory, []string{exampleNPMPackageDependency})
+	assertCommandOutput(t,
+		exec.Command("git", "tag", "--list"),
+		bareGitDirectory,
+		"v1.0.0\n",
+	)
+	assertCommandOutput(t,
+		exec.Command("git", "show", fmt.Sprintf("v%s:%s", exampleNPMPackageVersion, exampleNPMFilePath)),
+		bareGitDirectory,
+		exampleNPMFileContents,
+	)
+
+	s.runCloneCommand(t, bareGitDirectory, []string{exampleNPMPackageDependency, exampleNPMPackageDependency2})
+	assertCommandOutput(t,
+		exec.Command("git", "tag", "--list"),
+		bareGitDirectory,
+		"v1.0.0\nv2.0.0\n", // verify that the v2.0.0 tag got added
+	)
+	assertCommandOutput(t,
+		exec.Command("git", "show", fmt.Sprintf("v%s:%s", exampleNPMPackageVersion2, exampleNPMFilePath)),
+		bareGitDirectory,
+		exampleNPMFileContents2,
+	)
+	assertCommandOutput(t,
+		exec.Command("git", "show", fmt.Sprintf("latest:%s", exampleNPMFilePath)),
+		bareGitDirectory,
+		exampleNPMFileContents2,
+	)
+
+	s.runCloneCommand(t, bareGitDirectory, []string{exampleNPMPackageDependency})
+	assertCommandOutput(t,
+		exec.Command("git", "tag", "--list"),
+		bareGitDirectory,
+		"v1.0.0\n", // verify that the v2.0.0 tag has been removed.
+	)
+}
+
+func TestNPMNoMaliciousFiles(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range []string{
+		"package/ok.js",
+		"/package/absolute.js",
+		"package/../../escape.js",
+		"package/.git/config",
+		"toplevel.js",
+	} {
+		assert.Nil(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write([]byte("x"))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tarWriter.WriteHeader(&tar.Header{Name: "package/link", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}))
+	assert.Nil(t, tarWriter.Close())
+	assert.Nil(t, gzipWriter.Close())
+
+	extractPath := path.Join(dir, "extracted")
+	assert.Nil(t, os.Mkdir(extractPath, os.ModePerm))
+	assert.Nil(t, decompressTgz(&buf, extractPath))
+
+	files, err := os.ReadDir(extractPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "ok.js", files[0].Name())
+}
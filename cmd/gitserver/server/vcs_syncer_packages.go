@@ -0,0 +1,297 @@
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+// packageDependency is a version of a package. The repo of a package has one
+// git tag per version.
+type packageDependency interface {
+	PackageSyntax() string
+	PackageManagerSyntax() string
+	GitTagFromVersion() string
+}
+
+// packageSource is the part of a package syncer that is specific to a
+// package host.
+type packageSource interface {
+	// packageDependencies returns the versions of the package that belongs
+	// to the given URL path, sorted by semantic versioning. The latest
+	// version is the first element of the slice.
+	packageDependencies(ctx context.Context, repoUrlPath string) ([]packageDependency, error)
+
+	// exists returns a non-nil error if dependency can't be downloaded from
+	// the package host.
+	exists(ctx context.Context, dependency packageDependency) error
+
+	// download writes the files of dependency into workingDirectory.
+	download(ctx context.Context, dependency packageDependency, workingDirectory string) error
+}
+
+// packagesSyncer implements the VCSSyncer methods shared by the syncers of
+// package hosts, on top of the packageSource of a host.
+type packagesSyncer struct {
+	source packageSource
+
+	// placeholder is used to set GIT_AUTHOR_NAME for git commands that don't
+	// create commits or tags. The name of this dependency should never be
+	// publicly visible so it can have any random value.
+	placeholder packageDependency
+}
+
+// IsCloneable checks to see if the VCS remote URL is cloneable. Any non-nil
+// error indicates there is a problem.
+func (s *packagesSyncer) IsCloneable(ctx context.Context, remoteURL *vcs.URL) error {
+	dependencies, err := s.source.packageDependencies(ctx, remoteURL.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, dependency := range dependencies {
+		if err := s.source.exists(ctx, dependency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloneCommand returns the command to be executed for cloning from remote.
+// Like JVM packages, the actual cloning happens inside this method and the
+// returned command is a no-op.
+func (s *packagesSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, bareGitDirectory string) (*exec.Cmd, error) {
+	err := os.MkdirAll(bareGitDirectory, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "--bare", "init")
+	if _, err := runPackageCommandInDirectory(ctx, cmd, bareGitDirectory, s.placeholder); err != nil {
+		return nil, err
+	}
+
+	// The Fetch method is responsible for cleaning up temporary directories.
+	if err := s.Fetch(ctx, remoteURL, GitDir(bareGitDirectory)); err != nil {
+		return nil, err
+	}
+
+	// no-op command to satisfy VCSSyncer interface, see docstring for more details.
+	return exec.CommandContext(ctx, "git", "--version"), nil
+}
+
+// Fetch adds git tags for newly added dependency versions and removes git tags
+// for deleted versions.
+func (s *packagesSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	dependencies, err := s.source.packageDependencies(ctx, remoteURL.Path)
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]bool{}
+
+	out, err := runPackageCommandInDirectory(ctx, exec.CommandContext(ctx, "git", "tag"), string(dir), s.placeholder)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		tags[line] = true
+	}
+
+	for i, dependency := range dependencies {
+		if tags[dependency.GitTagFromVersion()] {
+			continue
+		}
+		// the gitPushDependencyTag method is reponsible for cleaning up temporary directories.
+		if err := s.gitPushDependencyTag(ctx, string(dir), dependency, i == 0); err != nil {
+			return errors.Wrapf(err, "error pushing dependency %q", dependency.PackageManagerSyntax())
+		}
+	}
+
+	dependencyTags := make(map[string]struct{}, len(dependencies))
+	for _, dependency := range dependencies {
+		dependencyTags[dependency.GitTagFromVersion()] = struct{}{}
+	}
+
+	for tag := range tags {
+		if _, isDependencyTag := dependencyTags[tag]; !isDependencyTag {
+			cmd := exec.CommandContext(ctx, "git", "tag", "-d", tag)
+			if _, err := runPackageCommandInDirectory(ctx, cmd, string(dir), s.placeholder); err != nil {
+				log15.Error("Failed to delete git tag", "error", err, "tag", tag)
+				continue
+			}
+		}
+	}
+
+	return nil
+}
+
+// RemoteShowCommand returns the command to be executed for showing remote.
+func (s *packagesSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
+	return exec.CommandContext(ctx, "git", "remote", "show", "./"), nil
+}
+
+// gitPushDependencyTag pushes a git tag to the given bareGitDirectory path. The
+// tag points to a commit that adds all sources of given dependency. When
+// isLatestVersion is true, the main branch of the bare git directory will also
+// be updated to point to the same commit as the git tag.
+func (s *packagesSyncer) gitPushDependencyTag(ctx context.Context, bareGitDirectory string, dependency packageDependency, isLatestVersion bool) error {
+	tmpDirectory, err := os.MkdirTemp("", "package")
+	if err != nil {
+		return err
+	}
+	// Always clean up created temporary directories.
+	defer os.RemoveAll(tmpDirectory)
+
+	cmd := exec.CommandContext(ctx, "git", "init")
+	if _, err := runPackageCommandInDirectory(ctx, cmd, tmpDirectory, dependency); err != nil {
+		return err
+	}
+
+	err = s.commitDependency(ctx, dependency, tmpDirectory)
+	if err != nil {
+		return err
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "remote", "add", "origin", bareGitDirectory)
+	if _, err := runPackageCommandInDirectory(ctx, cmd, tmpDirectory, dependency); err != nil {
+		return err
+	}
+
+	// Use --no-verify for security reasons. See https://github.com/sourcegraph/sourcegraph/pull/23399
+	cmd = exec.CommandContext(ctx, "git", "push", "--no-verify", "--force", "origin", "--tags")
+	if _, err := runPackageCommandInDirectory(ctx, cmd, tmpDirectory, dependency); err != nil {
+		return err
+	}
+
+	if isLatestVersion {
+		defaultBranch, err := runPackageCommandInDirectory(ctx, exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD"), tmpDirectory, dependency)
+		if err != nil {
+			return err
+		}
+		// Use --no-verify for security reasons. See https://github.com/sourcegraph/sourcegraph/pull/23399
+		cmd = exec.CommandContext(ctx, "git", "push", "--no-verify", "--force", "origin", strings.TrimSpace(defaultBranch)+":latest", dependency.GitTagFromVersion())
+		if _, err := runPackageCommandInDirectory(ctx, cmd, tmpDirectory, dependency); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commitDependency creates a git commit in the given working directory that
+// adds all the files of the given dependency, and tags it.
+func (s *packagesSyncer) commitDependency(ctx context.Context, dependency packageDependency, workingDirectory string) error {
+	if err := s.source.download(ctx, dependency, workingDirectory); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "add", ".")
+	if _, err := runPackageCommandInDirectory(ctx, cmd, workingDirectory, dependency); err != nil {
+		return err
+	}
+
+	// Use --no-verify for security reasons. See https://github.com/sourcegraph/sourcegraph/pull/23399
+	cmd = exec.CommandContext(ctx, "git", "commit", "--no-verify", "-m", dependency.PackageManagerSyntax(), "--date", stableGitCommitDate)
+	if _, err := runPackageCommandInDirectory(ctx, cmd, workingDirectory, dependency); err != nil {
+		return err
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "tag", "-m", dependency.PackageManagerSyntax(), dependency.GitTagFromVersion())
+	if _, err := runPackageCommandInDirectory(ctx, cmd, workingDirectory, dependency); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// decompressTgz extracts the regular files of a gzipped tarball into
+// destination. npm tarballs and Python sdists nest all files under a single
+// top-level directory (such as "package/"), which is stripped.
+func decompressTgz(tgz io.Reader, destination string) error {
+	gzipReader, err := gzip.NewReader(tgz)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	destinationDirectory := strings.TrimSuffix(destination, string(os.PathSeparator)) + string(os.PathSeparator)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			// Skip directories, and for security reasons links and devices.
+			continue
+		}
+
+		name := strings.TrimPrefix(header.Name, "./")
+		if strings.HasPrefix(name, "/") {
+			// Skip absolute paths.
+			continue
+		}
+		// Strip the top-level directory.
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name = parts[1]
+		if name == ".git" || strings.HasPrefix(name, ".git/") {
+			// For security reasons, don't extract files under the `.git/`
+			// directory. See https://github.com/sourcegraph/security-issues/issues/163
+			continue
+		}
+		cleanedOutputPath := path.Join(destination, name)
+		if !strings.HasPrefix(cleanedOutputPath, destinationDirectory) {
+			// For security reasons, skip file if it's not a child
+			// of the target directory. See "Zip Slip Vulnerability".
+			continue
+		}
+
+		if err := copyTarFileEntry(tarReader, cleanedOutputPath); err != nil {
+			return err
+		}
+	}
+}
+
+func copyTarFileEntry(reader io.Reader, outputPath string) (err error) {
+	if err = os.MkdirAll(path.Dir(outputPath), 0700); err != nil {
+		return err
+	}
+	outputFile, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err1 := outputFile.Close()
+		if err == nil {
+			err = err1
+		}
+	}()
+
+	_, err = io.Copy(outputFile, reader)
+	return err
+}
+
+func runPackageCommandInDirectory(ctx context.Context, cmd *exec.Cmd, workingDirectory string, dependency packageDependency) (string, error) {
+	return runCommandInDirectoryAs(ctx, cmd, workingDirectory, dependency.PackageSyntax()+" authors")
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/pythonpackages/pypi"
+	"github.com/sourcegraph/sourcegraph/internal/repos"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// placeholderPythonDependency is used to set GIT_AUTHOR_NAME for git commands
+// that don't create commits or tags. The name of this dependency should never
+// be publicly visible so it can have any random value.
+var placeholderPythonDependency = &reposource.PythonDependency{
+	PythonPackage: reposource.PythonPackage{Name: "sourcegraph"},
+	Version:       "1.0.0",
+}
+
+type PythonPackagesSyncer struct {
+	Config  *schema.PythonPackagesConnection
+	DBStore repos.PythonPackagesRepoStore
+}
+
+var _ VCSSyncer = &PythonPackagesSyncer{}
+
+func (s *PythonPackagesSyncer) Type() string {
+	return "python_packages"
+}
+
+// IsCloneable checks to see if the VCS remote URL is cloneable. Any non-nil
+// error indicates there is a problem.
+func (s *PythonPackagesSyncer) IsCloneable(ctx context.Context, remoteURL *vcs.URL) error {
+	return s.packagesSyncer().IsCloneable(ctx, remoteURL)
+}
+
+// CloneCommand returns the command to be executed for cloning from remote.
+func (s *PythonPackagesSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, bareGitDirectory string) (*exec.Cmd, error) {
+	return s.packagesSyncer().CloneCommand(ctx, remoteURL, bareGitDirectory)
+}
+
+// Fetch adds git tags for newly added dependency versions and removes git tags
+// for deleted versions.
+func (s *PythonPackagesSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	return s.packagesSyncer().Fetch(ctx, remoteURL, dir)
+}
+
+// RemoteShowCommand returns the command to be executed for showing remote.
+func (s *PythonPackagesSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
+	return s.packagesSyncer().RemoteShowCommand(ctx, remoteURL)
+}
+
+func (s *PythonPackagesSyncer) packagesSyncer() *packagesSyncer {
+	return &packagesSyncer{source: s, placeholder: placeholderPythonDependency}
+}
+
+// packageDependencies returns the list of Python dependencies that belong to
+// the given URL path, from both the configuration and the dependencies of
+// precise code intelligence uploads. The returned package dependencies are
+// sorted by semantic versioning. A URL maps to a single Python package, which
+// may contain multiple versions (one git tag per version).
+func (s *PythonPackagesSyncer) packageDependencies(ctx context.Context, repoUrlPath string) ([]packageDependency, error) {
+	pkg, err := reposource.ParsePythonPackageFromRepoURL(repoUrlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pythonDependencies []reposource.PythonDependency
+	seen := map[reposource.PythonDependency]struct{}{}
+	add := func(dependency reposource.PythonDependency) {
+		if _, ok := seen[dependency]; !ok {
+			seen[dependency] = struct{}{}
+			pythonDependencies = append(pythonDependencies, dependency)
+		}
+	}
+
+	for _, dependency := range s.Config.Dependencies {
+		if !pkg.MatchesDependencyString(dependency) {
+			continue
+		}
+		dependency, err := reposource.ParsePythonDependency(dependency)
+		if err != nil {
+			return nil, err
+		}
+		add(dependency)
+	}
+
+	if s.DBStore != nil {
+		dbDeps, err := s.DBStore.GetPythonDependencyRepos(ctx, dbstore.GetPythonDependencyReposOpts{
+			PackageName: pkg.Name,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get Python dependency repos from database for %s", repoUrlPath)
+		}
+		for _, dep := range dbDeps {
+			// we dont call pypi.Exists here, as existence should be verified by repo-updater
+			add(reposource.PythonDependency{PythonPackage: pkg, Version: dep.Version})
+		}
+	}
+
+	if len(pythonDependencies) == 0 {
+		return nil, errors.Errorf("no Python dependencies for URL path %s", repoUrlPath)
+	}
+
+	reposource.SortPythonDependencies(pythonDependencies)
+	dependencies := make([]packageDependency, 0, len(pythonDependencies))
+	for i := range pythonDependencies {
+		dependencies = append(dependencies, &pythonDependencies[i])
+	}
+	return dependencies, nil
+}
+
+func (s *PythonPackagesSyncer) exists(ctx context.Context, dependency packageDependency) error {
+	_, err := pypi.Exists(ctx, s.Config, *dependency.(*reposource.PythonDependency))
+	return err
+}
+
+// download fetches an sdist or wheel of the given dependency and extracts it
+// into workingDirectory.
+func (s *PythonPackagesSyncer) download(ctx context.Context, dependency packageDependency, workingDirectory string) error {
+	file, archive, err := pypi.FetchSources(ctx, s.Config, *dependency.(*reposource.PythonDependency))
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	if err := extractDistribution(file, archive, workingDirectory); err != nil {
+		return errors.Wrapf(err, "failed to extract %s for %s", file.Name, dependency.PackageManagerSyntax())
+	}
+	return nil
+}
+
+// extractDistribution extracts an sdist, whose files are nested under a
+// top-level "name-version/" directory that is stripped, or a wheel, whose
+// files are at the root of the zip archive.
+func extractDistribution(file pypi.File, archive io.Reader, destination string) error {
+	if file.IsSdist() {
+		return decompressTgz(archive, destination)
+	}
+	if !file.IsWheel() {
+		return errors.Errorf("unsupported distribution format %s", file.Name)
+	}
+
+	// zip archives can't be read as a stream.
+	tmp, err := os.CreateTemp("", "wheel")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, archive); err != nil {
+		return err
+	}
+	return unzipJarFile(tmp.Name(), filepath.Clean(destination))
+}
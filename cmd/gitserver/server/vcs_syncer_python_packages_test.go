@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/pythonpackages/pypi/pypitest"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestPythonCloneCommand(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	index := pypitest.NewIndex(t, map[string][]byte{
+		"example_pkg-1.0.0.tar.gz": pypitest.Sdist(t, "example_pkg", "1.0.0", map[string]string{
+			"example_pkg/__init__.py": "x = 1\n",
+			"setup.py":                "setup()\n",
+		}),
+		"example_pkg-2.0.0-py3-none-any.whl": pypitest.Wheel(t, map[string]string{
+			"example_pkg/__init__.py": "x = 2\n",
+		}),
+	})
+
+	s := PythonPackagesSyncer{
+		Config:  &schema.PythonPackagesConnection{Urls: []string{index.URL}},
+		DBStore: &simplePythonPackageDBStoreMock{versions: []string{"2.0.0"}},
+	}
+	bareGitDirectory := path.Join(dir, "git")
+	remoteURL := &vcs.URL{URL: url.URL{Path: "python/example-pkg"}}
+
+	s.Config.Dependencies = []string{"example_pkg==1.0.0"}
+	cmd, err := s.CloneCommand(context.Background(), remoteURL, bareGitDirectory)
+	assert.Nil(t, err)
+	assert.Nil(t, cmd.Run())
+
+	assertCommandOutput(t,
+		exec.Command("git", "tag", "--list"),
+		bareGitDirectory,
+		"v1.0.0\nv2.0.0\n",
+	)
+	// The sdist top-level directory is stripped.
+	assertCommandOutput(t,
+		exec.Command("git", "show", "v1.0.0:setup.py"),
+		bareGitDirectory,
+		"setup()\n",
+	)
+	// Wheels are extracted as-is.
+	assertCommandOutput(t,
+		exec.Command("git", "show", "v2.0.0:example_pkg/__init__.py"),
+		bareGitDirectory,
+		"x = 2\n",
+	)
+	assertCommandOutput(t,
+		exec.Command("git", "show", "latest:example_pkg/__init__.py"),
+		bareGitDirectory,
+		"x = 2\n",
+	)
+
+	s.DBStore = &simplePythonPackageDBStoreMock{}
+	assert.Nil(t, s.Fetch(context.Background(), remoteURL, GitDir(bareGitDirectory)))
+	assertCommandOutput(t,
+		exec.Command("git", "tag", "--list"),
+		bareGitDirectory,
+		"v1.0.0\n", // verify that the v2.0.0 tag has been removed.
+	)
+}
+
+type simplePythonPackageDBStoreMock struct {
+	versions []string
+}
+
+func (m *simplePythonPackageDBStoreMock) GetPythonDependencyRepos(ctx context.Context, filter dbstore.GetPythonDependencyReposOpts) ([]dbstore.PythonDependencyRepo, error) {
+	repos := make([]dbstore.PythonDependencyRepo, 0, len(m.versions))
+	for i, version := range m.versions {
+		repos = append(repos, dbstore.PythonDependencyRepo{ID: i + 1, Package: filter.PackageName, Version: version})
+	}
+	return repos, nil
+}
@@ -0,0 +1,303 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+const (
+	// svnRemotePrefix is the prefix of the remote-tracking refs git svn
+	// fetches Subversion branches and tags into. They are mapped to local
+	// branches and tags after each fetch.
+	svnRemotePrefix = "refs/remotes/origin/"
+
+	// Files written to the Git directory, which git svn is run with. None of
+	// them contain secrets: the password is passed in svnPasswordEnv.
+	svnAskpassFile     = "svn-askpass"
+	svnAuthorsFile     = "svn-authors"
+	svnAuthorsProgFile = "svn-authors-prog"
+
+	svnPasswordEnv = "SRC_SVN_PASSWORD"
+)
+
+// SVNRepoSyncer is a syncer for Subversion repositories. It converts them into
+// Git repositories with git svn.
+type SVNRepoSyncer struct {
+	// StandardLayout, if set, maps the trunk, branches and tags directories
+	// of repositories to the master branch, branches and tags. Otherwise the
+	// whole repository is mapped to the master branch.
+	StandardLayout bool
+
+	// AuthorsMap maps Subversion usernames to Git authors in the form
+	// "Name <email>".
+	AuthorsMap map[string]string
+}
+
+func (s *SVNRepoSyncer) Type() string {
+	return "subversion"
+}
+
+// IsCloneable checks to see if the Subversion remote URL is cloneable.
+func (s *SVNRepoSyncer) IsCloneable(ctx context.Context, remoteURL *vcs.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	u, username, password := svnCredentials(remoteURL)
+	args := []string{"info", "--non-interactive", "--no-auth-cache"}
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+	if password != "" {
+		args = append(args, "--password-from-stdin")
+	}
+	cmd := exec.CommandContext(ctx, "svn", append(args, u)...)
+	cmd.Env = svnEnv()
+	cmd.Stdin = strings.NewReader(password)
+	out, err := runWith(ctx, cmd, false, nil)
+	if err != nil {
+		if ctxerr := ctx.Err(); ctxerr != nil {
+			err = ctxerr
+		}
+		if len(out) > 0 {
+			err = errors.Errorf("%s (output follows)\n\n%s", err, newURLRedactor(remoteURL).redact(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+// CloneCommand returns the command to be executed for cloning a Subversion
+// repository as a Git repository.
+func (s *SVNRepoSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, tmpPath string) (cmd *exec.Cmd, err error) {
+	if err := os.MkdirAll(tmpPath, os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "clone failed to create tmp dir")
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "init", "--bare", ".")
+	cmd.Dir = tmpPath
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "clone setup failed")
+	}
+
+	// The URL stored by git svn init never contains the password.
+	u, username, _ := svnCredentials(remoteURL)
+	args := []string{"svn", "init", "--prefix=" + strings.TrimPrefix(svnRemotePrefix, "refs/remotes/")}
+	if s.StandardLayout {
+		args = append(args, "--stdlayout")
+	}
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+	cmd = exec.CommandContext(ctx, "git", append(args, u)...)
+	cmd.Dir = tmpPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, errors.Wrapf(err, "clone setup failed with output %q", newURLRedactor(remoteURL).redact(string(output)))
+	}
+
+	cmd, err = s.fetchCommand(ctx, remoteURL, GitDir(tmpPath))
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = tmpPath
+	return cmd, nil
+}
+
+// Fetch tries to fetch updates of a Subversion repository as a Git repository.
+// Only the revisions added since the last fetch are converted.
+func (s *SVNRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	cmd, err := s.fetchCommand(ctx, remoteURL, dir)
+	if err != nil {
+		return err
+	}
+	dir.Set(cmd)
+	if output, err := runWith(ctx, cmd, true, nil); err != nil {
+		return errors.Wrapf(err, "failed to update with output %q", newURLRedactor(remoteURL).redact(string(output)))
+	}
+	return s.updateRefs(ctx, dir)
+}
+
+// RemoteShowCommand returns the command to be executed for showing Git remote
+// of a Subversion repository.
+func (s *SVNRepoSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
+	// Trunk, or the whole repository, is mapped to master, which is what
+	// HEAD points to in the converted repository.
+	return exec.CommandContext(ctx, "git", "remote", "show", "./"), nil
+}
+
+// fetchCommand writes the files git svn is run with to dir and returns the
+// command to fetch the Subversion repository into the Git repository at dir.
+func (s *SVNRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL, dir GitDir) (*exec.Cmd, error) {
+	if err := s.writeFiles(dir); err != nil {
+		return nil, errors.Wrap(err, "failed to write git svn files")
+	}
+
+	args := []string{"svn", "fetch", "--no-auth-cache", "--authors-prog=" + dir.Path(svnAuthorsProgFile)}
+	if len(s.AuthorsMap) > 0 {
+		args = append(args, "--authors-file="+dir.Path(svnAuthorsFile))
+	}
+	_, username, password := svnCredentials(remoteURL)
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = svnEnv()
+	if password != "" {
+		// git svn asks GIT_ASKPASS for the password.
+		cmd.Env = append(cmd.Env, "GIT_ASKPASS="+dir.Path(svnAskpassFile), svnPasswordEnv+"="+password)
+	}
+	return cmd, nil
+}
+
+// writeFiles writes the programs and the authors file git svn is run with to
+// dir. They are rewritten before each fetch to pick up configuration changes.
+func (s *SVNRepoSyncer) writeFiles(dir GitDir) error {
+	files := []struct {
+		name    string
+		content string
+		perm    os.FileMode
+	}{
+		{svnAskpassFile, "#!/bin/sh\nprintf '%s\\n' \"$" + svnPasswordEnv + "\"\n", 0700},
+		// Usernames missing from the authors file are used as the name and
+		// email of the author.
+		{svnAuthorsProgFile, "#!/bin/sh\nprintf '%s <%s>\\n' \"$1\" \"$1\"\n", 0700},
+		{svnAuthorsFile, svnAuthors(s.AuthorsMap), 0600},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(dir.Path(f.name), []byte(f.content), f.perm); err != nil {
+			return err
+		}
+		// WriteFile does not change the permissions of existing files.
+		if err := os.Chmod(dir.Path(f.name), f.perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateRefs points the branches and tags of the Git repository at dir at the
+// remote-tracking refs fetched by git svn.
+func (s *SVNRepoSyncer) updateRefs(ctx context.Context, dir GitDir) error {
+	cmd := exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(objectname) %(refname)",
+		svnRemotePrefix, "refs/heads/", "refs/tags/")
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return errors.Wrap(err, "failed to list refs")
+	}
+
+	updates := svnRefUpdates(string(out), s.StandardLayout)
+	if len(updates) == 0 {
+		return nil
+	}
+	cmd = exec.CommandContext(ctx, "git", "update-ref", "--stdin")
+	dir.Set(cmd)
+	cmd.Stdin = strings.NewReader(strings.Join(updates, "\n") + "\n")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to update refs with output %q", string(output))
+	}
+	return nil
+}
+
+// svnRefUpdates returns the git update-ref --stdin instructions which map the
+// remote-tracking refs fetched by git svn to branches and tags, given the
+// output of git for-each-ref --format='%(objectname) %(refname)'. Branches
+// and tags which no longer exist in the Subversion repository are deleted.
+func svnRefUpdates(forEachRef string, standardLayout bool) []string {
+	current := map[string]string{}
+	want := map[string]string{}
+	for _, line := range strings.Split(forEachRef, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		sha, ref := fields[0], fields[1]
+		if !strings.HasPrefix(ref, svnRemotePrefix) {
+			current[ref] = sha
+			continue
+		}
+
+		name := strings.TrimPrefix(ref, svnRemotePrefix)
+		// git svn keeps deleted or replaced branches as name@revision.
+		if strings.Contains(name, "@") {
+			continue
+		}
+		switch {
+		case !standardLayout:
+			if name == "git-svn" {
+				want["refs/heads/master"] = sha
+			}
+		case name == "trunk":
+			want["refs/heads/master"] = sha
+		case strings.HasPrefix(name, "tags/"):
+			want["refs/tags/"+strings.TrimPrefix(name, "tags/")] = sha
+		default:
+			want["refs/heads/"+name] = sha
+		}
+	}
+
+	var updates []string
+	for ref, sha := range want {
+		if current[ref] != sha {
+			updates = append(updates, fmt.Sprintf("update %s %s", ref, sha))
+		}
+	}
+	for ref := range current {
+		if _, ok := want[ref]; !ok {
+			updates = append(updates, "delete "+ref)
+		}
+	}
+	sort.Strings(updates)
+	return updates
+}
+
+// svnAuthors returns the content of the git svn authors file for authors.
+func svnAuthors(authors map[string]string) string {
+	usernames := make([]string, 0, len(authors))
+	for username := range authors {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	var b bytes.Buffer
+	for _, username := range usernames {
+		fmt.Fprintf(&b, "%s = %s\n", username, authors[username])
+	}
+	return b.String()
+}
+
+// svnCredentials splits remoteURL into the URL passed to Subversion and the
+// credentials to authenticate with. The user of svn+ssh URLs is the SSH login
+// and stays in the URL.
+func svnCredentials(remoteURL *vcs.URL) (u, username, password string) {
+	stripped := remoteURL.URL
+	if stripped.User != nil {
+		username = stripped.User.Username()
+		password, _ = stripped.User.Password()
+	}
+	if stripped.Scheme == "svn+ssh" {
+		if username != "" {
+			stripped.User = url.User(username)
+		}
+		return stripped.String(), "", ""
+	}
+	stripped.User = nil
+	return stripped.String(), username, password
+}
+
+// svnEnv returns the environment to run svn, or git svn, in.
+func svnEnv() []string {
+	// Suppress asking to add SSH host keys, as configureRemoteGitCommand does
+	// for Git.
+	return append(os.Environ(), "SVN_SSH=ssh -o BatchMode=yes -o ConnectTimeout=30")
+}
@@ -165,5 +165,10 @@ func serveTestNotification(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if err := webhookNotifyTest(context.Background(), args.SavedSearch); err != nil {
+		writeError(w, errors.Errorf("error sending webhook notification: %s", err))
+		return
+	}
+
 	log15.Info("saved query test notification sent", "spec", args.SavedSearch.Spec, "key", args.SavedSearch.Spec.Key)
 }
@@ -151,7 +151,7 @@ func (e *executorT) run(ctx context.Context) error {
 // runQuery runs the given query if an appropriate amount of time has elapsed
 // since it last ran.
 func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery) error {
-	if !query.Notify && !query.NotifySlack {
+	if !query.Notify && !query.NotifySlack && !query.NotifyWebhook {
 		// No need to run this query because there will be nobody to notify.
 		return nil
 	}
@@ -310,9 +310,10 @@ func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSave
 		recipients: recipients,
 	}
 
-	// Send Slack and email notifications.
+	// Send Slack, email and webhook notifications.
 	n.slackNotify(ctx)
 	n.emailNotify(ctx)
+	n.webhookNotify(ctx)
 	return nil
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/slack"
+	"github.com/sourcegraph/sourcegraph/internal/webhook"
 )
 
 func (n *notifier) slackNotify(ctx context.Context) {
@@ -24,7 +25,19 @@ func (n *notifier) slackNotify(ctx context.Context) {
 		n.query.Description,
 	)
 	for _, recipient := range n.recipients {
-		if err := slackNotify(ctx, recipient, text, n.query.SlackWebhookURL); err != nil {
+		if !recipient.slack {
+			continue
+		}
+		if n.query.SlackWebhookURL == nil || *n.query.SlackWebhookURL == "" {
+			log15.Error("Failed to post Slack notification message.", "recipient", recipient, "error", "no Slack webhook URL configured")
+			continue
+		}
+
+		// Unlike the subscription messages, new results are delivered with
+		// retries and recorded in the saved search's delivery log.
+		delivery, err := webhook.New(*n.query.SlackWebhookURL, "").Post(ctx, "", slackPayload(text))
+		logDelivery(ctx, n.spec, "slack", "results", delivery, err)
+		if err != nil {
 			log15.Error("Failed to post Slack notification message.", "recipient", recipient, "text", text, "error", err)
 		}
 	}
@@ -67,13 +80,16 @@ func slackNotify(ctx context.Context, recipient *recipient, text string, slackWe
 		return errors.Errorf("unable to send Slack notification because recipient (%s) has no Slack webhook URL configured", recipient.spec)
 	}
 
-	payload := &slack.Payload{
+	client := slack.New(*slackWebhookURL)
+	return client.Post(ctx, slackPayload(text))
+}
+
+func slackPayload(text string) *slack.Payload {
+	return &slack.Payload{
 		Username:    "saved-search-bot",
 		IconEmoji:   ":mag:",
 		UnfurlLinks: false,
 		UnfurlMedia: false,
 		Text:        text,
 	}
-	client := slack.New(*slackWebhookURL)
-	return client.Post(ctx, payload)
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/webhook"
+)
+
+const utmSourceWebhook = "saved-search-webhook"
+
+// webhookPayload is the JSON body posted to a saved search's webhook.
+type webhookPayload struct {
+	Event                  string             `json:"event"`
+	SavedSearch            webhookSavedSearch `json:"savedSearch"`
+	SearchURL              string             `json:"searchURL"`
+	ApproximateResultCount string             `json:"approximateResultCount,omitempty"`
+	Results                []interface{}      `json:"results,omitempty"`
+}
+
+type webhookSavedSearch struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+func (n *notifier) webhookNotify(ctx context.Context) {
+	if !n.query.NotifyWebhook || n.query.WebhookURL == nil || *n.query.WebhookURL == "" {
+		return
+	}
+
+	payload := &webhookPayload{
+		Event: "results",
+		SavedSearch: webhookSavedSearch{
+			Key:         n.spec.Key,
+			Description: n.query.Description,
+			Query:       n.query.Query,
+		},
+		SearchURL:              searchURL(n.newQuery, utmSourceWebhook),
+		ApproximateResultCount: n.results.Data.Search.Results.ApproximateResultCount,
+		Results:                n.results.Data.Search.Results.Results,
+	}
+	if err := webhookNotify(ctx, n.spec, n.query, payload); err != nil {
+		log15.Error("Failed to post webhook notification.", "key", n.spec.Key, "error", err)
+	}
+	logEvent(0, "SavedSearchWebhookNotificationSent", "results")
+}
+
+func webhookNotifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig) error {
+	if !query.Config.NotifyWebhook || query.Config.WebhookURL == nil || *query.Config.WebhookURL == "" {
+		return nil
+	}
+
+	payload := &webhookPayload{
+		Event: "test",
+		SavedSearch: webhookSavedSearch{
+			Key:         query.Spec.Key,
+			Description: query.Config.Description,
+			Query:       query.Config.Query,
+		},
+		SearchURL: searchURL(query.Config.Query, utmSourceWebhook),
+	}
+	return webhookNotify(ctx, query.Spec, query.Config, payload)
+}
+
+// webhookNotify posts the payload to the saved search's webhook and records the
+// outcome in its delivery log.
+func webhookNotify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, payload *webhookPayload) error {
+	var secret string
+	if query.WebhookSecret != nil {
+		secret = *query.WebhookSecret
+	}
+
+	delivery, err := webhook.New(*query.WebhookURL, secret).Post(ctx, "saved_search."+payload.Event, payload)
+	logDelivery(ctx, spec, "webhook", payload.Event, delivery, err)
+	return err
+}
+
+// logDelivery records the outcome of an outbound notification in the delivery
+// log of the saved search identified by spec. Failures to record the delivery
+// are logged but otherwise ignored.
+func logDelivery(ctx context.Context, spec api.SavedQueryIDSpec, kind, event string, delivery webhook.Delivery, deliveryErr error) {
+	id, err := strconv.ParseInt(spec.Key, 10, 32)
+	if err != nil {
+		log15.Error("Failed to parse saved search ID for delivery log.", "key", spec.Key, "error", err)
+		return
+	}
+
+	d := &api.SavedQueryDelivery{
+		SavedSearchID: int32(id),
+		Kind:          kind,
+		Event:         event,
+		Attempts:      delivery.Attempts,
+		StatusCode:    delivery.StatusCode,
+		Duration:      delivery.Duration,
+	}
+	if deliveryErr != nil {
+		d.Error = deliveryErr.Error()
+	}
+	if err := api.InternalClient.SavedQueriesLogDelivery(ctx, d); err != nil {
+		log15.Error("Failed to log saved search delivery.", "key", spec.Key, "kind", kind, "error", err)
+	}
+}
@@ -28,11 +28,17 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/tracer"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
 var (
 	cacheDir    = env.Get("CACHE_DIR", "/tmp", "directory to store cached archives.")
 	cacheSizeMB = env.Get("SEARCHER_CACHE_SIZE_MB", "100000", "maximum size of the on disk cache in megabytes")
+
+	blobStore              = env.Get("SEARCHER_BLOB_STORE", "false", "store the files of commits deduplicated by git blob, so that searching many commits of a repository fetches and stores their unchanged files once. Only used by regexp search.")
+	blobStoreMaxFetchPaths = env.Get("SEARCHER_BLOB_STORE_MAX_FETCH_PATHS", "1000", "maximum number of missing files of a commit the blob store fetches by path. If more are missing, the full archive of the commit is fetched instead. Set to -1 to always fetch by path.")
+
+	resultCacheSizeMB = env.Get("SEARCHER_RESULT_CACHE_SIZE_MB", "1000", "maximum size of the on disk cache of search results in megabytes. Set to 0 to disable caching search results.")
 )
 
 const port = "3181"
@@ -64,14 +70,35 @@ func main() {
 			FetchTar: func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
 				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
 			},
+			FetchTarPaths: func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar", Paths: paths})
+			},
 			FilterTar:         search.NewFilter,
 			Path:              filepath.Join(cacheDir, "searcher-archives"),
 			MaxCacheSizeBytes: cacheSizeBytes,
 		},
 		Log: log15.Root(),
 	}
+	if enabled, _ := strconv.ParseBool(blobStore); enabled {
+		service.Store.ListBlobs = listBlobs
+		if i, err := strconv.Atoi(blobStoreMaxFetchPaths); err != nil {
+			log.Fatalf("invalid int %q for SEARCHER_BLOB_STORE_MAX_FETCH_PATHS: %s", blobStoreMaxFetchPaths, err)
+		} else {
+			service.Store.MaxBlobFetchPaths = i
+		}
+	}
 	service.Store.Start()
 
+	if i, err := strconv.ParseInt(resultCacheSizeMB, 10, 64); err != nil {
+		log.Fatalf("invalid int %q for SEARCHER_RESULT_CACHE_SIZE_MB: %s", resultCacheSizeMB, err)
+	} else if i > 0 {
+		service.ResultCache = &search.ResultCache{
+			Path:              filepath.Join(cacheDir, "searcher-results"),
+			MaxCacheSizeBytes: i * 1000 * 1000,
+		}
+		service.ResultCache.Start()
+	}
+
 	handler := ot.Middleware(trace.HTTPTraceMiddleware(service))
 
 	host := ""
@@ -101,6 +128,23 @@ func main() {
 	}
 }
 
+// listBlobs returns the regular files of repo at commit and their git blobs.
+func listBlobs(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]store.Blob, error) {
+	fis, err := git.ReadDir(ctx, repo, commit, "", true)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]store.Blob, 0, len(fis))
+	for _, fi := range fis {
+		oi, ok := fi.Sys().(git.ObjectInfo)
+		if !fi.Mode().IsRegular() || !ok {
+			continue
+		}
+		blobs = append(blobs, store.Blob{Path: fi.Name(), OID: oi.OID().String(), Size: fi.Size()})
+	}
+	return blobs, nil
+}
+
 func shutdownOnSIGINT(s *http.Server) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -0,0 +1,216 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+)
+
+// maxResultCacheEntrySize is the size of the largest set of matches we cache
+// for a search. Searches with more matches are not cached.
+const maxResultCacheEntrySize = 10 * 1024 * 1024
+
+// ResultCache is an on disk cache of the matches of searches. A commit never
+// changes, so the same search of the same commit always has the same matches.
+// Saved searches, code insights and code monitors run the same searches
+// repeatedly, which replay the cached matches instead of searching the
+// archive again.
+//
+// Only searches which ran to completion are cached, and entries are evicted
+// least recently used first once the cache is larger than MaxCacheSizeBytes.
+type ResultCache struct {
+	// Path is the directory to store the cache.
+	Path string
+
+	// MaxCacheSizeBytes is the maximum size of the cache in bytes. Note: We
+	// can temporarily be larger than MaxCacheSizeBytes.
+	MaxCacheSizeBytes int64
+
+	// once protects Start
+	once sync.Once
+
+	// cache is the disk backed cache.
+	cache *diskcache.Store
+}
+
+// Start initializes state and starts background goroutines. It can be called
+// more than once.
+func (c *ResultCache) Start() {
+	c.once.Do(func() {
+		c.cache = &diskcache.Store{
+			Dir:       c.Path,
+			Component: "result-cache",
+		}
+		_ = os.MkdirAll(c.Path, 0700)
+		go c.watchAndEvict()
+	})
+}
+
+// resultCacheKey returns the cache key of the search p of repo at commit.
+// Fields of p which do not change the matches are normalized away.
+func resultCacheKey(p *protocol.Request, largeFilePatterns []string) string {
+	pi := p.PatternInfo
+	// Limits are applied when replaying matches, and only searches which
+	// did not hit a limit are cached.
+	pi.Limit = 0
+	// Select is applied by the frontend.
+	pi.Select = ""
+	// IncludePatterns are ANDed together, so their order does not matter.
+	pi.IncludePatterns = append([]string(nil), pi.IncludePatterns...)
+	sort.Strings(pi.IncludePatterns)
+
+	b, _ := json.Marshal(struct {
+		Repo              api.RepoName
+		Commit            api.CommitID
+		PatternInfo       protocol.PatternInfo
+		LargeFilePatterns []string
+	}{
+		Repo:              p.Repo,
+		Commit:            p.Commit,
+		PatternInfo:       pi,
+		LargeFilePatterns: largeFilePatterns,
+	})
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// replay sends the cached matches for key to sender. ok is false if there are
+// no cached matches for key.
+func (c *ResultCache) replay(ctx context.Context, key string, sender matchSender) (ok bool, err error) {
+	c.Start()
+
+	f, err := c.cache.Get(key)
+	if os.IsNotExist(err) {
+		resultCacheRequests.WithLabelValues("miss").Inc()
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	// Decode all matches before sending any, so a bad entry can fall back to
+	// searching.
+	var matches []protocol.FileMatch
+	if err := json.NewDecoder(f).Decode(&matches); err != nil {
+		return false, errors.Wrapf(err, "decoding result cache entry %s", f.Path)
+	}
+
+	resultCacheRequests.WithLabelValues("hit").Inc()
+	for _, match := range matches {
+		if ctx.Err() != nil {
+			// The limit was hit or the search was canceled.
+			break
+		}
+		sender.Send(match)
+	}
+	return true, nil
+}
+
+// store caches matches for key.
+func (c *ResultCache) store(ctx context.Context, key string, matches []protocol.FileMatch) error {
+	c.Start()
+
+	if matches == nil {
+		// Distinguish no matches from a bad entry.
+		matches = []protocol.FileMatch{}
+	}
+	b, err := json.Marshal(matches)
+	if err != nil {
+		return err
+	}
+	if len(b) > maxResultCacheEntrySize {
+		return nil
+	}
+
+	f, err := c.cache.Open(ctx, key, func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// watchAndEvict is a loop which periodically checks the size of the cache and
+// evicts/deletes items if the cache gets too large.
+func (c *ResultCache) watchAndEvict() {
+	if c.MaxCacheSizeBytes == 0 {
+		return
+	}
+
+	for {
+		time.Sleep(10 * time.Second)
+
+		stats, err := c.cache.Evict(c.MaxCacheSizeBytes)
+		if err != nil {
+			log.Printf("failed to Evict: %s", err)
+			continue
+		}
+		resultCacheSizeBytes.Set(float64(stats.CacheSize))
+		resultCacheEvictions.Add(float64(stats.Evicted))
+	}
+}
+
+// recordingSender is a matchSender which records the matches sent to it, so
+// that they can be cached once the search completes.
+type recordingSender struct {
+	matchSender
+
+	mu      sync.Mutex
+	matches []protocol.FileMatch
+	size    int
+}
+
+func (s *recordingSender) Send(match protocol.FileMatch) {
+	s.mu.Lock()
+	// A rough estimate of the encoded size, to stop recording searches with
+	// too many matches to cache.
+	if s.size <= maxResultCacheEntrySize {
+		s.matches = append(s.matches, match)
+		s.size += len(match.Path)
+		for _, lm := range match.LineMatches {
+			s.size += len(lm.Preview) + 64
+		}
+	}
+	s.mu.Unlock()
+
+	s.matchSender.Send(match)
+}
+
+// Recorded returns the matches sent, and whether all of them were recorded.
+func (s *recordingSender) Recorded() ([]protocol.FileMatch, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.matches, s.size <= maxResultCacheEntrySize
+}
+
+var (
+	resultCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "searcher_result_cache_requests_total",
+		Help: "Number of searches looked up in the result cache.",
+	}, []string{"result"})
+	resultCacheSizeBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "searcher_result_cache_size_bytes",
+		Help: "The total size of items in the on disk result cache.",
+	})
+	resultCacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_result_cache_evictions",
+		Help: "The total number of items evicted from the result cache.",
+	})
+)
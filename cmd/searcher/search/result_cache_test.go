@@ -0,0 +1,105 @@
+package search_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/search"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestSearch_resultCache(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package main\n\nfunc a() {}\n",
+		"b.go": "package main\n\nfunc b() {}\n",
+	}
+
+	s, cleanup, err := newStore(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	fetchTar := s.FetchTar
+
+	ts := httptest.NewServer(&search.Service{
+		Store:       s,
+		ResultCache: &search.ResultCache{Path: filepath.Join(t.TempDir(), "results")},
+	})
+	defer ts.Close()
+
+	req := func(pattern string, includePatterns ...string) *protocol.Request {
+		return &protocol.Request{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			PatternInfo: protocol.PatternInfo{
+				Pattern:                pattern,
+				IncludePatterns:        includePatterns,
+				PathPatternsAreRegExps: true,
+				PatternMatchesContent:  true,
+			},
+			FetchTimeout: "10s",
+		}
+	}
+	search := func(t *testing.T, p *protocol.Request) string {
+		t.Helper()
+		m, err := doSearch(ts.URL, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Sort(sortByPath(m))
+		return toString(m)
+	}
+
+	want := search(t, req("func", `\.go$`, `^[ab]`))
+	if want == "" {
+		t.Fatal("expected matches")
+	}
+
+	// Make the archive unavailable, so that only cached results can be
+	// returned.
+	s.FetchTar = func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+		return nil, errors.New("archive unavailable")
+	}
+	if err := os.RemoveAll(s.Path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.FetchTar = fetchTar })
+
+	t.Run("hit", func(t *testing.T) {
+		// The order of include patterns does not matter.
+		if got := search(t, req("func", `^[ab]`, `\.go$`)); got != want {
+			t.Fatalf("unexpected cached results: want %q, got %q", want, got)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		p := req("func", `\.go$`, `^[ab]`)
+		p.Limit = 1
+		m, err := doSearch(ts.URL, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lineMatches := 0
+		for _, fm := range m {
+			lineMatches += len(fm.LineMatches)
+		}
+		if lineMatches != 1 {
+			t.Fatalf("expected cached results to be limited to 1 match, got %d", lineMatches)
+		}
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		if _, err := doSearch(ts.URL, req("package")); err == nil {
+			t.Fatal("expected search of uncached pattern to fetch the archive")
+		}
+	})
+}
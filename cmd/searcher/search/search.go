@@ -31,6 +31,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/search/searcher"
 	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
@@ -49,6 +50,9 @@ const (
 type Service struct {
 	Store *store.Store
 	Log   log15.Logger
+
+	// ResultCache, when non-nil, caches the matches of unindexed searches.
+	ResultCache *ResultCache
 }
 
 // ServeHTTP handles HTTP based search requests
@@ -205,6 +209,30 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchS
 		}
 	}
 
+	if s.ResultCache != nil {
+		key := resultCacheKey(p, conf.Get().SearchLargeFiles)
+		if ok, err := s.ResultCache.replay(ctx, key, sender); err != nil {
+			log15.Warn("failed to replay cached search results", "repo", p.Repo, "commit", p.Commit, "error", err)
+		} else if ok {
+			tr.LazyPrintf("replayed cached results")
+			span.SetTag("resultCache", "hit")
+			return false, nil
+		}
+
+		recorder := &recordingSender{matchSender: sender}
+		sender = recorder
+		defer func() {
+			// Only cache searches which ran to completion.
+			matches, complete := recorder.Recorded()
+			if err != nil || ctx.Err() != nil || sender.LimitHit() || !complete {
+				return
+			}
+			if err := s.ResultCache.store(ctx, key, matches); err != nil {
+				log15.Warn("failed to cache search results", "repo", p.Repo, "commit", p.Commit, "error", err)
+			}
+		}()
+	}
+
 	if p.FetchTimeout == "" {
 		p.FetchTimeout = "500ms"
 	}
@@ -224,14 +252,22 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchS
 		return path, zf, err
 	}
 
-	zipPath, zf, err := store.GetZipFileWithRetry(getZf)
+	var zipPath string
+	var zf *store.ZipFile
+	if s.Store.ListBlobs != nil && !p.IsStructuralPat {
+		// Structural search runs comby on the zip archive, so only regex
+		// search reads files from the deduplicated blobs.
+		zf, err = s.Store.PrepareBlobs(prepareCtx, p.Repo, p.Commit)
+	} else {
+		zipPath, zf, err = store.GetZipFileWithRetry(getZf)
+	}
 	if err != nil {
 		return false, errors.Wrap(err, "failed to get archive")
 	}
 	defer zf.Close()
 
 	nFiles := uint64(len(zf.Files))
-	bytes := int64(len(zf.Data))
+	bytes := zf.Size()
 	tr.LazyPrintf("files=%d bytes=%d", nFiles, bytes)
 	span.LogFields(
 		otlog.Uint64("archive.files", nFiles),
@@ -0,0 +1,75 @@
+package search_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/search"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/store"
+)
+
+func TestSearch_blobs(t *testing.T) {
+	files := map[string]string{
+		"README.md": "# Hello World\n\nHello world example in go\n",
+		"main.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"Hello world\")\n}\n",
+		"copy.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"Hello world\")\n}\n",
+		"bin":       "\x00Hello world\n",
+	}
+
+	search := func(t *testing.T, blobs bool, p *protocol.Request) string {
+		t.Helper()
+		s, cleanup, err := newStore(files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+		if blobs {
+			s.ListBlobs = func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]store.Blob, error) {
+				var blobs []store.Blob
+				for path, body := range files {
+					oid := sha1.Sum([]byte(body))
+					blobs = append(blobs, store.Blob{Path: path, OID: hex.EncodeToString(oid[:]), Size: int64(len(body))})
+				}
+				return blobs, nil
+			}
+		}
+		ts := httptest.NewServer(&search.Service{Store: s})
+		defer ts.Close()
+
+		m, err := doSearch(ts.URL, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Sort(sortByPath(m))
+		return toString(m)
+	}
+
+	cases := map[string]protocol.PatternInfo{
+		"content": {Pattern: "world", PatternMatchesContent: true},
+		"path":    {Pattern: "bin", PatternMatchesPath: true},
+		"include": {Pattern: "Hello", IncludePatterns: []string{`\.go$`}, PathPatternsAreRegExps: true, PatternMatchesContent: true},
+	}
+	for name, pi := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &protocol.Request{
+				Repo:         "foo",
+				Commit:       "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+				PatternInfo:  pi,
+				FetchTimeout: "10s",
+			}
+			want := search(t, false, p)
+			if want == "" {
+				t.Fatal("expected matches")
+			}
+			if got := search(t, true, p); got != want {
+				t.Fatalf("unexpected results searching blobs:\nwant: %q\ngot:  %q", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package gitserverauditlogs
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+)
+
+type staleLogDeleter interface {
+	DeleteStale(context.Context, time.Duration) error
+}
+
+type handler struct {
+	store staleLogDeleter
+}
+
+var _ goroutine.Handler = &handler{}
+var _ goroutine.ErrorHandler = &handler{}
+
+func (h *handler) Handle(ctx context.Context) error {
+	retention := calculateRetention(conf.Get())
+	log15.Debug("purging gitserver exec audit logs", "retention", retention)
+
+	return h.store.DeleteStale(ctx, retention)
+}
+
+func (h *handler) HandleError(err error) {
+	log15.Error("error deleting stale gitserver exec audit logs", "err", err)
+}
+
+// This matches the documented value in the site configuration schema.
+const defaultRetention = 30 * 24 * time.Hour
+
+// calculateRetention returns the configured retention period. Entries are
+// retained even while the audit log is disabled, so that disabling it does not
+// discard the existing audit trail early.
+func calculateRetention(c *conf.Unified) time.Duration {
+	if cfg := c.GitserverExecAuditLog; cfg != nil && cfg.Retention != "" {
+		retention, err := time.ParseDuration(cfg.Retention)
+		if err != nil {
+			log15.Warn("invalid gitserver exec audit log retention period; ignoring", "raw", cfg.Retention, "err", err)
+		} else if retention < time.Hour {
+			return time.Hour
+		} else {
+			return retention
+		}
+	}
+
+	return defaultRetention
+}
@@ -0,0 +1,64 @@
+package gitserverauditlogs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type fakeStore struct {
+	retentions []time.Duration
+	err        error
+}
+
+func (s *fakeStore) DeleteStale(_ context.Context, retention time.Duration) error {
+	s.retentions = append(s.retentions, retention)
+	return s.err
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("store error", func(t *testing.T) {
+		want := errors.New("error")
+		store := &fakeStore{err: want}
+		h := &handler{store: store}
+
+		err := h.Handle(context.Background())
+		assert.ErrorIs(t, err, want)
+		assert.Len(t, store.retentions, 1)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		store := &fakeStore{}
+		h := &handler{store: store}
+
+		err := h.Handle(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, []time.Duration{defaultRetention}, store.retentions)
+	})
+}
+
+func TestCalculateRetention(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg  *schema.GitserverExecAuditLog
+		want time.Duration
+	}{
+		"not configured": {nil, defaultRetention},
+		"no retention":   {&schema.GitserverExecAuditLog{Enabled: true}, defaultRetention},
+		"invalid":        {&schema.GitserverExecAuditLog{Retention: "a week"}, defaultRetention},
+		"too short":      {&schema.GitserverExecAuditLog{Retention: "5m"}, time.Hour},
+		"valid":          {&schema.GitserverExecAuditLog{Retention: "168h"}, 168 * time.Hour},
+	} {
+		t.Run(name, func(t *testing.T) {
+			have := calculateRetention(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				GitserverExecAuditLog: tc.cfg,
+			}})
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
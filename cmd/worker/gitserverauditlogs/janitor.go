@@ -0,0 +1,39 @@
+package gitserverauditlogs
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/worker/job"
+	"github.com/sourcegraph/sourcegraph/cmd/worker/workerdb"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+)
+
+// janitor is a worker responsible for expunging stale gitserver exec audit
+// log entries from the database.
+type janitor struct{}
+
+var _ job.Job = &janitor{}
+
+func NewJanitor() job.Job {
+	return &janitor{}
+}
+
+func (j *janitor) Config() []env.Config { return []env.Config{} }
+
+func (j *janitor) Routines(ctx context.Context) ([]goroutine.BackgroundRoutine, error) {
+	db, err := workerdb.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return []goroutine.BackgroundRoutine{
+		// As for webhook logs, retention values under an hour aren't
+		// supported, so there's no point running this more often.
+		goroutine.NewPeriodicGoroutine(context.Background(), 1*time.Hour, &handler{
+			store: database.GitserverExecAuditLogs(db),
+		}),
+	}, nil
+}
@@ -1,10 +1,12 @@
 package shared
 
 import (
+	"github.com/sourcegraph/sourcegraph/cmd/worker/gitserverauditlogs"
 	"github.com/sourcegraph/sourcegraph/cmd/worker/job"
 	"github.com/sourcegraph/sourcegraph/cmd/worker/webhooks"
 )
 
 var builtins = map[string]job.Job{
-	"webhook-log-janitor": webhooks.NewJanitor(),
+	"webhook-log-janitor":              webhooks.NewJanitor(),
+	"gitserver-exec-audit-log-janitor": gitserverauditlogs.NewJanitor(),
 }
@@ -114,6 +114,9 @@ func (r *Resolver) CreateCodeMonitor(ctx context.Context, args *graphqlbackend.C
 	if err != nil {
 		return nil, err
 	}
+	if err := cm.ValidateSymbolTriggerQuery(args.Trigger.Query); err != nil {
+		return nil, err
+	}
 	var mo *cm.Monitor
 	mo, err = r.store.CreateCodeMonitor(ctx, args)
 	if err != nil {
@@ -161,6 +164,10 @@ func (r *Resolver) UpdateCodeMonitor(ctx context.Context, args *graphqlbackend.U
 		return nil, errors.Errorf("update namespace: %w", err)
 	}
 
+	if err := cm.ValidateSymbolTriggerQuery(args.Trigger.Update.Query); err != nil {
+		return nil, err
+	}
+
 	var monitorID int64
 	err = relay.UnmarshalSpec(args.Monitor.Id, &monitorID)
 	if err != nil {
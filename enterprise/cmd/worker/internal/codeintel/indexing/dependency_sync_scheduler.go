@@ -22,6 +22,7 @@ import (
 
 var schemeToExternalService = map[string]string{
 	"semanticdb": extsvc.KindJVMPackages,
+	"pypi":       extsvc.KindPythonPackages,
 }
 
 // NewDependencySyncScheduler returns a new worker instance that processes
@@ -3,6 +3,7 @@ package enqueuer
 import (
 	"strings"
 
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
 )
@@ -11,6 +12,7 @@ func InferRepositoryAndRevision(pkg precise.Package) (repoName, gitTagOrCommit s
 	for _, fn := range []func(pkg precise.Package) (string, string, bool){
 		inferGoRepositoryAndRevision,
 		inferJVMRepositoryAndRevision,
+		inferPythonRepositoryAndRevision,
 	} {
 		if repoName, gitTagOrCommit, ok := fn(pkg); ok {
 			return repoName, gitTagOrCommit, true
@@ -48,3 +50,15 @@ func inferJVMRepositoryAndRevision(pkg precise.Package) (string, string, bool) {
 	}
 	return pkg.Name, "v" + pkg.Version, true
 }
+
+func inferPythonRepositoryAndRevision(pkg precise.Package) (string, string, bool) {
+	if pkg.Scheme != "pypi" {
+		return "", "", false
+	}
+	pythonPkg, err := reposource.ParsePythonPackage(pkg.Name)
+	if err != nil {
+		return "", "", false
+	}
+	dependency := reposource.PythonDependency{PythonPackage: pythonPkg, Version: pkg.Version}
+	return string(dependency.RepoName()), dependency.GitTagFromVersion(), true
+}
@@ -56,4 +56,23 @@ func TestInferRepositoryAndRevision(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Python", func(t *testing.T) {
+		pkg := precise.Package{
+			Scheme:  "pypi",
+			Name:    "Zope.Interface",
+			Version: "5.4.0",
+		}
+
+		repoName, revision, ok := InferRepositoryAndRevision(pkg)
+		if !ok {
+			t.Fatalf("expected repository to be inferred")
+		}
+		if want := "python/zope-interface"; repoName != want {
+			t.Errorf("unexpected repo name. want=%q have=%q", want, repoName)
+		}
+		if want := "v5.4.0"; revision != want {
+			t.Errorf("unexpected revision. want=%q have=%q", want, revision)
+		}
+	})
 }
@@ -150,26 +150,43 @@ func (c *Client) CommitGraph(ctx context.Context, repositoryID int, opts CommitG
 	}})
 	defer endObservation(1, observation.Args{})
 
-	args := []string{"log", "--pretty=%H %P", "--topo-order"}
-	if opts.AllRefs {
-		args = append(args, "--all")
-	}
-	if opts.Commit != "" {
-		args = append(args, opts.Commit)
+	repo, err := c.repositoryIDToRepo(ctx, repositoryID)
+	if err != nil {
+		return nil, err
 	}
-	if opts.Since != nil {
-		args = append(args, fmt.Sprintf("--since=%s", opts.Since.Format(time.RFC3339)))
+
+	req := &protocol.CommitAncestryRequest{
+		Repo: repo,
+		Graph: &protocol.CommitGraphOptions{
+			AllRefs: opts.AllRefs,
+			Since:   opts.Since,
+			Limit:   opts.Limit,
+		},
 	}
-	if opts.Limit > 0 {
-		args = append(args, fmt.Sprintf("-%d", opts.Limit))
+	if opts.Commit != "" {
+		req.Commits = []api.CommitID{api.CommitID(opts.Commit)}
+	} else if !opts.AllRefs {
+		req.Commits = []api.CommitID{"HEAD"}
 	}
 
-	out, err := c.execResolveRevGitCommand(ctx, repositoryID, opts.Commit, args...)
+	resp, err := gitserver.DefaultClient.CommitAncestry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	if len(resp.Missing) > 0 {
+		return nil, &gitdomain.RevisionNotFoundError{Repo: repo, Spec: string(resp.Missing[0])}
+	}
 
-	return ParseCommitGraph(strings.Split(out, "\n")), nil
+	commits := make([][]string, 0, len(resp.Graph))
+	for _, entry := range resp.Graph {
+		parts := make([]string, 0, len(entry.Parents)+1)
+		parts = append(parts, string(entry.Commit))
+		for _, parent := range entry.Parents {
+			parts = append(parts, string(parent))
+		}
+		commits = append(commits, parts)
+	}
+	return newCommitGraph(commits), nil
 }
 
 // ParseCommitGraph converts the output of git log into a map from commits to parent commits,
@@ -178,26 +195,31 @@ func (c *Client) CommitGraph(ctx context.Context, repositoryID int, opts CommitG
 // the map and the ordering. If the ordering is to be correct, the git log output must be
 // formatted with --topo-order.
 func ParseCommitGraph(lines []string) *CommitGraph {
-	// Process lines backwards so that we see all parents before children.
-	// We get a topological ordering by simply scraping the keys off in this
-	// order.
-
-	n := len(lines) - 1
-	for i := 0; i < len(lines)/2; i++ {
-		lines[i], lines[n-i] = lines[n-i], lines[i]
-	}
-
-	graph := make(map[string][]string, len(lines))
-	order := make([]string, 0, len(lines))
-
-	var prefix []string
+	commits := make([][]string, 0, len(lines))
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
 
-		parts := strings.Split(line, " ")
+		commits = append(commits, strings.Split(line, " "))
+	}
+
+	return newCommitGraph(commits)
+}
+
+// newCommitGraph builds a commit graph from a list of commits, each followed by its parents,
+// in topological order with children before their parents.
+func newCommitGraph(commits [][]string) *CommitGraph {
+	graph := make(map[string][]string, len(commits))
+	order := make([]string, 0, len(commits))
+
+	// Process commits backwards so that we see all parents before children.
+	// We get a topological ordering by simply scraping the keys off in this
+	// order.
+	var prefix []string
+	for i := len(commits) - 1; i >= 0; i-- {
+		parts := commits[i]
 
 		if len(parts) == 1 {
 			graph[parts[0]] = []string{}
@@ -406,36 +428,61 @@ func parseBranchesContaining(lines []string) []string {
 }
 
 // DefaultBranchContains tells if the default branch contains the given commit ID.
-//
-// TODO(apidocs): future: This could be implemented more optimally, but since it is called
-// infrequently it is fine for now.
 func (c *Client) DefaultBranchContains(ctx context.Context, repositoryID int, commit string) (bool, error) {
-	// Determine default branch name.
-	descriptions, err := c.RefDescriptions(ctx, repositoryID)
+	head, revisionExists, err := c.Head(ctx, repositoryID)
 	if err != nil {
-		return false, errors.Wrap(err, "RefDescriptions")
+		return false, errors.Wrap(err, "Head")
 	}
-	var defaultBranchName string
-	for _, descriptions := range descriptions {
-		for _, ref := range descriptions {
-			if ref.IsDefaultBranch {
-				defaultBranchName = ref.Name
-				break
-			}
-		}
+	if !revisionExists {
+		return false, nil
 	}
 
-	// Determine if branch contains commit.
-	branches, err := c.BranchesContaining(ctx, repositoryID, commit)
+	ancestry, err := c.CommitAncestry(ctx, repositoryID, []string{head}, []string{commit}, 0)
 	if err != nil {
-		return false, errors.Wrap(err, "BranchesContaining")
+		return false, errors.Wrap(err, "CommitAncestry")
 	}
-	for _, branch := range branches {
-		if branch == defaultBranchName {
-			return true, nil
+	if len(ancestry.Missing) > 0 {
+		repo, err := c.repositoryIDToRepo(ctx, repositoryID)
+		if err != nil {
+			return false, err
 		}
+		return false, &gitdomain.RevisionNotFoundError{Repo: repo, Spec: string(ancestry.Missing[0])}
 	}
-	return false, nil
+	return len(ancestry.Ancestors[api.CommitID(head)]) > 0, nil
+}
+
+// CommitAncestry returns the candidate commits which are ancestors or descendants of each of the
+// given commits, nearest first, along with the merge bases of the commits, in a single gitserver
+// request. If a non-zero max distance is supplied, candidates further than that many commits away
+// are left out. Commits and candidates which do not exist are listed in the Missing field.
+func (c *Client) CommitAncestry(ctx context.Context, repositoryID int, commits, candidates []string, maxDistance int) (_ *protocol.CommitAncestryResponse, err error) {
+	ctx, endObservation := c.operations.commitAncestry.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("repositoryID", repositoryID),
+		log.Int("numCommits", len(commits)),
+		log.Int("numCandidates", len(candidates)),
+		log.Int("maxDistance", maxDistance),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	repo, err := c.repositoryIDToRepo(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &protocol.CommitAncestryRequest{
+		Repo:        repo,
+		Commits:     make([]api.CommitID, 0, len(commits)),
+		Candidates:  make([]api.CommitID, 0, len(candidates)),
+		MaxDistance: maxDistance,
+	}
+	for _, commit := range commits {
+		req.Commits = append(req.Commits, api.CommitID(commit))
+	}
+	for _, candidate := range candidates {
+		req.Candidates = append(req.Candidates, api.CommitID(candidate))
+	}
+
+	return gitserver.DefaultClient.CommitAncestry(ctx, req)
 }
 
 // RawContents returns the contents of a file in a particular commit of a repository.
@@ -11,6 +11,7 @@ import (
 )
 
 type operations struct {
+	commitAncestry        *observation.Operation
 	commitDate            *observation.Operation
 	commitExists          *observation.Operation
 	commitGraph           *observation.Operation
@@ -48,6 +49,7 @@ func newOperations(observationContext *observation.Context) *operations {
 	}
 
 	return &operations{
+		commitAncestry:        op("CommitAncestry"),
 		commitDate:            op("CommitDate"),
 		commitExists:          op("CommitExists"),
 		commitGraph:           op("CommitGraph"),
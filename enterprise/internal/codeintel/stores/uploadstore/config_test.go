@@ -190,6 +190,45 @@ func TestConfigGCS(t *testing.T) {
 	}
 }
 
+func TestConfigFilesystem(t *testing.T) {
+	env := map[string]string{
+		"PRECISE_CODE_INTEL_UPLOAD_BACKEND":         "Filesystem",
+		"PRECISE_CODE_INTEL_UPLOAD_TTL":             "8h",
+		"PRECISE_CODE_INTEL_UPLOAD_FILESYSTEM_ROOT": "/data",
+	}
+
+	config := Config{}
+	config.SetMockGetter(mapGetter(env))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if config.Bucket != "lsif-uploads" {
+		t.Errorf("unexpected value for Bucket. want=%s have=%s", "lsif-uploads", config.Bucket)
+	}
+	if config.TTL != 8*time.Hour {
+		t.Errorf("unexpected value for TTL. want=%v have=%v", 8*time.Hour, config.TTL)
+	}
+	if !config.ManageBucket {
+		t.Errorf("expected bucket to be managed")
+	}
+	if config.Filesystem.Root != "/data" {
+		t.Errorf("unexpected value for Filesystem.Root. want=%s have=%s", "/data", config.Filesystem.Root)
+	}
+}
+
+func TestConfigFilesystemMissingRoot(t *testing.T) {
+	config := Config{}
+	config.SetMockGetter(mapGetter(map[string]string{"PRECISE_CODE_INTEL_UPLOAD_BACKEND": "filesystem"}))
+	config.Load()
+
+	if err := config.Validate(); err == nil {
+		t.Fatalf("expected validation error for missing root directory")
+	}
+}
+
 func mapGetter(env map[string]string) func(name, defaultValue, description string) string {
 	return func(name, defaultValue, description string) string {
 		if v, ok := env[name]; ok {
@@ -0,0 +1,422 @@
+package uploadstore
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/derision-test/glock"
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+type filesystemStore struct {
+	ctx          context.Context
+	dir          string
+	ttl          time.Duration
+	manageBucket bool
+	clock        glock.Clock
+	expirerOnce  sync.Once
+	expirer      *goroutine.PeriodicGoroutine
+	operations   *operations
+}
+
+var _ Store = &filesystemStore{}
+
+type FilesystemConfig struct {
+	Root string
+}
+
+func (c *FilesystemConfig) load(parent *env.BaseConfig) {
+	c.Root = parent.Get("PRECISE_CODE_INTEL_UPLOAD_FILESYSTEM_ROOT", "", "The directory on local disk in which the bucket directory is created.")
+}
+
+// expirationInterval is the time between scans of the bucket directory for objects
+// that are older than the configured TTL.
+const expirationInterval = time.Hour
+
+// newFilesystemFromConfig creates a new store backed by a directory on local disk. The
+// routine removing expired objects runs until the given context is canceled.
+func newFilesystemFromConfig(ctx context.Context, config *Config, operations *operations) (Store, error) {
+	if config.Filesystem.Root == "" {
+		return nil, errors.New("no root directory supplied for filesystem upload store")
+	}
+
+	return newFilesystemWithClock(ctx, filepath.Join(config.Filesystem.Root, config.Bucket), config.TTL, config.ManageBucket, glock.NewRealClock(), operations), nil
+}
+
+func newFilesystemWithClock(ctx context.Context, dir string, ttl time.Duration, manageBucket bool, clock glock.Clock, operations *operations) *filesystemStore {
+	return &filesystemStore{
+		ctx:          ctx,
+		dir:          dir,
+		ttl:          ttl,
+		manageBucket: manageBucket,
+		clock:        clock,
+		operations:   operations,
+	}
+}
+
+// Init creates the bucket directory. As there is no lifecycle configuration to apply
+// on local disk, this also starts a background routine that periodically removes
+// expired objects. The routine runs until the context the store was created with is
+// canceled or Stop is called.
+func (s *filesystemStore) Init(ctx context.Context) error {
+	if !s.manageBucket {
+		if _, err := os.Stat(s.dir); err != nil {
+			return errors.Wrap(err, "failed to stat bucket directory")
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create bucket directory")
+	}
+
+	s.expirerOnce.Do(func() {
+		s.expirer = goroutine.NewPeriodicGoroutine(
+			s.ctx,
+			expirationInterval,
+			goroutine.NewHandlerWithErrorMessage("expire upload store objects", s.expire),
+		)
+
+		go s.expirer.Start()
+	})
+
+	return nil
+}
+
+// Stop stops the background routine that removes expired objects, if it was started,
+// and waits for it to finish.
+func (s *filesystemStore) Stop() {
+	s.expirerOnce.Do(func() {})
+	if s.expirer != nil {
+		s.expirer.Stop()
+	}
+}
+
+func (s *filesystemStore) Get(ctx context.Context, key string) (_ io.ReadCloser, err error) {
+	ctx, endObservation := s.operations.get.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report missing objects from Get rather than from the first read.
+	if _, err := os.Stat(path); err != nil {
+		return nil, errors.Wrap(err, "failed to get object")
+	}
+
+	reader := writeToPipe(func(w io.Writer) error {
+		zeroReads := 0
+		byteOffset := int64(0)
+
+		for {
+			n, err := readFileInto(w, path, byteOffset)
+			if err == nil || !isTransientFileReadError(err) {
+				return err
+			}
+
+			byteOffset += n
+			log15.Warn("Transient error while reading payload", "key", key, "error", err)
+
+			if n == 0 {
+				zeroReads++
+
+				if zeroReads > maxZeroReads {
+					return errNoDownloadProgress
+				}
+			} else {
+				zeroReads = 0
+			}
+		}
+	})
+
+	return io.NopCloser(reader), nil
+}
+
+// readFileInto reads the content of the file at the given path starting at the given byte
+// offset into the given writer. The number of bytes read is returned. On successful read,
+// the error value is nil.
+func readFileInto(w io.Writer, path string, byteOffset int64) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(byteOffset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return ioCopyHook(w, f)
+}
+
+// isTransientFileReadError returns true if a read from local disk may succeed when it
+// is retried, as is the case for I/O errors of network file systems.
+func isTransientFileReadError(err error) bool {
+	return errors.Is(err, syscall.EIO) || errors.Is(err, syscall.ESTALE) || errors.Is(err, syscall.EINTR)
+}
+
+func (s *filesystemStore) Upload(ctx context.Context, key string, r io.Reader) (_ int64, err error) {
+	ctx, endObservation := s.operations.upload.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := s.writeAtomically(path, func(w io.Writer) (int64, error) {
+		return io.Copy(w, r)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to upload object")
+	}
+
+	return n, nil
+}
+
+func (s *filesystemStore) Compose(ctx context.Context, destination string, sources ...string) (_ int64, err error) {
+	ctx, endObservation := s.operations.compose.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("destination", destination),
+		log.String("sources", strings.Join(sources, ", ")),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	destinationPath, err := s.path(destination)
+	if err != nil {
+		return 0, err
+	}
+
+	sourcePaths := make([]string, 0, len(sources))
+	for _, source := range sources {
+		sourcePath, err := s.path(source)
+		if err != nil {
+			return 0, err
+		}
+
+		sourcePaths = append(sourcePaths, sourcePath)
+	}
+
+	defer func() {
+		if err == nil {
+			// Delete sources on success
+			if err := s.deleteSources(sourcePaths); err != nil {
+				log15.Error("Failed to delete source objects", "error", err)
+			}
+		}
+	}()
+
+	n, err := s.writeAtomically(destinationPath, func(w io.Writer) (int64, error) {
+		var total int64
+		for _, sourcePath := range sourcePaths {
+			n, err := copyFile(w, sourcePath)
+			total += n
+			if err != nil {
+				return total, err
+			}
+		}
+
+		return total, nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to compose objects")
+	}
+
+	return n, nil
+}
+
+func (s *filesystemStore) Delete(ctx context.Context, key string) (err error) {
+	ctx, endObservation := s.operations.delete.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	// Deleting a missing object is not an error, matching the behavior of the remote stores.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to delete object")
+	}
+
+	return nil
+}
+
+// path returns the location of the object with the given key on disk. Keys that would
+// resolve to a location outside of the bucket directory are rejected.
+func (s *filesystemStore) path(key string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(os.PathSeparator)) {
+		return "", errors.Errorf("invalid object key %q", key)
+	}
+
+	return path, nil
+}
+
+// writeAtomically writes the output of the given function to a temporary file in the
+// bucket directory and moves it to the given path on success. Readers will never see
+// a partially written object.
+func (s *filesystemStore) writeAtomically(path string, write func(w io.Writer) (int64, error)) (_ int64, err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if os.IsNotExist(err) {
+		// The directory was pruned by a concurrent expiry since it was created.
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return 0, err
+		}
+		tmp, err = os.CreateTemp(filepath.Dir(path), ".upload-*")
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := tmp.Close(); closeErr != nil && !errors.Is(closeErr, os.ErrClosed) {
+			err = multierror.Append(err, errors.Wrap(closeErr, "failed to close writer"))
+		}
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := write(tmp)
+	if err != nil {
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (s *filesystemStore) deleteSources(sourcePaths []string) error {
+	return goroutine.RunWorkersOverStrings(sourcePaths, func(index int, sourcePath string) error {
+		if err := os.Remove(sourcePath); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to delete source object")
+		}
+
+		return nil
+	})
+}
+
+// expire removes all objects in the bucket directory that were last written to more
+// than the configured TTL ago, and then prunes the directories left empty.
+func (s *filesystemStore) expire(ctx context.Context) error {
+	if s.ttl <= 0 {
+		return nil
+	}
+
+	cutoff := s.clock.Now().Add(-s.ttl)
+
+	var dirs []string
+	if err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != s.dir {
+				dirs = append(dirs, path)
+			}
+
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "failed to delete expired object")
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Directories are walked before their children, so removing them in reverse
+	// order removes nested empty directories before their parents.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := removeEmptyDir(dirs[i]); err != nil {
+			return errors.Wrap(err, "failed to delete empty directory")
+		}
+	}
+
+	return nil
+}
+
+// removeEmptyDir removes the directory at the given path if it is empty.
+func removeEmptyDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+	if len(entries) > 0 {
+		return nil
+	}
+
+	// A concurrent upload may have created an object in the meantime, in which
+	// case the directory is not empty anymore and is left alone.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTEMPTY) && !errors.Is(err, syscall.EEXIST) {
+		return err
+	}
+
+	return nil
+}
+
+// copyFile writes the content of the file at the given path into the given writer.
+func copyFile(w io.Writer, path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return io.Copy(w, f)
+}
@@ -0,0 +1,300 @@
+package uploadstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/derision-test/glock"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestFilesystemInit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-bucket")
+
+	client := rawFilesystemClient(dir, glock.NewRealClock(), true)
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+
+	if info, err := os.Stat(dir); err != nil {
+		t.Fatalf("unexpected error reading bucket directory: %s", err)
+	} else if !info.IsDir() {
+		t.Errorf("expected bucket directory to be created")
+	}
+}
+
+func TestFilesystemUnmanagedInit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-bucket")
+
+	client := rawFilesystemClient(dir, glock.NewRealClock(), false)
+	if err := client.Init(context.Background()); err == nil {
+		t.Fatalf("expected error initializing client with missing bucket directory")
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected bucket directory to not be created")
+	}
+}
+
+func TestFilesystemUploadGet(t *testing.T) {
+	client := testFilesystemClient(t)
+
+	size, err := client.Upload(context.Background(), "test-key", bytes.NewReader([]byte("TEST PAYLOAD")))
+	if err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	} else if size != 12 {
+		t.Errorf("unexpected size. want=%d have=%d", 12, size)
+	}
+
+	if contents := readObject(t, client, "test-key"); contents != "TEST PAYLOAD" {
+		t.Errorf("unexpected contents. want=%s have=%s", "TEST PAYLOAD", contents)
+	}
+
+	if _, err := client.Get(context.Background(), "missing-key"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("unexpected error getting missing key: %s", err)
+	}
+}
+
+func TestFilesystemInvalidKey(t *testing.T) {
+	client := testFilesystemClient(t)
+
+	for _, key := range []string{"", "..", "../test-key", "a/../../test-key"} {
+		if _, err := client.Upload(context.Background(), key, bytes.NewReader(nil)); err == nil {
+			t.Errorf("expected error uploading key %q", key)
+		}
+	}
+}
+
+func TestFilesystemCombine(t *testing.T) {
+	client := testFilesystemClient(t)
+
+	for key, payload := range map[string]string{
+		"test-src1": "TEST ",
+		"test-src2": "PAY",
+		"test-src3": "LOAD",
+	} {
+		if _, err := client.Upload(context.Background(), key, bytes.NewReader([]byte(payload))); err != nil {
+			t.Fatalf("unexpected error uploading key: %s", err)
+		}
+	}
+
+	size, err := client.Compose(context.Background(), "test-key", "test-src1", "test-src2", "test-src3")
+	if err != nil {
+		t.Fatalf("unexpected error composing objects: %s", err)
+	} else if size != 12 {
+		t.Errorf("unexpected size. want=%d have=%d", 12, size)
+	}
+
+	if contents := readObject(t, client, "test-key"); contents != "TEST PAYLOAD" {
+		t.Errorf("unexpected contents. want=%s have=%s", "TEST PAYLOAD", contents)
+	}
+
+	for _, key := range []string{"test-src1", "test-src2", "test-src3"} {
+		if _, err := client.Get(context.Background(), key); err == nil {
+			t.Errorf("expected source object %q to be deleted", key)
+		}
+	}
+}
+
+func TestFilesystemDelete(t *testing.T) {
+	client := testFilesystemClient(t)
+
+	if _, err := client.Upload(context.Background(), "test-key", bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting key: %s", err)
+	}
+	if _, err := client.Get(context.Background(), "test-key"); err == nil {
+		t.Errorf("expected object to be deleted")
+	}
+
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting missing key: %s", err)
+	}
+}
+
+func TestFilesystemExpire(t *testing.T) {
+	now := time.Now()
+	clock := glock.NewMockClockAt(now)
+	client := rawFilesystemClient(filepath.Join(t.TempDir(), "test-bucket"), clock, true)
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+
+	for _, key := range []string{"test-old", "test-new", "nested/test-old"} {
+		if _, err := client.Upload(context.Background(), key, bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+			t.Fatalf("unexpected error uploading key: %s", err)
+		}
+	}
+	for _, key := range []string{"test-old", "nested/test-old"} {
+		path, _ := client.path(key)
+		modTime := now.Add(-time.Hour * 24 * 4)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("unexpected error setting modification time: %s", err)
+		}
+	}
+
+	if err := client.expire(context.Background()); err != nil {
+		t.Fatalf("unexpected error expiring objects: %s", err)
+	}
+
+	for key, expectedExists := range map[string]bool{
+		"test-old":        false,
+		"nested/test-old": false,
+		"test-new":        true,
+	} {
+		rc, err := client.Get(context.Background(), key)
+		if err == nil {
+			rc.Close()
+		}
+		if exists := err == nil; exists != expectedExists {
+			t.Errorf("unexpected existence of object %q. want=%v have=%v", key, expectedExists, exists)
+		}
+	}
+}
+
+func TestFilesystemGetTransientErrors(t *testing.T) {
+	// read 50 bytes then return an I/O error
+	ioCopyHook = func(w io.Writer, r io.Reader) (int64, error) {
+		n, err := io.CopyN(w, r, 50)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		return n, &os.PathError{Op: "read", Path: "test", Err: syscall.EIO}
+	}
+	t.Cleanup(func() { ioCopyHook = io.Copy })
+
+	client := testFilesystemClient(t)
+	if _, err := client.Upload(context.Background(), "test-key", bytes.NewReader(fullContents)); err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+
+	if contents := readObject(t, client, "test-key"); contents != string(fullContents) {
+		t.Fatalf("unexpected contents. want=%d bytes have=%d bytes", len(fullContents), len(contents))
+	}
+}
+
+func TestFilesystemGetReadNothingLoop(t *testing.T) {
+	// read nothing then return an I/O error
+	ioCopyHook = func(w io.Writer, r io.Reader) (int64, error) {
+		return 0, &os.PathError{Op: "read", Path: "test", Err: syscall.EIO}
+	}
+	t.Cleanup(func() { ioCopyHook = io.Copy })
+
+	client := testFilesystemClient(t)
+	if _, err := client.Upload(context.Background(), "test-key", bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+
+	rc, err := client.Get(context.Background(), "test-key")
+	if err != nil {
+		t.Fatalf("unexpected error getting key: %s", err)
+	}
+	defer rc.Close()
+
+	if _, err := io.ReadAll(rc); err != errNoDownloadProgress {
+		t.Fatalf("unexpected error reading object. want=%q have=%q", errNoDownloadProgress, err)
+	}
+}
+
+func TestFilesystemExpirePrunesEmptyDirectories(t *testing.T) {
+	now := time.Now()
+	clock := glock.NewMockClockAt(now)
+	client := rawFilesystemClient(filepath.Join(t.TempDir(), "test-bucket"), clock, true)
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+	t.Cleanup(client.Stop)
+
+	for _, key := range []string{"a/b/test-old", "a/test-new", "c/test-old"} {
+		if _, err := client.Upload(context.Background(), key, bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+			t.Fatalf("unexpected error uploading key: %s", err)
+		}
+	}
+	for _, key := range []string{"a/b/test-old", "c/test-old"} {
+		path, _ := client.path(key)
+		modTime := now.Add(-time.Hour * 24 * 4)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("unexpected error setting modification time: %s", err)
+		}
+	}
+
+	if err := client.expire(context.Background()); err != nil {
+		t.Fatalf("unexpected error expiring objects: %s", err)
+	}
+
+	for dir, expectedExists := range map[string]bool{
+		"":    true,
+		"a":   true,
+		"a/b": false,
+		"c":   false,
+	} {
+		_, err := os.Stat(filepath.Join(client.dir, dir))
+		if exists := err == nil; exists != expectedExists {
+			t.Errorf("unexpected existence of directory %q. want=%v have=%v", dir, expectedExists, exists)
+		}
+	}
+
+	// Objects can still be written to pruned directories.
+	if _, err := client.Upload(context.Background(), "a/b/test-new", bytes.NewReader([]byte("TEST PAYLOAD"))); err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+}
+
+func TestFilesystemStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newFilesystemWithClock(ctx, filepath.Join(t.TempDir(), "test-bucket"), time.Hour, true, glock.NewRealClock(), newOperations(&observation.TestContext))
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		client.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected expirer to stop")
+	}
+}
+
+func testFilesystemClient(t *testing.T) Store {
+	return newLazyStore(rawFilesystemClient(filepath.Join(t.TempDir(), "test-bucket"), glock.NewRealClock(), true))
+}
+
+func rawFilesystemClient(dir string, clock glock.Clock, manageBucket bool) *filesystemStore {
+	return newFilesystemWithClock(context.Background(), dir, time.Hour*24*3, manageBucket, clock, newOperations(&observation.TestContext))
+}
+
+func readObject(t *testing.T, client Store, key string) string {
+	rc, err := client.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("unexpected error getting key: %s", err)
+	}
+	defer rc.Close()
+
+	contents, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error reading object: %s", err)
+	}
+
+	return string(contents)
+}
@@ -3,8 +3,10 @@ package codemonitors
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/keegancsmith/sqlf"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -40,6 +42,10 @@ type ActionJobMetadata struct {
 
 	// The query with after: filter.
 	Query string
+
+	// Results are the new search results found by the trigger job, as returned
+	// by the GraphQL API.
+	Results []interface{}
 }
 
 // ActionJobColumns is the list of db columns used to populate an ActionJob struct.
@@ -162,25 +168,63 @@ func (s *codeMonitorStore) CountActionJobs(ctx context.Context, opts ListActionJ
 	return count, err
 }
 
-const enqueueActionEmailFmtStr = `
-WITH due AS (
+const enqueueActionJobsFmtStr = `
+WITH due_emails AS (
 	SELECT e.id
 	FROM cm_emails e
 	INNER JOIN cm_queries q ON e.monitor = q.monitor
 	WHERE q.id = %s AND e.enabled = true
 ),
-busy AS (
+busy_emails AS (
 	SELECT DISTINCT email as id FROM cm_action_jobs
-	WHERE state = 'queued'
-	OR state = 'processing'
+	WHERE email IS NOT NULL
+	AND (state = 'queued' OR state = 'processing')
+),
+due_webhooks AS (
+	SELECT w.id
+	FROM cm_webhooks w
+	INNER JOIN cm_queries q ON w.monitor = q.monitor
+	WHERE q.id = %s AND w.enabled = true
+),
+busy_webhooks AS (
+	SELECT DISTINCT webhook as id FROM cm_action_jobs
+	WHERE webhook IS NOT NULL
+	AND (state = 'queued' OR state = 'processing')
+),
+due_slack_webhooks AS (
+	SELECT sw.id
+	FROM cm_slack_webhooks sw
+	INNER JOIN cm_queries q ON sw.monitor = q.monitor
+	WHERE q.id = %s AND sw.enabled = true
+),
+busy_slack_webhooks AS (
+	SELECT DISTINCT slack_webhook as id FROM cm_action_jobs
+	WHERE slack_webhook IS NOT NULL
+	AND (state = 'queued' OR state = 'processing')
 )
-INSERT INTO cm_action_jobs (email, trigger_event)
-SELECT id, %s::integer from due EXCEPT SELECT id, %s::integer from busy ORDER BY id
+INSERT INTO cm_action_jobs (email, webhook, slack_webhook, trigger_event)
+SELECT id, NULL::bigint, NULL::bigint, %s::integer FROM (SELECT id FROM due_emails EXCEPT SELECT id FROM busy_emails ORDER BY id) emails
+UNION ALL
+SELECT NULL::bigint, id, NULL::bigint, %s::integer FROM (SELECT id FROM due_webhooks EXCEPT SELECT id FROM busy_webhooks ORDER BY id) webhooks
+UNION ALL
+SELECT NULL::bigint, NULL::bigint, id, %s::integer FROM (SELECT id FROM due_slack_webhooks EXCEPT SELECT id FROM busy_slack_webhooks ORDER BY id) slack_webhooks
 `
 
+// EnqueueActionJobsForQueryIDInt64 enqueues an action job for every enabled
+// email, webhook and Slack webhook action of the monitor that owns the given
+// query, unless a job for that action is already queued or processing.
+//
 // TODO(camdencheek): could we enqueue based on monitor ID rather than query ID? Would avoid joins above.
-func (s *codeMonitorStore) EnqueueActionEmailsForQueryIDInt64(ctx context.Context, queryID int64, triggerEventID int) (err error) {
-	return s.Store.Exec(ctx, sqlf.Sprintf(enqueueActionEmailFmtStr, queryID, triggerEventID, triggerEventID))
+func (s *codeMonitorStore) EnqueueActionJobsForQueryIDInt64(ctx context.Context, queryID int64, triggerEventID int) (err error) {
+	return s.Store.Exec(ctx, sqlf.Sprintf(
+		enqueueActionJobsFmtStr,
+		queryID,
+		queryID,
+		queryID,
+		triggerEventID,
+		triggerEventID,
+		triggerEventID,
+	))
 }
 
 const getActionJobMetadataFmtStr = `
@@ -188,7 +232,8 @@ SELECT
 	cm.description,
 	ctj.query_string,
 	cm.id AS monitorID,
-	ctj.num_results
+	ctj.num_results,
+	ctj.search_results
 FROM cm_action_jobs caj
 INNER JOIN cm_trigger_jobs ctj on caj.trigger_event = ctj.id
 INNER JOIN cm_queries cq on cq.id = ctj.query
@@ -199,7 +244,28 @@ WHERE caj.id = %s
 func (s *codeMonitorStore) GetActionJobMetadata(ctx context.Context, recordID int) (*ActionJobMetadata, error) {
 	row := s.Store.QueryRow(ctx, sqlf.Sprintf(getActionJobMetadataFmtStr, recordID))
 	m := &ActionJobMetadata{}
-	return m, row.Scan(&m.Description, &m.Query, &m.MonitorID, &m.NumResults)
+	var searchResults []byte
+	if err := row.Scan(&m.Description, &m.Query, &m.MonitorID, &m.NumResults, &searchResults); err != nil {
+		return m, err
+	}
+	if len(searchResults) > 0 {
+		if err := json.Unmarshal(searchResults, &m.Results); err != nil {
+			return m, errors.Wrap(err, "unmarshalling search results")
+		}
+	}
+	return m, nil
+}
+
+const setActionJobLogContentsFmtStr = `
+UPDATE cm_action_jobs
+SET log_contents = %s
+WHERE id = %s
+`
+
+// SetActionJobLogContents records a human-readable description of the outcome
+// of an action job, such as the response to a webhook delivery.
+func (s *codeMonitorStore) SetActionJobLogContents(ctx context.Context, recordID int, logContents string) error {
+	return s.Store.Exec(ctx, sqlf.Sprintf(setActionJobLogContentsFmtStr, logContents, recordID))
 }
 
 const actionJobForIDFmtStr = `
@@ -9,7 +9,7 @@ import (
 	"github.com/keegancsmith/sqlf"
 )
 
-func TestEnqueueActionEmailsForQueryIDInt64QueryByRecordID(t *testing.T) {
+func TestEnqueueActionJobsForQueryIDInt64QueryByRecordID(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
 	}
@@ -24,7 +24,7 @@ func TestEnqueueActionEmailsForQueryIDInt64QueryByRecordID(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.EnqueueActionEmailsForQueryIDInt64(ctx, 1, 1)
+	err = s.EnqueueActionJobsForQueryIDInt64(ctx, 1, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -72,15 +72,16 @@ func TestGetActionJobMetadata(t *testing.T) {
 	}
 
 	var (
-		wantNumResults       = 42
+		wantNumResults       = 2
+		wantResults          = []interface{}{map[string]interface{}{"__typename": "CommitSearchResult"}, map[string]interface{}{"__typename": "FileMatch"}}
 		wantQuery            = testQuery + " after:\"" + s.Now().UTC().Format(time.RFC3339) + "\""
 		wantMonitorID  int64 = 1
 	)
-	err = s.LogSearch(ctx, wantQuery, wantNumResults, 1)
+	err = s.LogSearch(ctx, wantQuery, wantResults, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.EnqueueActionEmailsForQueryIDInt64(ctx, 1, 1)
+	err = s.EnqueueActionJobsForQueryIDInt64(ctx, 1, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -94,6 +95,7 @@ func TestGetActionJobMetadata(t *testing.T) {
 		Query:       wantQuery,
 		NumResults:  &wantNumResults,
 		MonitorID:   wantMonitorID,
+		Results:     wantResults,
 	}
 	if diff := cmp.Diff(got, want); diff != "" {
 		t.Fatalf("diff: %s", diff)
@@ -121,7 +123,7 @@ func TestScanActionJobs(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = s.EnqueueActionEmailsForQueryIDInt64(ctx, testQueryID, testTriggerEventID)
+	err = s.EnqueueActionJobsForQueryIDInt64(ctx, testQueryID, testTriggerEventID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,173 @@
+package codemonitors
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+// MonitorWebhook is a webhook action of a code monitor. New search results are
+// posted to URL as JSON.
+type MonitorWebhook struct {
+	Id        int64
+	Monitor   int64
+	Enabled   bool
+	URL       string
+	CreatedBy int32
+	CreatedAt time.Time
+	ChangedBy int32
+	ChangedAt time.Time
+}
+
+// MonitorSlackWebhook is a Slack action of a code monitor. New search results
+// are posted as a message to the Slack incoming webhook URL.
+type MonitorSlackWebhook struct {
+	Id        int64
+	Monitor   int64
+	Enabled   bool
+	URL       string
+	CreatedBy int32
+	CreatedAt time.Time
+	ChangedBy int32
+	ChangedAt time.Time
+}
+
+const createWebhookActionFmtStr = `
+INSERT INTO cm_webhooks
+(monitor, enabled, url, created_by, created_at, changed_by, changed_at)
+VALUES (%s,%s,%s,%s,%s,%s,%s)
+RETURNING %s;
+`
+
+func (s *codeMonitorStore) CreateWebhookAction(ctx context.Context, monitorID int64, enabled bool, url string) (*MonitorWebhook, error) {
+	now := s.Now()
+	a := actor.FromContext(ctx)
+	q := sqlf.Sprintf(
+		createWebhookActionFmtStr,
+		monitorID,
+		enabled,
+		url,
+		a.UID,
+		now,
+		a.UID,
+		now,
+		sqlf.Join(WebhookColumns, ", "),
+	)
+	return scanWebhook(s.QueryRow(ctx, q))
+}
+
+const webhookActionByIDFmtStr = `
+SELECT %s
+FROM cm_webhooks
+WHERE id = %s
+`
+
+func (s *codeMonitorStore) WebhookActionByIDInt64(ctx context.Context, webhookID int64) (*MonitorWebhook, error) {
+	q := sqlf.Sprintf(webhookActionByIDFmtStr, sqlf.Join(WebhookColumns, ", "), webhookID)
+	w, err := scanWebhook(s.QueryRow(ctx, q))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Errorf("webhook action %d not found", webhookID)
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+const createSlackWebhookActionFmtStr = `
+INSERT INTO cm_slack_webhooks
+(monitor, enabled, url, created_by, created_at, changed_by, changed_at)
+VALUES (%s,%s,%s,%s,%s,%s,%s)
+RETURNING %s;
+`
+
+func (s *codeMonitorStore) CreateSlackWebhookAction(ctx context.Context, monitorID int64, enabled bool, url string) (*MonitorSlackWebhook, error) {
+	now := s.Now()
+	a := actor.FromContext(ctx)
+	q := sqlf.Sprintf(
+		createSlackWebhookActionFmtStr,
+		monitorID,
+		enabled,
+		url,
+		a.UID,
+		now,
+		a.UID,
+		now,
+		sqlf.Join(SlackWebhookColumns, ", "),
+	)
+	return scanSlackWebhook(s.QueryRow(ctx, q))
+}
+
+const slackWebhookActionByIDFmtStr = `
+SELECT %s
+FROM cm_slack_webhooks
+WHERE id = %s
+`
+
+func (s *codeMonitorStore) SlackWebhookActionByIDInt64(ctx context.Context, slackWebhookID int64) (*MonitorSlackWebhook, error) {
+	q := sqlf.Sprintf(slackWebhookActionByIDFmtStr, sqlf.Join(SlackWebhookColumns, ", "), slackWebhookID)
+	w, err := scanSlackWebhook(s.QueryRow(ctx, q))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Errorf("Slack webhook action %d not found", slackWebhookID)
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+var WebhookColumns = []*sqlf.Query{
+	sqlf.Sprintf("cm_webhooks.id"),
+	sqlf.Sprintf("cm_webhooks.monitor"),
+	sqlf.Sprintf("cm_webhooks.enabled"),
+	sqlf.Sprintf("cm_webhooks.url"),
+	sqlf.Sprintf("cm_webhooks.created_by"),
+	sqlf.Sprintf("cm_webhooks.created_at"),
+	sqlf.Sprintf("cm_webhooks.changed_by"),
+	sqlf.Sprintf("cm_webhooks.changed_at"),
+}
+
+func scanWebhook(row dbutil.Scanner) (*MonitorWebhook, error) {
+	w := &MonitorWebhook{}
+	return w, row.Scan(
+		&w.Id,
+		&w.Monitor,
+		&w.Enabled,
+		&w.URL,
+		&w.CreatedBy,
+		&w.CreatedAt,
+		&w.ChangedBy,
+		&w.ChangedAt,
+	)
+}
+
+var SlackWebhookColumns = []*sqlf.Query{
+	sqlf.Sprintf("cm_slack_webhooks.id"),
+	sqlf.Sprintf("cm_slack_webhooks.monitor"),
+	sqlf.Sprintf("cm_slack_webhooks.enabled"),
+	sqlf.Sprintf("cm_slack_webhooks.url"),
+	sqlf.Sprintf("cm_slack_webhooks.created_by"),
+	sqlf.Sprintf("cm_slack_webhooks.created_at"),
+	sqlf.Sprintf("cm_slack_webhooks.changed_by"),
+	sqlf.Sprintf("cm_slack_webhooks.changed_at"),
+}
+
+func scanSlackWebhook(row dbutil.Scanner) (*MonitorSlackWebhook, error) {
+	w := &MonitorSlackWebhook{}
+	return w, row.Scan(
+		&w.Id,
+		&w.Monitor,
+		&w.Enabled,
+		&w.URL,
+		&w.CreatedBy,
+		&w.CreatedAt,
+		&w.ChangedBy,
+		&w.ChangedAt,
+	)
+}
@@ -34,12 +34,28 @@ const gqlSearchQuery = `query CodeMonitorSearch(
 			results {
 				__typename
 				... on FileMatch {
+					repository {
+						id
+						name
+					}
+					file {
+						path
+						commit {
+							oid
+						}
+					}
 					limitHit
 					lineMatches {
 						preview
 						lineNumber
 						offsetAndLengths
 					}
+					symbols {
+						name
+						containerName
+						kind
+						url
+					}
 				}
 				... on CommitSearchResult {
 					refs {
@@ -112,6 +128,7 @@ type gqlSearchResponse struct {
 		Search struct {
 			Results struct {
 				ApproximateResultCount string
+				LimitHit               bool
 				Cloning                []*api.Repo
 				Timedout               []*api.Repo
 				Results                []interface{}
@@ -0,0 +1,204 @@
+package background
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	cm "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// repoSymbols are the symbols matched by a symbol search in a single
+// repository.
+type repoSymbols struct {
+	repoID api.RepoID
+	commit string
+	// keys identify the matched symbols, see symbolKey.
+	keys map[string]struct{}
+	// fileMatches are the FileMatch results of the repository, in the order
+	// they were returned.
+	fileMatches []map[string]interface{}
+}
+
+// diffSymbolResults compares the symbols matched by a symbol search trigger
+// against those recorded in the previous run, records the current symbols and
+// returns FileMatch results that only contain the new symbols.
+//
+// Symbols are only considered new if the repository moved to a different
+// commit since the previous run. The first run of a trigger in a repository
+// records a baseline without reporting any results.
+//
+// Incomplete searches (limit hit, timed out or cloning repositories) neither
+// report results nor update the recorded symbols, since symbols missing from
+// them would otherwise be reported as new by the next complete search. Hitting
+// the limit is returned as an error, since unlike timeouts and cloning it
+// won't resolve itself.
+func diffSymbolResults(ctx context.Context, s cm.CodeMonitorStore, q *cm.MonitorQuery, response *gqlSearchResponse) ([]interface{}, error) {
+	results := response.Data.Search.Results
+	if results.LimitHit {
+		return nil, errors.Errorf("symbol search matched more than its result limit (at most %d), narrow the query", cm.SymbolTriggerMaxResults)
+	}
+	if len(results.Cloning) > 0 || len(results.Timedout) > 0 {
+		return nil, nil
+	}
+
+	states, err := s.SymbolTriggerStatesForQueryIDInt64(ctx, q.Id)
+	if err != nil {
+		return nil, errors.Errorf("store.SymbolTriggerStatesForQueryIDInt64: %w", err)
+	}
+
+	current, err := groupSymbolsByRepo(results.Results)
+	if err != nil {
+		return nil, err
+	}
+
+	var newResults []interface{}
+	for _, repo := range current {
+		prev := states[int32(repo.repoID)]
+		if prev != nil && prev.QueryString != q.QueryString {
+			// The trigger query was edited, so the previous symbols are not
+			// comparable.
+			prev = nil
+		}
+		if prev != nil && prev.Commit == repo.commit {
+			continue
+		}
+		if prev != nil {
+			newResults = append(newResults, repo.newSymbolResults(prev.Symbols)...)
+		}
+
+		if err := s.UpsertSymbolTriggerState(ctx, &cm.SymbolTriggerState{
+			Query:       q.Id,
+			RepoID:      int32(repo.repoID),
+			QueryString: q.QueryString,
+			Commit:      repo.commit,
+			Symbols:     repo.sortedKeys(),
+		}); err != nil {
+			return nil, errors.Errorf("store.UpsertSymbolTriggerState: %w", err)
+		}
+	}
+
+	// Repositories that no longer have any matching symbols are recorded as
+	// such, so that symbols reappearing later are reported.
+	seen := make(map[int32]struct{}, len(current))
+	for _, repo := range current {
+		seen[int32(repo.repoID)] = struct{}{}
+	}
+	for repoID, prev := range states {
+		if _, ok := seen[repoID]; ok || len(prev.Symbols) == 0 || prev.QueryString != q.QueryString {
+			continue
+		}
+		if err := s.UpsertSymbolTriggerState(ctx, &cm.SymbolTriggerState{
+			Query:       q.Id,
+			RepoID:      repoID,
+			QueryString: q.QueryString,
+			Commit:      prev.Commit,
+			Symbols:     []string{},
+		}); err != nil {
+			return nil, errors.Errorf("store.UpsertSymbolTriggerState: %w", err)
+		}
+	}
+
+	return newResults, nil
+}
+
+// groupSymbolsByRepo groups the FileMatch results of a symbol search by
+// repository, ordered by repository ID.
+func groupSymbolsByRepo(results []interface{}) ([]*repoSymbols, error) {
+	byRepo := map[api.RepoID]*repoSymbols{}
+	for _, result := range results {
+		fm, ok := result.(map[string]interface{})
+		if !ok || fm["__typename"] != "FileMatch" {
+			continue
+		}
+
+		repository, _ := fm["repository"].(map[string]interface{})
+		id, _ := repository["id"].(string)
+		var repoID api.RepoID
+		if err := relay.UnmarshalSpec(graphql.ID(id), &repoID); err != nil {
+			return nil, errors.Wrapf(err, "invalid repository ID %q", id)
+		}
+		file, _ := fm["file"].(map[string]interface{})
+		path, _ := file["path"].(string)
+		commit, _ := file["commit"].(map[string]interface{})
+		oid, _ := commit["oid"].(string)
+
+		repo, ok := byRepo[repoID]
+		if !ok {
+			repo = &repoSymbols{repoID: repoID, commit: oid, keys: map[string]struct{}{}}
+			byRepo[repoID] = repo
+		}
+		repo.fileMatches = append(repo.fileMatches, fm)
+		symbols, _ := fm["symbols"].([]interface{})
+		for _, symbol := range symbols {
+			repo.keys[symbolKey(path, symbol)] = struct{}{}
+		}
+	}
+
+	repos := make([]*repoSymbols, 0, len(byRepo))
+	for _, repo := range byRepo {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].repoID < repos[j].repoID })
+	return repos, nil
+}
+
+// newSymbolResults returns copies of the repository's FileMatch results that
+// only contain the symbols not identified by any of the given keys. Files
+// without new symbols are omitted.
+func (r *repoSymbols) newSymbolResults(previousKeys []string) []interface{} {
+	previous := make(map[string]struct{}, len(previousKeys))
+	for _, key := range previousKeys {
+		previous[key] = struct{}{}
+	}
+
+	var results []interface{}
+	for _, fm := range r.fileMatches {
+		file, _ := fm["file"].(map[string]interface{})
+		path, _ := file["path"].(string)
+		symbols, _ := fm["symbols"].([]interface{})
+
+		var newSymbols []interface{}
+		for _, symbol := range symbols {
+			if _, ok := previous[symbolKey(path, symbol)]; !ok {
+				newSymbols = append(newSymbols, symbol)
+			}
+		}
+		if len(newSymbols) == 0 {
+			continue
+		}
+
+		result := make(map[string]interface{}, len(fm))
+		for k, v := range fm {
+			result[k] = v
+		}
+		result["symbols"] = newSymbols
+		results = append(results, result)
+	}
+	return results
+}
+
+func (r *repoSymbols) sortedKeys() []string {
+	keys := make([]string, 0, len(r.keys))
+	for key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// symbolKey identifies a symbol independently of its position in the file, so
+// that symbols moving within a file are not reported as new.
+func symbolKey(path string, symbol interface{}) string {
+	m, _ := symbol.(map[string]interface{})
+	name, _ := m["name"].(string)
+	containerName, _ := m["containerName"].(string)
+	kind, _ := m["kind"].(string)
+	key, _ := json.Marshal([]string{path, containerName, name, kind})
+	return string(key)
+}
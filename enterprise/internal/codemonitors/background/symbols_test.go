@@ -0,0 +1,147 @@
+package background
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	cm "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// symbolStateStore is an in-memory implementation of the symbol trigger state
+// methods of the code monitor store.
+type symbolStateStore struct {
+	cm.CodeMonitorStore
+	states map[int32]*cm.SymbolTriggerState
+}
+
+func (s *symbolStateStore) SymbolTriggerStatesForQueryIDInt64(ctx context.Context, queryID int64) (map[int32]*cm.SymbolTriggerState, error) {
+	states := make(map[int32]*cm.SymbolTriggerState, len(s.states))
+	for repoID, state := range s.states {
+		copied := *state
+		states[repoID] = &copied
+	}
+	return states, nil
+}
+
+func (s *symbolStateStore) UpsertSymbolTriggerState(ctx context.Context, state *cm.SymbolTriggerState) error {
+	s.states[state.RepoID] = state
+	return nil
+}
+
+func TestDiffSymbolResults(t *testing.T) {
+	fileMatch := func(repoID int32, commit, path string, names ...string) map[string]interface{} {
+		symbols := make([]interface{}, 0, len(names))
+		for _, name := range names {
+			symbols = append(symbols, map[string]interface{}{"name": name, "containerName": "", "kind": "FUNCTION"})
+		}
+		return map[string]interface{}{
+			"__typename": "FileMatch",
+			"repository": map[string]interface{}{"id": string(relay.MarshalID("Repository", repoID)), "name": "repo"},
+			"file":       map[string]interface{}{"path": path, "commit": map[string]interface{}{"oid": commit}},
+			"symbols":    symbols,
+		}
+	}
+	response := func(results ...interface{}) *gqlSearchResponse {
+		var r gqlSearchResponse
+		r.Data.Search.Results.Results = results
+		return &r
+	}
+
+	ctx := context.Background()
+	store := &symbolStateStore{states: map[int32]*cm.SymbolTriggerState{}}
+	q := &cm.MonitorQuery{Id: 1, QueryString: "type:symbol Unsafe"}
+
+	// The first run records a baseline.
+	newResults, err := diffSymbolResults(ctx, store, q, response(fileMatch(1, "c1", "a.go", "DoUnsafe")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newResults) != 0 {
+		t.Fatalf("expected no results for baseline, got %v", newResults)
+	}
+
+	// Only symbols that are new at a different commit are reported.
+	newResults, err = diffSymbolResults(ctx, store, q, response(
+		fileMatch(1, "c2", "a.go", "DoUnsafe", "MoreUnsafe"),
+		fileMatch(1, "c2", "b.go", "DoUnsafe"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		fileMatch(1, "c2", "a.go", "MoreUnsafe"),
+		fileMatch(1, "c2", "b.go", "DoUnsafe"),
+	}
+	if diff := cmp.Diff(want, newResults); diff != "" {
+		t.Fatalf("unexpected results (-want +got):\n%s", diff)
+	}
+
+	// Running again at the same commit reports nothing.
+	newResults, err = diffSymbolResults(ctx, store, q, response(fileMatch(1, "c2", "a.go", "DoUnsafe", "MoreUnsafe")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newResults) != 0 {
+		t.Fatalf("expected no results for unchanged commit, got %v", newResults)
+	}
+
+	// A complete search without matches clears the state, so that symbols
+	// reappearing later are reported.
+	if _, err := diffSymbolResults(ctx, store, q, response()); err != nil {
+		t.Fatal(err)
+	}
+	if symbols := store.states[1].Symbols; len(symbols) != 0 {
+		t.Fatalf("expected symbols to be cleared, got %v", symbols)
+	}
+	newResults, err = diffSymbolResults(ctx, store, q, response(fileMatch(1, "c3", "a.go", "DoUnsafe")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]interface{}{fileMatch(1, "c3", "a.go", "DoUnsafe")}, newResults); diff != "" {
+		t.Fatalf("unexpected results (-want +got):\n%s", diff)
+	}
+
+	// Searches over the result limit fail, since the trigger would otherwise
+	// never fire.
+	r := response(fileMatch(1, "c4", "a.go", "DoUnsafe", "Unseen"))
+	r.Data.Search.Results.LimitHit = true
+	if _, err := diffSymbolResults(ctx, store, q, r); err == nil {
+		t.Fatal("expected an error for a search over the result limit")
+	}
+	if commit := store.states[1].Commit; commit != "c3" {
+		t.Fatalf("expected state to be untouched by a search over the result limit, got commit %q", commit)
+	}
+
+	// Incomplete searches neither report results nor update the state.
+	for name, incomplete := range map[string]func(*gqlSearchResponse){
+		"timed out": func(r *gqlSearchResponse) { r.Data.Search.Results.Timedout = []*api.Repo{{ID: 2}} },
+		"cloning":   func(r *gqlSearchResponse) { r.Data.Search.Results.Cloning = []*api.Repo{{ID: 2}} },
+	} {
+		r := response(fileMatch(1, "c4", "a.go", "DoUnsafe", "Unseen"))
+		incomplete(r)
+		newResults, err = diffSymbolResults(ctx, store, q, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(newResults) != 0 {
+			t.Fatalf("%s: expected no results for incomplete search, got %v", name, newResults)
+		}
+		if commit := store.states[1].Commit; commit != "c3" {
+			t.Fatalf("%s: expected state to be untouched, got commit %q", name, commit)
+		}
+	}
+
+	// Editing the query records a new baseline.
+	q.QueryString = "type:symbol Danger"
+	newResults, err = diffSymbolResults(ctx, store, q, response(fileMatch(1, "c4", "a.go", "DoDanger")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newResults) != 0 {
+		t.Fatalf("expected no results after editing the query, got %v", newResults)
+	}
+}
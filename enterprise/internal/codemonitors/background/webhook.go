@@ -0,0 +1,154 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cm "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/email"
+	"github.com/sourcegraph/sourcegraph/internal/slack"
+	"github.com/sourcegraph/sourcegraph/internal/webhook"
+)
+
+const (
+	utmSourceWebhook = "code-monitoring-webhook"
+	utmSourceSlack   = "code-monitoring-slack"
+
+	// webhookEventResults is the event sent with webhook deliveries of new
+	// search results.
+	webhookEventResults = "code_monitor.results"
+
+	// maxSlackResults is the number of results listed in a Slack message. The
+	// remaining results are only reachable through the search link.
+	maxSlackResults = 10
+)
+
+// webhookPayload is the JSON body posted to the URL of a webhook action.
+type webhookPayload struct {
+	MonitorDescription string        `json:"monitorDescription"`
+	MonitorURL         string        `json:"monitorURL"`
+	Query              string        `json:"query"`
+	SearchURL          string        `json:"searchURL"`
+	NumResults         int           `json:"numResults"`
+	Results            []interface{} `json:"results"`
+}
+
+func newWebhookPayload(ctx context.Context, m *cm.ActionJobMetadata) (*webhookPayload, error) {
+	searchURL, err := email.GetSearchURL(ctx, m.Query, utmSourceWebhook)
+	if err != nil {
+		return nil, err
+	}
+	monitorURL, err := email.GetCodeMonitorURL(ctx, m.MonitorID, utmSourceWebhook)
+	if err != nil {
+		return nil, err
+	}
+
+	results := m.Results
+	if results == nil {
+		results = []interface{}{}
+	}
+	return &webhookPayload{
+		MonitorDescription: m.Description,
+		MonitorURL:         monitorURL,
+		Query:              m.Query,
+		SearchURL:          searchURL,
+		NumResults:         zeroOrVal(m.NumResults),
+		Results:            results,
+	}, nil
+}
+
+func newSlackPayload(ctx context.Context, m *cm.ActionJobMetadata) (*slack.Payload, error) {
+	searchURL, err := email.GetSearchURL(ctx, m.Query, utmSourceSlack)
+	if err != nil {
+		return nil, err
+	}
+	monitorURL, err := email.GetCodeMonitorURL(ctx, m.MonitorID, utmSourceSlack)
+	if err != nil {
+		return nil, err
+	}
+
+	numResults := zeroOrVal(m.NumResults)
+	plural := "s"
+	if numResults == 1 {
+		plural = ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Code monitor <%s|%s> found %d new search result%s. <%s|View all results>", monitorURL, slackEscape(m.Description), numResults, plural, searchURL)
+	for i, result := range m.Results {
+		if i == maxSlackResults {
+			fmt.Fprintf(&b, "\n…and %d more", len(m.Results)-maxSlackResults)
+			break
+		}
+		if summary, ok := resultSummary(result); ok {
+			fmt.Fprintf(&b, "\n• %s", summary)
+		}
+	}
+
+	return &slack.Payload{
+		Username:    "code-monitor-bot",
+		IconEmoji:   ":mag:",
+		UnfurlLinks: false,
+		UnfurlMedia: false,
+		Text:        b.String(),
+	}, nil
+}
+
+// resultSummary returns a one line description of a commit, diff or symbol
+// search result, formatted for Slack.
+func resultSummary(result interface{}) (string, bool) {
+	m, ok := result.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	switch m["__typename"] {
+	case "CommitSearchResult":
+		commit, ok := m["commit"].(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		repository, _ := commit["repository"].(map[string]interface{})
+		repoName, _ := repository["name"].(string)
+		abbreviatedOID, _ := commit["abbreviatedOID"].(string)
+		message, _ := commit["message"].(string)
+		if i := strings.IndexByte(message, '\n'); i >= 0 {
+			message = message[:i]
+		}
+		return fmt.Sprintf("`%s` `%s` %s", slackEscape(repoName), abbreviatedOID, slackEscape(message)), true
+	case "FileMatch":
+		symbols, _ := m["symbols"].([]interface{})
+		if len(symbols) == 0 {
+			return "", false
+		}
+		repository, _ := m["repository"].(map[string]interface{})
+		repoName, _ := repository["name"].(string)
+		file, _ := m["file"].(map[string]interface{})
+		path, _ := file["path"].(string)
+		names := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			symbol, _ := symbol.(map[string]interface{})
+			name, _ := symbol["name"].(string)
+			names = append(names, "`"+slackEscape(name)+"`")
+		}
+		return fmt.Sprintf("`%s` `%s` %s", slackEscape(repoName), slackEscape(path), strings.Join(names, ", ")), true
+	default:
+		return "", false
+	}
+}
+
+// slackEscape escapes the characters that have a special meaning in Slack
+// message formatting.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// describeDelivery returns the description of a delivery that is recorded in
+// the log contents of its action job.
+func describeDelivery(kind string, delivery webhook.Delivery, err error) string {
+	description := fmt.Sprintf("%s delivery: attempts=%d status=%d duration=%s", kind, delivery.Attempts, delivery.StatusCode, delivery.Duration)
+	if err != nil {
+		description += fmt.Sprintf(" error=%q", err.Error())
+	}
+	return description
+}
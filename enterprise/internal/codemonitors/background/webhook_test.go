@@ -0,0 +1,86 @@
+package background
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	cm "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/email"
+	"github.com/sourcegraph/sourcegraph/internal/webhook"
+)
+
+func TestActionPayloads(t *testing.T) {
+	email.MockExternalURL = func() *url.URL {
+		externalURL, _ := url.Parse("https://www.sourcegraph.com")
+		return externalURL
+	}
+	defer func() { email.MockExternalURL = nil }()
+
+	numResults := 2
+	commit := map[string]interface{}{
+		"__typename": "CommitSearchResult",
+		"commit": map[string]interface{}{
+			"repository":     map[string]interface{}{"name": "github.com/sourcegraph/sourcegraph"},
+			"abbreviatedOID": "abc1234",
+			"message":        "fix <bug>\n\nlong description",
+		},
+	}
+	m := &cm.ActionJobMetadata{
+		Description: "test description",
+		Query:       "test type:diff",
+		MonitorID:   1,
+		NumResults:  &numResults,
+		Results:     []interface{}{commit, map[string]interface{}{"__typename": "FileMatch"}},
+	}
+	monitorID := string(relay.MarshalID("CodeMonitor", 1))
+
+	t.Run("webhook", func(t *testing.T) {
+		got, err := newWebhookPayload(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &webhookPayload{
+			MonitorDescription: "test description",
+			MonitorURL:         "https://www.sourcegraph.com/code-monitoring/" + monitorID + "?utm_source=code-monitoring-webhook",
+			Query:              "test type:diff",
+			SearchURL:          "https://www.sourcegraph.com/search?q=test+type%3Adiff&utm_source=code-monitoring-webhook",
+			NumResults:         2,
+			Results:            m.Results,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("unexpected payload (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("slack", func(t *testing.T) {
+		got, err := newSlackPayload(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "Code monitor <https://www.sourcegraph.com/code-monitoring/" + monitorID + "?utm_source=code-monitoring-slack|test description> found 2 new search results. " +
+			"<https://www.sourcegraph.com/search?q=test+type%3Adiff&utm_source=code-monitoring-slack|View all results>\n" +
+			"• `github.com/sourcegraph/sourcegraph` `abc1234` fix &lt;bug&gt;"
+		if diff := cmp.Diff(want, got.Text); diff != "" {
+			t.Fatalf("unexpected text (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestDescribeDelivery(t *testing.T) {
+	delivery := webhook.Delivery{Attempts: 3, StatusCode: 502, Duration: 2 * time.Second}
+
+	if got, want := describeDelivery("webhook", delivery, nil), "webhook delivery: attempts=3 status=502 duration=2s"; got != want {
+		t.Errorf("unexpected description. want=%q have=%q", want, got)
+	}
+	if got, want := describeDelivery("slack", delivery, errors.New("bad gateway")), `slack delivery: attempts=3 status=502 duration=2s error="bad gateway"`; got != want {
+		t.Errorf("unexpected description. want=%q have=%q", want, got)
+	}
+}
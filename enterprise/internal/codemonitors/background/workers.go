@@ -13,6 +13,7 @@ import (
 	cm "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/email"
 	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/webhook"
 	"github.com/sourcegraph/sourcegraph/internal/workerutil"
 	"github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker"
 	dbworkerstore "github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker/store"
@@ -157,32 +158,52 @@ func (r *queryRunner) Handle(ctx context.Context, record workerutil.Record) (err
 		return err
 	}
 
-	newQuery := newQueryWithAfterFilter(q)
-
-	// Search.
-	var results *gqlSearchResponse
-	results, err = search(ctx, newQuery, m.NamespaceUserID)
-	if err != nil {
-		return err
-	}
-	var numResults int
-	if results != nil {
-		numResults = len(results.Data.Search.Results.Results)
+	var (
+		newQuery        string
+		newResults      []interface{}
+		newLatestResult time.Time
+	)
+	if cm.IsSymbolQuery(q.QueryString) {
+		// Symbol searches don't support the after: filter. Instead, we compare
+		// the matching symbols against those seen in the previous run.
+		newQuery = cm.SymbolTriggerQuery(q.QueryString)
+		results, err := search(ctx, newQuery, m.NamespaceUserID)
+		if err != nil {
+			return err
+		}
+		newResults, err = diffSymbolResults(ctx, s, q, results)
+		if err != nil {
+			return err
+		}
+		newLatestResult = s.Now()
+		if len(newResults) == 0 && q.LatestResult != nil {
+			newLatestResult = *q.LatestResult
+		}
+	} else {
+		newQuery = newQueryWithAfterFilter(q)
+		results, err := search(ctx, newQuery, m.NamespaceUserID)
+		if err != nil {
+			return err
+		}
+		if results != nil {
+			newResults = results.Data.Search.Results.Results
+		}
+		newLatestResult = latestResultTime(q.LatestResult, results, err)
 	}
-	if numResults > 0 {
-		err := s.EnqueueActionEmailsForQueryIDInt64(ctx, q.Id, record.RecordID())
+
+	if len(newResults) > 0 {
+		err := s.EnqueueActionJobsForQueryIDInt64(ctx, q.Id, record.RecordID())
 		if err != nil {
-			return errors.Errorf("store.EnqueueActionEmailsForQueryIDInt64: %w", err)
+			return errors.Errorf("store.EnqueueActionJobsForQueryIDInt64: %w", err)
 		}
 	}
 	// Log next_run and latest_result to table cm_queries.
-	newLatestResult := latestResultTime(q.LatestResult, results, err)
 	err = s.SetTriggerQueryNextRun(ctx, q.Id, s.Clock()().Add(5*time.Minute), newLatestResult.UTC())
 	if err != nil {
 		return err
 	}
 	// Log the actual query we ran and whether we got any new results.
-	err = s.LogSearch(ctx, newQuery, numResults, record.RecordID())
+	err = s.LogSearch(ctx, newQuery, newResults, record.RecordID())
 	if err != nil {
 		return errors.Errorf("LogSearch: %w", err)
 	}
@@ -247,10 +268,53 @@ func (r *actionRunner) Handle(ctx context.Context, record workerutil.Record) (er
 			}
 		}
 		return nil
+	case j.Webhook != nil:
+		w, err := s.WebhookActionByIDInt64(ctx, int64(*j.Webhook))
+		if err != nil {
+			return errors.Errorf("store.WebhookActionByIDInt64: %w", err)
+		}
+		if !w.Enabled {
+			return nil
+		}
+
+		payload, err := newWebhookPayload(ctx, m)
+		if err != nil {
+			return errors.Errorf("newWebhookPayload: %w", err)
+		}
+		delivery, err := webhook.New(w.URL, "").Post(ctx, webhookEventResults, payload)
+		return r.logDelivery(ctx, record.RecordID(), "webhook", delivery, err)
+	case j.SlackWebhook != nil:
+		w, err := s.SlackWebhookActionByIDInt64(ctx, int64(*j.SlackWebhook))
+		if err != nil {
+			return errors.Errorf("store.SlackWebhookActionByIDInt64: %w", err)
+		}
+		if !w.Enabled {
+			return nil
+		}
+
+		payload, err := newSlackPayload(ctx, m)
+		if err != nil {
+			return errors.Errorf("newSlackPayload: %w", err)
+		}
+		// Slack incoming webhooks accept the same JSON posts as generic
+		// webhooks, so we reuse the webhook client for its retries.
+		delivery, err := webhook.New(w.URL, "").Post(ctx, "", payload)
+		return r.logDelivery(ctx, record.RecordID(), "slack", delivery, err)
 	default:
-		// TODO(camdencheek): handle j.SlackWebhook != nil and j.Webhook != nil
-		return errors.New("cannot yet handle non-email jobs")
+		return errors.New("action job has no action")
+	}
+}
+
+// logDelivery records the outcome of a webhook or Slack delivery in the log
+// contents of the action job and returns the delivery error, if any.
+//
+// The log contents are written outside of the handler's transaction so that
+// they are kept even when the failed delivery rolls the transaction back.
+func (r *actionRunner) logDelivery(ctx context.Context, recordID int, kind string, delivery webhook.Delivery, deliveryErr error) error {
+	if err := r.CodeMonitorStore.SetActionJobLogContents(ctx, recordID, describeDelivery(kind, delivery, deliveryErr)); err != nil {
+		log15.Error("Failed to record code monitor action delivery.", "id", recordID, "error", err)
 	}
+	return deliveryErr
 }
 
 // newQueryWithAfterFilter constructs a new query which finds search results
@@ -87,11 +87,11 @@ func TestActionRunner(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = ts.LogSearch(ctx, testQuery, tt.numResults, triggerEvent)
+			err = ts.LogSearch(ctx, testQuery, make([]interface{}, tt.numResults), triggerEvent)
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = ts.EnqueueActionEmailsForQueryIDInt64(ctx, queryID, triggerEvent)
+			err = ts.EnqueueActionJobsForQueryIDInt64(ctx, queryID, triggerEvent)
 			if err != nil {
 				t.Fatal(err)
 			}
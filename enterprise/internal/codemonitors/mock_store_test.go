@@ -49,9 +49,15 @@ type MockCodeMonitorStore struct {
 	// CreateRecipientsFunc is an instance of a mock function object
 	// controlling the behavior of the method CreateRecipients.
 	CreateRecipientsFunc *CodeMonitorStoreCreateRecipientsFunc
+	// CreateSlackWebhookActionFunc is an instance of a mock function object
+	// controlling the behavior of the method CreateSlackWebhookAction.
+	CreateSlackWebhookActionFunc *CodeMonitorStoreCreateSlackWebhookActionFunc
 	// CreateTriggerQueryFunc is an instance of a mock function object
 	// controlling the behavior of the method CreateTriggerQuery.
 	CreateTriggerQueryFunc *CodeMonitorStoreCreateTriggerQueryFunc
+	// CreateWebhookActionFunc is an instance of a mock function object
+	// controlling the behavior of the method CreateWebhookAction.
+	CreateWebhookActionFunc *CodeMonitorStoreCreateWebhookActionFunc
 	// DeleteActionsInt64Func is an instance of a mock function object
 	// controlling the behavior of the method DeleteActionsInt64.
 	DeleteActionsInt64Func *CodeMonitorStoreDeleteActionsInt64Func
@@ -70,10 +76,10 @@ type MockCodeMonitorStore struct {
 	// DoneFunc is an instance of a mock function object controlling the
 	// behavior of the method Done.
 	DoneFunc *CodeMonitorStoreDoneFunc
-	// EnqueueActionEmailsForQueryIDInt64Func is an instance of a mock
+	// EnqueueActionJobsForQueryIDInt64Func is an instance of a mock
 	// function object controlling the behavior of the method
-	// EnqueueActionEmailsForQueryIDInt64.
-	EnqueueActionEmailsForQueryIDInt64Func *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func
+	// EnqueueActionJobsForQueryIDInt64.
+	EnqueueActionJobsForQueryIDInt64Func *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func
 	// EnqueueTriggerQueriesFunc is an instance of a mock function object
 	// controlling the behavior of the method EnqueueTriggerQueries.
 	EnqueueTriggerQueriesFunc *CodeMonitorStoreEnqueueTriggerQueriesFunc
@@ -118,9 +124,20 @@ type MockCodeMonitorStore struct {
 	// object controlling the behavior of the method
 	// ResetTriggerQueryTimestamps.
 	ResetTriggerQueryTimestampsFunc *CodeMonitorStoreResetTriggerQueryTimestampsFunc
+	// SetActionJobLogContentsFunc is an instance of a mock function object
+	// controlling the behavior of the method SetActionJobLogContents.
+	SetActionJobLogContentsFunc *CodeMonitorStoreSetActionJobLogContentsFunc
 	// SetTriggerQueryNextRunFunc is an instance of a mock function object
 	// controlling the behavior of the method SetTriggerQueryNextRun.
 	SetTriggerQueryNextRunFunc *CodeMonitorStoreSetTriggerQueryNextRunFunc
+	// SlackWebhookActionByIDInt64Func is an instance of a mock function
+	// object controlling the behavior of the method
+	// SlackWebhookActionByIDInt64.
+	SlackWebhookActionByIDInt64Func *CodeMonitorStoreSlackWebhookActionByIDInt64Func
+	// SymbolTriggerStatesForQueryIDInt64Func is an instance of a mock
+	// function object controlling the behavior of the method
+	// SymbolTriggerStatesForQueryIDInt64.
+	SymbolTriggerStatesForQueryIDInt64Func *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func
 	// ToggleMonitorFunc is an instance of a mock function object
 	// controlling the behavior of the method ToggleMonitor.
 	ToggleMonitorFunc *CodeMonitorStoreToggleMonitorFunc
@@ -153,6 +170,12 @@ type MockCodeMonitorStore struct {
 	// UpdateTriggerQueryFunc is an instance of a mock function object
 	// controlling the behavior of the method UpdateTriggerQuery.
 	UpdateTriggerQueryFunc *CodeMonitorStoreUpdateTriggerQueryFunc
+	// UpsertSymbolTriggerStateFunc is an instance of a mock function object
+	// controlling the behavior of the method UpsertSymbolTriggerState.
+	UpsertSymbolTriggerStateFunc *CodeMonitorStoreUpsertSymbolTriggerStateFunc
+	// WebhookActionByIDInt64Func is an instance of a mock function object
+	// controlling the behavior of the method WebhookActionByIDInt64.
+	WebhookActionByIDInt64Func *CodeMonitorStoreWebhookActionByIDInt64Func
 }
 
 // NewMockCodeMonitorStore creates a new mock of the CodeMonitorStore
@@ -210,11 +233,21 @@ func NewMockCodeMonitorStore() *MockCodeMonitorStore {
 				return nil
 			},
 		},
+		CreateSlackWebhookActionFunc: &CodeMonitorStoreCreateSlackWebhookActionFunc{
+			defaultHook: func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error) {
+				return nil, nil
+			},
+		},
 		CreateTriggerQueryFunc: &CodeMonitorStoreCreateTriggerQueryFunc{
 			defaultHook: func(context.Context, int64, *graphqlbackend.CreateTriggerArgs) error {
 				return nil
 			},
 		},
+		CreateWebhookActionFunc: &CodeMonitorStoreCreateWebhookActionFunc{
+			defaultHook: func(context.Context, int64, bool, string) (*MonitorWebhook, error) {
+				return nil, nil
+			},
+		},
 		DeleteActionsInt64Func: &CodeMonitorStoreDeleteActionsInt64Func{
 			defaultHook: func(context.Context, []int64, int64) error {
 				return nil
@@ -245,7 +278,7 @@ func NewMockCodeMonitorStore() *MockCodeMonitorStore {
 				return nil
 			},
 		},
-		EnqueueActionEmailsForQueryIDInt64Func: &CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func{
+		EnqueueActionJobsForQueryIDInt64Func: &CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func{
 			defaultHook: func(context.Context, int64, int) error {
 				return nil
 			},
@@ -291,7 +324,7 @@ func NewMockCodeMonitorStore() *MockCodeMonitorStore {
 			},
 		},
 		LogSearchFunc: &CodeMonitorStoreLogSearchFunc{
-			defaultHook: func(context.Context, string, int, int) error {
+			defaultHook: func(context.Context, string, []interface{}, int) error {
 				return nil
 			},
 		},
@@ -320,11 +353,26 @@ func NewMockCodeMonitorStore() *MockCodeMonitorStore {
 				return nil
 			},
 		},
+		SetActionJobLogContentsFunc: &CodeMonitorStoreSetActionJobLogContentsFunc{
+			defaultHook: func(context.Context, int, string) error {
+				return nil
+			},
+		},
 		SetTriggerQueryNextRunFunc: &CodeMonitorStoreSetTriggerQueryNextRunFunc{
 			defaultHook: func(context.Context, int64, time.Time, time.Time) error {
 				return nil
 			},
 		},
+		SlackWebhookActionByIDInt64Func: &CodeMonitorStoreSlackWebhookActionByIDInt64Func{
+			defaultHook: func(context.Context, int64) (*MonitorSlackWebhook, error) {
+				return nil, nil
+			},
+		},
+		SymbolTriggerStatesForQueryIDInt64Func: &CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func{
+			defaultHook: func(context.Context, int64) (map[int32]*SymbolTriggerState, error) {
+				return nil, nil
+			},
+		},
 		ToggleMonitorFunc: &CodeMonitorStoreToggleMonitorFunc{
 			defaultHook: func(context.Context, *graphqlbackend.ToggleCodeMonitorArgs) (*Monitor, error) {
 				return nil, nil
@@ -375,6 +423,16 @@ func NewMockCodeMonitorStore() *MockCodeMonitorStore {
 				return nil
 			},
 		},
+		UpsertSymbolTriggerStateFunc: &CodeMonitorStoreUpsertSymbolTriggerStateFunc{
+			defaultHook: func(context.Context, *SymbolTriggerState) error {
+				return nil
+			},
+		},
+		WebhookActionByIDInt64Func: &CodeMonitorStoreWebhookActionByIDInt64Func{
+			defaultHook: func(context.Context, int64) (*MonitorWebhook, error) {
+				return nil, nil
+			},
+		},
 	}
 }
 
@@ -413,9 +471,15 @@ func NewMockCodeMonitorStoreFrom(i CodeMonitorStore) *MockCodeMonitorStore {
 		CreateRecipientsFunc: &CodeMonitorStoreCreateRecipientsFunc{
 			defaultHook: i.CreateRecipients,
 		},
+		CreateSlackWebhookActionFunc: &CodeMonitorStoreCreateSlackWebhookActionFunc{
+			defaultHook: i.CreateSlackWebhookAction,
+		},
 		CreateTriggerQueryFunc: &CodeMonitorStoreCreateTriggerQueryFunc{
 			defaultHook: i.CreateTriggerQuery,
 		},
+		CreateWebhookActionFunc: &CodeMonitorStoreCreateWebhookActionFunc{
+			defaultHook: i.CreateWebhookAction,
+		},
 		DeleteActionsInt64Func: &CodeMonitorStoreDeleteActionsInt64Func{
 			defaultHook: i.DeleteActionsInt64,
 		},
@@ -434,8 +498,8 @@ func NewMockCodeMonitorStoreFrom(i CodeMonitorStore) *MockCodeMonitorStore {
 		DoneFunc: &CodeMonitorStoreDoneFunc{
 			defaultHook: i.Done,
 		},
-		EnqueueActionEmailsForQueryIDInt64Func: &CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func{
-			defaultHook: i.EnqueueActionEmailsForQueryIDInt64,
+		EnqueueActionJobsForQueryIDInt64Func: &CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func{
+			defaultHook: i.EnqueueActionJobsForQueryIDInt64,
 		},
 		EnqueueTriggerQueriesFunc: &CodeMonitorStoreEnqueueTriggerQueriesFunc{
 			defaultHook: i.EnqueueTriggerQueries,
@@ -479,9 +543,18 @@ func NewMockCodeMonitorStoreFrom(i CodeMonitorStore) *MockCodeMonitorStore {
 		ResetTriggerQueryTimestampsFunc: &CodeMonitorStoreResetTriggerQueryTimestampsFunc{
 			defaultHook: i.ResetTriggerQueryTimestamps,
 		},
+		SetActionJobLogContentsFunc: &CodeMonitorStoreSetActionJobLogContentsFunc{
+			defaultHook: i.SetActionJobLogContents,
+		},
 		SetTriggerQueryNextRunFunc: &CodeMonitorStoreSetTriggerQueryNextRunFunc{
 			defaultHook: i.SetTriggerQueryNextRun,
 		},
+		SlackWebhookActionByIDInt64Func: &CodeMonitorStoreSlackWebhookActionByIDInt64Func{
+			defaultHook: i.SlackWebhookActionByIDInt64,
+		},
+		SymbolTriggerStatesForQueryIDInt64Func: &CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func{
+			defaultHook: i.SymbolTriggerStatesForQueryIDInt64,
+		},
 		ToggleMonitorFunc: &CodeMonitorStoreToggleMonitorFunc{
 			defaultHook: i.ToggleMonitor,
 		},
@@ -512,6 +585,12 @@ func NewMockCodeMonitorStoreFrom(i CodeMonitorStore) *MockCodeMonitorStore {
 		UpdateTriggerQueryFunc: &CodeMonitorStoreUpdateTriggerQueryFunc{
 			defaultHook: i.UpdateTriggerQuery,
 		},
+		UpsertSymbolTriggerStateFunc: &CodeMonitorStoreUpsertSymbolTriggerStateFunc{
+			defaultHook: i.UpsertSymbolTriggerState,
+		},
+		WebhookActionByIDInt64Func: &CodeMonitorStoreWebhookActionByIDInt64Func{
+			defaultHook: i.WebhookActionByIDInt64,
+		},
 	}
 }
 
@@ -1626,6 +1705,125 @@ func (c CodeMonitorStoreCreateRecipientsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// CodeMonitorStoreCreateSlackWebhookActionFunc describes the behavior when
+// the CreateSlackWebhookAction method of the parent MockCodeMonitorStore
+// instance is invoked.
+type CodeMonitorStoreCreateSlackWebhookActionFunc struct {
+	defaultHook func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error)
+	hooks       []func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error)
+	history     []CodeMonitorStoreCreateSlackWebhookActionFuncCall
+	mutex       sync.Mutex
+}
+
+// CreateSlackWebhookAction delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockCodeMonitorStore) CreateSlackWebhookAction(v0 context.Context, v1 int64, v2 bool, v3 string) (*MonitorSlackWebhook, error) {
+	r0, r1 := m.CreateSlackWebhookActionFunc.nextHook()(v0, v1, v2, v3)
+	m.CreateSlackWebhookActionFunc.appendCall(CodeMonitorStoreCreateSlackWebhookActionFuncCall{v0, v1, v2, v3, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// CreateSlackWebhookAction method of the parent MockCodeMonitorStore
+// instance is invoked and the hook queue is empty.
+func (f *CodeMonitorStoreCreateSlackWebhookActionFunc) SetDefaultHook(hook func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// CreateSlackWebhookAction method of the parent MockCodeMonitorStore
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *CodeMonitorStoreCreateSlackWebhookActionFunc) PushHook(hook func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *CodeMonitorStoreCreateSlackWebhookActionFunc) SetDefaultReturn(r0 *MonitorSlackWebhook, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *CodeMonitorStoreCreateSlackWebhookActionFunc) PushReturn(r0 *MonitorSlackWebhook, r1 error) {
+	f.PushHook(func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error) {
+		return r0, r1
+	})
+}
+
+func (f *CodeMonitorStoreCreateSlackWebhookActionFunc) nextHook() func(context.Context, int64, bool, string) (*MonitorSlackWebhook, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *CodeMonitorStoreCreateSlackWebhookActionFunc) appendCall(r0 CodeMonitorStoreCreateSlackWebhookActionFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// CodeMonitorStoreCreateSlackWebhookActionFuncCall objects describing the
+// invocations of this function.
+func (f *CodeMonitorStoreCreateSlackWebhookActionFunc) History() []CodeMonitorStoreCreateSlackWebhookActionFuncCall {
+	f.mutex.Lock()
+	history := make([]CodeMonitorStoreCreateSlackWebhookActionFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// CodeMonitorStoreCreateSlackWebhookActionFuncCall is an object that
+// describes an invocation of method CreateSlackWebhookAction on an instance
+// of MockCodeMonitorStore.
+type CodeMonitorStoreCreateSlackWebhookActionFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 bool
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *MonitorSlackWebhook
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c CodeMonitorStoreCreateSlackWebhookActionFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c CodeMonitorStoreCreateSlackWebhookActionFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // CodeMonitorStoreCreateTriggerQueryFunc describes the behavior when the
 // CreateTriggerQuery method of the parent MockCodeMonitorStore instance is
 // invoked.
@@ -1738,6 +1936,124 @@ func (c CodeMonitorStoreCreateTriggerQueryFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// CodeMonitorStoreCreateWebhookActionFunc describes the behavior when the
+// CreateWebhookAction method of the parent MockCodeMonitorStore instance is
+// invoked.
+type CodeMonitorStoreCreateWebhookActionFunc struct {
+	defaultHook func(context.Context, int64, bool, string) (*MonitorWebhook, error)
+	hooks       []func(context.Context, int64, bool, string) (*MonitorWebhook, error)
+	history     []CodeMonitorStoreCreateWebhookActionFuncCall
+	mutex       sync.Mutex
+}
+
+// CreateWebhookAction delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockCodeMonitorStore) CreateWebhookAction(v0 context.Context, v1 int64, v2 bool, v3 string) (*MonitorWebhook, error) {
+	r0, r1 := m.CreateWebhookActionFunc.nextHook()(v0, v1, v2, v3)
+	m.CreateWebhookActionFunc.appendCall(CodeMonitorStoreCreateWebhookActionFuncCall{v0, v1, v2, v3, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the CreateWebhookAction
+// method of the parent MockCodeMonitorStore instance is invoked and the
+// hook queue is empty.
+func (f *CodeMonitorStoreCreateWebhookActionFunc) SetDefaultHook(hook func(context.Context, int64, bool, string) (*MonitorWebhook, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// CreateWebhookAction method of the parent MockCodeMonitorStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *CodeMonitorStoreCreateWebhookActionFunc) PushHook(hook func(context.Context, int64, bool, string) (*MonitorWebhook, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *CodeMonitorStoreCreateWebhookActionFunc) SetDefaultReturn(r0 *MonitorWebhook, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64, bool, string) (*MonitorWebhook, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *CodeMonitorStoreCreateWebhookActionFunc) PushReturn(r0 *MonitorWebhook, r1 error) {
+	f.PushHook(func(context.Context, int64, bool, string) (*MonitorWebhook, error) {
+		return r0, r1
+	})
+}
+
+func (f *CodeMonitorStoreCreateWebhookActionFunc) nextHook() func(context.Context, int64, bool, string) (*MonitorWebhook, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *CodeMonitorStoreCreateWebhookActionFunc) appendCall(r0 CodeMonitorStoreCreateWebhookActionFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of CodeMonitorStoreCreateWebhookActionFuncCall
+// objects describing the invocations of this function.
+func (f *CodeMonitorStoreCreateWebhookActionFunc) History() []CodeMonitorStoreCreateWebhookActionFuncCall {
+	f.mutex.Lock()
+	history := make([]CodeMonitorStoreCreateWebhookActionFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// CodeMonitorStoreCreateWebhookActionFuncCall is an object that describes
+// an invocation of method CreateWebhookAction on an instance of
+// MockCodeMonitorStore.
+type CodeMonitorStoreCreateWebhookActionFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 bool
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *MonitorWebhook
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c CodeMonitorStoreCreateWebhookActionFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c CodeMonitorStoreCreateWebhookActionFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // CodeMonitorStoreDeleteActionsInt64Func describes the behavior when the
 // DeleteActionsInt64 method of the parent MockCodeMonitorStore instance is
 // invoked.
@@ -2386,37 +2702,37 @@ func (c CodeMonitorStoreDoneFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
-// CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func describes the
-// behavior when the EnqueueActionEmailsForQueryIDInt64 method of the parent
+// CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func describes the
+// behavior when the EnqueueActionJobsForQueryIDInt64 method of the parent
 // MockCodeMonitorStore instance is invoked.
-type CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func struct {
+type CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func struct {
 	defaultHook func(context.Context, int64, int) error
 	hooks       []func(context.Context, int64, int) error
-	history     []CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall
+	history     []CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall
 	mutex       sync.Mutex
 }
 
-// EnqueueActionEmailsForQueryIDInt64 delegates to the next hook function in
+// EnqueueActionJobsForQueryIDInt64 delegates to the next hook function in
 // the queue and stores the parameter and result values of this invocation.
-func (m *MockCodeMonitorStore) EnqueueActionEmailsForQueryIDInt64(v0 context.Context, v1 int64, v2 int) error {
-	r0 := m.EnqueueActionEmailsForQueryIDInt64Func.nextHook()(v0, v1, v2)
-	m.EnqueueActionEmailsForQueryIDInt64Func.appendCall(CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall{v0, v1, v2, r0})
+func (m *MockCodeMonitorStore) EnqueueActionJobsForQueryIDInt64(v0 context.Context, v1 int64, v2 int) error {
+	r0 := m.EnqueueActionJobsForQueryIDInt64Func.nextHook()(v0, v1, v2)
+	m.EnqueueActionJobsForQueryIDInt64Func.appendCall(CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall{v0, v1, v2, r0})
 	return r0
 }
 
 // SetDefaultHook sets function that is called when the
-// EnqueueActionEmailsForQueryIDInt64 method of the parent
+// EnqueueActionJobsForQueryIDInt64 method of the parent
 // MockCodeMonitorStore instance is invoked and the hook queue is empty.
-func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) SetDefaultHook(hook func(context.Context, int64, int) error) {
+func (f *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func) SetDefaultHook(hook func(context.Context, int64, int) error) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// EnqueueActionEmailsForQueryIDInt64 method of the parent
+// EnqueueActionJobsForQueryIDInt64 method of the parent
 // MockCodeMonitorStore instance invokes the hook at the front of the queue
 // and discards it. After the queue is empty, the default hook function is
 // invoked for any future action.
-func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) PushHook(hook func(context.Context, int64, int) error) {
+func (f *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func) PushHook(hook func(context.Context, int64, int) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -2424,7 +2740,7 @@ func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) PushHook(hook f
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) SetDefaultReturn(r0 error) {
+func (f *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func) SetDefaultReturn(r0 error) {
 	f.SetDefaultHook(func(context.Context, int64, int) error {
 		return r0
 	})
@@ -2432,13 +2748,13 @@ func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) SetDefaultRetur
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) PushReturn(r0 error) {
+func (f *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func) PushReturn(r0 error) {
 	f.PushHook(func(context.Context, int64, int) error {
 		return r0
 	})
 }
 
-func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) nextHook() func(context.Context, int64, int) error {
+func (f *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func) nextHook() func(context.Context, int64, int) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -2451,28 +2767,28 @@ func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) nextHook() func
 	return hook
 }
 
-func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) appendCall(r0 CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall) {
+func (f *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func) appendCall(r0 CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
 // History returns a sequence of
-// CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall objects
+// CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall objects
 // describing the invocations of this function.
-func (f *CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64Func) History() []CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall {
+func (f *CodeMonitorStoreEnqueueActionJobsForQueryIDInt64Func) History() []CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall {
 	f.mutex.Lock()
-	history := make([]CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall, len(f.history))
+	history := make([]CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall is an object
-// that describes an invocation of method EnqueueActionEmailsForQueryIDInt64
+// CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall is an object
+// that describes an invocation of method EnqueueActionJobsForQueryIDInt64
 // on an instance of MockCodeMonitorStore.
-type CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall struct {
+type CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
@@ -2489,13 +2805,13 @@ type CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall struct {
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall) Args() []interface{} {
+func (c CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall) Args() []interface{} {
 	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c CodeMonitorStoreEnqueueActionEmailsForQueryIDInt64FuncCall) Results() []interface{} {
+func (c CodeMonitorStoreEnqueueActionJobsForQueryIDInt64FuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
@@ -3379,15 +3695,15 @@ func (c CodeMonitorStoreListEmailActionsFuncCall) Results() []interface{} {
 // CodeMonitorStoreLogSearchFunc describes the behavior when the LogSearch
 // method of the parent MockCodeMonitorStore instance is invoked.
 type CodeMonitorStoreLogSearchFunc struct {
-	defaultHook func(context.Context, string, int, int) error
-	hooks       []func(context.Context, string, int, int) error
+	defaultHook func(context.Context, string, []interface{}, int) error
+	hooks       []func(context.Context, string, []interface{}, int) error
 	history     []CodeMonitorStoreLogSearchFuncCall
 	mutex       sync.Mutex
 }
 
 // LogSearch delegates to the next hook function in the queue and stores the
 // parameter and result values of this invocation.
-func (m *MockCodeMonitorStore) LogSearch(v0 context.Context, v1 string, v2 int, v3 int) error {
+func (m *MockCodeMonitorStore) LogSearch(v0 context.Context, v1 string, v2 []interface{}, v3 int) error {
 	r0 := m.LogSearchFunc.nextHook()(v0, v1, v2, v3)
 	m.LogSearchFunc.appendCall(CodeMonitorStoreLogSearchFuncCall{v0, v1, v2, v3, r0})
 	return r0
@@ -3396,7 +3712,7 @@ func (m *MockCodeMonitorStore) LogSearch(v0 context.Context, v1 string, v2 int,
 // SetDefaultHook sets function that is called when the LogSearch method of
 // the parent MockCodeMonitorStore instance is invoked and the hook queue is
 // empty.
-func (f *CodeMonitorStoreLogSearchFunc) SetDefaultHook(hook func(context.Context, string, int, int) error) {
+func (f *CodeMonitorStoreLogSearchFunc) SetDefaultHook(hook func(context.Context, string, []interface{}, int) error) {
 	f.defaultHook = hook
 }
 
@@ -3404,7 +3720,7 @@ func (f *CodeMonitorStoreLogSearchFunc) SetDefaultHook(hook func(context.Context
 // LogSearch method of the parent MockCodeMonitorStore instance invokes the
 // hook at the front of the queue and discards it. After the queue is empty,
 // the default hook function is invoked for any future action.
-func (f *CodeMonitorStoreLogSearchFunc) PushHook(hook func(context.Context, string, int, int) error) {
+func (f *CodeMonitorStoreLogSearchFunc) PushHook(hook func(context.Context, string, []interface{}, int) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -3413,7 +3729,7 @@ func (f *CodeMonitorStoreLogSearchFunc) PushHook(hook func(context.Context, stri
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
 func (f *CodeMonitorStoreLogSearchFunc) SetDefaultReturn(r0 error) {
-	f.SetDefaultHook(func(context.Context, string, int, int) error {
+	f.SetDefaultHook(func(context.Context, string, []interface{}, int) error {
 		return r0
 	})
 }
@@ -3421,12 +3737,12 @@ func (f *CodeMonitorStoreLogSearchFunc) SetDefaultReturn(r0 error) {
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
 func (f *CodeMonitorStoreLogSearchFunc) PushReturn(r0 error) {
-	f.PushHook(func(context.Context, string, int, int) error {
+	f.PushHook(func(context.Context, string, []interface{}, int) error {
 		return r0
 	})
 }
 
-func (f *CodeMonitorStoreLogSearchFunc) nextHook() func(context.Context, string, int, int) error {
+func (f *CodeMonitorStoreLogSearchFunc) nextHook() func(context.Context, string, []interface{}, int) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -3467,7 +3783,7 @@ type CodeMonitorStoreLogSearchFuncCall struct {
 	Arg1 string
 	// Arg2 is the value of the 3rd argument passed to this method
 	// invocation.
-	Arg2 int
+	Arg2 []interface{}
 	// Arg3 is the value of the 4th argument passed to this method
 	// invocation.
 	Arg3 int
@@ -4038,37 +4354,37 @@ func (c CodeMonitorStoreResetTriggerQueryTimestampsFuncCall) Results() []interfa
 	return []interface{}{c.Result0}
 }
 
-// CodeMonitorStoreSetTriggerQueryNextRunFunc describes the behavior when
-// the SetTriggerQueryNextRun method of the parent MockCodeMonitorStore
+// CodeMonitorStoreSetActionJobLogContentsFunc describes the behavior when
+// the SetActionJobLogContents method of the parent MockCodeMonitorStore
 // instance is invoked.
-type CodeMonitorStoreSetTriggerQueryNextRunFunc struct {
-	defaultHook func(context.Context, int64, time.Time, time.Time) error
-	hooks       []func(context.Context, int64, time.Time, time.Time) error
-	history     []CodeMonitorStoreSetTriggerQueryNextRunFuncCall
+type CodeMonitorStoreSetActionJobLogContentsFunc struct {
+	defaultHook func(context.Context, int, string) error
+	hooks       []func(context.Context, int, string) error
+	history     []CodeMonitorStoreSetActionJobLogContentsFuncCall
 	mutex       sync.Mutex
 }
 
-// SetTriggerQueryNextRun delegates to the next hook function in the queue
+// SetActionJobLogContents delegates to the next hook function in the queue
 // and stores the parameter and result values of this invocation.
-func (m *MockCodeMonitorStore) SetTriggerQueryNextRun(v0 context.Context, v1 int64, v2 time.Time, v3 time.Time) error {
-	r0 := m.SetTriggerQueryNextRunFunc.nextHook()(v0, v1, v2, v3)
-	m.SetTriggerQueryNextRunFunc.appendCall(CodeMonitorStoreSetTriggerQueryNextRunFuncCall{v0, v1, v2, v3, r0})
+func (m *MockCodeMonitorStore) SetActionJobLogContents(v0 context.Context, v1 int, v2 string) error {
+	r0 := m.SetActionJobLogContentsFunc.nextHook()(v0, v1, v2)
+	m.SetActionJobLogContentsFunc.appendCall(CodeMonitorStoreSetActionJobLogContentsFuncCall{v0, v1, v2, r0})
 	return r0
 }
 
 // SetDefaultHook sets function that is called when the
-// SetTriggerQueryNextRun method of the parent MockCodeMonitorStore instance
-// is invoked and the hook queue is empty.
-func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) SetDefaultHook(hook func(context.Context, int64, time.Time, time.Time) error) {
+// SetActionJobLogContents method of the parent MockCodeMonitorStore
+// instance is invoked and the hook queue is empty.
+func (f *CodeMonitorStoreSetActionJobLogContentsFunc) SetDefaultHook(hook func(context.Context, int, string) error) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// SetTriggerQueryNextRun method of the parent MockCodeMonitorStore instance
-// invokes the hook at the front of the queue and discards it. After the
-// queue is empty, the default hook function is invoked for any future
-// action.
-func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) PushHook(hook func(context.Context, int64, time.Time, time.Time) error) {
+// SetActionJobLogContents method of the parent MockCodeMonitorStore
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *CodeMonitorStoreSetActionJobLogContentsFunc) PushHook(hook func(context.Context, int, string) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -4076,21 +4392,21 @@ func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) PushHook(hook func(context.
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) SetDefaultReturn(r0 error) {
-	f.SetDefaultHook(func(context.Context, int64, time.Time, time.Time) error {
+func (f *CodeMonitorStoreSetActionJobLogContentsFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, string) error {
 		return r0
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) PushReturn(r0 error) {
-	f.PushHook(func(context.Context, int64, time.Time, time.Time) error {
+func (f *CodeMonitorStoreSetActionJobLogContentsFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, string) error {
 		return r0
 	})
 }
 
-func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) nextHook() func(context.Context, int64, time.Time, time.Time) error {
+func (f *CodeMonitorStoreSetActionJobLogContentsFunc) nextHook() func(context.Context, int, string) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -4103,40 +4419,37 @@ func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) nextHook() func(context.Con
 	return hook
 }
 
-func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) appendCall(r0 CodeMonitorStoreSetTriggerQueryNextRunFuncCall) {
+func (f *CodeMonitorStoreSetActionJobLogContentsFunc) appendCall(r0 CodeMonitorStoreSetActionJobLogContentsFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
 // History returns a sequence of
-// CodeMonitorStoreSetTriggerQueryNextRunFuncCall objects describing the
+// CodeMonitorStoreSetActionJobLogContentsFuncCall objects describing the
 // invocations of this function.
-func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) History() []CodeMonitorStoreSetTriggerQueryNextRunFuncCall {
+func (f *CodeMonitorStoreSetActionJobLogContentsFunc) History() []CodeMonitorStoreSetActionJobLogContentsFuncCall {
 	f.mutex.Lock()
-	history := make([]CodeMonitorStoreSetTriggerQueryNextRunFuncCall, len(f.history))
+	history := make([]CodeMonitorStoreSetActionJobLogContentsFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// CodeMonitorStoreSetTriggerQueryNextRunFuncCall is an object that
-// describes an invocation of method SetTriggerQueryNextRun on an instance
+// CodeMonitorStoreSetActionJobLogContentsFuncCall is an object that
+// describes an invocation of method SetActionJobLogContents on an instance
 // of MockCodeMonitorStore.
-type CodeMonitorStoreSetTriggerQueryNextRunFuncCall struct {
+type CodeMonitorStoreSetActionJobLogContentsFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
-	Arg1 int64
+	Arg1 int
 	// Arg2 is the value of the 3rd argument passed to this method
 	// invocation.
-	Arg2 time.Time
-	// Arg3 is the value of the 4th argument passed to this method
-	// invocation.
-	Arg3 time.Time
+	Arg2 string
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
 	Result0 error
@@ -4144,22 +4457,364 @@ type CodeMonitorStoreSetTriggerQueryNextRunFuncCall struct {
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c CodeMonitorStoreSetTriggerQueryNextRunFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+func (c CodeMonitorStoreSetActionJobLogContentsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c CodeMonitorStoreSetTriggerQueryNextRunFuncCall) Results() []interface{} {
+func (c CodeMonitorStoreSetActionJobLogContentsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
-// CodeMonitorStoreToggleMonitorFunc describes the behavior when the
-// ToggleMonitor method of the parent MockCodeMonitorStore instance is
-// invoked.
-type CodeMonitorStoreToggleMonitorFunc struct {
-	defaultHook func(context.Context, *graphqlbackend.ToggleCodeMonitorArgs) (*Monitor, error)
-	hooks       []func(context.Context, *graphqlbackend.ToggleCodeMonitorArgs) (*Monitor, error)
+// CodeMonitorStoreSetTriggerQueryNextRunFunc describes the behavior when
+// the SetTriggerQueryNextRun method of the parent MockCodeMonitorStore
+// instance is invoked.
+type CodeMonitorStoreSetTriggerQueryNextRunFunc struct {
+	defaultHook func(context.Context, int64, time.Time, time.Time) error
+	hooks       []func(context.Context, int64, time.Time, time.Time) error
+	history     []CodeMonitorStoreSetTriggerQueryNextRunFuncCall
+	mutex       sync.Mutex
+}
+
+// SetTriggerQueryNextRun delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockCodeMonitorStore) SetTriggerQueryNextRun(v0 context.Context, v1 int64, v2 time.Time, v3 time.Time) error {
+	r0 := m.SetTriggerQueryNextRunFunc.nextHook()(v0, v1, v2, v3)
+	m.SetTriggerQueryNextRunFunc.appendCall(CodeMonitorStoreSetTriggerQueryNextRunFuncCall{v0, v1, v2, v3, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the
+// SetTriggerQueryNextRun method of the parent MockCodeMonitorStore instance
+// is invoked and the hook queue is empty.
+func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) SetDefaultHook(hook func(context.Context, int64, time.Time, time.Time) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SetTriggerQueryNextRun method of the parent MockCodeMonitorStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) PushHook(hook func(context.Context, int64, time.Time, time.Time) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int64, time.Time, time.Time) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int64, time.Time, time.Time) error {
+		return r0
+	})
+}
+
+func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) nextHook() func(context.Context, int64, time.Time, time.Time) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) appendCall(r0 CodeMonitorStoreSetTriggerQueryNextRunFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// CodeMonitorStoreSetTriggerQueryNextRunFuncCall objects describing the
+// invocations of this function.
+func (f *CodeMonitorStoreSetTriggerQueryNextRunFunc) History() []CodeMonitorStoreSetTriggerQueryNextRunFuncCall {
+	f.mutex.Lock()
+	history := make([]CodeMonitorStoreSetTriggerQueryNextRunFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// CodeMonitorStoreSetTriggerQueryNextRunFuncCall is an object that
+// describes an invocation of method SetTriggerQueryNextRun on an instance
+// of MockCodeMonitorStore.
+type CodeMonitorStoreSetTriggerQueryNextRunFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 time.Time
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 time.Time
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c CodeMonitorStoreSetTriggerQueryNextRunFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c CodeMonitorStoreSetTriggerQueryNextRunFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// CodeMonitorStoreSlackWebhookActionByIDInt64Func describes the behavior
+// when the SlackWebhookActionByIDInt64 method of the parent
+// MockCodeMonitorStore instance is invoked.
+type CodeMonitorStoreSlackWebhookActionByIDInt64Func struct {
+	defaultHook func(context.Context, int64) (*MonitorSlackWebhook, error)
+	hooks       []func(context.Context, int64) (*MonitorSlackWebhook, error)
+	history     []CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall
+	mutex       sync.Mutex
+}
+
+// SlackWebhookActionByIDInt64 delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockCodeMonitorStore) SlackWebhookActionByIDInt64(v0 context.Context, v1 int64) (*MonitorSlackWebhook, error) {
+	r0, r1 := m.SlackWebhookActionByIDInt64Func.nextHook()(v0, v1)
+	m.SlackWebhookActionByIDInt64Func.appendCall(CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// SlackWebhookActionByIDInt64 method of the parent MockCodeMonitorStore
+// instance is invoked and the hook queue is empty.
+func (f *CodeMonitorStoreSlackWebhookActionByIDInt64Func) SetDefaultHook(hook func(context.Context, int64) (*MonitorSlackWebhook, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SlackWebhookActionByIDInt64 method of the parent MockCodeMonitorStore
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *CodeMonitorStoreSlackWebhookActionByIDInt64Func) PushHook(hook func(context.Context, int64) (*MonitorSlackWebhook, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *CodeMonitorStoreSlackWebhookActionByIDInt64Func) SetDefaultReturn(r0 *MonitorSlackWebhook, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64) (*MonitorSlackWebhook, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *CodeMonitorStoreSlackWebhookActionByIDInt64Func) PushReturn(r0 *MonitorSlackWebhook, r1 error) {
+	f.PushHook(func(context.Context, int64) (*MonitorSlackWebhook, error) {
+		return r0, r1
+	})
+}
+
+func (f *CodeMonitorStoreSlackWebhookActionByIDInt64Func) nextHook() func(context.Context, int64) (*MonitorSlackWebhook, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *CodeMonitorStoreSlackWebhookActionByIDInt64Func) appendCall(r0 CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall objects describing
+// the invocations of this function.
+func (f *CodeMonitorStoreSlackWebhookActionByIDInt64Func) History() []CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall {
+	f.mutex.Lock()
+	history := make([]CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall is an object that
+// describes an invocation of method SlackWebhookActionByIDInt64 on an
+// instance of MockCodeMonitorStore.
+type CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *MonitorSlackWebhook
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c CodeMonitorStoreSlackWebhookActionByIDInt64FuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func describes the
+// behavior when the SymbolTriggerStatesForQueryIDInt64 method of the parent
+// MockCodeMonitorStore instance is invoked.
+type CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func struct {
+	defaultHook func(context.Context, int64) (map[int32]*SymbolTriggerState, error)
+	hooks       []func(context.Context, int64) (map[int32]*SymbolTriggerState, error)
+	history     []CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall
+	mutex       sync.Mutex
+}
+
+// SymbolTriggerStatesForQueryIDInt64 delegates to the next hook function in
+// the queue and stores the parameter and result values of this invocation.
+func (m *MockCodeMonitorStore) SymbolTriggerStatesForQueryIDInt64(v0 context.Context, v1 int64) (map[int32]*SymbolTriggerState, error) {
+	r0, r1 := m.SymbolTriggerStatesForQueryIDInt64Func.nextHook()(v0, v1)
+	m.SymbolTriggerStatesForQueryIDInt64Func.appendCall(CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// SymbolTriggerStatesForQueryIDInt64 method of the parent
+// MockCodeMonitorStore instance is invoked and the hook queue is empty.
+func (f *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func) SetDefaultHook(hook func(context.Context, int64) (map[int32]*SymbolTriggerState, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SymbolTriggerStatesForQueryIDInt64 method of the parent
+// MockCodeMonitorStore instance invokes the hook at the front of the queue
+// and discards it. After the queue is empty, the default hook function is
+// invoked for any future action.
+func (f *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func) PushHook(hook func(context.Context, int64) (map[int32]*SymbolTriggerState, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func) SetDefaultReturn(r0 map[int32]*SymbolTriggerState, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64) (map[int32]*SymbolTriggerState, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func) PushReturn(r0 map[int32]*SymbolTriggerState, r1 error) {
+	f.PushHook(func(context.Context, int64) (map[int32]*SymbolTriggerState, error) {
+		return r0, r1
+	})
+}
+
+func (f *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func) nextHook() func(context.Context, int64) (map[int32]*SymbolTriggerState, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func) appendCall(r0 CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall objects
+// describing the invocations of this function.
+func (f *CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64Func) History() []CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall {
+	f.mutex.Lock()
+	history := make([]CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall is an object
+// that describes an invocation of method SymbolTriggerStatesForQueryIDInt64
+// on an instance of MockCodeMonitorStore.
+type CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 map[int32]*SymbolTriggerState
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c CodeMonitorStoreSymbolTriggerStatesForQueryIDInt64FuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// CodeMonitorStoreToggleMonitorFunc describes the behavior when the
+// ToggleMonitor method of the parent MockCodeMonitorStore instance is
+// invoked.
+type CodeMonitorStoreToggleMonitorFunc struct {
+	defaultHook func(context.Context, *graphqlbackend.ToggleCodeMonitorArgs) (*Monitor, error)
+	hooks       []func(context.Context, *graphqlbackend.ToggleCodeMonitorArgs) (*Monitor, error)
 	history     []CodeMonitorStoreToggleMonitorFuncCall
 	mutex       sync.Mutex
 }
@@ -5269,3 +5924,226 @@ func (c CodeMonitorStoreUpdateTriggerQueryFuncCall) Args() []interface{} {
 func (c CodeMonitorStoreUpdateTriggerQueryFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
+
+// CodeMonitorStoreUpsertSymbolTriggerStateFunc describes the behavior when
+// the UpsertSymbolTriggerState method of the parent MockCodeMonitorStore
+// instance is invoked.
+type CodeMonitorStoreUpsertSymbolTriggerStateFunc struct {
+	defaultHook func(context.Context, *SymbolTriggerState) error
+	hooks       []func(context.Context, *SymbolTriggerState) error
+	history     []CodeMonitorStoreUpsertSymbolTriggerStateFuncCall
+	mutex       sync.Mutex
+}
+
+// UpsertSymbolTriggerState delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockCodeMonitorStore) UpsertSymbolTriggerState(v0 context.Context, v1 *SymbolTriggerState) error {
+	r0 := m.UpsertSymbolTriggerStateFunc.nextHook()(v0, v1)
+	m.UpsertSymbolTriggerStateFunc.appendCall(CodeMonitorStoreUpsertSymbolTriggerStateFuncCall{v0, v1, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the
+// UpsertSymbolTriggerState method of the parent MockCodeMonitorStore
+// instance is invoked and the hook queue is empty.
+func (f *CodeMonitorStoreUpsertSymbolTriggerStateFunc) SetDefaultHook(hook func(context.Context, *SymbolTriggerState) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// UpsertSymbolTriggerState method of the parent MockCodeMonitorStore
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *CodeMonitorStoreUpsertSymbolTriggerStateFunc) PushHook(hook func(context.Context, *SymbolTriggerState) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *CodeMonitorStoreUpsertSymbolTriggerStateFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, *SymbolTriggerState) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *CodeMonitorStoreUpsertSymbolTriggerStateFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, *SymbolTriggerState) error {
+		return r0
+	})
+}
+
+func (f *CodeMonitorStoreUpsertSymbolTriggerStateFunc) nextHook() func(context.Context, *SymbolTriggerState) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *CodeMonitorStoreUpsertSymbolTriggerStateFunc) appendCall(r0 CodeMonitorStoreUpsertSymbolTriggerStateFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// CodeMonitorStoreUpsertSymbolTriggerStateFuncCall objects describing the
+// invocations of this function.
+func (f *CodeMonitorStoreUpsertSymbolTriggerStateFunc) History() []CodeMonitorStoreUpsertSymbolTriggerStateFuncCall {
+	f.mutex.Lock()
+	history := make([]CodeMonitorStoreUpsertSymbolTriggerStateFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// CodeMonitorStoreUpsertSymbolTriggerStateFuncCall is an object that
+// describes an invocation of method UpsertSymbolTriggerState on an instance
+// of MockCodeMonitorStore.
+type CodeMonitorStoreUpsertSymbolTriggerStateFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 *SymbolTriggerState
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c CodeMonitorStoreUpsertSymbolTriggerStateFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c CodeMonitorStoreUpsertSymbolTriggerStateFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// CodeMonitorStoreWebhookActionByIDInt64Func describes the behavior when
+// the WebhookActionByIDInt64 method of the parent MockCodeMonitorStore
+// instance is invoked.
+type CodeMonitorStoreWebhookActionByIDInt64Func struct {
+	defaultHook func(context.Context, int64) (*MonitorWebhook, error)
+	hooks       []func(context.Context, int64) (*MonitorWebhook, error)
+	history     []CodeMonitorStoreWebhookActionByIDInt64FuncCall
+	mutex       sync.Mutex
+}
+
+// WebhookActionByIDInt64 delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockCodeMonitorStore) WebhookActionByIDInt64(v0 context.Context, v1 int64) (*MonitorWebhook, error) {
+	r0, r1 := m.WebhookActionByIDInt64Func.nextHook()(v0, v1)
+	m.WebhookActionByIDInt64Func.appendCall(CodeMonitorStoreWebhookActionByIDInt64FuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// WebhookActionByIDInt64 method of the parent MockCodeMonitorStore instance
+// is invoked and the hook queue is empty.
+func (f *CodeMonitorStoreWebhookActionByIDInt64Func) SetDefaultHook(hook func(context.Context, int64) (*MonitorWebhook, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// WebhookActionByIDInt64 method of the parent MockCodeMonitorStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *CodeMonitorStoreWebhookActionByIDInt64Func) PushHook(hook func(context.Context, int64) (*MonitorWebhook, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *CodeMonitorStoreWebhookActionByIDInt64Func) SetDefaultReturn(r0 *MonitorWebhook, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64) (*MonitorWebhook, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *CodeMonitorStoreWebhookActionByIDInt64Func) PushReturn(r0 *MonitorWebhook, r1 error) {
+	f.PushHook(func(context.Context, int64) (*MonitorWebhook, error) {
+		return r0, r1
+	})
+}
+
+func (f *CodeMonitorStoreWebhookActionByIDInt64Func) nextHook() func(context.Context, int64) (*MonitorWebhook, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *CodeMonitorStoreWebhookActionByIDInt64Func) appendCall(r0 CodeMonitorStoreWebhookActionByIDInt64FuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// CodeMonitorStoreWebhookActionByIDInt64FuncCall objects describing the
+// invocations of this function.
+func (f *CodeMonitorStoreWebhookActionByIDInt64Func) History() []CodeMonitorStoreWebhookActionByIDInt64FuncCall {
+	f.mutex.Lock()
+	history := make([]CodeMonitorStoreWebhookActionByIDInt64FuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// CodeMonitorStoreWebhookActionByIDInt64FuncCall is an object that
+// describes an invocation of method WebhookActionByIDInt64 on an instance
+// of MockCodeMonitorStore.
+type CodeMonitorStoreWebhookActionByIDInt64FuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *MonitorWebhook
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c CodeMonitorStoreWebhookActionByIDInt64FuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c CodeMonitorStoreWebhookActionByIDInt64FuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
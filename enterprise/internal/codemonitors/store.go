@@ -32,9 +32,14 @@ type CodeMonitorStore interface {
 	ListActionJobs(context.Context, ListActionJobsOpts) ([]*ActionJob, error)
 	CountActionJobs(context.Context, ListActionJobsOpts) (int, error)
 	ListEmailActions(context.Context, ListActionsOpts) ([]*MonitorEmail, error)
-	EnqueueActionEmailsForQueryIDInt64(ctx context.Context, queryID int64, triggerEventID int) (err error)
+	EnqueueActionJobsForQueryIDInt64(ctx context.Context, queryID int64, triggerEventID int) (err error)
 	GetActionJobMetadata(ctx context.Context, recordID int) (*ActionJobMetadata, error)
 	ActionJobForIDInt(ctx context.Context, recordID int) (*ActionJob, error)
+	SetActionJobLogContents(ctx context.Context, recordID int, logContents string) error
+	CreateWebhookAction(ctx context.Context, monitorID int64, enabled bool, url string) (*MonitorWebhook, error)
+	WebhookActionByIDInt64(ctx context.Context, webhookID int64) (*MonitorWebhook, error)
+	CreateSlackWebhookAction(ctx context.Context, monitorID int64, enabled bool, url string) (*MonitorSlackWebhook, error)
+	SlackWebhookActionByIDInt64(ctx context.Context, slackWebhookID int64) (*MonitorSlackWebhook, error)
 	CreateActions(ctx context.Context, args []*graphqlbackend.CreateActionArgs, monitorID int64) (err error)
 	CreateCodeMonitor(ctx context.Context, args *graphqlbackend.CreateCodeMonitorArgs) (m *Monitor, err error)
 	CreateMonitor(ctx context.Context, args *graphqlbackend.CreateMonitorArgs) (m *Monitor, err error)
@@ -48,6 +53,8 @@ type CodeMonitorStore interface {
 	UpdateTriggerQuery(ctx context.Context, args *graphqlbackend.UpdateCodeMonitorArgs) (err error)
 	TriggerQueryByMonitorIDInt64(ctx context.Context, monitorID int64) (*MonitorQuery, error)
 	ResetTriggerQueryTimestamps(ctx context.Context, queryID int64) error
+	SymbolTriggerStatesForQueryIDInt64(ctx context.Context, queryID int64) (map[int32]*SymbolTriggerState, error)
+	UpsertSymbolTriggerState(ctx context.Context, state *SymbolTriggerState) error
 	GetQueryByRecordID(ctx context.Context, recordID int) (query *MonitorQuery, err error)
 	SetTriggerQueryNextRun(ctx context.Context, triggerQueryID int64, next time.Time, latestResults time.Time) error
 	CreateRecipients(ctx context.Context, recipients []graphql.ID, emailID int64) (err error)
@@ -56,7 +63,7 @@ type CodeMonitorStore interface {
 	AllRecipientsForEmailIDInt64(ctx context.Context, emailID int64) (rs []*Recipient, err error)
 	TotalCountRecipients(ctx context.Context, emailID int64) (count int32, err error)
 	EnqueueTriggerQueries(ctx context.Context) (err error)
-	LogSearch(ctx context.Context, queryString string, numResults int, recordID int) error
+	LogSearch(ctx context.Context, queryString string, results []interface{}, recordID int) error
 	DeleteObsoleteJobLogs(ctx context.Context) error
 	DeleteOldJobLogs(ctx context.Context, retentionInDays int) error
 	GetEventsForQueryIDInt64(ctx context.Context, queryID int64, args *graphqlbackend.ListEventsArgs) ([]*TriggerJobs, error)
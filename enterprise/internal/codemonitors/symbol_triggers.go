@@ -0,0 +1,154 @@
+package codemonitors
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+// IsSymbolQuery returns true if the given trigger query is a symbol search
+// (type:symbol or select:symbol). Unlike commit and diff searches, symbol
+// searches do not support the after: filter, so new results are detected by
+// comparing the matching symbols against the previous run instead.
+func IsSymbolQuery(queryString string) bool {
+	q, err := query.ParseLiteral(queryString)
+	if err != nil {
+		return false
+	}
+	types, _ := q.StringValues(query.FieldType)
+	for _, t := range types {
+		if t == "symbol" {
+			return true
+		}
+	}
+	selects, _ := q.StringValues(query.FieldSelect)
+	for _, s := range selects {
+		if s == "symbol" || strings.HasPrefix(s, "symbol.") {
+			return true
+		}
+	}
+	return false
+}
+
+// SymbolTriggerMaxResults is the maximum number of results a symbol search
+// trigger fetches. Symbol triggers compare all matching symbols against the
+// previous run, so they cannot use the default result limit of searches.
+const SymbolTriggerMaxResults = 10000
+
+// SymbolTriggerQuery returns the query a symbol search trigger runs for
+// queryString. It fetches up to SymbolTriggerMaxResults results, unless the
+// query sets its own count.
+func SymbolTriggerQuery(queryString string) string {
+	if symbolQueryCount(queryString) != "" {
+		return queryString
+	}
+	return strings.Join([]string{queryString, "count:" + strconv.Itoa(SymbolTriggerMaxResults)}, " ")
+}
+
+// ValidateSymbolTriggerQuery returns an error if queryString is a symbol
+// search with a count above SymbolTriggerMaxResults.
+func ValidateSymbolTriggerQuery(queryString string) error {
+	if !IsSymbolQuery(queryString) {
+		return nil
+	}
+	count := symbolQueryCount(queryString)
+	if count == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(count); err != nil || n > SymbolTriggerMaxResults {
+		return errors.Errorf("symbol search triggers support at most count:%d", SymbolTriggerMaxResults)
+	}
+	return nil
+}
+
+// symbolQueryCount returns the value of the count: field of queryString, or
+// the empty string if it has none.
+func symbolQueryCount(queryString string) string {
+	q, err := query.ParseLiteral(queryString)
+	if err != nil {
+		return ""
+	}
+	counts, _ := q.StringValues(query.FieldCount)
+	if len(counts) == 0 {
+		return ""
+	}
+	return counts[0]
+}
+
+// SymbolTriggerState is the set of symbols a symbol search trigger saw in a
+// repository the last time it ran.
+type SymbolTriggerState struct {
+	Query       int64
+	RepoID      int32
+	QueryString string
+	Commit      string
+	Symbols     []string
+}
+
+const symbolTriggerStatesForQueryFmtStr = `
+SELECT query, repo_id, query_string, commit_oid, symbols
+FROM cm_symbol_trigger_states
+WHERE query = %s
+`
+
+// SymbolTriggerStatesForQueryIDInt64 returns the symbol states recorded for the
+// given trigger query, keyed by repository ID.
+func (s *codeMonitorStore) SymbolTriggerStatesForQueryIDInt64(ctx context.Context, queryID int64) (map[int32]*SymbolTriggerState, error) {
+	rows, err := s.Query(ctx, sqlf.Sprintf(symbolTriggerStatesForQueryFmtStr, queryID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := map[int32]*SymbolTriggerState{}
+	for rows.Next() {
+		state, err := scanSymbolTriggerState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states[state.RepoID] = state
+	}
+	return states, rows.Err()
+}
+
+const upsertSymbolTriggerStateFmtStr = `
+INSERT INTO cm_symbol_trigger_states (query, repo_id, query_string, commit_oid, symbols, updated_at)
+VALUES (%s, %s, %s, %s, %s, %s)
+ON CONFLICT (query, repo_id) DO UPDATE
+SET query_string = EXCLUDED.query_string,
+	commit_oid = EXCLUDED.commit_oid,
+	symbols = EXCLUDED.symbols,
+	updated_at = EXCLUDED.updated_at
+`
+
+// UpsertSymbolTriggerState records the symbols a symbol search trigger saw in a
+// repository, replacing any previous state.
+func (s *codeMonitorStore) UpsertSymbolTriggerState(ctx context.Context, state *SymbolTriggerState) error {
+	return s.Exec(ctx, sqlf.Sprintf(
+		upsertSymbolTriggerStateFmtStr,
+		state.Query,
+		state.RepoID,
+		state.QueryString,
+		state.Commit,
+		pq.Array(state.Symbols),
+		s.Now(),
+	))
+}
+
+func scanSymbolTriggerState(row dbutil.Scanner) (*SymbolTriggerState, error) {
+	state := &SymbolTriggerState{}
+	return state, row.Scan(
+		&state.Query,
+		&state.RepoID,
+		&state.QueryString,
+		&state.Commit,
+		pq.Array(&state.Symbols),
+	)
+}
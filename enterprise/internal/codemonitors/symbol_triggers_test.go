@@ -0,0 +1,97 @@
+package codemonitors
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbconn"
+)
+
+func TestIsSymbolQuery(t *testing.T) {
+	tests := map[string]bool{
+		"type:symbol Unsafe":                      true,
+		"select:symbol Unsafe":                    true,
+		"select:symbol.function Unsafe":           true,
+		"repo:foo type:diff Unsafe":               false,
+		"repo:foo type:commit select:repo Unsafe": false,
+		"Unsafe": false,
+	}
+	for q, want := range tests {
+		if got := IsSymbolQuery(q); got != want {
+			t.Errorf("IsSymbolQuery(%q): want %t, got %t", q, want, got)
+		}
+	}
+}
+
+func TestSymbolTriggerQuery(t *testing.T) {
+	tests := map[string]string{
+		"type:symbol Unsafe":          "type:symbol Unsafe count:10000",
+		"type:symbol Unsafe count:50": "type:symbol Unsafe count:50",
+	}
+	for q, want := range tests {
+		if got := SymbolTriggerQuery(q); got != want {
+			t.Errorf("SymbolTriggerQuery(%q): want %q, got %q", q, want, got)
+		}
+	}
+}
+
+func TestValidateSymbolTriggerQuery(t *testing.T) {
+	tests := map[string]bool{
+		"type:symbol Unsafe":             true,
+		"type:symbol Unsafe count:10000": true,
+		"type:symbol Unsafe count:10001": false,
+		"type:symbol Unsafe count:all":   false,
+		"type:diff Unsafe count:all":     true,
+	}
+	for q, valid := range tests {
+		if err := ValidateSymbolTriggerQuery(q); (err == nil) != valid {
+			t.Errorf("ValidateSymbolTriggerQuery(%q): want valid %t, got error %v", q, valid, err)
+		}
+	}
+}
+
+func TestSymbolTriggerStates(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx, s := newTestStore(t)
+	_, _, _, userCTX := newTestUser(ctx, t)
+	_, err := s.insertTestMonitor(userCTX, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := sqlf.Sprintf("INSERT INTO repo (name) VALUES ('github.com/sourcegraph/sourcegraph') RETURNING id")
+	var repoID int32
+	if err := dbconn.Global.QueryRow(q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&repoID); err != nil {
+		t.Fatal(err)
+	}
+
+	state := &SymbolTriggerState{
+		Query:       1,
+		RepoID:      repoID,
+		QueryString: "type:symbol Unsafe",
+		Commit:      "deadbeef",
+		Symbols:     []string{"a", "b"},
+	}
+	if err := s.UpsertSymbolTriggerState(ctx, state); err != nil {
+		t.Fatal(err)
+	}
+	state.Commit = "cafebabe"
+	state.Symbols = []string{}
+	if err := s.UpsertSymbolTriggerState(ctx, state); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.SymbolTriggerStatesForQueryIDInt64(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int32]*SymbolTriggerState{repoID: state}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected states (-want +got):\n%s", diff)
+	}
+}
@@ -3,8 +3,10 @@ package codemonitors
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/keegancsmith/sqlf"
@@ -42,12 +44,24 @@ const logSearchFmtStr = `
 UPDATE cm_trigger_jobs
 SET query_string = %s,
     results = %s,
-    num_results = %s
+    num_results = %s,
+    search_results = %s
 WHERE id = %s
 `
 
-func (s *codeMonitorStore) LogSearch(ctx context.Context, queryString string, numResults int, recordID int) error {
-	return s.Store.Exec(ctx, sqlf.Sprintf(logSearchFmtStr, queryString, numResults > 0, numResults, recordID))
+// LogSearch records the query that was run for the given trigger job and the
+// new search results it found. The results are kept so that webhook and Slack
+// actions can include them in their payloads.
+func (s *codeMonitorStore) LogSearch(ctx context.Context, queryString string, results []interface{}, recordID int) error {
+	var searchResults []byte
+	if len(results) > 0 {
+		var err error
+		searchResults, err = json.Marshal(results)
+		if err != nil {
+			return errors.Wrap(err, "marshalling search results")
+		}
+	}
+	return s.Store.Exec(ctx, sqlf.Sprintf(logSearchFmtStr, queryString, len(results) > 0, len(results), searchResults, recordID))
 }
 
 const deleteObsoleteJobLogsFmtStr = `
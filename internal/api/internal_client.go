@@ -48,8 +48,11 @@ type ConfigSavedQuery struct {
 	Query           string  `json:"query"`
 	Notify          bool    `json:"notify,omitempty"`
 	NotifySlack     bool    `json:"notifySlack,omitempty"`
+	NotifyWebhook   bool    `json:"notifyWebhook,omitempty"`
 	UserID          int32   `json:"userID"`
 	SlackWebhookURL *string `json:"slackWebhookURL"`
+	WebhookURL      *string `json:"webhookURL,omitempty"`
+	WebhookSecret   *string `json:"webhookSecret,omitempty"`
 }
 
 func (sq ConfigSavedQuery) Equals(other ConfigSavedQuery) bool {
@@ -122,6 +125,24 @@ func (c *internalClient) SavedQueriesDeleteInfo(ctx context.Context, query strin
 	return c.postInternal(ctx, "saved-queries/delete-info", query, nil)
 }
 
+// SavedQueryDelivery describes an outbound notification that was sent for a
+// saved query.
+type SavedQueryDelivery struct {
+	SavedSearchID int32
+	Kind          string
+	Event         string
+	Attempts      int
+	StatusCode    int
+	Error         string
+	Duration      time.Duration
+}
+
+// SavedQueriesLogDelivery records the given delivery in the saved query's
+// delivery log.
+func (c *internalClient) SavedQueriesLogDelivery(ctx context.Context, delivery *SavedQueryDelivery) error {
+	return c.postInternal(ctx, "saved-queries/log-delivery", delivery, nil)
+}
+
 func (c *internalClient) SettingsGetForSubject(
 	ctx context.Context,
 	subject SettingsSubject,
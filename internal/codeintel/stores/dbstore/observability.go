@@ -8,8 +8,9 @@ import (
 )
 
 type Operations struct {
-	repoName           *observation.Operation
-	getJVMDependencies *observation.Operation
+	repoName              *observation.Operation
+	getJVMDependencies    *observation.Operation
+	getPythonDependencies *observation.Operation
 }
 
 func NewREDMetrics(observationContext *observation.Context) *metrics.REDMetrics {
@@ -31,7 +32,8 @@ func NewOperations(observationContext *observation.Context, metrics *metrics.RED
 	}
 
 	return &Operations{
-		repoName:           op("RepoName"),
-		getJVMDependencies: op("GetJVMDependencies"),
+		repoName:              op("RepoName"),
+		getJVMDependencies:    op("GetJVMDependencies"),
+		getPythonDependencies: op("GetPythonDependencies"),
 	}
 }
@@ -96,6 +96,75 @@ func scanJVMDependencyRepo(rows *sql.Rows, queryErr error) (dependencies []JVMDe
 	return dependencies, nil
 }
 
+type GetPythonDependencyReposOpts struct {
+	// PackageName is the normalized name of the package, see
+	// reposource.NormalizePythonPackageName.
+	PackageName string
+	After       int
+	Limit       int
+}
+
+type PythonDependencyRepo struct {
+	Package string
+	Version string
+	ID      int
+}
+
+// GetPythonDependencyRepos returns the dependency repos inserted for packages
+// of the `pypi` moniker scheme.
+func (s *Store) GetPythonDependencyRepos(ctx context.Context, filter GetPythonDependencyReposOpts) (repos []PythonDependencyRepo, err error) {
+	ctx, endObservation := s.operations.getPythonDependencies.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("after", filter.After),
+		log.Int("limit", filter.Limit),
+		log.Lazy(func(l log.Encoder) {
+			l.EmitInt("results", len(repos))
+		}),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	conds := make([]*sqlf.Query, 0, 3)
+	conds = append(conds, sqlf.Sprintf("scheme = 'pypi'"))
+
+	if filter.After > 0 {
+		conds = append(conds, sqlf.Sprintf("id > %d", filter.After))
+	}
+
+	if filter.PackageName != "" {
+		// Indexers may emit any spelling of a project name, so compare the
+		// PEP 503 normalized forms.
+		conds = append(conds, sqlf.Sprintf("lower(regexp_replace(name, '[-_.]+', '-', 'g')) = %s", filter.PackageName))
+	}
+
+	limit := sqlf.Sprintf("")
+	if filter.Limit != 0 {
+		limit = sqlf.Sprintf("LIMIT %s", filter.Limit)
+	}
+
+	return scanPythonDependencyRepo(s.Query(ctx, sqlf.Sprintf(getLSIFDependencyReposQuery, sqlf.Join(conds, "AND"), limit)))
+}
+
+func scanPythonDependencyRepo(rows *sql.Rows, queryErr error) (dependencies []PythonDependencyRepo, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	for rows.Next() {
+		var dep PythonDependencyRepo
+		if err = rows.Scan(
+			&dep.ID,
+			&dep.Package,
+			&dep.Version,
+		); err != nil {
+			return nil, err
+		}
+
+		dependencies = append(dependencies, dep)
+	}
+
+	return dependencies, nil
+}
+
 const getLSIFDependencyReposQuery = `
 -- source: internal/codeintel/stores/dbstore/repos.go:GetLSIFDependencyRepos
 SELECT id, name, version FROM lsif_dependency_repos
@@ -16,8 +16,12 @@ var (
 	_ Command = (*MatchOnly)(nil)
 	_ Command = (*Replace)(nil)
 	_ Command = (*Output)(nil)
+	_ Command = (*Count)(nil)
+	_ Command = (*Group)(nil)
 )
 
 func (MatchOnly) command() {}
 func (Replace) command()   {}
 func (Output) command()    {}
+func (Count) command()     {}
+func (Group) command()     {}
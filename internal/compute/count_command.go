@@ -0,0 +1,65 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// Count tallies the values of a match pattern across matches. If GroupPattern
+// is set, each match is expanded with it (e.g., `$1`) to compute the value
+// counted. Otherwise the first capture group is counted, or the whole match if
+// the pattern has no capture groups.
+type Count struct {
+	MatchPattern MatchPattern
+	GroupPattern string
+}
+
+func (c *Count) String() string {
+	if c.GroupPattern == "" {
+		return fmt.Sprintf("Count: %s", c.MatchPattern.String())
+	}
+	return fmt.Sprintf("Count: (%s) -> (%s)", c.MatchPattern.String(), c.GroupPattern)
+}
+
+// matchValues returns the value of each match of match in content. If
+// groupPattern is set, it is the expansion of groupPattern. Otherwise it is
+// the first capture group, or the whole match if the pattern has no capture
+// groups.
+func matchValues(content string, match *regexp.Regexp, groupPattern string) []string {
+	var values []string
+	for _, submatches := range match.FindAllStringSubmatchIndex(content, -1) {
+		switch {
+		case groupPattern != "":
+			values = append(values, string(match.ExpandString([]byte{}, groupPattern, content, submatches)))
+		case len(submatches) > 2 && submatches[2] != -1:
+			values = append(values, content[submatches[2]:submatches[3]])
+		default:
+			values = append(values, content[submatches[0]:submatches[1]])
+		}
+	}
+	return values
+}
+
+func count(content string, match *regexp.Regexp, groupPattern string) *Histogram {
+	h := &Histogram{}
+	for _, value := range matchValues(content, match, groupPattern) {
+		h.Add(value, 1)
+	}
+	return h
+}
+
+func (c *Count) Run(_ context.Context, fm *result.FileMatch) (Result, error) {
+	re, ok := c.MatchPattern.(*Regexp)
+	if !ok {
+		return nil, errors.Errorf("unsupported count operation for match pattern %T", c.MatchPattern)
+	}
+	h := &Histogram{}
+	for _, line := range fm.LineMatches {
+		h.Merge(count(line.Preview, re.Value, c.GroupPattern))
+	}
+	return h, nil
+}
@@ -0,0 +1,62 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hexops/autogold"
+)
+
+func Test_count(t *testing.T) {
+	test := func(input string, cmd *Count) string {
+		h := count(input, cmd.MatchPattern.(*Regexp).Value, cmd.GroupPattern)
+		var parts []string
+		for _, b := range h.Buckets() {
+			parts = append(parts, fmt.Sprintf("%s:%d", b.Value, b.Count))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	autogold.Want(
+		"count whole match",
+		"a:2 b:1").
+		Equal(t, test("a b a", &Count{
+			MatchPattern: &Regexp{Value: regexp.MustCompile(`\w`)},
+		}))
+
+	autogold.Want(
+		"count first capture group",
+		"1.2:2 1.3:1").
+		Equal(t, test("foo v1.2 foo v1.3 foo v1.2", &Count{
+			MatchPattern: &Regexp{Value: regexp.MustCompile(`foo v(\d+\.\d+)`)},
+		}))
+
+	autogold.Want(
+		"count group pattern",
+		"1:2 2:1").
+		Equal(t, test("v1.2 v1.3 v2.0", &Count{
+			MatchPattern: &Regexp{Value: regexp.MustCompile(`v(\d+)\.(\d+)`)},
+			GroupPattern: "$1",
+		}))
+}
+
+func TestRunCount(t *testing.T) {
+	computeQuery, err := Parse(`content:count(bar v(\d+))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &Histogram{}
+	for _, content := range []string{"bar v1", "bar v2 bar v1"} {
+		res, err := computeQuery.Command.Run(context.Background(), contentAsFileMatch(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Merge(res.(*Histogram))
+	}
+
+	autogold.Want("merged histogram", []Bucket{{Value: "1", Count: 2}, {Value: "2", Count: 1}}).Equal(t, h.Buckets())
+}
@@ -0,0 +1,39 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// Group partitions matches by the value of a match pattern, and lists the
+// files each value occurs in. The value of a match is computed like for
+// Count.
+type Group struct {
+	MatchPattern MatchPattern
+	GroupPattern string
+}
+
+func (c *Group) String() string {
+	if c.GroupPattern == "" {
+		return fmt.Sprintf("Group: %s", c.MatchPattern.String())
+	}
+	return fmt.Sprintf("Group: (%s) -> (%s)", c.MatchPattern.String(), c.GroupPattern)
+}
+
+func (c *Group) Run(_ context.Context, fm *result.FileMatch) (Result, error) {
+	re, ok := c.MatchPattern.(*Regexp)
+	if !ok {
+		return nil, errors.Errorf("unsupported group operation for match pattern %T", c.MatchPattern)
+	}
+	g := &Groups{}
+	file := GroupFile{Repo: fm.Repo, Commit: string(fm.CommitID), Path: fm.Path}
+	for _, line := range fm.LineMatches {
+		for _, value := range matchValues(line.Preview, re.Value, c.GroupPattern) {
+			g.Add(value, file)
+		}
+	}
+	return g, nil
+}
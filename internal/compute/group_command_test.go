@@ -0,0 +1,55 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hexops/autogold"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+func TestRunGroup(t *testing.T) {
+	computeQuery, err := Parse(`content:group(bar v(\d+))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &Groups{}
+	for _, file := range []struct{ path, content string }{
+		{"a", "bar v1"},
+		{"b", "bar v2 bar v1 bar v1"},
+	} {
+		fm := contentAsFileMatch(file.content)
+		fm.Path = file.path
+		res, err := computeQuery.Command.Run(context.Background(), fm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Merge(res.(*Groups))
+	}
+
+	var parts []string
+	for _, v := range g.Values() {
+		var paths []string
+		for _, f := range v.Files {
+			paths = append(paths, f.Path)
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%s", v.Value, v.Count, strings.Join(paths, ",")))
+	}
+	// Files are listed once per value, in the order they are merged.
+	autogold.Want("merged groups", "1:3:a,b 2:1:b").Equal(t, strings.Join(parts, " "))
+}
+
+func TestRunUnsupportedMatchPattern(t *testing.T) {
+	for _, cmd := range []Command{
+		&Count{MatchPattern: &Comby{Value: ":[x]"}},
+		&Group{MatchPattern: &Comby{Value: ":[x]"}},
+	} {
+		if _, err := cmd.Run(context.Background(), &result.FileMatch{}); err == nil {
+			t.Errorf("expected %s to fail for a structural pattern", cmd)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package compute
+
+import (
+	"sort"
+
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// GroupFile is a file a group value occurs in.
+type GroupFile struct {
+	Repo   types.MinimalRepo
+	Commit string
+	Path   string
+}
+
+// GroupValue is a value, the number of times it occurs and the files it
+// occurs in.
+type GroupValue struct {
+	Value string
+	Count int
+	Files []GroupFile
+}
+
+// Groups partitions occurrences of values by value.
+type Groups struct {
+	values map[string]*GroupValue
+	seen   map[string]map[GroupFile]struct{}
+}
+
+// Add adds an occurrence of value in file.
+func (g *Groups) Add(value string, file GroupFile) {
+	g.add(value, 1, []GroupFile{file})
+}
+
+func (g *Groups) add(value string, count int, files []GroupFile) {
+	if g.values == nil {
+		g.values = make(map[string]*GroupValue)
+		g.seen = make(map[string]map[GroupFile]struct{})
+	}
+	v, ok := g.values[value]
+	if !ok {
+		v = &GroupValue{Value: value}
+		g.values[value] = v
+		g.seen[value] = make(map[GroupFile]struct{})
+	}
+	v.Count += count
+	for _, file := range files {
+		if _, ok := g.seen[value][file]; ok {
+			continue
+		}
+		g.seen[value][file] = struct{}{}
+		v.Files = append(v.Files, file)
+	}
+}
+
+// Merge adds the occurrences of other to g.
+func (g *Groups) Merge(other *Groups) {
+	for value, v := range other.values {
+		g.add(value, v.Count, v.Files)
+	}
+}
+
+// Values returns the groups ordered by descending count.
+func (g *Groups) Values() []GroupValue {
+	values := make([]GroupValue, 0, len(g.values))
+	for _, v := range g.values {
+		values = append(values, *v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	return values
+}
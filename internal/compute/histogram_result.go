@@ -0,0 +1,43 @@
+package compute
+
+import "sort"
+
+type Bucket struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Histogram counts occurrences of values.
+type Histogram struct {
+	counts map[string]int
+}
+
+// Add adds n to the count of value.
+func (h *Histogram) Add(value string, n int) {
+	if h.counts == nil {
+		h.counts = make(map[string]int)
+	}
+	h.counts[value] += n
+}
+
+// Merge adds the counts of other to h.
+func (h *Histogram) Merge(other *Histogram) {
+	for value, n := range other.counts {
+		h.Add(value, n)
+	}
+}
+
+// Buckets returns the counted values ordered by descending count.
+func (h *Histogram) Buckets() []Bucket {
+	buckets := make([]Bucket, 0, len(h.counts))
+	for value, n := range h.counts {
+		buckets = append(buckets, Bucket{Value: value, Count: n})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Value < buckets[j].Value
+	})
+	return buckets
+}
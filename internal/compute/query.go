@@ -32,6 +32,10 @@ func (q Query) ToSearchQuery() (string, error) {
 		searchPattern = c.MatchPattern.String()
 	case *Output:
 		searchPattern = c.MatchPattern.String()
+	case *Count:
+		searchPattern = c.MatchPattern.String()
+	case *Group:
+		searchPattern = c.MatchPattern.String()
 	default:
 		return "", errors.Errorf("unsupported query conversion for compute command %T", c)
 	}
@@ -108,6 +112,8 @@ var ComputePredicateRegistry = query.PredicateRegistry{
 		"replace.regexp":     func() query.Predicate { return query.EmptyPredicate{} },
 		"replace.structural": func() query.Predicate { return query.EmptyPredicate{} },
 		"output":             func() query.Predicate { return query.EmptyPredicate{} },
+		"count":              func() query.Predicate { return query.EmptyPredicate{} },
+		"group":              func() query.Predicate { return query.EmptyPredicate{} },
 	},
 }
 
@@ -190,6 +196,49 @@ func parseOutput(pattern *query.Pattern) (Command, bool, error) {
 	return &Output{MatchPattern: matchPattern, OutputPattern: right, Separator: "\n"}, true, nil
 }
 
+func parseCount(pattern *query.Pattern) (Command, bool, error) {
+	name, args, ok := parseContentPredicate(pattern)
+	if !ok || name != "count" {
+		return nil, false, nil
+	}
+	matchPattern, groupPattern, err := parseValuePattern(args)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "count command")
+	}
+	return &Count{MatchPattern: matchPattern, GroupPattern: groupPattern}, true, nil
+}
+
+func parseGroup(pattern *query.Pattern) (Command, bool, error) {
+	name, args, ok := parseContentPredicate(pattern)
+	if !ok || name != "group" {
+		return nil, false, nil
+	}
+	matchPattern, groupPattern, err := parseValuePattern(args)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "group command")
+	}
+	return &Group{MatchPattern: matchPattern, GroupPattern: groupPattern}, true, nil
+}
+
+// parseValuePattern parses the arguments of commands that compute a value per
+// match. The group pattern is optional: regexp computes the first capture
+// group or whole match, regexp -> template computes the template.
+func parseValuePattern(args string) (MatchPattern, string, error) {
+	left, right := args, ""
+	if arrowSyntax.MatchString(args) {
+		var err error
+		left, right, err = parseArrowSyntax(args)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	matchPattern, err := toRegexpPattern(left)
+	if err != nil {
+		return nil, "", err
+	}
+	return matchPattern, right, nil
+}
+
 func parseMatchOnly(pattern *query.Pattern) (Command, bool, error) {
 	rp, err := toRegexpPattern(pattern.Value)
 	if err != nil {
@@ -217,6 +266,8 @@ func first(parsers ...commandParser) commandParser {
 }
 
 var parseCommand = first(
+	parseCount,
+	parseGroup,
 	parseReplace,
 	parseOutput,
 	parseMatchOnly,
@@ -46,6 +46,18 @@ func TestParse(t *testing.T) {
 	autogold.Want("replace no left hand side",
 		"Command: `Replace in place: () -> (b)`").
 		Equal(t, test("content:replace(->b)"))
+
+	autogold.Want("count",
+		"Command: `Count: v(\\d+)`").
+		Equal(t, test(`content:count(v(\d+))`))
+
+	autogold.Want("count with group pattern",
+		"Command: `Count: (v(\\d+)\\.(\\d+)) -> ($1)`").
+		Equal(t, test(`content:count(v(\d+)\.(\d+) -> $1)`))
+
+	autogold.Want("group",
+		"Command: `Group: (v(\\d+)\\.(\\d+)) -> ($1)`").
+		Equal(t, test(`content:group(v(\d+)\.(\d+) -> $1)`))
 }
 
 func TestToSearchQuery(t *testing.T) {
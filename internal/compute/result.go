@@ -7,7 +7,11 @@ type Result interface {
 var (
 	_ Result = (*MatchContext)(nil)
 	_ Result = (*Text)(nil)
+	_ Result = (*Histogram)(nil)
+	_ Result = (*Groups)(nil)
 )
 
 func (*MatchContext) result() {}
 func (*Text) result()         {}
+func (*Histogram) result()    {}
+func (*Groups) result()       {}
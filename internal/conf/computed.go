@@ -208,6 +208,12 @@ func SearchIndexEnabled() bool {
 	return true // always on by default in all deployment types, see confdefaults.go
 }
 
+// SearchIndexCommitsEnabled returns true if gitserver should maintain an
+// index of the commits of each repository for commit and diff search.
+func SearchIndexCommitsEnabled() bool {
+	return Get().SearchIndexCommitsEnabled
+}
+
 func BatchChangesEnabled() bool {
 	if enabled := Get().BatchChangesEnabled; enabled != nil {
 		return *enabled
@@ -423,6 +429,33 @@ func GitMaxConcurrentClones() int {
 	return v
 }
 
+// GitRepoOptimizationStrategy returns the strategy gitserver's janitor uses
+// to optimize repositories. If not set, it returns the default value "gc".
+func GitRepoOptimizationStrategy() string {
+	v := Get().GitRepoOptimizationStrategy
+	if v == "" {
+		return "gc"
+	}
+	return v
+}
+
+// GitServerReplicationFactor returns the number of gitservers each repository
+// is cloned onto. If not set, it returns the default value 1.
+func GitServerReplicationFactor() int {
+	v := Get().GitServerReplicationFactor
+	if v <= 0 {
+		return 1
+	}
+	return v
+}
+
+// GitserverExecAuditLogEnabled returns whether git commands run by gitserver
+// are recorded in the audit log.
+func GitserverExecAuditLogEnabled() bool {
+	cfg := Get().GitserverExecAuditLog
+	return cfg != nil && cfg.Enabled
+}
+
 func UserReposMaxPerUser() int {
 	v := Get().UserReposMaxPerUser
 	if v == 0 {
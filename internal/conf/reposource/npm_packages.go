@@ -0,0 +1,132 @@
+package reposource
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// NPMPackage is a package published to an npm registry, for example "react" or
+// "@types/node".
+type NPMPackage struct {
+	// Scope is the optional scope of the package, without the leading `@`.
+	Scope string
+	Name  string
+}
+
+// npmPackageNameRegex matches the package names accepted by the npm registry,
+// see https://github.com/npm/validate-npm-package-name.
+var npmPackageNameRegex = regexp.MustCompile(`^[a-z0-9-~][a-z0-9-._~]*$`)
+
+func (p *NPMPackage) MatchesDependencyString(dependency string) bool {
+	return strings.HasPrefix(dependency, p.PackageSyntax()+"@")
+}
+
+// PackageSyntax returns the name of the package as it is written in a
+// package.json file, for example "@types/node".
+func (p *NPMPackage) PackageSyntax() string {
+	if p.Scope != "" {
+		return fmt.Sprintf("@%s/%s", p.Scope, p.Name)
+	}
+	return p.Name
+}
+
+func (p *NPMPackage) SortText() string {
+	return p.PackageSyntax()
+}
+
+func (p *NPMPackage) RepoName() api.RepoName {
+	if p.Scope != "" {
+		return api.RepoName(fmt.Sprintf("npm/%s/%s", p.Scope, p.Name))
+	}
+	return api.RepoName("npm/" + p.Name)
+}
+
+func (p *NPMPackage) CloneURL() string {
+	cloneURL := url.URL{Path: string(p.RepoName())}
+	return cloneURL.String()
+}
+
+type NPMDependency struct {
+	NPMPackage
+	Version string
+}
+
+// SortNPMDependencies sorts the dependencies by the semantic version in
+// descending order. The latest version of a dependency becomes the first
+// element of the slice.
+func SortNPMDependencies(dependencies []NPMDependency) {
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].NPMPackage == dependencies[j].NPMPackage {
+			return versionGreaterThan(dependencies[i].Version, dependencies[j].Version)
+		}
+		return dependencies[i].NPMPackage.SortText() > dependencies[j].NPMPackage.SortText()
+	})
+}
+
+// PackageManagerSyntax returns the dependency in the "name@version" format used
+// by the npm command-line tool, for example "@types/node@16.0.0".
+func (d NPMDependency) PackageManagerSyntax() string {
+	return fmt.Sprintf("%s@%s", d.NPMPackage.PackageSyntax(), d.Version)
+}
+
+func (d NPMDependency) GitTagFromVersion() string {
+	return "v" + d.Version
+}
+
+// ParseNPMPackage parses a package name such as "react" or "@types/node".
+func ParseNPMPackage(name string) (NPMPackage, error) {
+	var pkg NPMPackage
+	if strings.HasPrefix(name, "@") {
+		parts := strings.SplitN(strings.TrimPrefix(name, "@"), "/", 2)
+		if len(parts) != 2 {
+			return NPMPackage{}, fmt.Errorf("scoped npm package %q must have the form @scope/name", name)
+		}
+		pkg = NPMPackage{Scope: parts[0], Name: parts[1]}
+	} else {
+		pkg = NPMPackage{Name: name}
+	}
+	if pkg.Scope != "" && !npmPackageNameRegex.MatchString(pkg.Scope) {
+		return NPMPackage{}, fmt.Errorf("invalid scope %q for npm package %q", pkg.Scope, name)
+	}
+	if !npmPackageNameRegex.MatchString(pkg.Name) {
+		return NPMPackage{}, fmt.Errorf("invalid npm package name %q", name)
+	}
+	return pkg, nil
+}
+
+// ParseNPMDependency parses a dependency string in the "name@version" format
+// used by the npm command-line tool into an NPMDependency.
+func ParseNPMDependency(dependency string) (NPMDependency, error) {
+	// The version separator is the last `@`. An `@` at index 0 is the scope
+	// prefix, which means the version is missing.
+	index := strings.LastIndex(dependency, "@")
+	if index <= 0 || index == len(dependency)-1 {
+		return NPMDependency{}, fmt.Errorf("dependency %q must have the form name@version", dependency)
+	}
+	pkg, err := ParseNPMPackage(dependency[:index])
+	if err != nil {
+		return NPMDependency{}, err
+	}
+	return NPMDependency{NPMPackage: pkg, Version: dependency[index+1:]}, nil
+}
+
+// ParseNPMPackageFromRepoURL returns the npm package for the provided URL
+// path, without a leading `/`. This is the inverse of NPMPackage.RepoName.
+func ParseNPMPackageFromRepoURL(urlPath string) (NPMPackage, error) {
+	if !strings.HasPrefix(urlPath, "npm/") {
+		return NPMPackage{}, fmt.Errorf("failed to parse an npm package from the path %s", urlPath)
+	}
+	parts := strings.Split(strings.TrimPrefix(urlPath, "npm/"), "/")
+	switch len(parts) {
+	case 1:
+		return ParseNPMPackage(parts[0])
+	case 2:
+		return ParseNPMPackage("@" + parts[0] + "/" + parts[1])
+	}
+	return NPMPackage{}, fmt.Errorf("failed to parse an npm package from the path %s", urlPath)
+}
@@ -0,0 +1,65 @@
+package reposource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestParseNPMDependency(t *testing.T) {
+	obtained, err := ParseNPMDependency("@types/node@16.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, NPMPackage{Scope: "types", Name: "node"}, obtained.NPMPackage)
+	assert.Equal(t, "16.0.0", obtained.Version)
+	assert.Equal(t, api.RepoName("npm/types/node"), obtained.RepoName())
+	assert.Equal(t, "@types/node@16.0.0", obtained.PackageManagerSyntax())
+
+	obtained, err = ParseNPMDependency("react@17.0.2")
+	assert.Nil(t, err)
+	assert.Equal(t, NPMPackage{Name: "react"}, obtained.NPMPackage)
+	assert.Equal(t, api.RepoName("npm/react"), obtained.RepoName())
+
+	for _, invalid := range []string{"react", "react@", "@types/node", "@types@1.0.0", "../evil@1.0.0", "React@1.0.0"} {
+		_, err := ParseNPMDependency(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestParseNPMPackageFromRepoURL(t *testing.T) {
+	for _, pkg := range []NPMPackage{{Name: "react"}, {Scope: "types", Name: "node"}} {
+		obtained, err := ParseNPMPackageFromRepoURL(string(pkg.RepoName()))
+		assert.Nil(t, err)
+		assert.Equal(t, pkg, obtained)
+	}
+
+	_, err := ParseNPMPackageFromRepoURL("maven/org.hamcrest/hamcrest-core")
+	assert.NotNil(t, err)
+}
+
+func TestSortNPMDependencies(t *testing.T) {
+	parse := func(s string) NPMDependency {
+		dependency, err := ParseNPMDependency(s)
+		if err != nil {
+			t.Fatalf("error=%s", err)
+		}
+		return dependency
+	}
+	dependencies := []NPMDependency{
+		parse("a@1.2.0"),
+		parse("b@1.11.0"),
+		parse("b@1.2.0"),
+		parse("@a/b@1.0.0"),
+		parse("b@1.2.0-rc.1"),
+	}
+	expected := []NPMDependency{
+		parse("b@1.11.0"),
+		parse("b@1.2.0"),
+		parse("b@1.2.0-rc.1"),
+		parse("a@1.2.0"),
+		parse("@a/b@1.0.0"),
+	}
+	SortNPMDependencies(dependencies)
+	assert.Equal(t, expected, dependencies)
+}
@@ -0,0 +1,114 @@
+package reposource
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// PythonPackage is a project published to a Python package index such as
+// PyPI, for example "requests".
+type PythonPackage struct {
+	// Name is the normalized name of the project, see NormalizePythonPackageName.
+	Name string
+}
+
+// pythonPackageNameRegex matches valid project names, see
+// https://www.python.org/dev/peps/pep-0508/#names.
+var pythonPackageNameRegex = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+
+// pythonNameSeparatorRegex matches the runs of characters that PEP 503 treats
+// as equivalent in project names.
+var pythonNameSeparatorRegex = regexp.MustCompile(`[-_.]+`)
+
+// NormalizePythonPackageName returns the PEP 503 normalized form of a project
+// name, under which "Foo.Bar", "foo_bar" and "foo-bar" are the same project.
+func NormalizePythonPackageName(name string) string {
+	return strings.ToLower(pythonNameSeparatorRegex.ReplaceAllString(name, "-"))
+}
+
+func (p *PythonPackage) MatchesDependencyString(dependency string) bool {
+	d, err := ParsePythonDependency(dependency)
+	return err == nil && d.PythonPackage == *p
+}
+
+// PackageSyntax returns the normalized name of the package, for example
+// "requests".
+func (p *PythonPackage) PackageSyntax() string {
+	return p.Name
+}
+
+func (p *PythonPackage) SortText() string {
+	return p.Name
+}
+
+func (p *PythonPackage) RepoName() api.RepoName {
+	return api.RepoName("python/" + p.Name)
+}
+
+func (p *PythonPackage) CloneURL() string {
+	cloneURL := url.URL{Path: string(p.RepoName())}
+	return cloneURL.String()
+}
+
+type PythonDependency struct {
+	PythonPackage
+	Version string
+}
+
+// SortPythonDependencies sorts the dependencies by the semantic version in
+// descending order. The latest version of a dependency becomes the first
+// element of the slice.
+func SortPythonDependencies(dependencies []PythonDependency) {
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].PythonPackage == dependencies[j].PythonPackage {
+			return versionGreaterThan(dependencies[i].Version, dependencies[j].Version)
+		}
+		return dependencies[i].PythonPackage.SortText() > dependencies[j].PythonPackage.SortText()
+	})
+}
+
+// PackageManagerSyntax returns the dependency in the "name==version" format
+// used by pip, for example "requests==2.26.0".
+func (d PythonDependency) PackageManagerSyntax() string {
+	return fmt.Sprintf("%s==%s", d.PythonPackage.Name, d.Version)
+}
+
+func (d PythonDependency) GitTagFromVersion() string {
+	return "v" + d.Version
+}
+
+// ParsePythonPackage parses and normalizes a project name.
+func ParsePythonPackage(name string) (PythonPackage, error) {
+	if !pythonPackageNameRegex.MatchString(name) {
+		return PythonPackage{}, fmt.Errorf("invalid python package name %q", name)
+	}
+	return PythonPackage{Name: NormalizePythonPackageName(name)}, nil
+}
+
+// ParsePythonDependency parses a dependency string in the "name==version"
+// format used by pip into a PythonDependency.
+func ParsePythonDependency(dependency string) (PythonDependency, error) {
+	parts := strings.Split(dependency, "==")
+	if len(parts) != 2 || parts[1] == "" {
+		return PythonDependency{}, fmt.Errorf("dependency %q must have the form name==version", dependency)
+	}
+	pkg, err := ParsePythonPackage(parts[0])
+	if err != nil {
+		return PythonDependency{}, err
+	}
+	return PythonDependency{PythonPackage: pkg, Version: parts[1]}, nil
+}
+
+// ParsePythonPackageFromRepoURL returns the Python package for the provided
+// URL path, without a leading `/`. This is the inverse of PythonPackage.RepoName.
+func ParsePythonPackageFromRepoURL(urlPath string) (PythonPackage, error) {
+	if !strings.HasPrefix(urlPath, "python/") {
+		return PythonPackage{}, fmt.Errorf("failed to parse a python package from the path %s", urlPath)
+	}
+	return ParsePythonPackage(strings.TrimPrefix(urlPath, "python/"))
+}
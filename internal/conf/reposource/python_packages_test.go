@@ -0,0 +1,39 @@
+package reposource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestParsePythonDependency(t *testing.T) {
+	obtained, err := ParsePythonDependency("Foo.Bar_baz==1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, PythonPackage{Name: "foo-bar-baz"}, obtained.PythonPackage)
+	assert.Equal(t, "1.0.0", obtained.Version)
+	assert.Equal(t, api.RepoName("python/foo-bar-baz"), obtained.RepoName())
+	assert.Equal(t, "foo-bar-baz==1.0.0", obtained.PackageManagerSyntax())
+
+	for _, invalid := range []string{"requests", "requests==", "requests>=1.0.0", "../evil==1.0.0", "-requests==1.0.0"} {
+		_, err := ParsePythonDependency(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestPythonPackageMatchesDependencyString(t *testing.T) {
+	pkg := PythonPackage{Name: "zope-interface"}
+	assert.True(t, pkg.MatchesDependencyString("zope.interface==5.4.0"))
+	assert.True(t, pkg.MatchesDependencyString("Zope_Interface==5.4.0"))
+	assert.False(t, pkg.MatchesDependencyString("zope-interfaces==5.4.0"))
+}
+
+func TestParsePythonPackageFromRepoURL(t *testing.T) {
+	obtained, err := ParsePythonPackageFromRepoURL("python/requests")
+	assert.Nil(t, err)
+	assert.Equal(t, PythonPackage{Name: "requests"}, obtained)
+
+	_, err = ParsePythonPackageFromRepoURL("npm/react")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/timeutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+type GitserverExecAuditLogStore struct {
+	*basestore.Store
+}
+
+// GitserverExecAuditLogs instantiates and returns a new GitserverExecAuditLogStore.
+func GitserverExecAuditLogs(db dbutil.DB) *GitserverExecAuditLogStore {
+	return &GitserverExecAuditLogStore{Store: basestore.NewWithDB(db, sql.TxOptions{})}
+}
+
+// GitserverExecAuditLogsWith instantiates and returns a new
+// GitserverExecAuditLogStore using the other store handle.
+func GitserverExecAuditLogsWith(other basestore.ShareableStore) *GitserverExecAuditLogStore {
+	return &GitserverExecAuditLogStore{Store: basestore.NewWithHandle(other.Handle())}
+}
+
+func (s *GitserverExecAuditLogStore) With(other basestore.ShareableStore) *GitserverExecAuditLogStore {
+	return &GitserverExecAuditLogStore{Store: s.Store.With(other)}
+}
+
+// Create appends the given entries to the audit log in a single statement.
+// Entries without a creation time are created at the current time.
+func (s *GitserverExecAuditLogStore) Create(ctx context.Context, logs ...*types.GitserverExecAuditLog) (err error) {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	now := timeutil.Now()
+	values := make([]*sqlf.Query, 0, len(logs))
+	for _, log := range logs {
+		createdAt := log.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		args := log.Args
+		if args == nil {
+			args = []string{}
+		}
+		values = append(values, sqlf.Sprintf(
+			"(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)",
+			log.Actor,
+			log.Client,
+			log.Gitserver,
+			log.Repo,
+			log.Command,
+			pq.Array(args),
+			log.Status,
+			log.ExitStatus,
+			log.Error,
+			log.Duration.Milliseconds(),
+			createdAt,
+		))
+	}
+
+	rows, err := s.Query(ctx, sqlf.Sprintf(gitserverExecAuditLogsCreateQueryFmtstr, sqlf.Join(values, ",\n")))
+	if err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	for i := 0; rows.Next(); i++ {
+		if err := rows.Scan(&logs[i].ID, &logs[i].CreatedAt); err != nil {
+			return errors.Wrap(err, "Scan")
+		}
+	}
+	return nil
+}
+
+const gitserverExecAuditLogsCreateQueryFmtstr = `
+-- source: internal/database/gitserver_exec_audit_logs.go:Create
+INSERT INTO gitserver_exec_audit_logs (actor, client, gitserver, repo_name, command, args, status, exit_status, error, duration_ms, created_at)
+VALUES %s
+RETURNING id, created_at
+`
+
+type GitserverExecAuditLogListOpts struct {
+	// The maximum number of entries to return, and the cursor, if any. As for
+	// webhook logs, the cursor is an ID, since the audit log is appended to
+	// while it is paged through.
+	Limit  int
+	Cursor int64
+
+	// If set, only entries for this repository are returned.
+	Repo api.RepoName
+
+	// If set, only entries for this actor are returned.
+	Actor string
+
+	Since *time.Time
+	Until *time.Time
+}
+
+func (opts *GitserverExecAuditLogListOpts) predicates() []*sqlf.Query {
+	preds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if opts.Repo != "" {
+		preds = append(preds, sqlf.Sprintf("repo_name = %s", opts.Repo))
+	}
+	if opts.Actor != "" {
+		preds = append(preds, sqlf.Sprintf("actor = %s", opts.Actor))
+	}
+	if since := opts.Since; since != nil {
+		preds = append(preds, sqlf.Sprintf("created_at >= %s", *since))
+	}
+	if until := opts.Until; until != nil {
+		preds = append(preds, sqlf.Sprintf("created_at <= %s", *until))
+	}
+	return preds
+}
+
+// Count returns the number of audit log entries matching opts, ignoring its
+// limit and cursor.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. It is the
+// callers responsibility to ensure that only site admins can access the audit
+// log.
+func (s *GitserverExecAuditLogStore) Count(ctx context.Context, opts GitserverExecAuditLogListOpts) (int64, error) {
+	count, _, err := basestore.ScanFirstInt64(s.Query(ctx, sqlf.Sprintf(
+		gitserverExecAuditLogsCountQueryFmtstr,
+		sqlf.Join(opts.predicates(), " AND "),
+	)))
+	return count, err
+}
+
+const gitserverExecAuditLogsCountQueryFmtstr = `
+-- source: internal/database/gitserver_exec_audit_logs.go:Count
+SELECT COUNT(id)
+FROM gitserver_exec_audit_logs
+WHERE %s
+`
+
+// List returns the audit log entries matching opts, newest first, and the
+// cursor of the next page, which is zero if there are no more entries.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. It is the
+// callers responsibility to ensure that only site admins can access the audit
+// log.
+func (s *GitserverExecAuditLogStore) List(ctx context.Context, opts GitserverExecAuditLogListOpts) (_ []*types.GitserverExecAuditLog, next int64, err error) {
+	preds := opts.predicates()
+	if cursor := opts.Cursor; cursor != 0 {
+		preds = append(preds, sqlf.Sprintf("id <= %s", cursor))
+	}
+
+	limit := sqlf.Sprintf("")
+	if opts.Limit != 0 {
+		limit = sqlf.Sprintf("LIMIT %s", opts.Limit+1)
+	}
+
+	rows, err := s.Query(ctx, sqlf.Sprintf(
+		gitserverExecAuditLogsListQueryFmtstr,
+		sqlf.Join(preds, " AND "),
+		limit,
+	))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Query")
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	logs := []*types.GitserverExecAuditLog{}
+	for rows.Next() {
+		var (
+			log        types.GitserverExecAuditLog
+			durationMs int64
+		)
+		if err := rows.Scan(
+			&log.ID,
+			&log.Actor,
+			&log.Client,
+			&log.Gitserver,
+			&log.Repo,
+			&log.Command,
+			pq.Array(&log.Args),
+			&log.Status,
+			&log.ExitStatus,
+			&log.Error,
+			&durationMs,
+			&log.CreatedAt,
+		); err != nil {
+			return nil, 0, errors.Wrap(err, "Scan")
+		}
+		log.Duration = time.Duration(durationMs) * time.Millisecond
+		logs = append(logs, &log)
+	}
+
+	if opts.Limit != 0 && len(logs) == opts.Limit+1 {
+		next = logs[len(logs)-1].ID
+		logs = logs[:len(logs)-1]
+	}
+	return logs, next, nil
+}
+
+const gitserverExecAuditLogsListQueryFmtstr = `
+-- source: internal/database/gitserver_exec_audit_logs.go:List
+SELECT id, actor, client, gitserver, repo_name, command, args, status, exit_status, error, duration_ms, created_at
+FROM gitserver_exec_audit_logs
+WHERE %s
+ORDER BY id DESC
+%s -- LIMIT
+`
+
+// DeleteStale removes the audit log entries older than retention.
+func (s *GitserverExecAuditLogStore) DeleteStale(ctx context.Context, retention time.Duration) error {
+	return s.Exec(ctx, sqlf.Sprintf(gitserverExecAuditLogsDeleteStaleQueryFmtstr, timeutil.Now().Add(-retention)))
+}
+
+const gitserverExecAuditLogsDeleteStaleQueryFmtstr = `
+-- source: internal/database/gitserver_exec_audit_logs.go:DeleteStale
+DELETE FROM gitserver_exec_audit_logs
+WHERE created_at <= %s
+`
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestGitserverExecAuditLogs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Parallel()
+	db := dbtest.NewDB(t)
+	ctx := context.Background()
+	store := GitserverExecAuditLogs(db)
+
+	now := time.Now().Truncate(time.Millisecond)
+	exitStatus := 128
+	errorMessage := "exit status 128"
+	logs := []*types.GitserverExecAuditLog{
+		{
+			Actor:     "internal",
+			Client:    "searcher",
+			Gitserver: "gitserver-0",
+			Repo:      "github.com/foo/bar",
+			Command:   "rev-parse",
+			Args:      []string{"rev-parse", "HEAD"},
+			Status:    "0",
+			Duration:  20 * time.Millisecond,
+			CreatedAt: now.Add(-48 * time.Hour),
+		},
+		{
+			Actor:      "1",
+			Client:     "frontend",
+			Gitserver:  "gitserver-0",
+			Repo:       "github.com/foo/bar",
+			Command:    "log",
+			Args:       []string{"log", "-n", "1"},
+			Status:     "128",
+			ExitStatus: &exitStatus,
+			Error:      &errorMessage,
+			Duration:   time.Second,
+			CreatedAt:  now.Add(-time.Hour),
+		},
+		{
+			Actor:     "0",
+			Client:    "frontend",
+			Gitserver: "gitserver-1",
+			Repo:      "github.com/foo/baz",
+			Command:   "show",
+			Args:      []string{"show"},
+			Status:    "repo-not-found",
+		},
+	}
+	if err := store.Create(ctx, logs...); err != nil {
+		t.Fatal(err)
+	}
+	for _, log := range logs {
+		if log.ID == 0 || log.CreatedAt.IsZero() {
+			t.Fatalf("expected ID and creation time to be set: %+v", log)
+		}
+	}
+
+	list := func(opts GitserverExecAuditLogListOpts) ([]*types.GitserverExecAuditLog, int64) {
+		t.Helper()
+		have, next, err := store.List(ctx, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count, err := store.Count(ctx, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opts.Limit == 0 && count != int64(len(have)) {
+			t.Fatalf("unexpected count: want=%d have=%d", len(have), count)
+		}
+		return have, next
+	}
+	timeComparer := cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })
+
+	have, next := list(GitserverExecAuditLogListOpts{})
+	if diff := cmp.Diff([]*types.GitserverExecAuditLog{logs[2], logs[1], logs[0]}, have, timeComparer); diff != "" {
+		t.Fatalf("unexpected logs (-want +have):\n%s", diff)
+	}
+	if next != 0 {
+		t.Fatalf("unexpected next cursor %d", next)
+	}
+
+	have, next = list(GitserverExecAuditLogListOpts{Limit: 1, Cursor: logs[1].ID})
+	if diff := cmp.Diff([]*types.GitserverExecAuditLog{logs[1]}, have, timeComparer); diff != "" {
+		t.Fatalf("unexpected logs (-want +have):\n%s", diff)
+	}
+	if next != logs[0].ID {
+		t.Fatalf("unexpected next cursor: want=%d have=%d", logs[0].ID, next)
+	}
+
+	since := now.Add(-2 * time.Hour)
+	for _, test := range []struct {
+		opts GitserverExecAuditLogListOpts
+		want []*types.GitserverExecAuditLog
+	}{
+		{GitserverExecAuditLogListOpts{Repo: "github.com/foo/bar"}, []*types.GitserverExecAuditLog{logs[1], logs[0]}},
+		{GitserverExecAuditLogListOpts{Actor: "internal"}, []*types.GitserverExecAuditLog{logs[0]}},
+		{GitserverExecAuditLogListOpts{Since: &since}, []*types.GitserverExecAuditLog{logs[2], logs[1]}},
+		{GitserverExecAuditLogListOpts{Until: &since}, []*types.GitserverExecAuditLog{logs[0]}},
+	} {
+		have, _ := list(test.opts)
+		if diff := cmp.Diff(test.want, have, timeComparer, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("unexpected logs for %+v (-want +have):\n%s", test.opts, diff)
+		}
+	}
+
+	if err := store.DeleteStale(ctx, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	have, _ = list(GitserverExecAuditLogListOpts{})
+	if diff := cmp.Diff([]*types.GitserverExecAuditLog{logs[2], logs[1]}, have, timeComparer); diff != "" {
+		t.Fatalf("unexpected logs after deleting stale logs (-want +have):\n%s", diff)
+	}
+}
@@ -198,6 +198,9 @@ SELECT
        last_error,
        last_fetched,
        last_changed,
+       last_fsck,
+       corrupted_at,
+       corruption_log,
        updated_at
 FROM gitserver_repos
 WHERE repo_id = %s
@@ -217,6 +220,9 @@ WHERE repo_id = %s
 		&dbutil.NullString{S: &gr.LastError},
 		&dbutil.NullTime{Time: &gr.LastFetched},
 		&dbutil.NullTime{Time: &gr.LastChanged},
+		&dbutil.NullTime{Time: &gr.LastFsck},
+		&dbutil.NullTime{Time: &gr.CorruptedAt},
+		&dbutil.NullString{S: &gr.CorruptionLog},
 		&gr.UpdatedAt,
 	)
 	if err != nil {
@@ -294,6 +300,27 @@ SET (last_fetched, last_changed, shard_id, updated_at) =
 	return errors.Wrap(err, "setting last fetched")
 }
 
+// SetFsckResult will attempt to update ONLY the integrity check data of a
+// GitServerRepo. An empty corruptionLog means the check succeeded, which clears
+// any earlier corruption. If a matching row does not yet exist a new one will
+// be created.
+func (s *GitserverRepoStore) SetFsckResult(ctx context.Context, name api.RepoName, corruptionLog, shardID string) error {
+	ns := dbutil.NewNullString(sanitizeToUTF8(corruptionLog))
+
+	err := s.Exec(ctx, sqlf.Sprintf(`
+-- source: internal/database/gitserver_repos.go:GitserverRepoStore.SetFsckResult
+INSERT INTO gitserver_repos(repo_id, last_fsck, corrupted_at, corruption_log, shard_id, updated_at)
+SELECT id, now(), CASE WHEN %s::text IS NULL THEN NULL ELSE now() END, %s, %s, now()
+FROM repo
+WHERE name = %s
+ON CONFLICT (repo_id) DO UPDATE
+SET (last_fsck, corrupted_at, corruption_log, shard_id, updated_at) =
+    (EXCLUDED.last_fsck, CASE WHEN EXCLUDED.corrupted_at IS NULL THEN NULL ELSE COALESCE(gitserver_repos.corrupted_at, EXCLUDED.corrupted_at) END, EXCLUDED.corruption_log, EXCLUDED.shard_id, now())
+`, ns, ns, shardID, name))
+
+	return errors.Wrap(err, "setting fsck result")
+}
+
 // sanitizeToUTF8 will remove any null character terminated string. The null character can be
 // represented in one of the following ways in Go:
 //
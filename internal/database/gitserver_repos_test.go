@@ -342,6 +342,78 @@ func TestSetLastError(t *testing.T) {
 	}
 }
 
+func TestSetFsckResult(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	db := dbtest.NewDB(t)
+	ctx := context.Background()
+	const shardID = "test"
+
+	repo1 := &types.Repo{
+		Name:         "github.com/sourcegraph/repo1",
+		URI:          "github.com/sourcegraph/repo1",
+		ExternalRepo: api.ExternalRepoSpec{},
+	}
+
+	// Create one test repo
+	err := Repos(db).Create(ctx, repo1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A corrupt repository records when it was first found to be corrupt.
+	err = GitserverRepos(db).SetFsckResult(ctx, repo1.Name, "missing blob 1234\x00", shardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := GitserverRepos(db).GetByID(ctx, repo1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.LastFsck.IsZero() || first.CorruptedAt.IsZero() {
+		t.Fatalf("expected last_fsck and corrupted_at to be set: %+v", first)
+	}
+	if want := "missing blob 1234"; first.CorruptionLog != want {
+		t.Fatalf("unexpected corruption log: want=%q have=%q", want, first.CorruptionLog)
+	}
+
+	err = GitserverRepos(db).SetFsckResult(ctx, repo1.Name, "missing blob 5678", shardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := GitserverRepos(db).GetByID(ctx, repo1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.CorruptedAt.Equal(first.CorruptedAt) {
+		t.Fatalf("expected corrupted_at to be kept: want=%s have=%s", first.CorruptedAt, second.CorruptedAt)
+	}
+	if want := "missing blob 5678"; second.CorruptionLog != want {
+		t.Fatalf("unexpected corruption log: want=%q have=%q", want, second.CorruptionLog)
+	}
+
+	// A successful check clears the corruption.
+	err = GitserverRepos(db).SetFsckResult(ctx, repo1.Name, "", shardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	healthy, err := GitserverRepos(db).GetByID(ctx, repo1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !healthy.CorruptedAt.IsZero() || healthy.CorruptionLog != "" {
+		t.Fatalf("expected corruption to be cleared: %+v", healthy)
+	}
+	if healthy.LastFsck.Before(second.LastFsck) {
+		t.Fatalf("expected last_fsck to advance: before=%s after=%s", second.LastFsck, healthy.LastFsck)
+	}
+}
+
 func TestGitserverRepoUpsertNullShard(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -35,8 +35,12 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/jvmpackages"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/mercurial"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/npmpackages"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/perforce"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/phabricator"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/pythonpackages"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
@@ -495,14 +499,22 @@ func scanRepo(rows *sql.Rows, r *types.Repo) (err error) {
 		r.Metadata = new(awscodecommit.Repository)
 	case extsvc.TypeGitolite:
 		r.Metadata = new(gitolite.Repo)
+	case extsvc.TypeMercurial:
+		r.Metadata = new(mercurial.Repo)
 	case extsvc.TypePerforce:
 		r.Metadata = new(perforce.Depot)
 	case extsvc.TypePhabricator:
 		r.Metadata = new(phabricator.Repo)
+	case extsvc.TypeSubversion:
+		r.Metadata = new(subversion.Repo)
 	case extsvc.TypeOther:
 		r.Metadata = new(extsvc.OtherRepoMetadata)
 	case extsvc.TypeJVMPackages:
 		r.Metadata = new(jvmpackages.Metadata)
+	case extsvc.TypeNPMPackages:
+		r.Metadata = new(npmpackages.Metadata)
+	case extsvc.TypePythonPackages:
+		r.Metadata = new(pythonpackages.Metadata)
 	default:
 		log15.Warn("scanRepo - unknown service type", "typ", typ)
 		return nil
@@ -591,6 +603,15 @@ type ReposListOptions struct {
 	// OnlyArchived excludes non-archived repositories from the list.
 	OnlyArchived bool
 
+	// Topics, if non empty, limits the list to repositories tagged with all of
+	// the given topics on their code host. This is backed by the GitHub
+	// repository topics and GitLab project tags stored in the repo metadata.
+	Topics []string
+
+	// Metadata, if non empty, limits the list to repositories whose code host
+	// metadata matches all of the given filters.
+	Metadata []RepoMetadataFilter
+
 	// NoCloned excludes cloned repositories from the list.
 	NoCloned bool
 
@@ -915,6 +936,16 @@ func (s *repoStore) listSQL(ctx context.Context, opt ReposListOptions) (*sqlf.Qu
 	if opt.OnlyArchived {
 		where = append(where, sqlf.Sprintf("archived"))
 	}
+	for _, topic := range opt.Topics {
+		cond, err := repoTopicCond(topic)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, cond)
+	}
+	for _, filter := range opt.Metadata {
+		where = append(where, repoMetadataCond(filter))
+	}
 	if opt.NoCloned {
 		where = append(where, sqlf.Sprintf("(gr.clone_status = 'not_cloned' OR gr.clone_status IS NULL)"))
 	}
@@ -1542,6 +1573,50 @@ func (s *repoStore) GetFirstRepoNamesByCloneURL(ctx context.Context, cloneURL st
 	return api.RepoName(name), nil
 }
 
+// repoTopicCond returns a condition matching repositories tagged with topic on
+// their code host. GitHub stores topics under RepositoryTopics in the repo
+// metadata, GitLab stores them as the project's tag_list.
+func repoTopicCond(topic string) (*sqlf.Query, error) {
+	githubTopics, err := json.Marshal([]github.RepositoryTopic{{Topic: github.Topic{Name: topic}}})
+	if err != nil {
+		return nil, err
+	}
+	gitlabTags, err := json.Marshal([]string{topic})
+	if err != nil {
+		return nil, err
+	}
+	return sqlf.Sprintf(
+		repoTopicCondFmtstr,
+		extsvc.TypeGitHub, string(githubTopics),
+		extsvc.TypeGitLab, string(gitlabTags),
+	), nil
+}
+
+const repoTopicCondFmtstr = `
+(
+	(repo.external_service_type = %s AND repo.metadata->'RepositoryTopics'->'Nodes' @> %s::jsonb)
+	OR
+	(repo.external_service_type = %s AND repo.metadata->'tag_list' @> %s::jsonb)
+)
+`
+
+// RepoMetadataFilter matches repositories whose code host metadata has the
+// top-level field Key set to a non-null value. If Value is non-nil, the field
+// must also equal it.
+type RepoMetadataFilter struct {
+	Key   string
+	Value *string
+}
+
+// repoMetadataCond returns a condition matching repositories whose metadata
+// matches filter.
+func repoMetadataCond(filter RepoMetadataFilter) *sqlf.Query {
+	if filter.Value == nil {
+		return sqlf.Sprintf("COALESCE(jsonb_typeof(repo.metadata->%s), 'null') <> 'null'", filter.Key)
+	}
+	return sqlf.Sprintf("repo.metadata->>%s = %s", filter.Key, *filter.Value)
+}
+
 func parsePattern(p string, caseSensitive bool) ([]*sqlf.Query, error) {
 	exact, like, pattern, err := parseIncludePattern(p)
 	if err != nil {
@@ -19,6 +19,8 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
@@ -385,6 +387,102 @@ func TestRepos_Create(t *testing.T) {
 	})
 }
 
+func TestRepos_List_topicsAndMetadata(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	db := dbtest.NewDB(t)
+	ctx := actor.WithInternalActor(context.Background())
+
+	svcs := types.MakeExternalServices()
+	if err := ExternalServices(db).Upsert(ctx, svcs...); err != nil {
+		t.Fatalf("Upsert error: %s", err)
+	}
+	msvcs := types.ExternalServicesToMap(svcs)
+
+	githubTopic := types.MakeGithubRepo(msvcs[extsvc.KindGitHub])
+	githubTopic.Metadata = &github.Repository{
+		IsLocked: true,
+		RepositoryTopics: &github.RepositoryTopics{
+			Nodes: []github.RepositoryTopic{{Topic: github.Topic{Name: "go"}}},
+		},
+	}
+
+	githubNoTopic := types.MakeGithubRepo(msvcs[extsvc.KindGitHub])
+	githubNoTopic.Name = "github.com/foo/baz"
+	githubNoTopic.ExternalRepo.ID = "5678"
+	githubNoTopic.Metadata = &github.Repository{}
+
+	gitlabTag := types.MakeGitlabRepo(msvcs[extsvc.KindGitLab])
+	gitlabTag.Metadata = &gitlab.Project{
+		Visibility: gitlab.Internal,
+		TagList:    []string{"go", "search"},
+	}
+
+	if err := Repos(db).Create(ctx, githubTopic, githubNoTopic, gitlabTag); err != nil {
+		t.Fatalf("Create error: %s", err)
+	}
+
+	internal := "internal"
+	locked := "true"
+	tests := []struct {
+		name string
+		opt  ReposListOptions
+		want []api.RepoName
+	}{
+		{
+			name: "topic on github and gitlab",
+			opt:  ReposListOptions{Topics: []string{"go"}},
+			want: []api.RepoName{githubTopic.Name, gitlabTag.Name},
+		},
+		{
+			name: "topic only on gitlab",
+			opt:  ReposListOptions{Topics: []string{"search"}},
+			want: []api.RepoName{gitlabTag.Name},
+		},
+		{
+			name: "all topics must match",
+			opt:  ReposListOptions{Topics: []string{"go", "search"}},
+			want: []api.RepoName{gitlabTag.Name},
+		},
+		{
+			name: "unknown topic",
+			opt:  ReposListOptions{Topics: []string{"rust"}},
+		},
+		{
+			name: "metadata key present",
+			opt:  ReposListOptions{Metadata: []RepoMetadataFilter{{Key: "RepositoryTopics"}}},
+			want: []api.RepoName{githubTopic.Name},
+		},
+		{
+			name: "metadata key and value",
+			opt:  ReposListOptions{Metadata: []RepoMetadataFilter{{Key: "visibility", Value: &internal}}},
+			want: []api.RepoName{gitlabTag.Name},
+		},
+		{
+			name: "metadata boolean value",
+			opt:  ReposListOptions{Metadata: []RepoMetadataFilter{{Key: "IsLocked", Value: &locked}}},
+			want: []api.RepoName{githubTopic.Name},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repos, err := Repos(db).List(ctx, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var have []api.RepoName
+			for _, r := range repos {
+				have = append(have, r.Name)
+			}
+			if diff := cmp.Diff(test.want, have, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("mismatch (-want +have):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestListIndexableRepos(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// maxSavedSearchDeliveries is the number of delivery log entries retained per
+// saved search. Older entries are removed when new ones are created.
+const maxSavedSearchDeliveries = 100
+
+type SavedSearchDeliveryStore struct {
+	*basestore.Store
+}
+
+// SavedSearchDeliveries instantiates and returns a new SavedSearchDeliveryStore with prepared statements.
+func SavedSearchDeliveries(db dbutil.DB) *SavedSearchDeliveryStore {
+	return &SavedSearchDeliveryStore{Store: basestore.NewWithDB(db, sql.TxOptions{})}
+}
+
+// SavedSearchDeliveriesWith instantiates and returns a new SavedSearchDeliveryStore using the other store handle.
+func SavedSearchDeliveriesWith(other basestore.ShareableStore) *SavedSearchDeliveryStore {
+	return &SavedSearchDeliveryStore{Store: basestore.NewWithHandle(other.Handle())}
+}
+
+func (s *SavedSearchDeliveryStore) With(other basestore.ShareableStore) *SavedSearchDeliveryStore {
+	return &SavedSearchDeliveryStore{Store: s.Store.With(other)}
+}
+
+func (s *SavedSearchDeliveryStore) Transact(ctx context.Context) (*SavedSearchDeliveryStore, error) {
+	txBase, err := s.Store.Transact(ctx)
+	return &SavedSearchDeliveryStore{Store: txBase}, err
+}
+
+// Create appends the given delivery to the delivery log of its saved search,
+// pruning the oldest entries beyond the retention limit.
+func (s *SavedSearchDeliveryStore) Create(ctx context.Context, delivery *types.SavedSearchDelivery) (err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = tx.Done(err) }()
+
+	if err := tx.QueryRow(ctx, sqlf.Sprintf(
+		savedSearchDeliveriesCreateQueryFmtstr,
+		delivery.SavedSearchID,
+		delivery.Kind,
+		delivery.Event,
+		delivery.Attempts,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Duration.Milliseconds(),
+	)).Scan(&delivery.ID, &delivery.CreatedAt); err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+
+	if err := tx.Exec(ctx, sqlf.Sprintf(
+		savedSearchDeliveriesPruneQueryFmtstr,
+		delivery.SavedSearchID,
+		delivery.SavedSearchID,
+		maxSavedSearchDeliveries,
+	)); err != nil {
+		return errors.Wrap(err, "DELETE")
+	}
+
+	return nil
+}
+
+const savedSearchDeliveriesCreateQueryFmtstr = `
+-- source: internal/database/saved_search_deliveries.go:Create
+INSERT INTO saved_search_deliveries (saved_search_id, kind, event, attempts, status_code, error, duration_ms)
+VALUES (%s, %s, %s, %s, %s, %s, %s)
+RETURNING id, created_at
+`
+
+const savedSearchDeliveriesPruneQueryFmtstr = `
+-- source: internal/database/saved_search_deliveries.go:Create
+DELETE FROM saved_search_deliveries
+WHERE saved_search_id = %s AND id NOT IN (
+	SELECT id FROM saved_search_deliveries
+	WHERE saved_search_id = %s
+	ORDER BY created_at DESC, id DESC
+	LIMIT %s
+)
+`
+
+// List returns the most recent deliveries for the given saved search, newest
+// first.
+//
+// 🚨 SECURITY: This method does NOT verify the user's identity or that the
+// user is an admin. It is the callers responsibility to ensure that only users
+// with the proper permissions can access the saved search's delivery log.
+func (s *SavedSearchDeliveryStore) List(ctx context.Context, savedSearchID int32, limit int) (_ []*types.SavedSearchDelivery, err error) {
+	rows, err := s.Query(ctx, sqlf.Sprintf(savedSearchDeliveriesListQueryFmtstr, savedSearchID, limit))
+	if err != nil {
+		return nil, errors.Wrap(err, "Query")
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var deliveries []*types.SavedSearchDelivery
+	for rows.Next() {
+		var (
+			d          types.SavedSearchDelivery
+			durationMs int64
+		)
+		if err := rows.Scan(
+			&d.ID,
+			&d.SavedSearchID,
+			&d.Kind,
+			&d.Event,
+			&d.Attempts,
+			&d.StatusCode,
+			&d.Error,
+			&durationMs,
+			&d.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, "Scan")
+		}
+		d.Duration = time.Duration(durationMs) * time.Millisecond
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, nil
+}
+
+const savedSearchDeliveriesListQueryFmtstr = `
+-- source: internal/database/saved_search_deliveries.go:List
+SELECT id, saved_search_id, kind, event, attempts, status_code, error, duration_ms, created_at
+FROM saved_search_deliveries
+WHERE saved_search_id = %s
+ORDER BY created_at DESC, id DESC
+LIMIT %s
+`
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestSavedSearchDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Parallel()
+	db := dbtest.NewDB(t)
+	ctx := context.Background()
+	_, err := Users(db).Create(ctx, NewUser{DisplayName: "test", Email: "test@test.com", Username: "test", Password: "test", EmailVerificationCode: "c2"})
+	if err != nil {
+		t.Fatal("can't create user", err)
+	}
+	webhookURL := "https://example.com/hook"
+	ss, err := SavedSearches(db).Create(ctx, &types.SavedSearch{
+		Query:         "test",
+		Description:   "test",
+		NotifyWebhook: true,
+		UserID:        1,
+		WebhookURL:    &webhookURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusCode := 502
+	errorMessage := "bad gateway"
+	store := SavedSearchDeliveries(db)
+	for i := 0; i < maxSavedSearchDeliveries+1; i++ {
+		delivery := &types.SavedSearchDelivery{
+			SavedSearchID: ss.ID,
+			Kind:          "webhook",
+			Event:         "results",
+			Attempts:      3,
+			StatusCode:    &statusCode,
+			Error:         &errorMessage,
+			Duration:      2 * time.Second,
+		}
+		if err := store.Create(ctx, delivery); err != nil {
+			t.Fatal(err)
+		}
+		if delivery.ID == 0 {
+			t.Fatal("expected ID to be set")
+		}
+	}
+
+	deliveries, err := store.List(ctx, ss.ID, maxSavedSearchDeliveries*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != maxSavedSearchDeliveries {
+		t.Fatalf("unexpected number of deliveries. want=%d have=%d", maxSavedSearchDeliveries, len(deliveries))
+	}
+	if deliveries[0].ID != int64(maxSavedSearchDeliveries+1) {
+		t.Errorf("expected newest delivery first, got %d", deliveries[0].ID)
+	}
+	if d := deliveries[0]; d.Kind != "webhook" || d.Event != "results" || d.Attempts != 3 || *d.StatusCode != statusCode || *d.Error != errorMessage || d.Duration != 2*time.Second {
+		t.Errorf("unexpected delivery %+v", d)
+	}
+
+	if err := SavedSearches(db).Delete(ctx, ss.ID); err != nil {
+		t.Fatal(err)
+	}
+	if deliveries, err := store.List(ctx, ss.ID, 10); err != nil {
+		t.Fatal(err)
+	} else if len(deliveries) != 0 {
+		t.Errorf("expected deliveries to be deleted with the saved search")
+	}
+}
@@ -85,8 +85,11 @@ func (s *savedSearchStore) ListAll(ctx context.Context) (savedSearches []api.Sav
 		query,
 		notify_owner,
 		notify_slack,
+		notify_webhook,
 		user_id,
-		slack_webhook_url FROM saved_searches
+		slack_webhook_url,
+		webhook_url,
+		webhook_secret FROM saved_searches
 	`)
 	rows, err := s.Query(ctx, q)
 	if err != nil {
@@ -101,8 +104,11 @@ func (s *savedSearchStore) ListAll(ctx context.Context) (savedSearches []api.Sav
 			&sq.Config.Query,
 			&sq.Config.Notify,
 			&sq.Config.NotifySlack,
+			&sq.Config.NotifyWebhook,
 			&sq.Config.UserID,
-			&sq.Config.SlackWebhookURL); err != nil {
+			&sq.Config.SlackWebhookURL,
+			&sq.Config.WebhookURL,
+			&sq.Config.WebhookSecret); err != nil {
 			return nil, errors.Wrap(err, "Scan")
 		}
 		sq.Spec.Key = sq.Config.Key
@@ -126,16 +132,22 @@ func (s *savedSearchStore) GetByID(ctx context.Context, id int32) (*api.SavedQue
 		query,
 		notify_owner,
 		notify_slack,
+		notify_webhook,
 		user_id,
-		slack_webhook_url
+		slack_webhook_url,
+		webhook_url,
+		webhook_secret
 		FROM saved_searches WHERE id=$1`, id).Scan(
 		&sq.Config.Key,
 		&sq.Config.Description,
 		&sq.Config.Query,
 		&sq.Config.Notify,
 		&sq.Config.NotifySlack,
+		&sq.Config.NotifyWebhook,
 		&sq.Config.UserID,
-		&sq.Config.SlackWebhookURL)
+		&sq.Config.SlackWebhookURL,
+		&sq.Config.WebhookURL,
+		&sq.Config.WebhookSecret)
 	if err != nil {
 		return nil, err
 	}
@@ -160,8 +172,11 @@ func (s *savedSearchStore) ListSavedSearchesByUserID(ctx context.Context, userID
 		query,
 		notify_owner,
 		notify_slack,
+		notify_webhook,
 		user_id,
-		slack_webhook_url
+		slack_webhook_url,
+		webhook_url,
+		webhook_secret
 		FROM saved_searches %v`, conds)
 
 	rows, err := s.Query(ctx, query)
@@ -170,7 +185,7 @@ func (s *savedSearchStore) ListSavedSearchesByUserID(ctx context.Context, userID
 	}
 	for rows.Next() {
 		var ss types.SavedSearch
-		if err := rows.Scan(&ss.ID, &ss.Description, &ss.Query, &ss.Notify, &ss.NotifySlack, &ss.UserID, &ss.SlackWebhookURL); err != nil {
+		if err := rows.Scan(&ss.ID, &ss.Description, &ss.Query, &ss.Notify, &ss.NotifySlack, &ss.NotifyWebhook, &ss.UserID, &ss.SlackWebhookURL, &ss.WebhookURL, &ss.WebhookSecret); err != nil {
 			return nil, errors.Wrap(err, "Scan(2)")
 		}
 		savedSearches = append(savedSearches, &ss)
@@ -196,11 +211,14 @@ func (s *savedSearchStore) Create(ctx context.Context, newSavedSearch *types.Sav
 	}()
 
 	savedQuery = &types.SavedSearch{
-		Description: newSavedSearch.Description,
-		Query:       newSavedSearch.Query,
-		Notify:      newSavedSearch.Notify,
-		NotifySlack: newSavedSearch.NotifySlack,
-		UserID:      newSavedSearch.UserID,
+		Description:   newSavedSearch.Description,
+		Query:         newSavedSearch.Query,
+		Notify:        newSavedSearch.Notify,
+		NotifySlack:   newSavedSearch.NotifySlack,
+		NotifyWebhook: newSavedSearch.NotifyWebhook,
+		UserID:        newSavedSearch.UserID,
+		WebhookURL:    newSavedSearch.WebhookURL,
+		WebhookSecret: newSavedSearch.WebhookSecret,
 	}
 
 	err = s.Handle().DB().QueryRowContext(ctx, `INSERT INTO saved_searches(
@@ -208,13 +226,19 @@ func (s *savedSearchStore) Create(ctx context.Context, newSavedSearch *types.Sav
 			query,
 			notify_owner,
 			notify_slack,
-			user_id
-		) VALUES($1, $2, $3, $4, $5) RETURNING id`,
+			notify_webhook,
+			user_id,
+			webhook_url,
+			webhook_secret
+		) VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
 		newSavedSearch.Description,
 		savedQuery.Query,
 		newSavedSearch.Notify,
 		newSavedSearch.NotifySlack,
+		newSavedSearch.NotifyWebhook,
 		newSavedSearch.UserID,
+		newSavedSearch.WebhookURL,
+		newSavedSearch.WebhookSecret,
 	).Scan(&savedQuery.ID)
 	if err != nil {
 		return nil, err
@@ -239,8 +263,11 @@ func (s *savedSearchStore) Update(ctx context.Context, savedSearch *types.SavedS
 		Query:           savedSearch.Query,
 		Notify:          savedSearch.Notify,
 		NotifySlack:     savedSearch.NotifySlack,
+		NotifyWebhook:   savedSearch.NotifyWebhook,
 		UserID:          savedSearch.UserID,
 		SlackWebhookURL: savedSearch.SlackWebhookURL,
+		WebhookURL:      savedSearch.WebhookURL,
+		WebhookSecret:   savedSearch.WebhookSecret,
 	}
 
 	fieldUpdates := []*sqlf.Query{
@@ -249,8 +276,11 @@ func (s *savedSearchStore) Update(ctx context.Context, savedSearch *types.SavedS
 		sqlf.Sprintf("query=%s", savedSearch.Query),
 		sqlf.Sprintf("notify_owner=%t", savedSearch.Notify),
 		sqlf.Sprintf("notify_slack=%t", savedSearch.NotifySlack),
+		sqlf.Sprintf("notify_webhook=%t", savedSearch.NotifyWebhook),
 		sqlf.Sprintf("user_id=%v", savedSearch.UserID),
 		sqlf.Sprintf("slack_webhook_url=%v", savedSearch.SlackWebhookURL),
+		sqlf.Sprintf("webhook_url=%v", savedSearch.WebhookURL),
+		sqlf.Sprintf("webhook_secret=%v", savedSearch.WebhookSecret),
 	}
 
 	updateQuery := sqlf.Sprintf(`UPDATE saved_searches SET %s WHERE ID=%v RETURNING id`, sqlf.Join(fieldUpdates, ", "), savedSearch.ID)
@@ -139,6 +139,30 @@ func (s *Store) OpenWithPath(ctx context.Context, key string, fetcher FetcherWit
 	}
 }
 
+// Get opens the file for key if it is in the cache. It never fetches, so if
+// key is missing the returned error satisfies os.IsNotExist.
+func (s *Store) Get(key string) (*File, error) {
+	if s.Dir == "" {
+		return nil, errors.New("diskcache.Store.Dir must be set")
+	}
+
+	path := s.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// Update modified time. Modified time is used to decide which files to
+	// evict from the cache.
+	touch(path)
+	return &File{File: f, Path: path}, nil
+}
+
+// Path returns the path of the file for key, whether or not it is in the
+// cache.
+func (s *Store) Path(key string) string {
+	return s.path(key)
+}
+
 // path returns the path for key.
 func (s *Store) path(key string) string {
 	// path uses a sha256 hash of the key since we want to use it for the
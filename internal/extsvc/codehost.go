@@ -24,10 +24,18 @@ var (
 	MavenURL    = &url.URL{Host: "maven"}
 	JVMPackages = NewCodeHost(MavenURL, TypeJVMPackages)
 
+	NPMURL      = &url.URL{Host: "npm"}
+	NPMPackages = NewCodeHost(NPMURL, TypeNPMPackages)
+
+	PythonURL      = &url.URL{Host: "python"}
+	PythonPackages = NewCodeHost(PythonURL, TypePythonPackages)
+
 	PublicCodeHosts = []*CodeHost{
 		GitHubDotCom,
 		GitLabDotCom,
 		JVMPackages,
+		NPMPackages,
+		PythonPackages,
 	}
 )
 
@@ -1660,6 +1660,26 @@ type Repository struct {
 	// Metadata retained for ranking
 	StargazerCount int `json:",omitempty"`
 	ForkCount      int `json:",omitempty"`
+
+	// RepositoryTopics are the topics applied to the repository, retained for
+	// filtering searches with repo:has.topic().
+	RepositoryTopics *RepositoryTopics `json:",omitempty"`
+}
+
+// RepositoryTopics is the list of topics on a repository, in the shape returned
+// by the GraphQL API.
+type RepositoryTopics struct {
+	Nodes []RepositoryTopic
+}
+
+// RepositoryTopic is a single topic applied to a repository.
+type RepositoryTopic struct {
+	Topic Topic
+}
+
+// Topic is a GitHub topic.
+type Topic struct {
+	Name string
 }
 
 func ownerNameCacheKey(owner, name string) string       { return "0:" + owner + "/" + name }
@@ -1731,6 +1751,7 @@ type restRepository struct {
 	Permissions restRepositoryPermissions `json:"permissions"`
 	Stars       int                       `json:"stargazers_count"`
 	Forks       int                       `json:"forks_count"`
+	Topics      []string                  `json:"topics"`
 }
 
 // getRepositoryFromAPI attempts to fetch a repository from the GitHub API without use of the redis cache.
@@ -1766,7 +1787,21 @@ func convertRestRepo(restRepo restRepository) *Repository {
 		ViewerPermission: convertRestRepoPermissions(restRepo.Permissions),
 		StargazerCount:   restRepo.Stars,
 		ForkCount:        restRepo.Forks,
+		RepositoryTopics: convertRestRepoTopics(restRepo.Topics),
+	}
+}
+
+// convertRestRepoTopics converts the topic names returned by the rest API to
+// the shape returned by the GraphQL API.
+func convertRestRepoTopics(topics []string) *RepositoryTopics {
+	if len(topics) == 0 {
+		return nil
+	}
+	nodes := make([]RepositoryTopic, 0, len(topics))
+	for _, t := range topics {
+		nodes = append(nodes, RepositoryTopic{Topic: Topic{Name: t}})
 	}
+	return &RepositoryTopics{Nodes: nodes}
 }
 
 // convertRestRepoPermissions converts repo information returned by the rest API
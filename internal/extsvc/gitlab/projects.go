@@ -30,6 +30,7 @@ type Project struct {
 	Archived          bool           `json:"archived"`
 	StarCount         int            `json:"star_count"`
 	ForksCount        int            `json:"forks_count"`
+	TagList           []string       `json:"tag_list,omitempty"` // topics applied to the project
 }
 
 type ProjectCommon struct {
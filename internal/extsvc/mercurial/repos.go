@@ -0,0 +1,8 @@
+package mercurial
+
+// Repo contains information of a Mercurial repository.
+type Repo struct {
+	// RelativePath is the path of the repository relative to the URL of the
+	// Mercurial server.
+	RelativePath string `json:"relativePath"`
+}
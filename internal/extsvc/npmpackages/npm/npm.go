@@ -0,0 +1,174 @@
+// Package npm is a client for the npm registry API, which is used to resolve
+// and download the tarballs of published npm packages.
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// DefaultRegistry is the registry used when the connection does not configure
+// one.
+const DefaultRegistry = "https://registry.npmjs.org"
+
+var (
+	observationContext *observation.Context
+	operations         *Operations
+	requestTimeout, _  = time.ParseDuration(env.Get("SRC_NPM_TIMEOUT", "2m", "Time limit per request to the npm registry, which is used to resolve JavaScript/TypeScript dependencies."))
+
+	// client is deliberately not the shared external client because that
+	// client caches responses in Redis, which is not where tarballs belong.
+	client, _ = httpcli.NewFactory(
+		httpcli.NewMiddleware(httpcli.ContextErrorMiddleware),
+		httpcli.ExternalTransportOpt,
+		httpcli.TracedTransportOpt,
+	).Doer()
+)
+
+func init() {
+	observationContext = &observation.Context{
+		Logger:     log15.Root(),
+		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		Registerer: prometheus.DefaultRegisterer,
+	}
+	operations = NewOperations(observationContext)
+}
+
+// versionInfo is the subset of the registry's version document that we need.
+// See https://github.com/npm/registry/blob/master/docs/responses/package-metadata.md.
+type versionInfo struct {
+	Dist struct {
+		Tarball string `json:"tarball"`
+	} `json:"dist"`
+}
+
+// FetchSources downloads the tarball of the given dependency. The caller must
+// close the returned reader. The tarball is a gzipped tar archive whose
+// entries are usually nested under a "package/" directory.
+func FetchSources(ctx context.Context, config *schema.NPMPackagesConnection, dependency reposource.NPMDependency) (tarball io.ReadCloser, err error) {
+	ctx, endObservation := operations.fetchSources.With(ctx, &err, observation.Args{LogFields: []otlog.Field{
+		otlog.String("dependency", dependency.PackageManagerSyntax()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	info, err := fetchVersionInfo(ctx, config, dependency)
+	if err != nil {
+		return nil, err
+	}
+	if info.Dist.Tarball == "" {
+		return nil, errors.Errorf("no tarball for dependency %s", dependency.PackageManagerSyntax())
+	}
+
+	// The tarball URL comes from the registry response and may point at any
+	// host, so only hand it the credentials if it is the registry itself.
+	var credentials string
+	if sameOrigin(registryURL(config), info.Dist.Tarball) {
+		credentials = config.Credentials
+	}
+	body, err := get(ctx, credentials, info.Dist.Tarball)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download tarball for dependency %s", dependency.PackageManagerSyntax())
+	}
+	return body, nil
+}
+
+// Exists returns true if the registry knows about the given version of the
+// dependency.
+func Exists(ctx context.Context, config *schema.NPMPackagesConnection, dependency reposource.NPMDependency) (exists bool, err error) {
+	ctx, endObservation := operations.exists.With(ctx, &err, observation.Args{LogFields: []otlog.Field{
+		otlog.String("dependency", dependency.PackageManagerSyntax()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	_, err = fetchVersionInfo(ctx, config, dependency)
+	return err == nil, err
+}
+
+func fetchVersionInfo(ctx context.Context, config *schema.NPMPackagesConnection, dependency reposource.NPMDependency) (*versionInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	// Scoped packages keep the `@`, but the slash between scope and name is
+	// escaped, which is the form every registry implementation accepts.
+	u := fmt.Sprintf("%s/%s/%s",
+		strings.TrimSuffix(registryURL(config), "/"),
+		strings.Replace(dependency.PackageSyntax(), "/", "%2f", 1),
+		url.PathEscape(dependency.Version),
+	)
+
+	body, err := get(ctx, config.Credentials, u)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var info versionInfo
+	if err := json.NewDecoder(body).Decode(&info); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode version info for dependency %s", dependency.PackageManagerSyntax())
+	}
+	return &info, nil
+}
+
+func registryURL(config *schema.NPMPackagesConnection) string {
+	if config.Registry != "" {
+		return config.Registry
+	}
+	return DefaultRegistry
+}
+
+// sameOrigin returns true if both URLs have the same scheme and host.
+func sameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// get fetches u, authenticating with credentials if they are non-empty.
+func get(ctx context.Context, credentials, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if credentials != "" {
+		req.Header.Set("Authorization", "Bearer "+credentials)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.Errorf("%s not found", u)
+		}
+		bs, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("unexpected status code %d from %s: %s", resp.StatusCode, u, bs)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,84 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestFetchSourcesCredentials(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		authHeaders = map[string]string{}
+	)
+	record := func(server string, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		authHeaders[server] = r.Header.Get("Authorization")
+	}
+
+	tarballs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record("tarballs", r)
+		_, _ = w.Write([]byte("tarball"))
+	}))
+	t.Cleanup(tarballs.Close)
+
+	var registry *httptest.Server
+	registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/tarball" {
+			record("registry-tarball", r)
+			_, _ = w.Write([]byte("tarball"))
+			return
+		}
+		record("registry", r)
+		tarball := tarballs.URL + "/left-pad-1.3.0.tgz"
+		if r.URL.Path == "/same-host/1.0.0" {
+			tarball = registry.URL + "/-/tarball"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"dist": map[string]string{"tarball": tarball},
+		})
+	}))
+	t.Cleanup(registry.Close)
+
+	config := &schema.NPMPackagesConnection{
+		Registry:    registry.URL,
+		Credentials: "secret",
+	}
+
+	fetch := func(dependency string) {
+		t.Helper()
+		dep, err := reposource.ParseNPMDependency(dependency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := FetchSources(context.Background(), config, dep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer body.Close()
+		if _, err := io.ReadAll(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fetch("left-pad@1.3.0")
+	if have, want := authHeaders["registry"], "Bearer secret"; have != want {
+		t.Errorf("registry Authorization header: have %q, want %q", have, want)
+	}
+	if have := authHeaders["tarballs"]; have != "" {
+		t.Errorf("foreign tarball host received Authorization header %q", have)
+	}
+
+	fetch("same-host@1.0.0")
+	if have, want := authHeaders["registry-tarball"], "Bearer secret"; have != want {
+		t.Errorf("registry tarball Authorization header: have %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,101 @@
+// Package npmtest provides a local stand-in for an npm registry, for use in
+// tests that would otherwise need network access.
+package npmtest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// NewRegistry starts a server that answers version and tarball requests the
+// way the npm registry does. tarballs maps a dependency in "name@version"
+// syntax, for example "@types/node@16.0.0", to the contents of its tarball.
+// The server is closed when the test finishes.
+func NewRegistry(t testing.TB, tarballs map[string][]byte) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if strings.HasPrefix(path, "-/tarballs/") {
+			tarball, ok := tarballs[strings.TrimPrefix(path, "-/tarballs/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(tarball)
+			return
+		}
+
+		// Requests for version documents have the form NAME/VERSION, where
+		// the name of a scoped package contains a slash itself.
+		index := strings.LastIndex(path, "/")
+		if index < 0 {
+			http.NotFound(w, r)
+			return
+		}
+		dependency := path[:index] + "@" + path[index+1:]
+		if _, ok := tarballs[dependency]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"dist": map[string]string{
+				"tarball": server.URL + "/-/tarballs/" + dependency,
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Tarball returns a gzipped tar archive that contains the given files nested
+// under a "package/" directory, like the tarballs produced by `npm pack`.
+func Tarball(t testing.TB, files map[string]string) []byte {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range names {
+		contents := files[name]
+		header := &tar.Header{
+			Name:     "package/" + name,
+			Mode:     0644,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,259 @@
+// Package pypi is a client for PEP 503 simple repository APIs, such as the
+// one served by https://pypi.org/simple, which is used to resolve and
+// download the distributions of published Python packages.
+package pypi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/html"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// DefaultURL is the index used when the connection does not configure any.
+const DefaultURL = "https://pypi.org/simple"
+
+var (
+	observationContext *observation.Context
+	operations         *Operations
+	requestTimeout, _  = time.ParseDuration(env.Get("SRC_PYPI_TIMEOUT", "2m", "Time limit per request to a Python package index, which is used to resolve Python dependencies."))
+
+	// client is deliberately not the shared external client because that
+	// client caches responses in Redis, which is not where archives belong.
+	client, _ = httpcli.NewFactory(
+		httpcli.NewMiddleware(httpcli.ContextErrorMiddleware),
+		httpcli.ExternalTransportOpt,
+		httpcli.TracedTransportOpt,
+	).Doer()
+)
+
+func init() {
+	observationContext = &observation.Context{
+		Logger:     log15.Root(),
+		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		Registerer: prometheus.DefaultRegisterer,
+	}
+	operations = NewOperations(observationContext)
+}
+
+// File is a distribution of a project listed by a simple repository API.
+type File struct {
+	Name string
+	URL  string
+}
+
+// IsWheel returns true if f is a built distribution. Wheels are zip archives
+// with the package contents at the root.
+func (f File) IsWheel() bool {
+	return strings.HasSuffix(f.Name, ".whl")
+}
+
+// IsSdist returns true if f is a source distribution in the gzipped tarball
+// format. The contents of sdists are nested under a "name-version/" directory.
+func (f File) IsSdist() bool {
+	return strings.HasSuffix(f.Name, ".tar.gz")
+}
+
+// FetchSources downloads the preferred distribution of the given dependency.
+// Source distributions are preferred over wheels because they usually contain
+// more of the original sources. The caller must close the returned reader.
+func FetchSources(ctx context.Context, config *schema.PythonPackagesConnection, dependency reposource.PythonDependency) (_ File, _ io.ReadCloser, err error) {
+	ctx, endObservation := operations.fetchSources.With(ctx, &err, observation.Args{LogFields: []otlog.Field{
+		otlog.String("dependency", dependency.PackageManagerSyntax()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	file, err := findFile(ctx, config, dependency)
+	if err != nil {
+		return File{}, nil, err
+	}
+
+	body, err := get(ctx, file.URL)
+	if err != nil {
+		return File{}, nil, errors.Wrapf(err, "failed to download %s for dependency %s", file.Name, dependency.PackageManagerSyntax())
+	}
+	return file, body, nil
+}
+
+// Exists returns true if one of the configured indexes has a distribution for
+// the given version of the dependency.
+func Exists(ctx context.Context, config *schema.PythonPackagesConnection, dependency reposource.PythonDependency) (exists bool, err error) {
+	ctx, endObservation := operations.exists.With(ctx, &err, observation.Args{LogFields: []otlog.Field{
+		otlog.String("dependency", dependency.PackageManagerSyntax()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	_, err = findFile(ctx, config, dependency)
+	return err == nil, err
+}
+
+// findFile returns the preferred distribution of dependency from the first
+// index that has one.
+func findFile(ctx context.Context, config *schema.PythonPackagesConnection, dependency reposource.PythonDependency) (File, error) {
+	urls := config.Urls
+	if len(urls) == 0 {
+		urls = []string{DefaultURL}
+	}
+
+	var lastErr error
+	for _, index := range urls {
+		files, err := ListFiles(ctx, index, dependency.PythonPackage)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if file, ok := preferredFile(files, dependency); ok {
+			return file, nil
+		}
+	}
+	if lastErr != nil {
+		return File{}, lastErr
+	}
+	return File{}, errors.Errorf("distribution for dependency %s not found", dependency.PackageManagerSyntax())
+}
+
+// ListFiles returns all distributions listed on the project page of pkg on
+// the given index.
+func ListFiles(ctx context.Context, index string, pkg reposource.PythonPackage) ([]File, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	pageURL := strings.TrimSuffix(index, "/") + "/" + url.PathEscape(pkg.Name) + "/"
+	body, err := get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return parseProjectPage(pageURL, body)
+}
+
+// parseProjectPage returns the anchors of a PEP 503 project page. Relative
+// links are resolved against pageURL and URL fragments (hashes) are dropped.
+func parseProjectPage(pageURL string, r io.Reader) ([]File, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, err
+			}
+			return files, nil
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			if token.Data != "a" {
+				continue
+			}
+			for _, attr := range token.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				href, err := base.Parse(attr.Val)
+				if err != nil {
+					continue
+				}
+				href.Fragment = ""
+				files = append(files, File{Name: path.Base(href.Path), URL: href.String()})
+			}
+		}
+	}
+}
+
+// preferredFile returns the sdist of dependency if there is one, and
+// otherwise its first wheel.
+func preferredFile(files []File, dependency reposource.PythonDependency) (File, bool) {
+	var wheel *File
+	for i, file := range files {
+		name, version, ok := parseFileName(file)
+		if !ok || reposource.NormalizePythonPackageName(name) != dependency.Name || version != dependency.Version {
+			continue
+		}
+		if file.IsSdist() {
+			return file, true
+		}
+		if wheel == nil {
+			wheel = &files[i]
+		}
+	}
+	if wheel != nil {
+		return *wheel, true
+	}
+	return File{}, false
+}
+
+// parseFileName returns the project name and version encoded in the name of
+// a distribution. Wheel names have the form
+// "{name}-{version}(-{build})?-{python}-{abi}-{platform}.whl", where dashes in
+// the name are escaped as underscores, and sdist names have the form
+// "{name}-{version}.tar.gz".
+func parseFileName(file File) (name, version string, ok bool) {
+	switch {
+	case file.IsWheel():
+		parts := strings.Split(strings.TrimSuffix(file.Name, ".whl"), "-")
+		if len(parts) < 5 {
+			return "", "", false
+		}
+		return parts[0], parts[1], true
+	case file.IsSdist():
+		stem := strings.TrimSuffix(file.Name, ".tar.gz")
+		i := strings.LastIndex(stem, "-")
+		if i <= 0 {
+			return "", "", false
+		}
+		return stem[:i], stem[i+1:], true
+	}
+	return "", "", false
+}
+
+func get(ctx context.Context, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.Errorf("%s not found", redact(u))
+		}
+		bs, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("unexpected status code %d from %s: %s", resp.StatusCode, redact(u), bs)
+	}
+	return resp.Body, nil
+}
+
+// redact removes credentials from an index URL so that it can be logged.
+func redact(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,94 @@
+package pypi
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/pythonpackages/pypi/pypitest"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestParseProjectPage(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Links for requests</h1>
+    <a href="../../packages/ab/cd/requests-2.26.0-py2.py3-none-any.whl#sha256=6c1246513ecd5ecd4528a0906f910e8f0f9c6b8ec72030dc9fd154dc1a6efd24" data-requires-python="&gt;=3.6">requests-2.26.0-py2.py3-none-any.whl</a><br/>
+    <a href="https://files.example.com/requests-2.26.0.tar.gz">requests-2.26.0.tar.gz</a><br/>
+  </body>
+</html>`
+
+	files, err := parseProjectPage("https://pypi.org/simple/requests/", strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []File{
+		{Name: "requests-2.26.0-py2.py3-none-any.whl", URL: "https://pypi.org/packages/ab/cd/requests-2.26.0-py2.py3-none-any.whl"},
+		{Name: "requests-2.26.0.tar.gz", URL: "https://files.example.com/requests-2.26.0.tar.gz"},
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestPreferredFile(t *testing.T) {
+	files := []File{
+		{Name: "zope.interface-5.4.0-cp39-cp39-manylinux2010_x86_64.whl"},
+		{Name: "zope.interface-5.3.0.tar.gz"},
+		{Name: "zope.interface-5.4.0.tar.gz"},
+	}
+	dependency, err := reposource.ParsePythonDependency("zope-interface==5.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, ok := preferredFile(files, dependency)
+	if !ok || file.Name != "zope.interface-5.4.0.tar.gz" {
+		t.Fatalf("expected sdist to be preferred, got %v", file)
+	}
+
+	file, ok = preferredFile(files[:1], dependency)
+	if !ok || file.Name != files[0].Name {
+		t.Fatalf("expected wheel as a fallback, got %v", file)
+	}
+
+	dependency.Version = "6.0.0"
+	if _, ok := preferredFile(files, dependency); ok {
+		t.Fatal("expected no file for unknown version")
+	}
+}
+
+func TestFetchSources(t *testing.T) {
+	sdist := pypitest.Sdist(t, "example", "1.0.0", map[string]string{"example.py": "x = 1\n"})
+	index := pypitest.NewIndex(t, map[string][]byte{"example-1.0.0.tar.gz": sdist})
+	config := &schema.PythonPackagesConnection{Urls: []string{index.URL + "/missing", index.URL}}
+
+	dependency, err := reposource.ParsePythonDependency("example==1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, archive, err := FetchSources(context.Background(), config, dependency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	contents, err := io.ReadAll(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name != "example-1.0.0.tar.gz" || string(contents) != string(sdist) {
+		t.Fatalf("unexpected download %s", file.Name)
+	}
+
+	dependency.Version = "2.0.0"
+	if exists, _ := Exists(context.Background(), config, dependency); exists {
+		t.Fatal("expected unknown version to not exist")
+	}
+}
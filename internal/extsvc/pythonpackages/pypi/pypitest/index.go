@@ -0,0 +1,140 @@
+// Package pypitest provides a local stand-in for a PEP 503 simple repository
+// API, for use in tests that would otherwise need network access.
+package pypitest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+)
+
+// NewIndex starts a server that serves project pages and distributions the
+// way https://pypi.org/simple does. files maps the name of a distribution, for
+// example "requests-2.26.0.tar.gz", to its contents. The server is closed
+// when the test finishes.
+func NewIndex(t testing.TB, files map[string][]byte) *httptest.Server {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/files/") {
+			contents, ok := files[strings.TrimPrefix(r.URL.Path, "/files/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(contents)
+			return
+		}
+
+		project := strings.Trim(r.URL.Path, "/")
+		var page strings.Builder
+		page.WriteString("<!DOCTYPE html>\n<html><body>\n")
+		found := false
+		for _, name := range names {
+			if reposource.NormalizePythonPackageName(projectOf(name)) != project {
+				continue
+			}
+			found = true
+			// Links are relative and carry a hash fragment, like on PyPI.
+			fmt.Fprintf(&page, "<a href=\"../files/%s#sha256=0\">%s</a><br/>\n", name, name)
+		}
+		page.WriteString("</body></html>\n")
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(page.String()))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// projectOf returns the project part of a distribution file name.
+func projectOf(name string) string {
+	if strings.HasSuffix(name, ".whl") {
+		return strings.SplitN(name, "-", 2)[0]
+	}
+	stem := strings.TrimSuffix(name, ".tar.gz")
+	if i := strings.LastIndex(stem, "-"); i > 0 {
+		return stem[:i]
+	}
+	return stem
+}
+
+// Sdist returns a gzipped tarball that contains the given files nested under
+// a "name-version/" directory, like the archives produced by `python setup.py sdist`.
+func Sdist(t testing.TB, name, version string, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, path := range sortedKeys(files) {
+		contents := files[path]
+		header := &tar.Header{
+			Name:     name + "-" + version + "/" + path,
+			Mode:     0644,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Wheel returns a zip archive that contains the given files at its root, like
+// a wheel.
+func Wheel(t testing.TB, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, path := range sortedKeys(files) {
+		w, err := zipWriter.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[path])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
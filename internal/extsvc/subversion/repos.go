@@ -0,0 +1,8 @@
+package subversion
+
+// Repo contains information of a Subversion repository.
+type Repo struct {
+	// RelativePath is the path of the repository relative to the URL of the
+	// Subversion server.
+	RelativePath string `json:"relativePath"`
+}
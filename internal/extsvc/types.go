@@ -80,9 +80,13 @@ const (
 	KindGitHub          = "GITHUB"
 	KindGitLab          = "GITLAB"
 	KindGitolite        = "GITOLITE"
+	KindMercurial       = "MERCURIAL"
 	KindPerforce        = "PERFORCE"
 	KindPhabricator     = "PHABRICATOR"
+	KindSubversion      = "SUBVERSION"
 	KindJVMPackages     = "JVMPACKAGES"
+	KindNPMPackages     = "NPMPACKAGES"
+	KindPythonPackages  = "PYTHONPACKAGES"
 	KindOther           = "OTHER"
 )
 
@@ -113,15 +117,29 @@ const (
 	// TypeGitolite is the (api.ExternalRepoSpec).ServiceType value for Gitolite projects.
 	TypeGitolite = "gitolite"
 
+	// TypeMercurial is the (api.ExternalRepoSpec).ServiceType value for Mercurial repositories. The
+	// ServiceID value is the base URL to the Mercurial server.
+	TypeMercurial = "mercurial"
+
 	// TypePerforce is the (api.ExternalRepoSpec).ServiceType value for Perforce projects.
 	TypePerforce = "perforce"
 
 	// TypePhabricator is the (api.ExternalRepoSpec).ServiceType value for Phabricator projects.
 	TypePhabricator = "phabricator"
 
+	// TypeSubversion is the (api.ExternalRepoSpec).ServiceType value for Subversion repositories. The
+	// ServiceID value is the base URL to the Subversion server.
+	TypeSubversion = "subversion"
+
 	// TypeJVMPackages is the (api.ExternalRepoSpec).ServiceType value for Maven packages (Java/JVM ecosystem libraries).
 	TypeJVMPackages = "jvmPackages"
 
+	// TypeNPMPackages is the (api.ExternalRepoSpec).ServiceType value for npm packages (JavaScript/TypeScript ecosystem libraries).
+	TypeNPMPackages = "npmPackages"
+
+	// TypePythonPackages is the (api.ExternalRepoSpec).ServiceType value for Python packages published to PyPI compatible indexes.
+	TypePythonPackages = "pythonPackages"
+
 	// TypeOther is the (api.ExternalRepoSpec).ServiceType value for other projects.
 	TypeOther = "other"
 
@@ -144,12 +162,20 @@ func KindToType(kind string) string {
 		return TypeGitLab
 	case KindGitolite:
 		return TypeGitolite
+	case KindMercurial:
+		return TypeMercurial
 	case KindPhabricator:
 		return TypePhabricator
 	case KindPerforce:
 		return TypePerforce
+	case KindSubversion:
+		return TypeSubversion
 	case KindJVMPackages:
 		return TypeJVMPackages
+	case KindNPMPackages:
+		return TypeNPMPackages
+	case KindPythonPackages:
+		return TypePythonPackages
 	case KindOther:
 		return TypeOther
 	default:
@@ -173,12 +199,20 @@ func TypeToKind(t string) string {
 		return KindGitLab
 	case TypeGitolite:
 		return KindGitolite
+	case TypeMercurial:
+		return KindMercurial
 	case TypePerforce:
 		return KindPerforce
 	case TypePhabricator:
 		return KindPhabricator
+	case TypeSubversion:
+		return KindSubversion
 	case TypeJVMPackages:
 		return KindJVMPackages
+	case TypeNPMPackages:
+		return KindNPMPackages
+	case TypePythonPackages:
+		return KindPythonPackages
 	case TypeOther:
 		return KindOther
 	default:
@@ -191,6 +225,8 @@ var (
 	bbsLower = strings.ToLower(TypeBitbucketServer)
 	bbcLower = strings.ToLower(TypeBitbucketCloud)
 	jvmLower = strings.ToLower(TypeJVMPackages)
+	npmLower = strings.ToLower(TypeNPMPackages)
+	pyLower  = strings.ToLower(TypePythonPackages)
 )
 
 // ParseServiceType will return a ServiceType constant after doing a case insensitive match on s.
@@ -209,12 +245,20 @@ func ParseServiceType(s string) (string, bool) {
 		return TypeGitLab, true
 	case TypeGitolite:
 		return TypeGitolite, true
+	case TypeMercurial:
+		return TypeMercurial, true
 	case TypePerforce:
 		return TypePerforce, true
 	case TypePhabricator:
 		return TypePhabricator, true
+	case TypeSubversion:
+		return TypeSubversion, true
 	case jvmLower:
 		return TypeJVMPackages, true
+	case npmLower:
+		return TypeNPMPackages, true
+	case pyLower:
+		return TypePythonPackages, true
 	case TypeOther:
 		return TypeOther, true
 	default:
@@ -238,12 +282,20 @@ func ParseServiceKind(s string) (string, bool) {
 		return KindGitLab, true
 	case KindGitolite:
 		return KindGitolite, true
+	case KindMercurial:
+		return KindMercurial, true
 	case KindPerforce:
 		return KindPerforce, true
 	case KindPhabricator:
 		return KindPhabricator, true
+	case KindSubversion:
+		return KindSubversion, true
 	case KindJVMPackages:
 		return KindJVMPackages, true
+	case KindNPMPackages:
+		return KindNPMPackages, true
+	case KindPythonPackages:
+		return KindPythonPackages, true
 	case KindOther:
 		return KindOther, true
 	default:
@@ -279,12 +331,20 @@ func ParseConfig(kind, config string) (cfg interface{}, _ error) {
 		cfg = &schema.GitLabConnection{}
 	case KindGitolite:
 		cfg = &schema.GitoliteConnection{}
+	case KindMercurial:
+		cfg = &schema.MercurialConnection{}
 	case KindPerforce:
 		cfg = &schema.PerforceConnection{}
 	case KindPhabricator:
 		cfg = &schema.PhabricatorConnection{}
+	case KindSubversion:
+		cfg = &schema.SubversionConnection{}
 	case KindJVMPackages:
 		cfg = &schema.JVMPackagesConnection{}
+	case KindNPMPackages:
+		cfg = &schema.NPMPackagesConnection{}
+	case KindPythonPackages:
+		cfg = &schema.PythonPackagesConnection{}
 	case KindOther:
 		cfg = &schema.OtherExternalServiceConnection{}
 	default:
@@ -410,6 +470,20 @@ func GetLimitFromConfig(kind string, config interface{}) (rlc RateLimitConfig, e
 			rlc.IsDefault = false
 		}
 		rlc.BaseURL = "maven"
+	case *schema.NPMPackagesConnection:
+		rlc.Limit = defaultRateLimit
+		if c != nil && c.RateLimit != nil {
+			rlc.Limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
+			rlc.IsDefault = false
+		}
+		rlc.BaseURL = "npm"
+	case *schema.PythonPackagesConnection:
+		rlc.Limit = defaultRateLimit
+		if c != nil && c.RateLimit != nil {
+			rlc.Limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
+			rlc.IsDefault = false
+		}
+		rlc.BaseURL = "python"
 	default:
 		return rlc, ErrRateLimitUnsupported{codehostKind: kind}
 	}
@@ -498,6 +572,10 @@ func UniqueCodeHostIdentifier(kind, config string) (string, error) {
 		rawURL = c.Url
 	case *schema.GitoliteConnection:
 		rawURL = c.Host
+	case *schema.MercurialConnection:
+		rawURL = c.Url
+	case *schema.SubversionConnection:
+		rawURL = c.Url
 	case *schema.AWSCodeCommitConnection:
 		// AWS Code Commit does not have a URL in the config, so we return a
 		// unique string here and return early:
@@ -507,6 +585,10 @@ func UniqueCodeHostIdentifier(kind, config string) (string, error) {
 		return c.P4Port, nil
 	case *schema.JVMPackagesConnection:
 		return KindJVMPackages, nil
+	case *schema.NPMPackagesConnection:
+		return KindNPMPackages, nil
+	case *schema.PythonPackagesConnection:
+		return KindPythonPackages, nil
 	default:
 		return "", errors.Errorf("unknown external service kind: %s", kind)
 	}
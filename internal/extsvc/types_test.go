@@ -314,6 +314,11 @@ func TestUniqueCodeHostIdentifier(t *testing.T) {
 			config: `{"host": "git@gitolite.example.com"}`,
 			want:   "git@gitolite.example.com/",
 		},
+		{
+			kind:   KindMercurial,
+			config: `{"url": "https://hg.example.com"}`,
+			want:   "https://hg.example.com/",
+		},
 		{
 			kind:   KindPerforce,
 			config: `{"p4.port": "ssl:111.222.333.444:1666"}`,
@@ -324,6 +329,11 @@ func TestUniqueCodeHostIdentifier(t *testing.T) {
 			config: `{"url": "https://phabricator.sgdev.org/"}`,
 			want:   "https://phabricator.sgdev.org/",
 		},
+		{
+			kind:   KindSubversion,
+			config: `{"url": "svn://svn.example.com/repos"}`,
+			want:   "svn://svn.example.com/repos/",
+		},
 		{
 			kind:   KindOther,
 			config: `{"url": "ssh://user@host.xz:2333/"}`,
@@ -24,6 +24,7 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/neelance/parallel"
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	otlog "github.com/opentracing/opentracing-go/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -66,8 +67,9 @@ func NewClient(cli httpcli.Doer) *Client {
 		Addrs: func() []string {
 			return conf.Get().ServiceConnections().GitServers
 		},
-		HTTPClient:  cli,
-		HTTPLimiter: parallel.NewRun(500),
+		ReplicationFactor: conf.GitServerReplicationFactor,
+		HTTPClient:        cli,
+		HTTPLimiter:       parallel.NewRun(500),
 		// Use the binary name for UserAgent. This should effectively identify
 		// which service is making the request (excluding requests proxied via the
 		// frontend internal API)
@@ -88,6 +90,11 @@ type Client struct {
 	// concurrent use. It may return different results at different times.
 	Addrs func() []string
 
+	// ReplicationFactor is a function which should return the number of
+	// gitservers each repo is cloned onto. Like Addrs, it is called each time a
+	// request is made. If nil, repos are not replicated.
+	ReplicationFactor func() int
+
 	// UserAgent is a string identifying who the client is. It will be logged in
 	// the telemetry in gitserver.
 	UserAgent string
@@ -113,6 +120,21 @@ func (c *Client) RendezvousAddrForRepo(repo api.RepoName) string {
 	return RendezvousAddrForRepo(repo, addrs)
 }
 
+// ReplicaAddrsForRepo returns the addresses of the gitservers holding a copy of
+// the given repo. The first address is the primary gitserver returned by
+// AddrForRepo, followed by the secondary replicas.
+func (c *Client) ReplicaAddrsForRepo(repo api.RepoName) []string {
+	addrs := c.Addrs()
+	if len(addrs) == 0 {
+		panic("unexpected state: no gitserver addresses")
+	}
+	factor := 1
+	if c.ReplicationFactor != nil {
+		factor = c.ReplicationFactor()
+	}
+	return ReplicaAddrsForRepo(repo, addrs, factor)
+}
+
 // addrForKey returns the gitserver address to use for the given string key,
 // which is hashed for sharding purposes.
 func (c *Client) addrForKey(key string) string {
@@ -146,6 +168,36 @@ func RendezvousAddrForRepo(repo api.RepoName, addrs []string) string {
 	return r.Lookup(string(protocol.NormalizeRepo(repo)))
 }
 
+// ReplicaAddrsForRepo returns the addresses of the gitservers holding a copy of
+// the given repo when each repo is cloned onto factor gitservers. The first
+// address is the primary gitserver returned by AddrForRepo. The secondary
+// replicas are picked from the remaining addresses using the Rendezvous hashing
+// scheme, so that adding or removing a gitserver only moves few replicas.
+//
+// It should never be called with an empty slice.
+func ReplicaAddrsForRepo(repo api.RepoName, addrs []string, factor int) []string {
+	primary := AddrForRepo(repo, addrs)
+	replicas := []string{primary}
+
+	remaining := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr != primary {
+			remaining = append(remaining, addr)
+		}
+	}
+	for len(replicas) < factor && len(remaining) > 0 {
+		addr := RendezvousAddrForRepo(repo, remaining)
+		replicas = append(replicas, addr)
+		for i := range remaining {
+			if remaining[i] == addr {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	return replicas
+}
+
 // addrForKey returns the gitserver address to use for the given string key,
 // which is hashed for sharding purposes.
 func addrForKey(key string, addrs []string) string {
@@ -234,6 +286,13 @@ func (c *Client) Archive(ctx context.Context, repo api.RepoName, opt ArchiveOpti
 
 	switch resp.StatusCode {
 	case http.StatusOK:
+		// Archives of partial clones leave out the files whose blobs were
+		// not cloned.
+		if skipped, _ := strconv.Atoi(resp.Header.Get("X-Archive-Skipped-Files")); skipped > 0 {
+			span.SetTag("SkippedFiles", skipped)
+			archiveSkippedFilesCounter.Add(float64(skipped))
+			log15.Warn("archive of partial clone is missing files", "repo", repo, "treeish", opt.Treeish, "skipped", skipped)
+		}
 		return &archiveReader{
 			base: &cmdReader{
 				rc:      resp.Body,
@@ -375,6 +434,111 @@ func (c *Client) Search(ctx context.Context, args *protocol.SearchRequest, onMat
 	return eventDone.LimitHit, eventDone.Err()
 }
 
+// StreamBlame streams the blame of a file, calling onHunks with each set of
+// hunks as gitserver computes them. Hunks are not received in line order.
+func (c *Client) StreamBlame(ctx context.Context, req *protocol.BlameRequest, onHunks func([]protocol.BlameHunk)) (err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "GitserverClient.StreamBlame")
+	span.SetTag("repo", string(req.Repo))
+	span.SetTag("commit", string(req.Commit))
+	span.SetTag("path", req.Path)
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	repoName := protocol.NormalizeRepo(req.Repo)
+	resp, err := c.httpPost(ctx, repoName, "blame", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		var payload protocol.NotFoundPayload
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			return err
+		}
+		return &gitdomain.RepoNotExistError{Repo: repoName, CloneInProgress: payload.CloneInProgress, CloneProgress: payload.CloneProgress}
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("unexpected status code: %d (%s)", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var (
+		decodeErr error
+		eventDone protocol.BlameEventDone
+	)
+	dec := StreamBlameDecoder{
+		OnHunks: func(e protocol.BlameEventHunks) {
+			onHunks(e)
+		},
+		OnDone: func(e protocol.BlameEventDone) {
+			eventDone = e
+		},
+		OnUnknown: func(event, _ []byte) {
+			decodeErr = errors.Errorf("unknown event %s", event)
+		},
+	}
+
+	if err := dec.ReadAll(resp.Body); err != nil {
+		return err
+	}
+
+	if decodeErr != nil {
+		return decodeErr
+	}
+
+	return eventDone.Err()
+}
+
+// CommitAncestry returns which of req.Candidates are ancestors or descendants
+// of each of req.Commits and at which distance, along with the merge bases of
+// req.Commits.
+func (c *Client) CommitAncestry(ctx context.Context, req *protocol.CommitAncestryRequest) (_ *protocol.CommitAncestryResponse, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "GitserverClient.CommitAncestry")
+	span.SetTag("repo", string(req.Repo))
+	span.SetTag("commits", len(req.Commits))
+	span.SetTag("candidates", len(req.Candidates))
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	repoName := protocol.NormalizeRepo(req.Repo)
+	resp, err := c.httpPost(ctx, repoName, "commit-ancestry", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		var payload protocol.NotFoundPayload
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		return nil, &gitdomain.RepoNotExistError{Repo: repoName, CloneInProgress: payload.CloneInProgress, CloneProgress: payload.CloneProgress}
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errors.Errorf("unexpected status code: %d (%s)", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var res protocol.CommitAncestryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 // P4Exec sends a p4 command with given arguments and returns an io.ReadCloser for the output.
 func (c *Client) P4Exec(ctx context.Context, host, user, password string, args ...string) (_ io.ReadCloser, _ http.Header, errRes error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Client.P4Exec")
@@ -423,6 +587,11 @@ var deadlineExceededCounter = promauto.NewCounter(prometheus.CounterOpts{
 	Help: "Times that Client.sendExec() returned context.DeadlineExceeded",
 })
 
+var archiveSkippedFilesCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "src_gitserver_client_archive_skipped_files",
+	Help: "Files left out of archives by Client.Archive() since their blobs are missing from partial clones",
+})
+
 // Cmd represents a command to be executed remotely.
 type Cmd struct {
 	client *Client
@@ -839,6 +1008,81 @@ func (c *Client) RepoCloneProgress(ctx context.Context, repos ...api.RepoName) (
 	return &res, err.ErrorOrNil()
 }
 
+// CommitIndexStatus reports which of the given repositories have a commit
+// index on gitserver that covers all commits reachable from HEAD.
+//
+// If multiple errors occurred, an incomplete result is returned along with a
+// *multierror.Error.
+func (c *Client) CommitIndexStatus(ctx context.Context, repos ...api.RepoName) (*protocol.CommitIndexStatusResponse, error) {
+	numPossibleShards := len(c.Addrs())
+	shards := make(map[string]*protocol.CommitIndexStatusRequest, (len(repos)/numPossibleShards)*2) // 2x because it may not be a perfect division
+
+	for _, r := range repos {
+		addr := c.AddrForRepo(r)
+		shard := shards[addr]
+
+		if shard == nil {
+			shard = new(protocol.CommitIndexStatusRequest)
+			shards[addr] = shard
+		}
+
+		shard.Repos = append(shard.Repos, r)
+	}
+
+	type op struct {
+		req *protocol.CommitIndexStatusRequest
+		res *protocol.CommitIndexStatusResponse
+		err error
+	}
+
+	ch := make(chan op, len(shards))
+	for _, req := range shards {
+		go func(o op) {
+			var resp *http.Response
+			resp, o.err = c.httpPost(ctx, o.req.Repos[0], "commit-index-status", o.req)
+			if o.err != nil {
+				ch <- o
+				return
+			}
+
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				o.err = &url.Error{
+					URL: resp.Request.URL.String(),
+					Op:  "CommitIndexStatus",
+					Err: errors.Errorf("CommitIndexStatus: http status %d", resp.StatusCode),
+				}
+				ch <- o
+				return // we never get an error status code AND result
+			}
+
+			o.res = new(protocol.CommitIndexStatusResponse)
+			o.err = json.NewDecoder(resp.Body).Decode(o.res)
+			ch <- o
+		}(op{req: req})
+	}
+
+	err := new(multierror.Error)
+	res := protocol.CommitIndexStatusResponse{
+		Current: make(map[api.RepoName]bool),
+	}
+
+	for i := 0; i < cap(ch); i++ {
+		o := <-ch
+
+		if o.err != nil {
+			err = multierror.Append(err, o.err)
+			continue
+		}
+
+		for repo, current := range o.res.Current {
+			res.Current[repo] = current
+		}
+	}
+
+	return &res, err.ErrorOrNil()
+}
+
 // RepoInfo retrieves information about one or more repositories on gitserver.
 //
 // The repository not existing is not an error; in that case, RepoInfoResponse.Results[i].Cloned
@@ -1001,7 +1245,24 @@ func (c *Client) httpPostWithURI(ctx context.Context, repo api.RepoName, uri str
 	return c.do(ctx, repo, "POST", uri, b)
 }
 
+var replicaFailoverCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_gitserver_client_replica_failover_total",
+	Help: "Number of reads retried against a replica because a gitserver was unreachable",
+}, []string{"path"})
+
+// replicatedReadPaths are the endpoints that only read from a repo, and can
+// therefore be served by any of its replicas.
+var replicatedReadPaths = map[string]bool{
+	"/exec":            true,
+	"/archive":         true,
+	"/search":          true,
+	"/blame":           true,
+	"/commit-ancestry": true,
+}
+
 // do performs a request to a gitserver instance based on the address in the uri argument.
+// Reads from a replicated repo fail over to its other replicas if the gitserver
+// is unreachable.
 func (c *Client) do(ctx context.Context, repo api.RepoName, method, uri string, payload []byte) (resp *http.Response, err error) {
 	parsedURL, err := url.ParseRequestURI(uri)
 	if err != nil {
@@ -1018,6 +1279,44 @@ func (c *Client) do(ctx context.Context, repo api.RepoName, method, uri string,
 		span.Finish()
 	}()
 
+	addrs := []string{parsedURL.Host}
+	if repo != "" && replicatedReadPaths[parsedURL.Path] {
+		addrs = c.failoverAddrs(repo, parsedURL.Host)
+	}
+
+	if c.HTTPLimiter != nil {
+		c.HTTPLimiter.Acquire()
+		defer c.HTTPLimiter.Release()
+		span.LogKV("event", "Acquired HTTP limiter")
+	}
+
+	for i, addr := range addrs {
+		u := *parsedURL
+		u.Host = addr
+		resp, err = c.doAddr(ctx, span, method, u.String(), payload)
+		if err == nil || ctx.Err() != nil || i == len(addrs)-1 {
+			return resp, err
+		}
+		replicaFailoverCounter.WithLabelValues(parsedURL.Path).Inc()
+		span.LogKV("event", "failing over to replica", "addr", addrs[i+1], "error", err.Error())
+	}
+	return resp, err
+}
+
+// failoverAddrs returns the addresses a read from repo is attempted against, in
+// order: the address the request was made for, followed by the repo's other
+// replicas.
+func (c *Client) failoverAddrs(repo api.RepoName, addr string) []string {
+	addrs := []string{addr}
+	for _, replica := range c.ReplicaAddrsForRepo(repo) {
+		if replica != addr {
+			addrs = append(addrs, replica)
+		}
+	}
+	return addrs
+}
+
+func (c *Client) doAddr(ctx context.Context, span opentracing.Span, method, uri string, payload []byte) (*http.Response, error) {
 	req, err := http.NewRequest(method, uri, bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
@@ -1028,12 +1327,6 @@ func (c *Client) do(ctx context.Context, repo api.RepoName, method, uri string,
 	req.Header.Set("X-Sourcegraph-Actor", userFromContext(ctx))
 	req = req.WithContext(ctx)
 
-	if c.HTTPLimiter != nil {
-		c.HTTPLimiter.Acquire()
-		defer c.HTTPLimiter.Release()
-		span.LogKV("event", "Acquired HTTP limiter")
-	}
-
 	req, ht := nethttp.TraceRequest(span.Tracer(), req,
 		nethttp.OperationName("Gitserver Client"),
 		nethttp.ClientTrace(false))
@@ -360,6 +360,88 @@ func TestRendezvousAddrForRepo(t *testing.T) {
 	}
 }
 
+func TestReplicaAddrsForRepo(t *testing.T) {
+	addrs := []string{"gitserver-1", "gitserver-2", "gitserver-3"}
+	repo := api.RepoName("github.com/sourcegraph/sourcegraph")
+
+	testCases := []struct {
+		name   string
+		factor int
+		want   []string
+	}{
+		{
+			name:   "no replication",
+			factor: 1,
+			want:   []string{"gitserver-2"},
+		},
+		{
+			name:   "two replicas",
+			factor: 2,
+			want:   []string{"gitserver-2", gitserver.RendezvousAddrForRepo(repo, []string{"gitserver-1", "gitserver-3"})},
+		},
+		{
+			name:   "factor larger than gitservers",
+			factor: 5,
+			want:   []string{"gitserver-2", "gitserver-3", "gitserver-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := gitserver.ReplicaAddrsForRepo(repo, addrs, tc.factor)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClient_ReadFailover(t *testing.T) {
+	repo := api.RepoName("github.com/sourcegraph/sourcegraph")
+	addrs := []string{"gitserver-1", "gitserver-2", "gitserver-3"}
+	replicas := gitserver.ReplicaAddrsForRepo(repo, addrs, 2)
+
+	var requested []string
+	cli := &gitserver.Client{
+		Addrs:             func() []string { return addrs },
+		ReplicationFactor: func() int { return 2 },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			requested = append(requested, r.URL.Host+r.URL.Path)
+			if r.URL.Host == replicas[0] {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("deadbeef")),
+				Trailer:    http.Header{"X-Exec-Exit-Status": {"0"}},
+			}, nil
+		}),
+	}
+
+	// Reads fail over to the secondary replica.
+	cmd := cli.Command("git", "rev-parse", "HEAD")
+	cmd.Repo = repo
+	out, err := cmd.Output(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "deadbeef" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if want := []string{replicas[0] + "/exec", replicas[1] + "/exec"}; !cmp.Equal(want, requested) {
+		t.Fatalf("mismatch (-want +got):\n%s", cmp.Diff(want, requested))
+	}
+
+	// Writes are only sent to the primary.
+	requested = nil
+	if err := cli.Remove(context.Background(), repo); err == nil {
+		t.Fatal("expected error removing repo from unreachable primary")
+	}
+	if want := []string{replicas[0] + "/delete"}; !cmp.Equal(want, requested) {
+		t.Fatalf("mismatch (-want +got):\n%s", cmp.Diff(want, requested))
+	}
+}
+
 func TestClient_P4Exec(t *testing.T) {
 	root, err := os.MkdirTemp("", t.Name())
 	if err != nil {
@@ -0,0 +1,60 @@
+package protocol
+
+import (
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// BlameRequest is a request to stream the blame of a file.
+type BlameRequest struct {
+	Repo   api.RepoName
+	Commit api.CommitID
+	Path   string
+
+	StartLine int `json:",omitempty"` // 1-indexed start line (or 0 for beginning of file)
+	EndLine   int `json:",omitempty"` // 1-indexed end line (or 0 for end of file)
+
+	// IgnoreRevs ignores the revisions listed in the .git-blame-ignore-revs
+	// file at the root of the repository at Commit, if present. Lines changed
+	// by an ignored revision are blamed on the previous revision that changed
+	// them.
+	IgnoreRevs bool `json:",omitempty"`
+}
+
+// BlameHunk is a contiguous portion of a file associated with a commit.
+type BlameHunk struct {
+	StartLine int // 1-indexed start line number
+	EndLine   int // 1-indexed end line number (exclusive)
+	CommitID  api.CommitID
+	Author    Signature
+	Message   string
+
+	// Filename is the path of the file in CommitID. It differs from the
+	// requested path if the file was renamed since.
+	Filename string
+}
+
+// BlameEventHunks is the payload of the "hunks" event of a blame stream.
+// Hunks are sent in the order git computes them, not in line order.
+type BlameEventHunks []BlameHunk
+
+// BlameEventDone is the payload of the "done" event of a blame stream.
+type BlameEventDone struct {
+	Error string
+}
+
+func (e BlameEventDone) Err() error {
+	if e.Error != "" {
+		return errors.New(e.Error)
+	}
+	return nil
+}
+
+func NewBlameEventDone(err error) BlameEventDone {
+	var event BlameEventDone
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
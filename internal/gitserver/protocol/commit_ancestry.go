@@ -0,0 +1,82 @@
+package protocol
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// CommitAncestryRequest is a request for the ancestry relations between a set
+// of commits and a set of candidate commits, such as the commits which have
+// precise code intelligence uploads.
+type CommitAncestryRequest struct {
+	Repo api.RepoName
+
+	// Commits are the commits the ancestry relations are computed for.
+	Commits []api.CommitID
+
+	// Candidates are the commits searched for among the ancestors and the
+	// descendants of each commit.
+	Candidates []api.CommitID `json:",omitempty"`
+
+	// MaxDistance, if non-zero, is the maximum number of parent links between
+	// a commit and the candidates returned for it.
+	MaxDistance int `json:",omitempty"`
+
+	// Graph, if set, requests the commit graph reachable from Commits
+	// instead of the ancestry relations. Candidates and MaxDistance are
+	// ignored.
+	Graph *CommitGraphOptions `json:",omitempty"`
+}
+
+// CommitGraphOptions narrows the commit graph requested by a
+// CommitAncestryRequest.
+type CommitGraphOptions struct {
+	// AllRefs includes the commits reachable from any ref. Commits may be
+	// empty if it is set.
+	AllRefs bool `json:",omitempty"`
+
+	// Since, if set, leaves out commits committed before it.
+	Since *time.Time `json:",omitempty"`
+
+	// Limit, if non-zero, is the maximum number of commits returned.
+	Limit int `json:",omitempty"`
+}
+
+// CommitAncestryResponse is the response to a CommitAncestryRequest. Its maps
+// are keyed by the commits as given in the request.
+type CommitAncestryResponse struct {
+	// Ancestors maps each commit to the candidates which are its ancestors,
+	// nearest first. A commit is its own ancestor, at distance 0.
+	Ancestors map[api.CommitID][]CommitDistance
+
+	// Descendants maps each commit to the candidates which are its
+	// descendants, nearest first. A commit is its own descendant, at distance
+	// 0.
+	Descendants map[api.CommitID][]CommitDistance
+
+	// MergeBases are the best common ancestors of all the commits.
+	MergeBases []api.CommitID
+
+	// Missing are the commits and candidates which do not exist in the
+	// repository. They are left out of all other fields.
+	Missing []api.CommitID
+
+	// Graph is the commit graph requested with CommitAncestryRequest.Graph,
+	// in topological order with children before their parents. Parents
+	// outside of the requested graph are not listed as commits themselves.
+	Graph []CommitParents `json:",omitempty"`
+}
+
+// CommitParents is a commit and its parents.
+type CommitParents struct {
+	Commit  api.CommitID
+	Parents []api.CommitID `json:",omitempty"`
+}
+
+// CommitDistance is a commit and its distance to another commit, the minimum
+// number of parent links between them.
+type CommitDistance struct {
+	Commit   api.CommitID
+	Distance int
+}
@@ -33,6 +33,20 @@ type RevisionSpecifier struct {
 	ExcludeRefGlob string
 }
 
+// RevisionsAreHEAD reports whether revs only specifies HEAD, which is the
+// revision covered by a commit index.
+func RevisionsAreHEAD(revs []RevisionSpecifier) bool {
+	if len(revs) == 0 {
+		// git log defaults to HEAD.
+		return true
+	}
+	if len(revs) != 1 {
+		return false
+	}
+	rev := revs[0]
+	return (rev.RevSpec == "" || rev.RevSpec == "HEAD") && rev.RefGlob == "" && rev.ExcludeRefGlob == ""
+}
+
 type SearchEventMatches []CommitMatch
 
 type SearchEventDone struct {
@@ -229,6 +243,18 @@ type ReposStats struct {
 
 	// GitDirBytes is the amount of bytes stored in .git directories.
 	GitDirBytes int64
+
+	// Packfiles is the number of packfiles in .git directories. It is only
+	// counted with the incremental repo optimization strategy.
+	Packfiles int64
+
+	// LooseObjects is the number of loose objects in .git directories. It is
+	// only counted with the incremental repo optimization strategy.
+	LooseObjects int64
+
+	// CommitIndexBytes is the amount of bytes stored in commit indexes. It is
+	// included in GitDirBytes.
+	CommitIndexBytes int64
 }
 
 // RepoCloneProgressRequest is a request for information about the clone progress of multiple
@@ -250,6 +276,20 @@ type RepoCloneProgressResponse struct {
 	Results map[api.RepoName]*RepoCloneProgress
 }
 
+// CommitIndexStatusRequest is a request for the state of the commit indexes of
+// multiple repositories on gitserver.
+type CommitIndexStatusRequest struct {
+	Repos []api.RepoName
+}
+
+// CommitIndexStatusResponse is the response to a CommitIndexStatusRequest.
+type CommitIndexStatusResponse struct {
+	// Current maps the name of a repository to whether its commit index covers
+	// all commits reachable from HEAD. Repositories without a commit index are
+	// not current.
+	Current map[api.RepoName]bool
+}
+
 // CreateCommitFromPatchRequest is the request information needed for creating
 // the simulated staging area git object for a repo.
 type CreateCommitFromPatchRequest struct {
@@ -0,0 +1,22 @@
+package protocol
+
+import "testing"
+
+func TestRevisionsAreHEAD(t *testing.T) {
+	cases := []struct {
+		revs []RevisionSpecifier
+		want bool
+	}{
+		{revs: []RevisionSpecifier{{}}, want: true},
+		{revs: []RevisionSpecifier{{RevSpec: "HEAD"}}, want: true},
+		{revs: nil, want: true},
+		{revs: []RevisionSpecifier{{RevSpec: "main"}}, want: false},
+		{revs: []RevisionSpecifier{{RefGlob: "refs/heads/*"}}, want: false},
+		{revs: []RevisionSpecifier{{}, {RevSpec: "main"}}, want: false},
+	}
+	for _, tc := range cases {
+		if got := RevisionsAreHEAD(tc.revs); got != tc.want {
+			t.Errorf("RevisionsAreHEAD(%+v) = %t, want %t", tc.revs, got, tc.want)
+		}
+	}
+}
@@ -85,6 +85,30 @@ func (d *DiffModifiesFile) String() string {
 	return fmt.Sprintf("%T(%s)", d, d.Expr)
 }
 
+// CommitModifiesFile is a predicate that matches if the commit modifies any
+// files that match the given regex pattern. Unlike DiffModifiesFile, it only
+// needs the paths changed by the commit rather than its full diff.
+type CommitModifiesFile struct {
+	Expr       string
+	IgnoreCase bool
+}
+
+func (c *CommitModifiesFile) String() string {
+	return fmt.Sprintf("%T(%s)", c, c.Expr)
+}
+
+// TrailerMatches is a predicate that matches if any line of the trailers of the
+// commit message, like "Signed-off-by: Alice <alice@example.com>", matches the
+// regex pattern.
+type TrailerMatches struct {
+	Expr       string
+	IgnoreCase bool
+}
+
+func (t *TrailerMatches) String() string {
+	return fmt.Sprintf("%T(%s)", t, t.Expr)
+}
+
 // Boolean is a predicate that will either always match or never match
 type Boolean struct {
 	Value bool
@@ -219,6 +243,8 @@ func RegisterGob() {
 		gob.Register(&MessageMatches{})
 		gob.Register(&DiffMatches{})
 		gob.Register(&DiffModifiesFile{})
+		gob.Register(&CommitModifiesFile{})
+		gob.Register(&TrailerMatches{})
 		gob.Register(&Boolean{})
 		gob.Register(&Operator{})
 	})
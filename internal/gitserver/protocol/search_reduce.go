@@ -216,6 +216,26 @@ func mergeOrRegexp(n Node) Node {
 			} else {
 				mergeable[key] = v
 			}
+		case *CommitModifiesFile:
+			key := CommitModifiesFile{IgnoreCase: v.IgnoreCase}
+			if prev, ok := mergeable[key]; ok {
+				mergeable[key] = &CommitModifiesFile{
+					Expr:       "(" + prev.(*CommitModifiesFile).Expr + ")|(" + v.Expr + ")",
+					IgnoreCase: v.IgnoreCase,
+				}
+			} else {
+				mergeable[key] = v
+			}
+		case *TrailerMatches:
+			key := TrailerMatches{IgnoreCase: v.IgnoreCase}
+			if prev, ok := mergeable[key]; ok {
+				mergeable[key] = &TrailerMatches{
+					Expr:       "(" + prev.(*TrailerMatches).Expr + ")|(" + v.Expr + ")",
+					IgnoreCase: v.IgnoreCase,
+				}
+			} else {
+				mergeable[key] = v
+			}
 		default:
 			unmergeable = append(unmergeable, operand)
 		}
@@ -246,8 +266,10 @@ func estimatedNodeCost(n Node) float64 {
 		return 1
 	case *AuthorMatches, *CommitterMatches:
 		return 5
-	case *MessageMatches:
+	case *MessageMatches, *TrailerMatches:
 		return 10
+	case *CommitModifiesFile:
+		return 500
 	case *DiffModifiesFile:
 		return 1000
 	case *DiffMatches:
@@ -0,0 +1,692 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/cockroachdb/errors"
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// commitIndexVersion is the version of the on-disk format of commit
+	// indexes. Indexes with a different version are rebuilt.
+	commitIndexVersion = 2
+
+	commitIndexMetaFile = "meta.json"
+
+	// maxCommitIndexSegments is the number of segments after which the
+	// segments of a commit index are merged into one.
+	maxCommitIndexSegments = 16
+
+	// maxIndexedDiffSize is the size of the largest diff stored in a commit
+	// index. Larger diffs are fetched from git when they are needed.
+	maxIndexedDiffSize = 1 << 20
+
+	// maxIndexedFieldSize guards against reading corrupt records.
+	maxIndexedFieldSize = 1 << 30
+)
+
+var headRef = []byte("HEAD")
+
+var (
+	// commitIndexCheckpointInterval is the number of commits after which the
+	// progress of an update is recorded, so that an interrupted update
+	// resumes from there.
+	commitIndexCheckpointInterval = 1000
+
+	// onCommitIndexCheckpoint is called after the progress of an update is
+	// recorded. It is used by tests to interrupt updates.
+	onCommitIndexCheckpoint = func() {}
+)
+
+// commitIndexMeta describes the segments of a commit index.
+type commitIndexMeta struct {
+	Version int
+	// Head is the commit the index was last updated to.
+	Head string
+	// Segments are the names of the segment files, newest first. Each segment
+	// lists its commits in git log order, so reading the segments in order
+	// lists all commits in git log order.
+	Segments []string
+	// Commits is the number of commits in the index.
+	Commits int
+	// DiffBytes is the compressed size of the diffs stored in the segments.
+	DiffBytes int64
+	// Pending is the segment being written by an update that was interrupted,
+	// if any. It is not part of the index until it is complete.
+	Pending *pendingCommitIndexSegment `json:",omitempty"`
+}
+
+// pendingCommitIndexSegment is a segment listing the commits reachable from
+// Head but not from the Head of the index. Only its first Commits records,
+// which take up Size bytes, are complete.
+type pendingCommitIndexSegment struct {
+	Name      string
+	Head      string
+	Commits   int
+	Size      int64
+	DiffBytes int64
+}
+
+// segmentFiles returns the names of all segment files in use, including the
+// pending segment.
+func (m *commitIndexMeta) segmentFiles() []string {
+	if m.Pending == nil || m.Pending.Name == "" {
+		return m.Segments
+	}
+	return append([]string{m.Pending.Name}, m.Segments...)
+}
+
+// CommitIndex is an on-disk index of the commits reachable from HEAD of a
+// repository, storing their metadata, messages and compressed diffs.
+// Searching the index avoids running git log and computing diffs for the
+// indexed commits.
+//
+// The index is not an inverted index: a search still reads every indexed
+// commit, so its cost is linear in the size of the index. Diffs are only
+// decompressed when a search needs them. What the index saves is the cost of
+// walking the history and computing the diffs, which dominates commit and diff
+// searches.
+//
+// The index is updated incrementally with UpdateCommitIndex, which appends a
+// segment for the commits added since the last update.
+type CommitIndex struct {
+	meta     commitIndexMeta
+	segments []*os.File
+}
+
+// CommitIndexOptions configures UpdateCommitIndex.
+type CommitIndexOptions struct {
+	// MaxDiffBytes is the maximum compressed size of the diffs stored in the
+	// index. Once it is reached, diffs of further commits are not stored and
+	// are fetched from git when a search needs them. Zero means no limit.
+	MaxDiffBytes int64
+}
+
+// OpenCommitIndex opens the commit index in indexDir. The segments of the index
+// are opened immediately, so that they can still be read after a concurrent
+// update removes them. If there is no index, the returned error satisfies
+// errors.Is(err, os.ErrNotExist).
+func OpenCommitIndex(indexDir string) (*CommitIndex, error) {
+	meta, err := readCommitIndexMeta(indexDir)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Version != commitIndexVersion {
+		return nil, errors.Errorf("unsupported commit index version %d", meta.Version)
+	}
+	if meta.Head == "" {
+		// The first update of the index has not completed yet.
+		return nil, errors.Wrap(os.ErrNotExist, "commit index is being built")
+	}
+
+	ci := &CommitIndex{meta: meta}
+	for _, name := range meta.Segments {
+		f, err := os.Open(filepath.Join(indexDir, name))
+		if err != nil {
+			ci.Close()
+			return nil, err
+		}
+		ci.segments = append(ci.segments, f)
+	}
+	return ci, nil
+}
+
+// Head returns the commit the index was last updated to.
+func (ci *CommitIndex) Head() string {
+	return ci.meta.Head
+}
+
+// Len returns the number of commits in the index.
+func (ci *CommitIndex) Len() int {
+	return ci.meta.Commits
+}
+
+// Close closes the segments of the index.
+func (ci *CommitIndex) Close() error {
+	var err error
+	for _, f := range ci.segments {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	ci.segments = nil
+	return err
+}
+
+// Scan calls fn for each commit in the index, in git log order. The diff of the
+// commit is available to a LazyCommit wrapping it, unless it was not stored.
+// Ref names are not indexed since they change when refs move, see
+// refNamesByCommit.
+func (ci *CommitIndex) Scan(ctx context.Context, fn func(*RawCommit)) error {
+	for _, f := range ci.segments {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r := bufio.NewReaderSize(f, 64*1024)
+		for {
+			if ctx.Err() != nil {
+				return nil
+			}
+			rc, err := readCommitIndexRecord(r)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return errors.Wrapf(err, "reading commit index segment %s", filepath.Base(f.Name()))
+			}
+			// The index is only searched for HEAD, so like git log HEAD
+			// --source we report HEAD as the ref each commit was reached from.
+			rc.SourceRefs = headRef
+			fn(rc)
+		}
+	}
+	return nil
+}
+
+// CommitIndexState describes a commit index without opening it.
+type CommitIndexState struct {
+	// Head is the commit the index was last updated to. It is empty if the
+	// index was never completely built, or needs to be rebuilt.
+	Head string
+	// Pending is true if an update of the index was interrupted. The next
+	// update resumes it.
+	Pending bool
+}
+
+// ReadCommitIndexState returns the state of the commit index in indexDir. If
+// there is no index, the returned error satisfies errors.Is(err,
+// os.ErrNotExist).
+func ReadCommitIndexState(indexDir string) (CommitIndexState, error) {
+	meta, err := readCommitIndexMeta(indexDir)
+	if err != nil {
+		return CommitIndexState{}, err
+	}
+	if meta.Version != commitIndexVersion {
+		return CommitIndexState{}, nil
+	}
+	return CommitIndexState{Head: meta.Head, Pending: meta.Pending != nil}, nil
+}
+
+// CommitIndexIsCurrent reports whether the commit index in indexDir covers all
+// commits reachable from HEAD of the repository in repoDir.
+func CommitIndexIsCurrent(ctx context.Context, repoDir, indexDir string) (bool, error) {
+	state, err := ReadCommitIndexState(indexDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if state.Head == "" {
+		return false, nil
+	}
+	head, err := resolveHEAD(ctx, repoDir)
+	if err != nil {
+		return false, err
+	}
+	return head == state.Head, nil
+}
+
+// UpdateCommitIndex brings the commit index in indexDir up to date with HEAD of
+// the repository in repoDir. Only the commits added since the last update are
+// indexed, unless HEAD was rewritten, in which case the index is rebuilt.
+//
+// Progress is recorded regularly while the new commits are indexed. If the
+// update is interrupted, for example because ctx times out, the next update
+// resumes from the last recorded commit instead of starting over.
+func UpdateCommitIndex(ctx context.Context, repoDir, indexDir string, opts CommitIndexOptions) error {
+	head, err := resolveHEAD(ctx, repoDir)
+	if err != nil {
+		return err
+	}
+	if head == "" {
+		// An empty repository has nothing to index.
+		return os.RemoveAll(indexDir)
+	}
+
+	if err := os.MkdirAll(indexDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	meta, err := readCommitIndexMeta(indexDir)
+	if err != nil || meta.Version != commitIndexVersion {
+		meta = commitIndexMeta{Version: commitIndexVersion}
+	}
+	if meta.Head == head && meta.Pending == nil {
+		return nil
+	}
+	if meta.Head != "" && meta.Head != head {
+		if ok, err := isAncestor(ctx, repoDir, meta.Head, head); err != nil || !ok {
+			// HEAD was rewritten, so we start over.
+			meta = commitIndexMeta{Version: commitIndexVersion}
+		}
+	}
+	if p := meta.Pending; p != nil && p.Head != head {
+		if ok, err := isAncestor(ctx, repoDir, p.Head, head); err != nil || !ok {
+			// The interrupted update indexed commits that are gone now.
+			meta.Pending = nil
+		}
+	}
+
+	// Remove segments left behind by failed updates, or no longer used after
+	// starting over.
+	if err := removeUnusedCommitIndexSegments(indexDir, meta.segmentFiles()); err != nil {
+		return err
+	}
+
+	// An interrupted update is completed first, and then followed by an update
+	// for the commits added since.
+	for meta.Pending != nil || meta.Head != head {
+		if meta.Pending == nil {
+			meta.Pending = &pendingCommitIndexSegment{Head: head}
+		}
+		if err := writeCommitIndexSegment(ctx, repoDir, indexDir, &meta, opts); err != nil {
+			return err
+		}
+	}
+
+	if len(meta.Segments) > maxCommitIndexSegments {
+		merged, err := mergeCommitIndexSegments(indexDir, meta.Segments)
+		if err != nil {
+			return err
+		}
+		meta.Segments = []string{merged}
+	}
+
+	if err := writeCommitIndexMeta(indexDir, meta); err != nil {
+		return err
+	}
+	return removeUnusedCommitIndexSegments(indexDir, meta.segmentFiles())
+}
+
+// writeCommitIndexSegment writes the commits reachable from meta.Pending.Head
+// but not from meta.Head to the pending segment, continuing after the commits
+// it already contains. Once all commits are written, the segment is added to
+// meta. Otherwise, the progress is recorded in the metadata on disk.
+func writeCommitIndexSegment(ctx context.Context, repoDir, indexDir string, meta *commitIndexMeta, opts CommitIndexOptions) (err error) {
+	p := meta.Pending
+
+	var f *os.File
+	if p.Name != "" {
+		f, err = os.OpenFile(filepath.Join(indexDir, p.Name), os.O_WRONLY, 0)
+		if err == nil {
+			// Drop records written after the last recorded progress.
+			if err = f.Truncate(p.Size); err == nil {
+				_, err = f.Seek(p.Size, io.SeekStart)
+			}
+			if err != nil {
+				f.Close()
+				return err
+			}
+		} else if os.IsNotExist(err) {
+			*p = pendingCommitIndexSegment{Head: p.Head}
+		} else {
+			return err
+		}
+	}
+	if p.Name == "" {
+		if f, err = os.CreateTemp(indexDir, "segment-*"); err != nil {
+			return err
+		}
+		p.Name = filepath.Base(f.Name())
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	diffFetcher, err := NewDiffFetcher(repoDir)
+	if err != nil {
+		return err
+	}
+	defer diffFetcher.Stop()
+
+	w := bufio.NewWriterSize(f, 64*1024)
+	size, commits, diffBytes := p.Size, p.Commits, p.DiffBytes
+	checkpoint := func() error {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			return err
+		}
+		p.Size, p.Commits, p.DiffBytes = size, commits, diffBytes
+		if err := writeCommitIndexMeta(indexDir, *meta); err != nil {
+			return err
+		}
+		onCommitIndexCheckpoint()
+		return nil
+	}
+
+	revArgs := []string{p.Head}
+	if meta.Head != "" {
+		revArgs = append(revArgs, "^"+meta.Head)
+	}
+	if p.Commits > 0 {
+		// git log lists the commits of a range in a stable order, so we can
+		// skip those already written.
+		revArgs = append([]string{"--skip=" + strconv.Itoa(p.Commits)}, revArgs...)
+	}
+
+	var compressor diffCompressor
+	err = logCommits(ctx, repoDir, revArgs, func(rc *RawCommit) error {
+		if opts.MaxDiffBytes <= 0 || meta.DiffBytes+diffBytes < opts.MaxDiffBytes {
+			diff, err := diffFetcher.Fetch(rc.Hash)
+			if err != nil {
+				return errors.Wrapf(err, "fetching diff of %s", rc.Hash)
+			}
+			if len(diff) <= maxIndexedDiffSize {
+				if rc.indexedDiff, err = compressor.compress(diff); err != nil {
+					return err
+				}
+				diffBytes += int64(len(rc.indexedDiff))
+			}
+		}
+
+		n, err := writeCommitIndexRecord(w, rc)
+		if err != nil {
+			return err
+		}
+		size += n
+		commits++
+		if commits%commitIndexCheckpointInterval == 0 {
+			return checkpoint()
+		}
+		return nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		// Keep the records written so far for the next update.
+		if checkpointErr := checkpoint(); checkpointErr != nil {
+			return multierror.Append(err, errors.Wrap(checkpointErr, "recording commit index progress"))
+		}
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	meta.Head = p.Head
+	meta.Segments = append([]string{p.Name}, meta.Segments...)
+	meta.Commits += commits
+	meta.DiffBytes += diffBytes
+	meta.Pending = nil
+	return nil
+}
+
+// mergeCommitIndexSegments concatenates segments into a new segment in
+// indexDir and returns its name.
+func mergeCommitIndexSegments(indexDir string, segments []string) (name string, err error) {
+	f, err := os.CreateTemp(indexDir, "segment-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	for _, segment := range segments {
+		if err := appendFile(f, filepath.Join(indexDir, segment)); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Base(f.Name()), f.Sync()
+}
+
+func appendFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func removeUnusedCommitIndexSegments(indexDir string, segments []string) error {
+	used := make(map[string]struct{}, len(segments))
+	for _, segment := range segments {
+		used[segment] = struct{}{}
+	}
+
+	entries, err := os.ReadDir(indexDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, ok := used[entry.Name()]; ok || !strings.HasPrefix(entry.Name(), "segment-") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(indexDir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func readCommitIndexMeta(indexDir string) (commitIndexMeta, error) {
+	var meta commitIndexMeta
+	b, err := os.ReadFile(filepath.Join(indexDir, commitIndexMetaFile))
+	if err != nil {
+		return meta, err
+	}
+	return meta, json.Unmarshal(b, &meta)
+}
+
+// writeCommitIndexMeta atomically replaces the metadata of the index in
+// indexDir.
+func writeCommitIndexMeta(indexDir string, meta commitIndexMeta) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(indexDir, commitIndexMetaFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(indexDir, commitIndexMetaFile))
+}
+
+// A commit index record starts with a flags byte, followed by the fields of
+// the commit, each prefixed with its length as a uvarint. The diff is the last
+// field, compressed with DEFLATE, and only present if commitIndexRecordHasDiff
+// is set.
+const commitIndexRecordHasDiff = 1 << 0
+
+// writeCommitIndexRecord writes rc to w and returns the size of the record.
+func writeCommitIndexRecord(w *bufio.Writer, rc *RawCommit) (int64, error) {
+	var flags byte
+	if rc.indexedDiff != nil {
+		flags |= commitIndexRecordHasDiff
+	}
+	if err := w.WriteByte(flags); err != nil {
+		return 0, err
+	}
+	size := int64(1)
+
+	fields := [][]byte{
+		rc.Hash,
+		rc.AuthorName,
+		rc.AuthorEmail,
+		rc.AuthorDate,
+		rc.CommitterName,
+		rc.CommitterEmail,
+		rc.CommitterDate,
+		rc.Message,
+		rc.ParentHashes,
+	}
+	if rc.indexedDiff != nil {
+		fields = append(fields, rc.indexedDiff)
+	}
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, field := range fields {
+		n := binary.PutUvarint(lenBuf[:], uint64(len(field)))
+		if _, err := w.Write(lenBuf[:n]); err != nil {
+			return 0, err
+		}
+		if _, err := w.Write(field); err != nil {
+			return 0, err
+		}
+		size += int64(n + len(field))
+	}
+	return size, nil
+}
+
+func readCommitIndexRecord(r *bufio.Reader) (*RawCommit, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &RawCommit{}
+	fields := []*[]byte{
+		&rc.Hash,
+		&rc.AuthorName,
+		&rc.AuthorEmail,
+		&rc.AuthorDate,
+		&rc.CommitterName,
+		&rc.CommitterEmail,
+		&rc.CommitterDate,
+		&rc.Message,
+		&rc.ParentHashes,
+	}
+	if flags&commitIndexRecordHasDiff != 0 {
+		fields = append(fields, &rc.indexedDiff)
+	}
+
+	for _, field := range fields {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if n > maxIndexedFieldSize {
+			return nil, errors.Errorf("commit index record field too large: %d bytes", n)
+		}
+		*field = make([]byte, n)
+		if _, err := io.ReadFull(r, *field); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+	return rc, nil
+}
+
+// diffCompressor compresses diffs for storage in a commit index, reusing its
+// DEFLATE writer across diffs.
+type diffCompressor struct {
+	buf bytes.Buffer
+	w   *flate.Writer
+}
+
+func (c *diffCompressor) compress(diff []byte) ([]byte, error) {
+	c.buf.Reset()
+	if c.w == nil {
+		w, err := flate.NewWriter(&c.buf, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		c.w = w
+	} else {
+		c.w.Reset(&c.buf)
+	}
+	if _, err := c.w.Write(diff); err != nil {
+		return nil, err
+	}
+	if err := c.w.Close(); err != nil {
+		return nil, err
+	}
+	return append([]byte{}, c.buf.Bytes()...), nil
+}
+
+// decompressDiff decompresses a diff stored in a commit index.
+func decompressDiff(compressed []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(compressed))
+	defer r.Close()
+	diff, err := io.ReadAll(io.LimitReader(r, maxIndexedDiffSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "decompressing indexed diff")
+	}
+	return diff, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// resolveHEAD returns the commit HEAD points to, or an empty string if the
+// repository has no commits.
+func resolveHEAD(ctx context.Context, repoDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "HEAD^{commit}")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		var e *exec.ExitError
+		if errors.As(err, &e) && e.Sys().(syscall.WaitStatus).ExitStatus() == 1 {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "resolving HEAD")
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
+
+// isAncestor reports whether commit is an ancestor of rev, or the same commit.
+func isAncestor(ctx context.Context, repoDir, commit, rev string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", commit, rev)
+	cmd.Dir = repoDir
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var e *exec.ExitError
+	if errors.As(err, &e) && e.Sys().(syscall.WaitStatus).ExitStatus() == 1 {
+		return false, nil
+	}
+	return false, errors.Wrapf(err, "checking whether %s is an ancestor of %s", commit, rev)
+}
+
+// refNamesByCommit returns the ref names of the commits pointed to by refs, as
+// formatted by git log. They are not stored in a commit index, so they are
+// looked up for each search of the index.
+func refNamesByCommit(ctx context.Context, repoDir string) (map[string][]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "log", "--no-walk", "--all", "--decorate=full", "-z", "--format=format:%H%x00%D")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing ref names")
+	}
+
+	refNames := make(map[string][]byte)
+	fields := bytes.Split(out, sep)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if len(fields[i+1]) > 0 {
+			refNames[string(fields[i])] = fields[i+1]
+		}
+	}
+	return refNames, nil
+}
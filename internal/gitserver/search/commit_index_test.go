@@ -0,0 +1,244 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestUpdateCommitIndex(t *testing.T) {
+	dir := initGitRepository(t,
+		"echo a > file1",
+		"git add -A",
+		"git -c user.name=a -c user.email=a commit -m commit1",
+		"echo b > file2",
+		"git add -A",
+		"git -c user.name=a -c user.email=a commit -m commit2",
+	)
+	indexDir := filepath.Join(t.TempDir(), "index")
+	ctx := context.Background()
+
+	head := func() string {
+		out, err := gitCommand(dir, "git", "rev-parse", "HEAD").Output()
+		require.NoError(t, err)
+		return string(bytes.TrimSpace(out))
+	}
+	run := func(cmd string) {
+		out, err := gitCommand(dir, "bash", "-c", cmd).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	open := func() *CommitIndex {
+		index, err := OpenCommitIndex(indexDir)
+		require.NoError(t, err)
+		t.Cleanup(func() { index.Close() })
+		return index
+	}
+	messages := func(index *CommitIndex) []string {
+		var messages []string
+		err := index.Scan(ctx, func(rc *RawCommit) {
+			messages = append(messages, string(rc.Message))
+		})
+		require.NoError(t, err)
+		return messages
+	}
+
+	_, err := OpenCommitIndex(indexDir)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, UpdateCommitIndex(ctx, dir, indexDir, CommitIndexOptions{}))
+	index := open()
+	require.Equal(t, head(), index.Head())
+	require.Equal(t, 2, index.Len())
+	require.Equal(t, []string{"commit2", "commit1"}, messages(index))
+
+	// New commits are added in a new segment.
+	run("echo c > file3 && git add -A && git -c user.name=a -c user.email=a commit -m commit3")
+	require.NoError(t, UpdateCommitIndex(ctx, dir, indexDir, CommitIndexOptions{}))
+	index = open()
+	require.Equal(t, head(), index.Head())
+	require.Len(t, index.meta.Segments, 2)
+	require.Equal(t, []string{"commit3", "commit2", "commit1"}, messages(index))
+
+	// Rewriting HEAD rebuilds the index.
+	run("git -c user.name=a -c user.email=a commit --amend -m commit3-amended")
+	require.NoError(t, UpdateCommitIndex(ctx, dir, indexDir, CommitIndexOptions{}))
+	index = open()
+	require.Equal(t, head(), index.Head())
+	require.Len(t, index.meta.Segments, 1)
+	require.Equal(t, []string{"commit3-amended", "commit2", "commit1"}, messages(index))
+
+	// Segments are merged once there are too many of them.
+	for i := 0; i < maxCommitIndexSegments; i++ {
+		run(fmt.Sprintf("git -c user.name=a -c user.email=a commit --allow-empty -m empty%d", i))
+		require.NoError(t, UpdateCommitIndex(ctx, dir, indexDir, CommitIndexOptions{}))
+	}
+	index = open()
+	require.Len(t, index.meta.Segments, 1)
+	require.Equal(t, maxCommitIndexSegments+3, index.Len())
+	require.Len(t, messages(index), maxCommitIndexSegments+3)
+
+	entries, err := os.ReadDir(indexDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "expected only the metadata and a single segment")
+}
+
+func TestUpdateCommitIndexResume(t *testing.T) {
+	var cmds []string
+	for i := 0; i < 5; i++ {
+		cmds = append(cmds,
+			fmt.Sprintf("echo %d > file%d", i, i),
+			"git add -A",
+			fmt.Sprintf("git -c user.name=a -c user.email=a commit -m commit%d", i),
+		)
+	}
+	dir := initGitRepository(t, cmds...)
+	indexDir := filepath.Join(t.TempDir(), "index")
+
+	origInterval, origHook := commitIndexCheckpointInterval, onCommitIndexCheckpoint
+	t.Cleanup(func() { commitIndexCheckpointInterval, onCommitIndexCheckpoint = origInterval, origHook })
+	commitIndexCheckpointInterval = 1
+
+	// Interrupt the first update after two commits are indexed.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	checkpoints := 0
+	onCommitIndexCheckpoint = func() {
+		if checkpoints++; checkpoints == 2 {
+			cancel()
+		}
+	}
+	require.ErrorIs(t, UpdateCommitIndex(ctx, dir, indexDir, CommitIndexOptions{}), context.Canceled)
+
+	state, err := ReadCommitIndexState(indexDir)
+	require.NoError(t, err)
+	require.Equal(t, CommitIndexState{Pending: true}, state)
+	_, err = OpenCommitIndex(indexDir)
+	require.ErrorIs(t, err, os.ErrNotExist, "an incomplete index must not be searched")
+
+	// The next update resumes after the indexed commits.
+	checkpoints = 0
+	onCommitIndexCheckpoint = func() { checkpoints++ }
+	require.NoError(t, UpdateCommitIndex(context.Background(), dir, indexDir, CommitIndexOptions{}))
+	require.Equal(t, 3, checkpoints)
+
+	index, err := OpenCommitIndex(indexDir)
+	require.NoError(t, err)
+	defer index.Close()
+	require.Equal(t, 5, index.Len())
+	var messages []string
+	require.NoError(t, index.Scan(context.Background(), func(rc *RawCommit) {
+		messages = append(messages, string(rc.Message))
+	}))
+	require.Equal(t, []string{"commit4", "commit3", "commit2", "commit1", "commit0"}, messages)
+
+	state, err = ReadCommitIndexState(indexDir)
+	require.NoError(t, err)
+	require.False(t, state.Pending)
+}
+
+func TestUpdateCommitIndexDiffBudget(t *testing.T) {
+	dir := initGitRepository(t,
+		"echo a > file1",
+		"git add -A",
+		"git -c user.name=a -c user.email=a commit -m commit1",
+		"echo b > file2",
+		"git add -A",
+		"git -c user.name=a -c user.email=a commit -m commit2",
+	)
+	indexDir := filepath.Join(t.TempDir(), "index")
+	ctx := context.Background()
+
+	// The budget is used up by the first diff, so the second is not stored.
+	require.NoError(t, UpdateCommitIndex(ctx, dir, indexDir, CommitIndexOptions{MaxDiffBytes: 1}))
+	index, err := OpenCommitIndex(indexDir)
+	require.NoError(t, err)
+	defer index.Close()
+	require.Greater(t, index.meta.DiffBytes, int64(0))
+
+	var stored []bool
+	require.NoError(t, index.Scan(ctx, func(rc *RawCommit) {
+		stored = append(stored, rc.indexedDiff != nil)
+	}))
+	require.Equal(t, []bool{true, false}, stored)
+
+	// Stored diffs are compressed and decompressed on demand.
+	require.NoError(t, index.Scan(ctx, func(rc *RawCommit) {
+		if rc.indexedDiff == nil {
+			return
+		}
+		diff, err := (&LazyCommit{RawCommit: rc}).RawDiff()
+		require.NoError(t, err)
+		require.Contains(t, string(diff), "file2")
+	}))
+}
+
+func TestCommitIndexSearch(t *testing.T) {
+	dir := initGitRepository(t,
+		"echo lorem ipsum > file1",
+		"git add -A",
+		"git -c user.name=a -c user.email=a commit -m commit1",
+		"echo dolor sit amet > file2",
+		"git add -A",
+		"git -c user.name=b -c user.email=b commit -m commit2",
+	)
+	indexDir := filepath.Join(t.TempDir(), "index")
+	ctx := context.Background()
+	require.NoError(t, UpdateCommitIndex(ctx, dir, indexDir, CommitIndexOptions{}))
+
+	// Commits added after the index was updated are searched with git log.
+	// Refs are not indexed, so a tag of an indexed commit is still reported.
+	out, err := gitCommand(dir, "bash", "-c", "echo ipsum dolor > file3 && git add -A && git -c user.name=c -c user.email=c commit -m commit3 && git tag v1 HEAD~2").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	search := func(t *testing.T, query protocol.Node, useIndex bool) []*protocol.CommitMatch {
+		tree, err := ToMatchTree(query)
+		require.NoError(t, err)
+		searcher := &CommitSearcher{
+			RepoDir:     dir,
+			Query:       tree,
+			IncludeDiff: true,
+		}
+		if useIndex {
+			index, err := OpenCommitIndex(indexDir)
+			require.NoError(t, err)
+			defer index.Close()
+			searcher.Index = index
+		}
+		var matches []*protocol.CommitMatch
+		err = searcher.Search(ctx, func(match *protocol.CommitMatch) {
+			matches = append(matches, match)
+		})
+		require.NoError(t, err)
+		return matches
+	}
+
+	queries := map[string]protocol.Node{
+		"diff":          &protocol.DiffMatches{Expr: "ipsum"},
+		"message":       &protocol.MessageMatches{Expr: "commit"},
+		"author":        &protocol.AuthorMatches{Expr: "b"},
+		"modifies file": &protocol.CommitModifiesFile{Expr: "file2"},
+	}
+	for name, query := range queries {
+		t.Run(name, func(t *testing.T) {
+			want := search(t, query, false)
+			require.NotEmpty(t, want)
+			have := search(t, query, true)
+			require.Equal(t, want, have)
+		})
+	}
+
+	t.Run("rewritten HEAD", func(t *testing.T) {
+		out, err := gitCommand(dir, "bash", "-c", "git reset --hard HEAD~2 && git -c user.name=d -c user.email=d commit --allow-empty -m commit4").CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		query := &protocol.MessageMatches{Expr: "commit"}
+		require.Equal(t, search(t, query, false), search(t, query, true))
+	})
+}
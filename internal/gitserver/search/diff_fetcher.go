@@ -14,7 +14,9 @@ import (
 // DiffFetcher is a handle to the stdin and stdout of a git diff-tree subprocess
 // started with StartDiffFetcher
 type DiffFetcher struct {
-	dir string
+	dir          string
+	args         []string
+	configureCmd func(*exec.Cmd)
 
 	startOnce sync.Once
 	stdin     io.Writer
@@ -27,8 +29,27 @@ type DiffFetcher struct {
 // NewDiffFetcher starts a git diff-tree subprocess that waits, listening on stdin
 // for comimt hashes to generate patches for.
 func NewDiffFetcher(dir string) (*DiffFetcher, error) {
+	return &DiffFetcher{
+		dir: dir,
+		args: []string{
+			"--no-prefix", // Do not prefix file names with a/ and b/
+			"-p",          // Output in patch format
+		},
+	}, nil
+}
 
-	return &DiffFetcher{dir: dir}, nil
+// NewChangedFilesFetcher starts a git diff-tree subprocess that waits, listening
+// on stdin for commit hashes to list the changed files for. The file names are
+// separated by null bytes.
+func NewChangedFilesFetcher(dir string) (*DiffFetcher, error) {
+	return &DiffFetcher{
+		dir: dir,
+		args: []string{
+			"-r",          // Recurse into subtrees to list files rather than directories
+			"--name-only", // Output only the names of the changed files
+			"-z",          // Separate file names with null bytes, without quoting them
+		},
+	}, nil
 }
 
 func (d *DiffFetcher) Stop() {
@@ -42,15 +63,17 @@ func (d *DiffFetcher) start() (err error) {
 	d.startOnce.Do(func() {
 		ctx := context.Background()
 		ctx, d.cancel = context.WithCancel(ctx)
-		d.cmd = exec.CommandContext(ctx, "git",
+		args := append([]string{
 			"diff-tree",
 			"--stdin",          // Read commit hashes from stdin
-			"--no-prefix",      // Do not prefix file names with a/ and b/
-			"-p",               // Output in patch format
 			"--format=format:", // Output only the patch, not any other commit metadata
 			"--root",           // Treat the root commit as a big creation event (otherwise the diff would be empty)
-		)
+		}, d.args...)
+		d.cmd = exec.CommandContext(ctx, "git", args...)
 		d.cmd.Dir = d.dir
+		if d.configureCmd != nil {
+			d.configureCmd(d.cmd)
+		}
 
 		var stdoutReader io.ReadCloser
 		stdoutReader, err = d.cmd.StdoutPipe()
@@ -8,6 +8,7 @@ import (
 
 	"github.com/sourcegraph/go-diff/diff"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 )
 
 // LazyCommit wraps a RawCommit and a DiffFetcher so that we can have a unified interface
@@ -18,8 +19,13 @@ type LazyCommit struct {
 
 	// diff is the parsed output from the diff fetcher, cached here for performance
 	diff        []*diff.FileDiff
+	rawDiff     []byte
 	diffFetcher *DiffFetcher
 
+	// changedFiles is the list of paths changed by the commit, cached here for performance
+	changedFiles        []string
+	changedFilesFetcher *DiffFetcher
+
 	// LowerBuf is a re-usable buffer for doing case-transformations on the fields of LazyCommit
 	LowerBuf []byte
 }
@@ -44,6 +50,18 @@ func (l *LazyCommit) CommitterDate() (time.Time, error) {
 
 // RawDiff returns the diff exactly as returned by git diff-tree
 func (l *LazyCommit) RawDiff() ([]byte, error) {
+	if l.rawDiff != nil {
+		return l.rawDiff, nil
+	}
+	if l.indexedDiff != nil {
+		// The diff was read from a commit index.
+		rawDiff, err := decompressDiff(l.indexedDiff)
+		if err != nil {
+			return nil, err
+		}
+		l.rawDiff = rawDiff
+		return rawDiff, nil
+	}
 	return l.diffFetcher.Fetch(l.Hash)
 }
 
@@ -67,6 +85,73 @@ func (l *LazyCommit) Diff() ([]*diff.FileDiff, error) {
 	return diff, nil
 }
 
+// ChangedFiles returns the paths of the files changed by the commit. For a
+// renamed file, both the old and the new path are included. If the diff was
+// already fetched or read from a commit index, the paths are taken from it;
+// otherwise only the paths are fetched, which is much cheaper than fetching the
+// diff.
+func (l *LazyCommit) ChangedFiles() ([]string, error) {
+	if l.changedFiles != nil {
+		return l.changedFiles, nil
+	}
+
+	changedFiles := []string{}
+	if l.diff != nil || l.indexedDiff != nil || l.changedFilesFetcher == nil {
+		fileDiffs, err := l.Diff()
+		if err != nil {
+			return nil, err
+		}
+		for _, fileDiff := range fileDiffs {
+			if fileDiff.OrigName != "/dev/null" {
+				changedFiles = append(changedFiles, fileDiff.OrigName)
+			}
+			if fileDiff.NewName != "/dev/null" && fileDiff.NewName != fileDiff.OrigName {
+				changedFiles = append(changedFiles, fileDiff.NewName)
+			}
+		}
+	} else {
+		raw, err := l.changedFilesFetcher.Fetch(l.Hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range bytes.Split(raw, []byte{0}) {
+			if len(name) > 0 {
+				changedFiles = append(changedFiles, string(name))
+			}
+		}
+	}
+
+	l.changedFiles = changedFiles
+	return changedFiles, nil
+}
+
+// trailerLineRe matches the first line of a commit message trailer, like
+// "Signed-off-by: Alice <alice@example.com>".
+var trailerLineRe = lazyregexp.New(`^[A-Za-z0-9][A-Za-z0-9-]*:\s`)
+
+// Trailers returns the trailers of the commit message and their offset in the
+// message. The trailers are the last paragraph of the message if each of its
+// lines is a trailer or the continuation of one, which starts with whitespace.
+// A message consisting of a single paragraph has no trailers.
+func (l *LazyCommit) Trailers() (trailers []byte, offset int) {
+	idx := bytes.LastIndex(l.Message, []byte("\n\n"))
+	if idx == -1 {
+		return nil, 0
+	}
+
+	offset = idx + len("\n\n")
+	trailers = l.Message[offset:]
+	for i, line := range bytes.Split(trailers, []byte("\n")) {
+		if i > 0 && len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			continue
+		}
+		if !trailerLineRe.Match(line) {
+			return nil, 0
+		}
+	}
+	return trailers, offset
+}
+
 func (l *LazyCommit) ParentIDs() []api.CommitID {
 	strs := strings.Split(string(l.ParentHashes), " ")
 	commitIDs := make([]api.CommitID, 0, len(strs))
@@ -35,6 +35,12 @@ func ToMatchTree(q protocol.Node) (MatchTree, error) {
 	case *protocol.DiffModifiesFile:
 		re, err := casetransform.CompileRegexp(v.Expr, v.IgnoreCase)
 		return &DiffModifiesFile{re}, err
+	case *protocol.CommitModifiesFile:
+		re, err := casetransform.CompileRegexp(v.Expr, v.IgnoreCase)
+		return &CommitModifiesFile{re}, err
+	case *protocol.TrailerMatches:
+		re, err := casetransform.CompileRegexp(v.Expr, v.IgnoreCase)
+		return &TrailerMatches{re}, err
 	case *protocol.Boolean:
 		return &Constant{v.Value}, nil
 	case *protocol.Operator:
@@ -218,6 +224,57 @@ func (dmf *DiffModifiesFile) Match(lc *LazyCommit) (CommitFilterResult, MatchedC
 	return CommitFilterResult{MatchedFileDiffs: matchedFileDiffs}, MatchedCommit{Diff: fileDiffHighlights}, nil
 }
 
+// CommitModifiesFile is a predicate that matches if the commit modifies any
+// files that match the given regex pattern. Unlike DiffModifiesFile, it does
+// not constrain the matched file diffs, so it only needs the changed paths.
+type CommitModifiesFile struct {
+	*casetransform.Regexp
+}
+
+func (cmf *CommitModifiesFile) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit, error) {
+	changedFiles, err := lc.ChangedFiles()
+	if err != nil {
+		return filterResult(false), MatchedCommit{}, err
+	}
+
+	for _, changedFile := range changedFiles {
+		if cmf.Regexp.Match([]byte(changedFile), &lc.LowerBuf) {
+			return filterResult(true), MatchedCommit{}, nil
+		}
+	}
+	return filterResult(false), MatchedCommit{}, nil
+}
+
+// TrailerMatches is a predicate that matches if any line of the trailers of the
+// commit message matches the provided regex pattern.
+type TrailerMatches struct {
+	*casetransform.Regexp
+}
+
+func (tm *TrailerMatches) Match(lc *LazyCommit) (CommitFilterResult, MatchedCommit, error) {
+	trailers, offset := lc.Trailers()
+	if trailers == nil {
+		return filterResult(false), MatchedCommit{}, nil
+	}
+
+	// Match each line separately so that patterns can be anchored to the
+	// start and end of a trailer.
+	var results [][]int
+	for _, line := range bytes.SplitAfter(trailers, []byte("\n")) {
+		for _, match := range tm.FindAllIndex(bytes.TrimSuffix(line, []byte("\n")), -1, &lc.LowerBuf) {
+			results = append(results, []int{offset + match[0], offset + match[1]})
+		}
+		offset += len(line)
+	}
+	if results == nil {
+		return filterResult(false), MatchedCommit{}, nil
+	}
+
+	return filterResult(true), MatchedCommit{
+		Message: matchesToRanges(lc.Message, results),
+	}, nil
+}
+
 type Constant struct {
 	Value bool
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 )
 
@@ -89,3 +90,56 @@ func Test_matchesToRanges(t *testing.T) {
 		})
 	}
 }
+
+func TestTrailerMatches(t *testing.T) {
+	lc := func(message string) *LazyCommit {
+		return &LazyCommit{RawCommit: &RawCommit{Message: []byte(message)}}
+	}
+
+	cases := []struct {
+		name    string
+		expr    string
+		message string
+		matches result.Ranges
+	}{{
+		name:    "subject only",
+		expr:    "Signed-off-by",
+		message: "Signed-off-by: Alice <alice@example.com>",
+	}, {
+		name:    "last paragraph is not a trailer block",
+		expr:    "Signed-off-by",
+		message: "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>\nwith some more text",
+	}, {
+		name:    "trailer mentioned in the body",
+		expr:    "Reviewed-by",
+		message: "Fix the bug\n\nReviewed-by: nobody yet\n\nSigned-off-by: Alice <alice@example.com>",
+	}, {
+		name:    "anchored match",
+		expr:    "^Signed-off-by: Alice",
+		message: "Fix the bug\n\nCo-authored-by: Bob <bob@example.com>\nSigned-off-by: Alice <alice@example.com>",
+		matches: result.Ranges{{
+			Start: result.Location{Offset: 51, Line: 3, Column: 0},
+			End:   result.Location{Offset: 71, Line: 3, Column: 20},
+		}},
+	}, {
+		name:    "continuation line",
+		expr:    "<carol",
+		message: "Fix the bug\n\nCo-authored-by: Bob <bob@example.com>,\n Carol <carol@example.com>",
+		matches: result.Ranges{{
+			Start: result.Location{Offset: 59, Line: 3, Column: 7},
+			End:   result.Location{Offset: 65, Line: 3, Column: 13},
+		}},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mt, err := ToMatchTree(&protocol.TrailerMatches{Expr: tc.expr, IgnoreCase: true})
+			require.NoError(t, err)
+
+			cfr, highlights, err := mt.Match(lc(tc.message))
+			require.NoError(t, err)
+			require.Equal(t, tc.matches != nil, cfr.Satisfies())
+			require.Equal(t, tc.matches, highlights.Message)
+		})
+	}
+}
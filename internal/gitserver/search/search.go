@@ -77,6 +77,15 @@ type CommitSearcher struct {
 	Query       MatchTree
 	Revisions   []protocol.RevisionSpecifier
 	IncludeDiff bool
+
+	// ConfigureDiffCmd, if set, is called with the git command that computes
+	// diffs before it is started.
+	ConfigureDiffCmd func(*exec.Cmd)
+
+	// Index, if set, is a commit index of the repository used instead of
+	// listing the indexed commits with git log. Revisions are ignored when
+	// Index is set, since an index only covers HEAD.
+	Index *CommitIndex
 }
 
 // Search runs a search for commits matching the given predicate across the revisions passed in as revisionArgs.
@@ -123,9 +132,77 @@ func (cs *CommitSearcher) Search(ctx context.Context, onMatch func(*protocol.Com
 }
 
 func (cs *CommitSearcher) feedBatches(ctx context.Context, jobs chan job, resultChans chan chan *protocol.CommitMatch) (err error) {
+	batch := make([]*RawCommit, 0, batchSize)
+	sendBatch := func() {
+		resultChan := make(chan *protocol.CommitMatch, 128)
+		resultChans <- resultChan
+		jobs <- job{
+			batch:      batch,
+			resultChan: resultChan,
+		}
+		batch = make([]*RawCommit, 0, batchSize)
+	}
+	addCommit := func(rc *RawCommit) error {
+		batch = append(batch, rc)
+		if len(batch) == batchSize {
+			sendBatch()
+		}
+		return nil
+	}
+
 	revArgs := revsToGitArgs(cs.Revisions)
+	index := cs.usableIndex(ctx)
+	if index != nil {
+		// Only the commits added since the index was last updated need to be
+		// listed by git log.
+		revArgs = []string{"HEAD", "^" + index.Head()}
+	}
+
+	if err := logCommits(ctx, cs.RepoDir, revArgs, addCommit); err != nil || ctx.Err() != nil {
+		return err
+	}
+	if index != nil {
+		refNames, err := refNamesByCommit(ctx, cs.RepoDir)
+		if err != nil {
+			return err
+		}
+		err = index.Scan(ctx, func(rc *RawCommit) {
+			rc.RefNames = refNames[string(rc.Hash)]
+			addCommit(rc)
+		})
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	if len(batch) > 0 {
+		sendBatch()
+	}
+	return nil
+}
+
+// usableIndex returns cs.Index if it can be used to search HEAD, or nil if the
+// index was built for a HEAD that has since been rewritten.
+func (cs *CommitSearcher) usableIndex(ctx context.Context) *CommitIndex {
+	if cs.Index == nil {
+		return nil
+	}
+	ok, err := isAncestor(ctx, cs.RepoDir, cs.Index.Head(), "HEAD")
+	if err != nil {
+		log15.Warn("commit index cannot be used for search", "repoDir", cs.RepoDir, "error", err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	return cs.Index
+}
+
+// logCommits calls fn with each commit listed by git log for revArgs. It stops
+// early if fn returns an error or ctx is canceled.
+func logCommits(ctx context.Context, repoDir string, revArgs []string, fn func(*RawCommit) error) (err error) {
 	cmd := exec.CommandContext(ctx, "git", append(logArgs, revArgs...)...)
-	cmd.Dir = cs.RepoDir
+	cmd.Dir = repoDir
 	stdoutReader, err := cmd.StdoutPipe()
 	if err != nil {
 		return err
@@ -139,38 +216,23 @@ func (cs *CommitSearcher) feedBatches(ctx context.Context, jobs chan job, result
 
 	defer func() {
 		// Always call cmd.Wait to avoid leaving zombie processes around.
-		if e := cmd.Wait(); e != nil {
-			err = multierror.Append(err, tryInterpretErrorWithStderr(ctx, err, stderrBuf.String())).ErrorOrNil()
+		if e := cmd.Wait(); e != nil && err == nil {
+			err = tryInterpretErrorWithStderr(ctx, e, stderrBuf.String())
 		}
 	}()
 
-	batch := make([]*RawCommit, 0, batchSize)
-	sendBatch := func() {
-		resultChan := make(chan *protocol.CommitMatch, 128)
-		resultChans <- resultChan
-		jobs <- job{
-			batch:      batch,
-			resultChan: resultChan,
-		}
-		batch = make([]*RawCommit, 0, batchSize)
-	}
-
 	scanner := NewCommitScanner(stdoutReader)
 	for scanner.Scan() {
 		if ctx.Err() != nil {
 			return nil
 		}
-		cv := scanner.NextRawCommit()
-		batch = append(batch, cv)
-		if len(batch) == batchSize {
-			sendBatch()
+		if err := fn(scanner.NextRawCommit()); err != nil {
+			// Stop git log rather than waiting for it to list all commits.
+			cmd.Process.Kill()
+			return err
 		}
 	}
 
-	if len(batch) > 0 {
-		sendBatch()
-	}
-
 	return scanner.Err()
 }
 
@@ -193,8 +255,16 @@ func (cs *CommitSearcher) runJobs(ctx context.Context, jobs chan job) error {
 	if err != nil {
 		return err
 	}
+	diffFetcher.configureCmd = cs.ConfigureDiffCmd
 	defer diffFetcher.Stop()
 
+	changedFilesFetcher, err := NewChangedFilesFetcher(cs.RepoDir)
+	if err != nil {
+		return err
+	}
+	changedFilesFetcher.configureCmd = cs.ConfigureDiffCmd
+	defer changedFilesFetcher.Stop()
+
 	startBuf := make([]byte, 1024)
 
 	runJob := func(j job) error {
@@ -207,9 +277,10 @@ func (cs *CommitSearcher) runJobs(ctx context.Context, jobs chan job) error {
 			}
 
 			lc := &LazyCommit{
-				RawCommit:   cv,
-				diffFetcher: diffFetcher,
-				LowerBuf:    startBuf,
+				RawCommit:           cv,
+				diffFetcher:         diffFetcher,
+				changedFilesFetcher: changedFilesFetcher,
+				LowerBuf:            startBuf,
 			}
 			mergedResult, highlights, err := cs.Query.Match(lc)
 			if err != nil {
@@ -262,6 +333,10 @@ type RawCommit struct {
 	CommitterDate  []byte
 	Message        []byte
 	ParentHashes   []byte
+
+	// indexedDiff is the compressed diff of the commit if it was read from a
+	// commit index.
+	indexedDiff []byte
 }
 
 type CommitScanner struct {
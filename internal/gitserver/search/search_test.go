@@ -167,6 +167,23 @@ func TestSearch(t *testing.T) {
 		require.Equal(t, matches[0].Author.Name, "camden1")
 	})
 
+	t.Run("commit modifies file", func(t *testing.T) {
+		query := &protocol.CommitModifiesFile{Expr: "^file2$"}
+		tree, err := ToMatchTree(query)
+		require.NoError(t, err)
+		searcher := &CommitSearcher{
+			RepoDir: dir,
+			Query:   tree,
+		}
+		var matches []*protocol.CommitMatch
+		err = searcher.Search(context.Background(), func(match *protocol.CommitMatch) {
+			matches = append(matches, match)
+		})
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		require.Equal(t, matches[0].Author.Name, "camden2")
+	})
+
 	t.Run("and match", func(t *testing.T) {
 		query := protocol.NewAnd(
 			&protocol.DiffMatches{Expr: "lorem"},
@@ -190,6 +207,37 @@ func TestSearch(t *testing.T) {
 	})
 }
 
+func TestChangedFiles(t *testing.T) {
+	dir := initGitRepository(t,
+		"mkdir -p migrations/frontend",
+		"echo a > migrations/frontend/1_up.sql",
+		"echo b > 'file with spaces'",
+		"git add -A",
+		"git -c user.name=a -c user.email=a commit -m add",
+		"git mv 'file with spaces' renamed",
+		"git -c user.name=a -c user.email=a commit -m rename",
+	)
+
+	changedFilesFetcher, err := NewChangedFilesFetcher(dir)
+	require.NoError(t, err)
+	defer changedFilesFetcher.Stop()
+
+	changedFiles := func(rev string) []string {
+		hash, err := gitCommand(dir, "git", "rev-parse", rev).Output()
+		require.NoError(t, err)
+		lc := &LazyCommit{
+			RawCommit:           &RawCommit{Hash: bytes.TrimSpace(hash)},
+			changedFilesFetcher: changedFilesFetcher,
+		}
+		files, err := lc.ChangedFiles()
+		require.NoError(t, err)
+		return files
+	}
+
+	require.Equal(t, []string{"file with spaces", "migrations/frontend/1_up.sql"}, changedFiles("HEAD~1"))
+	require.Equal(t, []string{"file with spaces", "renamed"}, changedFiles("HEAD"))
+}
+
 func TestCommitScanner(t *testing.T) {
 	cases := []struct {
 		input    []byte
@@ -59,8 +59,13 @@ func Remove(input string, path ...string) (string, error) {
 
 // Edit returns the input JSON with the given path set to v.
 func Edit(input string, v interface{}, path ...string) (string, error) {
+	return EditPath(input, v, jsonx.PropertyPath(path...))
+}
+
+// EditPath is like Edit, except path may contain array indexes.
+func EditPath(input string, v interface{}, path jsonx.Path) (string, error) {
 	edits, _, err := jsonx.ComputePropertyEdit(input,
-		jsonx.PropertyPath(path...),
+		path,
 		v,
 		nil,
 		DefaultFormatOptions,
@@ -75,8 +80,13 @@ func Edit(input string, v interface{}, path ...string) (string, error) {
 // ReadProperty attempts to read the value of the specified path, ignoring parse errors. it will only error if the path
 // doesn't exist
 func ReadProperty(input string, path ...string) (interface{}, error) {
+	return ReadPropertyPath(input, jsonx.PropertyPath(path...))
+}
+
+// ReadPropertyPath is like ReadProperty, except path may contain array indexes.
+func ReadPropertyPath(input string, path jsonx.Path) (interface{}, error) {
 	root, _ := jsonx.ParseTree(input, jsonx.ParseOptions{Comments: true, TrailingCommas: true})
-	node := jsonx.FindNodeAtLocation(root, jsonx.PropertyPath(path...))
+	node := jsonx.FindNodeAtLocation(root, path)
 	if node == nil {
 		return nil, errors.Errorf("couldn't find node: %s", path)
 	}
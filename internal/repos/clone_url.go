@@ -3,6 +3,7 @@ package repos
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/inconshreveable/log15"
@@ -15,8 +16,12 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/jvmpackages"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/mercurial"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/npmpackages"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/perforce"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/phabricator"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/pythonpackages"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
@@ -55,6 +60,10 @@ func CloneURL(kind, config string, repo *types.Repo) (string, error) {
 		if r, ok := repo.Metadata.(*gitolite.Repo); ok {
 			return r.URL, nil
 		}
+	case *schema.MercurialConnection:
+		if r, ok := repo.Metadata.(*mercurial.Repo); ok {
+			return relativeCloneURL(t.Url, r.RelativePath)
+		}
 	case *schema.PerforceConnection:
 		if r, ok := repo.Metadata.(*perforce.Depot); ok {
 			return perforceCloneURL(r, t), nil
@@ -63,6 +72,10 @@ func CloneURL(kind, config string, repo *types.Repo) (string, error) {
 		if r, ok := repo.Metadata.(*phabricator.Repo); ok {
 			return phabricatorCloneURL(r, t), nil
 		}
+	case *schema.SubversionConnection:
+		if r, ok := repo.Metadata.(*subversion.Repo); ok {
+			return subversionCloneURL(r, t)
+		}
 	case *schema.OtherExternalServiceConnection:
 		if r, ok := repo.Metadata.(*extsvc.OtherRepoMetadata); ok {
 			return otherCloneURL(repo, r), nil
@@ -71,6 +84,14 @@ func CloneURL(kind, config string, repo *types.Repo) (string, error) {
 		if r, ok := repo.Metadata.(*jvmpackages.Metadata); ok {
 			return r.Module.CloneURL(), nil
 		}
+	case *schema.NPMPackagesConnection:
+		if r, ok := repo.Metadata.(*npmpackages.Metadata); ok {
+			return r.Package.CloneURL(), nil
+		}
+	case *schema.PythonPackagesConnection:
+		if r, ok := repo.Metadata.(*pythonpackages.Metadata); ok {
+			return r.Package.CloneURL(), nil
+		}
 	default:
 		return "", errors.Errorf("unknown external service kind %q for repo %d", kind, repo.ID)
 	}
@@ -257,3 +278,40 @@ func phabricatorCloneURL(repo *phabricator.Repo, _ *schema.PhabricatorConnection
 func otherCloneURL(repo *types.Repo, m *extsvc.OtherRepoMetadata) string {
 	return repo.ExternalRepo.ServiceID + m.RelativePath
 }
+
+// relativeCloneURL returns the URL of the repository at relativePath on the
+// server at baseURL.
+func relativeCloneURL(baseURL, relativePath string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	// Repository paths are relative to the path of the base URL.
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	u, err := base.Parse(strings.TrimPrefix(relativePath, "/"))
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// subversionCloneURL returns the URL of a Subversion repository with the
+// configured credentials inserted in the URL userinfo.
+func subversionCloneURL(repo *subversion.Repo, cfg *schema.SubversionConnection) (string, error) {
+	cloneURL, err := relativeCloneURL(cfg.Url, repo.RelativePath)
+	if err != nil || cfg.Username == "" {
+		return cloneURL, err
+	}
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Password != "" {
+		u.User = url.UserPassword(cfg.Username, cfg.Password)
+	} else {
+		u.User = url.User(cfg.Username)
+	}
+	return u.String(), nil
+}
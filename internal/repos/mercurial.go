@@ -0,0 +1,96 @@
+package repos
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/mercurial"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// A MercurialSource yields repositories from a single Mercurial connection
+// configured in Sourcegraph via the external services configuration.
+type MercurialSource struct {
+	svc    *types.ExternalService
+	config *schema.MercurialConnection
+}
+
+// NewMercurialSource returns a new MercurialSource from the given external
+// service.
+func NewMercurialSource(svc *types.ExternalService) (*MercurialSource, error) {
+	var c schema.MercurialConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Wrapf(err, "external service id=%d config error", svc.ID)
+	}
+	return newMercurialSource(svc, &c)
+}
+
+func newMercurialSource(svc *types.ExternalService, c *schema.MercurialConnection) (*MercurialSource, error) {
+	return &MercurialSource{
+		svc:    svc,
+		config: c,
+	}, nil
+}
+
+// ListRepos returns all Mercurial repositories configured in the Mercurial
+// connection.
+func (s MercurialSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	for _, repo := range s.config.Repos {
+		r, err := s.makeRepo(repo)
+		if err != nil {
+			results <- SourceResult{Source: s, Err: err}
+			return
+		}
+		results <- SourceResult{Source: s, Repo: r}
+	}
+}
+
+func (s MercurialSource) makeRepo(relativePath string) (*types.Repo, error) {
+	relativePath = strings.Trim(relativePath, "/")
+	cloneURL, err := relativeCloneURL(s.config.Url, relativePath)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(s.config.Url)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials in the URL are only used for cloning.
+	base.User = nil
+	serviceID := extsvc.NormalizeBaseURL(base).String()
+	host := (&url.URL{Host: base.Host, Path: base.Path}).String()
+
+	urn := s.svc.URN()
+	return &types.Repo{
+		Name: api.RepoName(reposource.OtherRepoName(s.config.RepositoryPathPattern, host, relativePath)),
+		URI:  reposource.OtherRepoName("", host, relativePath),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          relativePath,
+			ServiceType: extsvc.TypeMercurial,
+			ServiceID:   serviceID,
+		},
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: cloneURL,
+			},
+		},
+		Metadata: &mercurial.Repo{
+			RelativePath: relativePath,
+		},
+	}, nil
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s MercurialSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
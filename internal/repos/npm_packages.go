@@ -0,0 +1,103 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/npmpackages"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// An NPMPackagesSource creates git repositories from the tarballs of npm
+// packages published to an npm registry.
+type NPMPackagesSource struct {
+	svc    *types.ExternalService
+	config *schema.NPMPackagesConnection
+}
+
+// NewNPMPackagesSource returns a new NPMPackagesSource from the given external
+// service.
+func NewNPMPackagesSource(svc *types.ExternalService) (*NPMPackagesSource, error) {
+	var c schema.NPMPackagesConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, fmt.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return &NPMPackagesSource{svc: svc, config: &c}, nil
+}
+
+// ListRepos returns all npm packages configured in the external service. Each
+// package becomes a single repository, with one git tag per version.
+func (s *NPMPackagesSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	packages, err := NPMPackages(*s.config)
+	if err != nil {
+		results <- SourceResult{Err: err}
+		return
+	}
+	for _, pkg := range packages {
+		results <- SourceResult{
+			Source: s,
+			Repo:   s.makeRepo(pkg),
+		}
+	}
+}
+
+func (s *NPMPackagesSource) makeRepo(pkg reposource.NPMPackage) *types.Repo {
+	urn := s.svc.URN()
+	return &types.Repo{
+		Name: pkg.RepoName(),
+		URI:  string(pkg.RepoName()),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          string(pkg.RepoName()),
+			ServiceID:   extsvc.TypeNPMPackages,
+			ServiceType: extsvc.TypeNPMPackages,
+		},
+		Private: false,
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: pkg.CloneURL(),
+			},
+		},
+		Metadata: &npmpackages.Metadata{
+			Package: pkg,
+		},
+	}
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s *NPMPackagesSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
+
+func NPMDependencies(connection schema.NPMPackagesConnection) (dependencies []reposource.NPMDependency, err error) {
+	for _, dep := range connection.Dependencies {
+		dependency, err := reposource.ParseNPMDependency(dep)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, nil
+}
+
+func NPMPackages(connection schema.NPMPackagesConnection) ([]reposource.NPMPackage, error) {
+	isAdded := make(map[reposource.NPMPackage]bool)
+	packages := []reposource.NPMPackage{}
+	dependencies, err := NPMDependencies(connection)
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range dependencies {
+		pkg := dep.NPMPackage
+		if _, added := isAdded[pkg]; !added {
+			packages = append(packages, pkg)
+		}
+		isAdded[pkg] = true
+	}
+	return packages, nil
+}
@@ -0,0 +1,193 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/pythonpackages"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/pythonpackages/pypi"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// A PythonPackagesSource creates git repositories from the source
+// distributions (or wheels) of Python packages published to PyPI compatible
+// package indexes.
+type PythonPackagesSource struct {
+	svc     *types.ExternalService
+	config  *schema.PythonPackagesConnection
+	dbStore PythonPackagesRepoStore
+}
+
+type PythonPackagesRepoStore interface {
+	GetPythonDependencyRepos(ctx context.Context, filter dbstore.GetPythonDependencyReposOpts) ([]dbstore.PythonDependencyRepo, error)
+}
+
+// NewPythonPackagesSource returns a new PythonPackagesSource from the given
+// external service.
+func NewPythonPackagesSource(svc *types.ExternalService) (*PythonPackagesSource, error) {
+	var c schema.PythonPackagesConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, fmt.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return &PythonPackagesSource{
+		svc:     svc,
+		config:  &c,
+		dbStore: nil, // set via SetDB decorator
+	}, nil
+}
+
+func (s *PythonPackagesSource) SetDB(db dbutil.DB) {
+	once.Do(func() {
+		observationContext = &observation.Context{
+			Logger:     log15.Root(),
+			Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+			Registerer: prometheus.DefaultRegisterer,
+		}
+		operationMetrics = dbstore.NewREDMetrics(observationContext)
+	})
+	s.dbStore = dbstore.NewWithDB(db, observationContext, operationMetrics)
+}
+
+// ListRepos returns all Python packages configured in the external service,
+// and all packages referenced by precise code intelligence uploads that can be
+// resolved from the configured indexes.
+func (s *PythonPackagesSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	packages, err := PythonPackages(*s.config)
+	if err != nil {
+		results <- SourceResult{Err: err}
+		return
+	}
+	for _, pkg := range packages {
+		results <- SourceResult{
+			Source: s,
+			Repo:   s.makeRepo(pkg),
+		}
+	}
+
+	if s.dbStore == nil {
+		return
+	}
+
+	var (
+		totalDBFetched  int
+		totalDBResolved int
+		lastID          int
+		timedOut        int
+	)
+	for {
+		dbDeps, err := s.dbStore.GetPythonDependencyRepos(ctx, dbstore.GetPythonDependencyReposOpts{
+			After: lastID,
+			Limit: 100,
+		})
+		if err != nil {
+			results <- SourceResult{Err: err}
+			return
+		}
+
+		if len(dbDeps) == 0 {
+			break
+		}
+
+		totalDBFetched += len(dbDeps)
+
+		lastID = dbDeps[len(dbDeps)-1].ID
+
+		for _, dep := range dbDeps {
+			pkg, err := reposource.ParsePythonPackage(dep.Package)
+			if err != nil {
+				log15.Warn("error parsing python package", "error", err, "package", dep.Package)
+				continue
+			}
+			dependency := reposource.PythonDependency{PythonPackage: pkg, Version: dep.Version}
+
+			// As for JVM packages, only return resolvable dependencies to
+			// reduce logspam from gitserver.
+			if exists, err := pypi.Exists(ctx, s.config, dependency); !exists {
+				if errors.Is(err, context.DeadlineExceeded) {
+					timedOut++
+				} else {
+					log15.Warn("python package not resolvable from package indexes", "package", dependency.PackageManagerSyntax())
+				}
+				continue
+			}
+
+			totalDBResolved++
+			results <- SourceResult{
+				Source: s,
+				Repo:   s.makeRepo(pkg),
+			}
+		}
+	}
+
+	log15.Info("finished listing resolvable python packages", "totalDB", totalDBFetched, "resolvedDB", totalDBResolved, "totalConfig", len(packages), "timedout", timedOut)
+}
+
+func (s *PythonPackagesSource) makeRepo(pkg reposource.PythonPackage) *types.Repo {
+	urn := s.svc.URN()
+	return &types.Repo{
+		Name: pkg.RepoName(),
+		URI:  string(pkg.RepoName()),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          string(pkg.RepoName()),
+			ServiceID:   extsvc.TypePythonPackages,
+			ServiceType: extsvc.TypePythonPackages,
+		},
+		Private: false,
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: pkg.CloneURL(),
+			},
+		},
+		Metadata: &pythonpackages.Metadata{
+			Package: pkg,
+		},
+	}
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s *PythonPackagesSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
+
+func PythonDependencies(connection schema.PythonPackagesConnection) (dependencies []reposource.PythonDependency, err error) {
+	for _, dep := range connection.Dependencies {
+		dependency, err := reposource.ParsePythonDependency(dep)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, nil
+}
+
+func PythonPackages(connection schema.PythonPackagesConnection) ([]reposource.PythonPackage, error) {
+	isAdded := make(map[reposource.PythonPackage]bool)
+	packages := []reposource.PythonPackage{}
+	dependencies, err := PythonDependencies(connection)
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range dependencies {
+		pkg := dep.PythonPackage
+		if _, added := isAdded[pkg]; !added {
+			packages = append(packages, pkg)
+		}
+		isAdded[pkg] = true
+	}
+	return packages, nil
+}
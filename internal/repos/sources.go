@@ -58,8 +58,16 @@ func NewSource(svc *types.ExternalService, cf *httpcli.Factory) (Source, error)
 		return NewAWSCodeCommitSource(svc, cf)
 	case extsvc.KindPerforce:
 		return NewPerforceSource(svc)
+	case extsvc.KindMercurial:
+		return NewMercurialSource(svc)
+	case extsvc.KindSubversion:
+		return NewSubversionSource(svc)
 	case extsvc.KindJVMPackages:
 		return NewJVMPackagesSource(svc)
+	case extsvc.KindNPMPackages:
+		return NewNPMPackagesSource(svc)
+	case extsvc.KindPythonPackages:
+		return NewPythonPackagesSource(svc)
 	case extsvc.KindOther:
 		return NewOtherSource(svc, cf)
 	default:
@@ -0,0 +1,96 @@
+package repos
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// A SubversionSource yields repositories from a single Subversion connection
+// configured in Sourcegraph via the external services configuration.
+type SubversionSource struct {
+	svc    *types.ExternalService
+	config *schema.SubversionConnection
+}
+
+// NewSubversionSource returns a new SubversionSource from the given external
+// service.
+func NewSubversionSource(svc *types.ExternalService) (*SubversionSource, error) {
+	var c schema.SubversionConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Wrapf(err, "external service id=%d config error", svc.ID)
+	}
+	return newSubversionSource(svc, &c)
+}
+
+func newSubversionSource(svc *types.ExternalService, c *schema.SubversionConnection) (*SubversionSource, error) {
+	return &SubversionSource{
+		svc:    svc,
+		config: c,
+	}, nil
+}
+
+// ListRepos returns all Subversion repositories configured in the Subversion
+// connection.
+func (s SubversionSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	for _, repo := range s.config.Repos {
+		r, err := s.makeRepo(repo)
+		if err != nil {
+			results <- SourceResult{Source: s, Err: err}
+			return
+		}
+		results <- SourceResult{Source: s, Repo: r}
+	}
+}
+
+func (s SubversionSource) makeRepo(relativePath string) (*types.Repo, error) {
+	metadata := &subversion.Repo{
+		RelativePath: strings.Trim(relativePath, "/"),
+	}
+	cloneURL, err := subversionCloneURL(metadata, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(s.config.Url)
+	if err != nil {
+		return nil, err
+	}
+	// The user is only used for cloning.
+	base.User = nil
+	serviceID := extsvc.NormalizeBaseURL(base).String()
+	host := (&url.URL{Host: base.Host, Path: base.Path}).String()
+
+	urn := s.svc.URN()
+	return &types.Repo{
+		Name: api.RepoName(reposource.OtherRepoName(s.config.RepositoryPathPattern, host, metadata.RelativePath)),
+		URI:  reposource.OtherRepoName("", host, metadata.RelativePath),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          metadata.RelativePath,
+			ServiceType: extsvc.TypeSubversion,
+			ServiceID:   serviceID,
+		},
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: cloneURL,
+			},
+		},
+		Metadata: metadata,
+	}, nil
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s SubversionSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
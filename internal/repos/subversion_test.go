@@ -0,0 +1,59 @@
+package repos
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSubversionSource_makeRepo(t *testing.T) {
+	repos := []string{
+		"my-project",
+		"/tools/build/",
+	}
+
+	svc := types.ExternalService{ID: 1, Kind: extsvc.KindSubversion}
+
+	tests := []struct {
+		name   string
+		schema *schema.SubversionConnection
+	}{
+		{
+			name: "simple",
+			schema: &schema.SubversionConnection{
+				Url:      "https://svn.example.com/repos",
+				Username: "admin",
+				Password: "pa$$word",
+			},
+		}, {
+			name: "path-pattern",
+			schema: &schema.SubversionConnection{
+				Url:                   "svn+ssh://svn@svn.example.com/var/svn/",
+				RepositoryPathPattern: "svn/{repo}",
+			},
+		},
+	}
+	for _, test := range tests {
+		test.name = "SubversionSource_makeRepo_" + test.name
+		t.Run(test.name, func(t *testing.T) {
+			s, err := newSubversionSource(&svc, test.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*types.Repo
+			for _, repo := range repos {
+				r, err := s.makeRepo(repo)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, r)
+			}
+
+			testutil.AssertGolden(t, "testdata/golden/"+test.name, update(test.name), got)
+		})
+	}
+}
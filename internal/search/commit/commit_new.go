@@ -7,9 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/inconshreveable/log15"
 	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -55,6 +57,13 @@ func (j *CommitSearch) Run(ctx context.Context, stream streaming.Sender, repos s
 		return err
 	}
 
+	if j.RepoOpts.Limit == 0 && !j.HasTimeFilter && conf.SearchIndexCommitsEnabled() {
+		limit := search.SearchLimits(conf.Get()).CommitDiffMaxRepos
+		if err := checkUnindexedReposLimit(ctx, repoRevs, limit, resultType, gitserver.DefaultClient.CommitIndexStatus); err != nil {
+			return err
+		}
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
 	for _, repoRev := range repoRevs {
 		repoRev := repoRev // we close over repoRev in onMatches
@@ -282,6 +291,8 @@ func queryParameterToPredicate(parameter query.Parameter, caseSensitive, diff bo
 		newPred = &gitprotocol.CommitAfter{Time: t}
 	case query.FieldMessage:
 		newPred = &gitprotocol.MessageMatches{Expr: parameter.Value, IgnoreCase: !caseSensitive}
+	case query.FieldTrailer:
+		newPred = &gitprotocol.TrailerMatches{Expr: parameter.Value, IgnoreCase: !caseSensitive}
 	case query.FieldContent:
 		if diff {
 			newPred = &gitprotocol.DiffMatches{Expr: parameter.Value, IgnoreCase: !caseSensitive}
@@ -289,9 +300,9 @@ func queryParameterToPredicate(parameter query.Parameter, caseSensitive, diff bo
 			newPred = &gitprotocol.MessageMatches{Expr: parameter.Value, IgnoreCase: !caseSensitive}
 		}
 	case query.FieldFile:
-		newPred = &gitprotocol.DiffModifiesFile{Expr: parameter.Value, IgnoreCase: !caseSensitive}
+		newPred = modifiesFilePredicate(parameter.Value, !caseSensitive, diff)
 	case query.FieldLang:
-		newPred = &gitprotocol.DiffModifiesFile{Expr: search.LangToFileRegexp(parameter.Value), IgnoreCase: true}
+		newPred = modifiesFilePredicate(search.LangToFileRegexp(parameter.Value), true, diff)
 	}
 
 	if parameter.Negated && newPred != nil {
@@ -300,6 +311,16 @@ func queryParameterToPredicate(parameter query.Parameter, caseSensitive, diff bo
 	return newPred
 }
 
+// modifiesFilePredicate returns a predicate matching commits that modify a file
+// matching expr. Diff searches need the matching file diffs to constrain the
+// other diff predicates, while commit searches only need the changed paths.
+func modifiesFilePredicate(expr string, ignoreCase, diff bool) gitprotocol.Node {
+	if diff {
+		return &gitprotocol.DiffModifiesFile{Expr: expr, IgnoreCase: ignoreCase}
+	}
+	return &gitprotocol.CommitModifiesFile{Expr: expr, IgnoreCase: ignoreCase}
+}
+
 func protocolMatchToCommitMatch(repo types.MinimalRepo, diff bool, in protocol.CommitMatch) *result.CommitMatch {
 	var (
 		matchBody       string
@@ -403,14 +424,63 @@ func newReposLimitError(limit int, hasTimeFilter bool, resultType string) error
 	return &RepoLimitError{ResultType: resultType, Max: limit}
 }
 
+// reposLimit returns the maximum number of repos a commit or diff search may
+// cover. If commit indexing is enabled, this is the limit for repos searched
+// with a commit index; checkUnindexedReposLimit enforces the limit for the
+// other repos.
 func reposLimit(hasTimeFilter bool) int {
 	limits := search.SearchLimits(conf.Get())
 	if hasTimeFilter {
 		return limits.CommitDiffWithTimeFilterMaxRepos
 	}
+	if conf.SearchIndexCommitsEnabled() {
+		return limits.CommitDiffIndexedMaxRepos
+	}
 	return limits.CommitDiffMaxRepos
 }
 
+// checkUnindexedReposLimit returns a RepoLimitError if more than limit of
+// repoRevs would be searched without a commit index, which is the case for
+// repos whose index is missing or not current, and for repos searched at
+// revisions other than HEAD. Repos whose index status cannot be determined
+// are treated as not indexed.
+func checkUnindexedReposLimit(
+	ctx context.Context,
+	repoRevs []*search.RepositoryRevisions,
+	limit int,
+	resultType string,
+	commitIndexStatus func(context.Context, ...api.RepoName) (*protocol.CommitIndexStatusResponse, error),
+) error {
+	if len(repoRevs) <= limit {
+		return nil
+	}
+
+	var atHEAD []api.RepoName
+	for _, repoRev := range repoRevs {
+		if gitprotocol.RevisionsAreHEAD(searchRevsToGitserverRevs(repoRev.Revs)) {
+			atHEAD = append(atHEAD, repoRev.Repo.Name)
+		}
+	}
+
+	unindexed := len(repoRevs) - len(atHEAD)
+	if unindexed <= limit {
+		status, err := commitIndexStatus(ctx, atHEAD...)
+		if err != nil {
+			log15.Warn("failed to get commit index status", "error", err)
+		}
+		for _, repo := range atHEAD {
+			if status == nil || !status.Current[repo] {
+				unindexed++
+			}
+		}
+	}
+
+	if unindexed > limit {
+		return newReposLimitError(limit, false, resultType)
+	}
+	return nil
+}
+
 type DiffCommitError struct {
 	ResultType string
 	Max        int
@@ -6,11 +6,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
@@ -51,6 +54,7 @@ func TestQueryToGitQuery(t *testing.T) {
 			query.Parameter{Field: query.FieldFile, Value: "file"},
 			query.Parameter{Field: query.FieldMessage, Value: "message1"},
 			query.Pattern{Value: "message2"},
+			query.Parameter{Field: query.FieldTrailer, Value: "trailer"},
 		},
 		diff: false,
 		output: protocol.NewAnd(
@@ -60,7 +64,19 @@ func TestQueryToGitQuery(t *testing.T) {
 			&protocol.CommitterMatches{Expr: "committer", IgnoreCase: true},
 			&protocol.MessageMatches{Expr: "message1", IgnoreCase: true},
 			&protocol.MessageMatches{Expr: "message2", IgnoreCase: true},
+			&protocol.TrailerMatches{Expr: "trailer", IgnoreCase: true},
+			&protocol.CommitModifiesFile{Expr: "file", IgnoreCase: true},
+		),
+	}, {
+		name: "file filters constrain file diffs in diff searches",
+		input: []query.Node{
+			query.Parameter{Field: query.FieldFile, Value: "file"},
+			query.Pattern{Value: "a"},
+		},
+		diff: true,
+		output: protocol.NewAnd(
 			&protocol.DiffModifiesFile{Expr: "file", IgnoreCase: true},
+			&protocol.DiffMatches{Expr: "a", IgnoreCase: true},
 		),
 	}}
 
@@ -104,3 +120,70 @@ func resetMocks() {
 	database.Mocks = database.MockStores{}
 	backend.Mocks = backend.MockServices{}
 }
+
+func TestCheckUnindexedReposLimit(t *testing.T) {
+	repoRevs := func(revs map[api.RepoName]string) []*search.RepositoryRevisions {
+		var out []*search.RepositoryRevisions
+		for name, rev := range revs {
+			out = append(out, &search.RepositoryRevisions{
+				Repo: types.MinimalRepo{Name: name},
+				Revs: []search.RevisionSpecifier{{RevSpec: rev}},
+			})
+		}
+		return out
+	}
+	status := func(current ...api.RepoName) func(context.Context, ...api.RepoName) (*protocol.CommitIndexStatusResponse, error) {
+		return func(context.Context, ...api.RepoName) (*protocol.CommitIndexStatusResponse, error) {
+			resp := &protocol.CommitIndexStatusResponse{Current: map[api.RepoName]bool{}}
+			for _, repo := range current {
+				resp.Current[repo] = true
+			}
+			return resp, nil
+		}
+	}
+	failingStatus := func(context.Context, ...api.RepoName) (*protocol.CommitIndexStatusResponse, error) {
+		return nil, errors.New("gitserver unavailable")
+	}
+
+	cases := []struct {
+		name    string
+		revs    map[api.RepoName]string
+		status  func(context.Context, ...api.RepoName) (*protocol.CommitIndexStatusResponse, error)
+		wantErr bool
+	}{{
+		name:   "within limit without index",
+		revs:   map[api.RepoName]string{"a": ""},
+		status: status(),
+	}, {
+		name:   "over limit with current indexes",
+		revs:   map[api.RepoName]string{"a": "", "b": "HEAD", "c": ""},
+		status: status("a", "b"),
+	}, {
+		name:    "over limit without current indexes",
+		revs:    map[api.RepoName]string{"a": "", "b": "", "c": ""},
+		status:  status("a"),
+		wantErr: true,
+	}, {
+		name:    "indexes are not used for other revisions",
+		revs:    map[api.RepoName]string{"a": "main", "b": "main", "c": ""},
+		status:  status("a", "b", "c"),
+		wantErr: true,
+	}, {
+		name:    "unknown status",
+		revs:    map[api.RepoName]string{"a": "", "b": "", "c": ""},
+		status:  failingStatus,
+		wantErr: true,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkUnindexedReposLimit(context.Background(), repoRevs(tc.revs), 1, "diff", tc.status)
+			if !tc.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			var limitErr *RepoLimitError
+			require.ErrorAs(t, err, &limitErr)
+			require.Equal(t, 1, limitErr.Max)
+		})
+	}
+}
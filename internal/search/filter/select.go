@@ -38,6 +38,9 @@ var validSelectors = object{
 			"added":   nil,
 			"removed": nil,
 		},
+		"author":  nil,
+		"message": nil,
+		"date":    nil,
 	},
 	Content: nil,
 	File: {
@@ -38,6 +38,7 @@ func SearchLimits(c *conf.Unified) schema.SearchLimits {
 	withDefault(&limits.MaxRepos, math.MaxInt32>>1)
 	withDefault(&limits.CommitDiffMaxRepos, 50)
 	withDefault(&limits.CommitDiffWithTimeFilterMaxRepos, 10000)
+	withDefault(&limits.CommitDiffIndexedMaxRepos, 10000)
 	withDefault(&limits.MaxTimeoutSeconds, 60)
 
 	return limits
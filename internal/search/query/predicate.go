@@ -31,6 +31,8 @@ var DefaultPredicateRegistry = PredicateRegistry{
 		"contains.file":         func() Predicate { return &RepoContainsFilePredicate{} },
 		"contains.content":      func() Predicate { return &RepoContainsContentPredicate{} },
 		"contains.commit.after": func() Predicate { return &RepoContainsCommitAfterPredicate{} },
+		"has.topic":             func() Predicate { return &RepoHasTopicPredicate{} },
+		"has.meta":              func() Predicate { return &RepoHasMetaPredicate{} },
 	},
 	FieldFile: {
 		"contains.content": func() Predicate { return &FileContainsContentPredicate{} },
@@ -261,6 +263,94 @@ func (f *RepoContainsCommitAfterPredicate) Plan(parent Basic) (Plan, error) {
 	return ToPlan(Dnf(nodes))
 }
 
+/* repo:has.topic(topic) */
+
+// RepoHasTopicPredicate represents the `repo:has.topic()` predicate, which
+// filters to repos tagged with the given topic on their code host (GitHub
+// topics, GitLab tags).
+type RepoHasTopicPredicate struct {
+	Topic string
+}
+
+func (f *RepoHasTopicPredicate) ParseParams(params string) error {
+	if params == "" {
+		return errors.Errorf("has.topic argument should not be empty")
+	}
+	f.Topic = params
+	return nil
+}
+
+func (f *RepoHasTopicPredicate) Field() string { return FieldRepo }
+func (f *RepoHasTopicPredicate) Name() string  { return "has.topic" }
+func (f *RepoHasTopicPredicate) Plan(parent Basic) (Plan, error) {
+	nodes := make([]Node, 0, 3)
+	nodes = append(nodes, Parameter{
+		Field: FieldCount,
+		Value: "99999",
+	}, Parameter{
+		Field: FieldRepoHasTopic,
+		Value: f.Topic,
+	})
+
+	nodes = append(nodes, nonPredicateRepos(parent)...)
+	return ToPlan(Dnf(nodes))
+}
+
+/* repo:has.meta(key:value) */
+
+// RepoHasMetaPredicate represents the `repo:has.meta()` predicate, which
+// filters to repos whose code host metadata has the field Key. If Value is
+// non-nil, the field must also equal it.
+type RepoHasMetaPredicate struct {
+	Key   string
+	Value *string
+}
+
+func (f *RepoHasMetaPredicate) ParseParams(params string) error {
+	key, value, err := ParseRepoHasMeta(params)
+	if err != nil {
+		return err
+	}
+	f.Key, f.Value = key, value
+	return nil
+}
+
+func (f *RepoHasMetaPredicate) Field() string { return FieldRepo }
+func (f *RepoHasMetaPredicate) Name() string  { return "has.meta" }
+func (f *RepoHasMetaPredicate) Plan(parent Basic) (Plan, error) {
+	value := f.Key
+	if f.Value != nil {
+		value += ":" + *f.Value
+	}
+
+	nodes := make([]Node, 0, 3)
+	nodes = append(nodes, Parameter{
+		Field: FieldCount,
+		Value: "99999",
+	}, Parameter{
+		Field: FieldRepoHasMeta,
+		Value: value,
+	})
+
+	nodes = append(nodes, nonPredicateRepos(parent)...)
+	return ToPlan(Dnf(nodes))
+}
+
+// ParseRepoHasMeta parses the argument of repo:has.meta(), which is either a
+// key or a key:value pair.
+func ParseRepoHasMeta(params string) (key string, value *string, err error) {
+	if i := strings.Index(params, ":"); i >= 0 {
+		v := params[i+1:]
+		key, value = params[:i], &v
+	} else {
+		key = params
+	}
+	if key == "" {
+		return "", nil, errors.Errorf("has.meta argument should have a non-empty key")
+	}
+	return key, value, nil
+}
+
 type FileContainsContentPredicate struct {
 	Pattern string
 }
@@ -56,6 +56,98 @@ func TestRepoContainsPredicate(t *testing.T) {
 	})
 }
 
+func TestRepoHasTopicPredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		p := &RepoHasTopicPredicate{}
+		if err := p.ParseParams("payments"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if want := (&RepoHasTopicPredicate{Topic: "payments"}); !reflect.DeepEqual(want, p) {
+			t.Fatalf("expected %#v, got %#v", want, p)
+		}
+
+		if err := (&RepoHasTopicPredicate{}).ParseParams(""); err == nil {
+			t.Fatal("expected error but got none")
+		}
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		parent, err := ParseLiteral("repo:^github\\.com/sourcegraph/ repo:has.topic(payments) foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		basic, err := ToBasicQuery(parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p := &RepoHasTopicPredicate{Topic: "payments"}
+		plan, err := p.Plan(basic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `(and "count:99999" "repohastopic:payments" "repo:^github\\.com/sourcegraph/")`
+		if got := plan.ToParseTree().String(); got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	})
+}
+
+func TestRepoHasMetaPredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		value := "internal"
+		tests := []struct {
+			params string
+			want   *RepoHasMetaPredicate
+		}{
+			{params: "forked_from_project", want: &RepoHasMetaPredicate{Key: "forked_from_project"}},
+			{params: "visibility:internal", want: &RepoHasMetaPredicate{Key: "visibility", Value: &value}},
+			{params: "", want: nil},
+			{params: ":internal", want: nil},
+		}
+		for _, tc := range tests {
+			p := &RepoHasMetaPredicate{}
+			err := p.ParseParams(tc.params)
+			if tc.want == nil {
+				if err == nil {
+					t.Fatalf("expected error for %q but got none", tc.params)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %s", tc.params, err)
+			}
+			if !reflect.DeepEqual(tc.want, p) {
+				t.Fatalf("expected %#v, got %#v", tc.want, p)
+			}
+		}
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		parent, err := ParseLiteral("repo:^github\\.com/sourcegraph/ repo:has.meta(visibility:internal) foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		basic, err := ToBasicQuery(parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value := "internal"
+		p := &RepoHasMetaPredicate{Key: "visibility", Value: &value}
+		plan, err := p.Plan(basic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `(and "count:99999" "repohasmeta:visibility:internal" "repo:^github\\.com/sourcegraph/")`
+		if got := plan.ToParseTree().String(); got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	})
+}
+
 func TestParseAsPredicate(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -401,6 +401,8 @@ func (q Q) valueToTypedValue(field, value string, label labels) []*Value {
 
 	case
 		FieldRepoHasCommitAfter,
+		FieldRepoHasTopic,
+		FieldRepoHasMeta,
 		FieldBefore, "until",
 		FieldAfter, "since":
 		return []*Value{{String: &value}}
@@ -408,7 +410,8 @@ func (q Q) valueToTypedValue(field, value string, label labels) []*Value {
 	case
 		FieldAuthor,
 		FieldCommitter,
-		FieldMessage, "m", "msg":
+		FieldMessage, "m", "msg",
+		FieldTrailer:
 		return []*Value{{Regexp: parseRegexpOrPanic(field, value)}}
 
 	case
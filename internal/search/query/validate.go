@@ -322,6 +322,10 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 	case
 		FieldRepoHasCommitAfter:
 		return satisfies(isSingular, isNotNegated)
+	case
+		FieldRepoHasTopic,
+		FieldRepoHasMeta:
+		return satisfies(isNotNegated)
 	case
 		FieldBefore,
 		FieldAfter:
@@ -329,7 +333,8 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 	case
 		FieldAuthor,
 		FieldCommitter,
-		FieldMessage:
+		FieldMessage,
+		FieldTrailer:
 		return satisfies(isValidRegexp)
 	case
 		FieldIndex,
@@ -398,7 +403,7 @@ func validateCommitParameters(nodes []Node) error {
 	var seenCommitParam string
 	var typeCommitExists bool
 	VisitParameter(nodes, func(field, value string, _ bool, _ Annotation) {
-		if field == FieldAuthor || field == FieldBefore || field == FieldAfter || field == FieldMessage {
+		if field == FieldAuthor || field == FieldBefore || field == FieldAfter || field == FieldMessage || field == FieldTrailer {
 			seenCommitParam = field
 		}
 		if field == FieldType && (value == "commit" || value == "diff") {
@@ -140,6 +140,15 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 		return Resolved{}, err
 	}
 
+	metadataFilters := make([]database.RepoMetadataFilter, 0, len(op.HasMeta))
+	for _, meta := range op.HasMeta {
+		key, value, err := query.ParseRepoHasMeta(meta)
+		if err != nil {
+			return Resolved{}, err
+		}
+		metadataFilters = append(metadataFilters, database.RepoMetadataFilter{Key: key, Value: value})
+	}
+
 	options := database.ReposListOptions{
 		IncludePatterns:       includePatterns,
 		ExcludePattern:        UnionRegExps(excludePatterns),
@@ -153,6 +162,8 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 		OnlyArchived:           op.OnlyArchived,
 		NoPrivate:              op.Visibility == query.Public,
 		OnlyPrivate:            op.Visibility == query.Private,
+		Topics:                 op.HasTopics,
+		Metadata:               metadataFilters,
 		SearchContextID:        searchContext.ID,
 		UserID:                 searchContext.NamespaceUserID,
 		OrgID:                  searchContext.NamespaceOrgID,
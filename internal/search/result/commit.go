@@ -21,6 +21,14 @@ type CommitMatch struct {
 	MessagePreview *HighlightedString
 	DiffPreview    *HighlightedString
 	Body           HighlightedString
+
+	// Projection is set when the match is narrowed to a single commit field by
+	// select:commit.author, select:commit.message or select:commit.date. It
+	// holds the selected value. Matches in the same repository with the same
+	// projection are merged, and ProjectionCount is the number of commits
+	// merged into the match.
+	Projection      string
+	ProjectionCount int
 }
 
 // ResultCount for CommitSearchResult returns the number of highlights if there
@@ -29,6 +37,9 @@ type CommitMatch struct {
 // compatibility for our GraphQL API. The GraphQL API calls ResultCount on the
 // resolver, while streaming calls ResultCount on CommitSearchResult.
 func (r *CommitMatch) ResultCount() int {
+	if r.Projection != "" {
+		return r.ProjectionCount
+	}
 	if n := len(r.Body.Highlights); n > 0 {
 		return n
 	}
@@ -72,6 +83,9 @@ func (r *CommitMatch) Select(path filter.SelectPath) Match {
 			}
 			return nil
 		}
+		if len(fields) == 1 {
+			return selectCommitField(r, fields[0])
+		}
 		return r
 	}
 	return nil
@@ -82,6 +96,10 @@ func (r *CommitMatch) Select(path filter.SelectPath) Match {
 // cannot reliably merge this way because of offset issues with markdown
 // rendering.
 func (r *CommitMatch) AppendMatches(src *CommitMatch) {
+	if r.Projection != "" {
+		r.ProjectionCount += src.ProjectionCount
+		return
+	}
 	if r.MessagePreview != nil && src.MessagePreview != nil {
 		r.MessagePreview.Highlights = append(r.MessagePreview.Highlights, src.MessagePreview.Highlights...)
 		r.Body.Highlights = append(r.Body.Highlights, src.Body.Highlights...)
@@ -90,6 +108,13 @@ func (r *CommitMatch) AppendMatches(src *CommitMatch) {
 
 // Key implements Match interface's Key() method
 func (r *CommitMatch) Key() Key {
+	if r.Projection != "" {
+		return Key{
+			TypeRank:   rankCommitMatch,
+			Repo:       r.Repo.Name,
+			Projection: r.Projection,
+		}
+	}
 	typeRank := rankCommitMatch
 	if r.DiffPreview != nil {
 		typeRank = rankDiffMatch
@@ -204,4 +229,38 @@ func selectCommitDiffKind(c *CommitMatch, field string) Match {
 	return nil // No matching lines.
 }
 
+// selectCommitField returns a copy of c narrowed to the commit field `field`
+// (author, message or date). The body of the returned match is the selected
+// value, so that the distinct authors, messages or days of each repository can
+// be read off the result set directly, along with the number of commits for
+// each. Dates are bucketed by day in UTC.
+func selectCommitField(c *CommitMatch, field string) Match {
+	var value string
+	switch field {
+	case "author":
+		value = c.Commit.Author.Name
+		if c.Commit.Author.Email != "" {
+			value = fmt.Sprintf("%s <%s>", c.Commit.Author.Name, c.Commit.Author.Email)
+		}
+	case "message":
+		value = strings.TrimSpace(string(c.Commit.Message))
+	case "date":
+		value = c.Commit.Author.Date.UTC().Format("2006-01-02")
+	default:
+		return nil
+	}
+
+	body := HighlightedString{Value: value}
+	return &CommitMatch{
+		Commit:          c.Commit,
+		Repo:            c.Repo,
+		Refs:            c.Refs,
+		SourceRefs:      c.SourceRefs,
+		MessagePreview:  &body,
+		Body:            body,
+		Projection:      value,
+		ProjectionCount: 1,
+	}
+}
+
 func (r *CommitMatch) searchResultMarker() {}
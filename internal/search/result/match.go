@@ -55,6 +55,11 @@ type Key struct {
 	// Empty if there is no file associated with the match (e.g. RepoMatch or CommitMatch)
 	Path string
 
+	// Projection is the selected value of a match narrowed to a field that does
+	// not identify it, such as the author of a commit match.
+	// Empty if the match is not narrowed this way.
+	Projection string
+
 	// TypeRank is the sorting rank of the type this key belongs to.
 	TypeRank int
 }
@@ -77,6 +82,10 @@ func (k Key) Less(other Key) bool {
 		return k.Path < other.Path
 	}
 
+	if k.Projection != other.Projection {
+		return k.Projection < other.Projection
+	}
+
 	return k.TypeRank < other.TypeRank
 }
 
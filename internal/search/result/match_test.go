@@ -1,11 +1,17 @@
 package result
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hexops/autogold"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
 )
 
 func TestSelect(t *testing.T) {
@@ -30,3 +36,45 @@ func TestSelect(t *testing.T) {
 	autogold.Want("filter any symbol", "a():func, b():function, var c:variable").Equal(t, test("symbol"))
 	autogold.Want("filter symbol kind variable", "var c:variable").Equal(t, test("symbol.variable"))
 }
+
+func TestSelectCommitField(t *testing.T) {
+	commit := func(repo, id, name, email, message string, date time.Time) *CommitMatch {
+		return &CommitMatch{
+			Repo: types.MinimalRepo{Name: api.RepoName(repo)},
+			Commit: gitapi.Commit{
+				ID:      api.CommitID(id),
+				Author:  gitapi.Signature{Name: name, Email: email, Date: date},
+				Message: gitapi.Message(message),
+			},
+		}
+	}
+
+	day := time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC)
+	data := []Match{
+		commit("a", "1", "alice", "alice@example.com", "fix a\n", day),
+		commit("a", "2", "bob", "bob@example.com", "fix b\n", day.Add(time.Hour)),
+		commit("a", "3", "alice", "alice@example.com", "fix a\n", day.Add(2*time.Hour)),
+		commit("a", "4", "alice", "alice@example.com", "fix a\n", day.Add(24*time.Hour)),
+		commit("b", "5", "alice", "alice@example.com", "fix a\n", day),
+	}
+
+	test := func(input string) string {
+		selectPath, _ := filter.SelectPathFromString(input)
+		dedup := NewDeduper()
+		for _, m := range data {
+			if selected := m.Select(selectPath); selected != nil {
+				dedup.Add(selected)
+			}
+		}
+		var values []string
+		for _, m := range dedup.Results() {
+			c := m.(*CommitMatch)
+			values = append(values, fmt.Sprintf("%s %s:%d", c.Repo.Name, c.Body.Value, c.ResultCount()))
+		}
+		return strings.Join(values, ", ")
+	}
+
+	autogold.Want("select commit author", "a alice <alice@example.com>:3, a bob <bob@example.com>:1, b alice <alice@example.com>:1").Equal(t, test("commit.author"))
+	autogold.Want("select commit message", "a fix a:3, a fix b:1, b fix a:1").Equal(t, test("commit.message"))
+	autogold.Want("select commit date", "a 2021-11-02:3, a 2021-11-03:1, b 2021-11-02:1").Equal(t, test("commit.date"))
+}
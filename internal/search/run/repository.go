@@ -44,6 +44,8 @@ func (s *RepoSearch) Run(ctx context.Context, stream streaming.Sender, repos sea
 		query.FieldCase:               {},
 		query.FieldRepoHasFile:        {},
 		query.FieldRepoHasCommitAfter: {},
+		query.FieldRepoHasTopic:       {},
+		query.FieldRepoHasMeta:        {},
 		query.FieldPatternType:        {},
 		query.FieldSelect:             {},
 	}
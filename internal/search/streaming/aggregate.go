@@ -0,0 +1,143 @@
+package streaming
+
+import (
+	"path"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/inventory"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// AggregateMode is the dimension by which an Aggregator buckets matches.
+type AggregateMode string
+
+const (
+	AggregateRepo    AggregateMode = "repo"
+	AggregateLang    AggregateMode = "lang"
+	AggregateAuthor  AggregateMode = "author"
+	AggregatePathDir AggregateMode = "path.dir"
+)
+
+// ParseAggregateMode returns the AggregateMode for s, or an error if s is not
+// a supported mode.
+func ParseAggregateMode(s string) (AggregateMode, error) {
+	switch m := AggregateMode(s); m {
+	case AggregateRepo, AggregateLang, AggregateAuthor, AggregatePathDir:
+		return m, nil
+	}
+	return "", errors.Errorf("unsupported aggregate mode %q, expected one of repo, lang, author or path.dir", s)
+}
+
+// AggregateBucket is the number of matches counted for a single value of an
+// AggregateMode.
+type AggregateBucket struct {
+	Label string
+	Count int
+}
+
+const (
+	// maxAggregateLabels is the maximum number of labels an Aggregator
+	// counts matches for. Matches with further labels are only counted as
+	// other matches.
+	maxAggregateLabels = 10000
+
+	// maxAggregateBuckets is the maximum number of buckets returned by
+	// Aggregator.Compute.
+	maxAggregateBuckets = 100
+)
+
+// Aggregator counts every match sent on a search stream into buckets. Unlike
+// the results shown to a user, it is computed from all events and so is not
+// affected by the display limit.
+type Aggregator struct {
+	Mode AggregateMode
+
+	// Dirty is true if buckets have changed since the last call to Compute.
+	Dirty bool
+
+	buckets map[string]int
+
+	// other is the count of matches whose label was dropped because of
+	// maxAggregateLabels.
+	other int
+}
+
+// Update internal state for the results in event.
+func (a *Aggregator) Update(event SearchEvent) {
+	// Initialize state on first call.
+	if a.buckets == nil {
+		a.buckets = make(map[string]int)
+	}
+
+	for _, match := range event.Results {
+		label, ok := a.label(match)
+		if !ok {
+			continue
+		}
+		if _, ok := a.buckets[label]; !ok && len(a.buckets) >= maxAggregateLabels {
+			a.other += match.ResultCount()
+		} else {
+			a.buckets[label] += match.ResultCount()
+		}
+		a.Dirty = true
+	}
+}
+
+func (a *Aggregator) label(match result.Match) (string, bool) {
+	switch a.Mode {
+	case AggregateRepo:
+		return string(match.RepoName().Name), true
+	case AggregateLang:
+		fm, ok := match.(*result.FileMatch)
+		if !ok {
+			return "", false
+		}
+		language, _ := inventory.GetLanguageByFilename(fm.Path)
+		if language == "" {
+			return "", false
+		}
+		return language, true
+	case AggregatePathDir:
+		fm, ok := match.(*result.FileMatch)
+		if !ok {
+			return "", false
+		}
+		return path.Dir(fm.Path), true
+	case AggregateAuthor:
+		cm, ok := match.(*result.CommitMatch)
+		if !ok {
+			return "", false
+		}
+		return cm.Commit.Author.Name, true
+	}
+	return "", false
+}
+
+// Compute returns the buckets with the highest counts ordered by descending
+// count, and the count of the matches in no returned bucket. It marks the
+// aggregator as clean.
+func (a *Aggregator) Compute() (buckets []AggregateBucket, other int) {
+	a.Dirty = false
+
+	buckets = make([]AggregateBucket, 0, len(a.buckets))
+	for label, count := range a.buckets {
+		buckets = append(buckets, AggregateBucket{Label: label, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Label < buckets[j].Label
+	})
+
+	other = a.other
+	if len(buckets) > maxAggregateBuckets {
+		for _, b := range buckets[maxAggregateBuckets:] {
+			other += b.Count
+		}
+		buckets = buckets[:maxAggregateBuckets]
+	}
+	return buckets, other
+}
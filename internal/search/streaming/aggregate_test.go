@@ -0,0 +1,127 @@
+package streaming
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
+)
+
+func TestAggregator(t *testing.T) {
+	fileMatch := func(repo, path string, lines int) *result.FileMatch {
+		lineMatches := make([]*result.LineMatch, 0, lines)
+		for i := 0; i < lines; i++ {
+			lineMatches = append(lineMatches, &result.LineMatch{OffsetAndLengths: [][2]int32{{0, 1}}})
+		}
+		return &result.FileMatch{
+			File: result.File{
+				Repo: types.MinimalRepo{Name: api.RepoName("repo/" + repo)},
+				Path: path,
+			},
+			LineMatches: lineMatches,
+		}
+	}
+	commitMatch := func(repo, author string) *result.CommitMatch {
+		return &result.CommitMatch{
+			Repo:   types.MinimalRepo{Name: api.RepoName("repo/" + repo)},
+			Commit: gitapi.Commit{Author: gitapi.Signature{Name: author}},
+		}
+	}
+
+	events := []SearchEvent{{
+		Results: []result.Match{
+			fileMatch("a", "cmd/main.go", 2),
+			fileMatch("a", "cmd/util.go", 1),
+			fileMatch("b", "README.md", 1),
+		},
+	}, {
+		Results: []result.Match{
+			commitMatch("a", "alice"),
+			commitMatch("b", "alice"),
+			commitMatch("b", "bob"),
+		},
+	}}
+
+	cases := []struct {
+		mode AggregateMode
+		want []AggregateBucket
+	}{{
+		mode: AggregateRepo,
+		want: []AggregateBucket{{"repo/a", 4}, {"repo/b", 3}},
+	}, {
+		mode: AggregateLang,
+		want: []AggregateBucket{{"Go", 3}, {"Markdown", 1}},
+	}, {
+		mode: AggregatePathDir,
+		want: []AggregateBucket{{"cmd", 3}, {".", 1}},
+	}, {
+		mode: AggregateAuthor,
+		want: []AggregateBucket{{"alice", 2}, {"bob", 1}},
+	}}
+
+	for _, tc := range cases {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			a := &Aggregator{Mode: tc.mode}
+			for _, e := range events {
+				a.Update(e)
+			}
+			if !a.Dirty {
+				t.Fatal("expected aggregator to be dirty after update")
+			}
+			got, other := a.Compute()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected buckets (-want +got):\n%s", diff)
+			}
+			if other != 0 {
+				t.Fatalf("expected no other matches, got %d", other)
+			}
+			if a.Dirty {
+				t.Fatal("expected aggregator to be clean after compute")
+			}
+		})
+	}
+}
+
+func TestAggregator_Limits(t *testing.T) {
+	a := &Aggregator{Mode: AggregateRepo}
+	for i := 0; i < maxAggregateLabels+10; i++ {
+		a.Update(SearchEvent{Results: []result.Match{&result.CommitMatch{
+			Repo: types.MinimalRepo{Name: api.RepoName(fmt.Sprintf("repo/%05d", i))},
+		}}})
+	}
+	// The first repo is counted again, even though the labels are capped.
+	a.Update(SearchEvent{Results: []result.Match{&result.CommitMatch{
+		Repo: types.MinimalRepo{Name: "repo/00000"},
+	}}})
+
+	if n := len(a.buckets); n != maxAggregateLabels {
+		t.Fatalf("expected %d labels, got %d", maxAggregateLabels, n)
+	}
+
+	buckets, other := a.Compute()
+	if len(buckets) != maxAggregateBuckets {
+		t.Fatalf("expected %d buckets, got %d", maxAggregateBuckets, len(buckets))
+	}
+	if want := (AggregateBucket{Label: "repo/00000", Count: 2}); buckets[0] != want {
+		t.Fatalf("expected first bucket %v, got %v", want, buckets[0])
+	}
+	if want := maxAggregateLabels + 10 - maxAggregateBuckets; other != want {
+		t.Fatalf("expected %d other matches, got %d", want, other)
+	}
+}
+
+func TestParseAggregateMode(t *testing.T) {
+	for _, s := range []string{"repo", "lang", "author", "path.dir"} {
+		if _, err := ParseAggregateMode(s); err != nil {
+			t.Errorf("unexpected error for %q: %s", s, err)
+		}
+	}
+	if _, err := ParseAggregateMode("file"); err == nil {
+		t.Error("expected error for unsupported mode")
+	}
+}
@@ -142,6 +142,9 @@ type EventCommitMatch struct {
 	Content         string     `json:"content"`
 	// [line, character, length]
 	Ranges [][3]int32 `json:"ranges"`
+	// Count is the number of commits the match stands for when it is
+	// narrowed to a commit field, such as with select:commit.date.
+	Count int `json:"count,omitempty"`
 }
 
 func (e *EventCommitMatch) eventMatch() {}
@@ -156,6 +159,23 @@ type EventFilter struct {
 	Kind     string `json:"kind"`
 }
 
+// EventAggregate is the bucketed count of all matches found so far, sent when
+// the client requests an aggregation mode. Each event replaces the previous.
+type EventAggregate struct {
+	Mode    string                 `json:"mode"`
+	Buckets []EventAggregateBucket `json:"buckets"`
+
+	// OtherCount is the number of matches which are not counted in Buckets,
+	// since only the buckets with the highest counts are sent.
+	OtherCount int `json:"otherCount,omitempty"`
+}
+
+// EventAggregateBucket is the number of matches counted for Label.
+type EventAggregateBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
 // EventAlert is GQL.SearchAlert. It replaces when sent to match existing
 // behaviour.
 type EventAlert struct {
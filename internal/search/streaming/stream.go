@@ -72,11 +72,17 @@ func WithLimit(ctx context.Context, parent Sender, limit int) (context.Context,
 
 // WithSelect returns a child Stream of parent that runs the select operation
 // on each event, deduplicating where possible.
-func WithSelect(parent Sender, s filter.SelectPath) Sender {
+//
+// Commits narrowed to a field are merged by their selected value, so that
+// each value is sent once with the number of commits it was selected from.
+// They are held back until flush is called, which must happen once all
+// events were sent.
+func WithSelect(parent Sender, s filter.SelectPath) (stream Sender, flush func()) {
 	var mux sync.Mutex
 	dedup := result.NewDeduper()
+	projections := result.NewDeduper()
 
-	return StreamFunc(func(e SearchEvent) {
+	stream = StreamFunc(func(e SearchEvent) {
 		if parent == nil {
 			return
 		}
@@ -89,6 +95,13 @@ func WithSelect(parent Sender, s filter.SelectPath) Sender {
 				continue
 			}
 
+			// Commits narrowed to a field are counted towards their selected
+			// value, which is sent by flush.
+			if c, ok := current.(*result.CommitMatch); ok && c.Projection != "" {
+				projections.Add(current)
+				continue
+			}
+
 			// If the selected file is a file match, send it unconditionally
 			// to ensure we get all line matches for a file.
 			_, isFileMatch := current.(*result.FileMatch)
@@ -105,6 +118,21 @@ func WithSelect(parent Sender, s filter.SelectPath) Sender {
 		mux.Unlock()
 		parent.Send(e)
 	})
+
+	flush = func() {
+		if parent == nil {
+			return
+		}
+		mux.Lock()
+		results := projections.Results()
+		projections = result.NewDeduper()
+		mux.Unlock()
+
+		if len(results) > 0 {
+			parent.Send(SearchEvent{Results: results})
+		}
+	}
+	return stream, flush
 }
 
 type StreamFunc func(SearchEvent)
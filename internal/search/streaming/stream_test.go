@@ -0,0 +1,44 @@
+package streaming
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
+)
+
+func TestWithSelectCommitField(t *testing.T) {
+	var sent []result.Match
+	stream, flush := WithSelect(StreamFunc(func(e SearchEvent) {
+		sent = append(sent, e.Results...)
+	}), filter.SelectPath{filter.Commit, "author"})
+
+	commit := func(repo, author string) *result.CommitMatch {
+		return &result.CommitMatch{
+			Repo:   types.MinimalRepo{Name: api.RepoName("repo" + repo)},
+			Commit: gitapi.Commit{Author: gitapi.Signature{Name: author}},
+		}
+	}
+	stream.Send(SearchEvent{Results: []result.Match{commit("a", "alice"), commit("a", "alice")}})
+	stream.Send(SearchEvent{Results: []result.Match{commit("a", "alice"), commit("b", "alice")}})
+	if len(sent) != 0 {
+		t.Fatalf("expected projected commits to be held back until flush, got %d", len(sent))
+	}
+	flush()
+
+	// Each author is sent once per repository, counting all of its commits.
+	want := map[string]int{"repoa": 3, "repob": 1}
+	if len(sent) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(sent))
+	}
+	for _, m := range sent {
+		repo := string(m.Key().Repo)
+		if n := m.ResultCount(); want[repo] != n {
+			t.Errorf("expected %d commits for %s, got %d", want[repo], repo, n)
+		}
+	}
+}
@@ -0,0 +1,540 @@
+package store
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+// Blob is a file of a commit and the git blob holding its contents.
+type Blob struct {
+	Path string
+
+	// OID is the hex encoded object ID of the git blob.
+	OID string
+
+	// Size is the size of the blob in bytes.
+	Size int64
+}
+
+// defaultMaxBlobFetchPaths is the default of Store.MaxBlobFetchPaths.
+const defaultMaxBlobFetchPaths = 1000
+
+// blobManifest lists the searchable files of a commit and where their
+// contents are stored. It is stored as JSON in the cache.
+type blobManifest struct {
+	// Packs are the cache keys of the packs of blobs holding the contents of
+	// Files. A pack is a zip of blobs named by their OID. Its key is derived
+	// from the OIDs of its blobs, so packs are shared by all repositories.
+	Packs []string
+
+	Files []blobManifestFile
+}
+
+type blobManifestFile struct {
+	Path string
+
+	// OID is the OID of the blob holding the contents of the file. It is
+	// empty if the contents of the file are not searched.
+	OID string
+
+	// Pack is the index in Packs of the pack holding the contents of the
+	// file, or -1 if OID is empty.
+	Pack int
+	Off  int64
+	Len  int32
+}
+
+// blobLocation is where the contents of a blob are stored.
+type blobLocation struct {
+	pack string
+	off  int64
+	len  int32
+}
+
+// PrepareBlobs returns the searchable files of repo at commit, the same as
+// the archive prepared by PrepareZip. Instead of a zip per commit, the
+// contents of files are stored once per git blob in packs, and a manifest per
+// commit lists its files. The blobs index of the store maps each stored blob
+// to its pack, so only the blobs which are not stored yet are fetched, and
+// searching many commits fetches and stores little more than searching one of
+// them.
+//
+// The returned ZipFile MUST be Closed when it is no longer needed.
+func (s *Store) PrepareBlobs(ctx context.Context, repo api.RepoName, commit api.CommitID) (zf *ZipFile, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "Store.PrepareBlobs")
+	ext.Component.Set(span, "store")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	// Ensure we have initialized
+	s.Start()
+
+	if s.ListBlobs == nil {
+		return nil, errors.New("Store.ListBlobs must be set")
+	}
+	// We rely on commit being absolute for caching.
+	if len(commit) != 40 {
+		return nil, errors.Errorf("commit must be resolved (repo=%q, commit=%q)", repo, commit)
+	}
+
+	largeFilePatterns := conf.Get().SearchLargeFiles
+	key := fmt.Sprintf("%q %q %q manifest", repo, commit, largeFilePatterns)
+
+	for attempt := 0; ; attempt++ {
+		m, path, err := s.openManifest(ctx, key, func(ctx context.Context) (*blobManifest, error) {
+			return s.buildManifest(ctx, repo, commit, largeFilePatterns)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		zf, err := s.openPacks(m)
+		if err == nil {
+			return zf, nil
+		}
+		if attempt > 0 {
+			return nil, err
+		}
+		// A pack of the manifest was evicted or rebuilt, so we build the
+		// manifest again which fetches the blobs of the pack.
+		blobManifestsStale.Inc()
+		_ = os.Remove(path)
+	}
+}
+
+// openManifest returns the manifest with the cache key key, building it with
+// build if it is not in the cache.
+func (s *Store) openManifest(ctx context.Context, key string, build func(context.Context) (*blobManifest, error)) (*blobManifest, string, error) {
+	f, err := s.cache.Open(ctx, key, func(ctx context.Context) (io.ReadCloser, error) {
+		m, err := build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	var m blobManifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		_ = os.Remove(f.Path)
+		return nil, "", errors.Wrapf(err, "decoding blob manifest %s", f.Path)
+	}
+	return &m, f.Path, nil
+}
+
+// buildManifest lists the searchable files of repo at commit, and fetches the
+// blobs of the files which are not stored yet into a new pack.
+func (s *Store) buildManifest(ctx context.Context, repo api.RepoName, commit api.CommitID, largeFilePatterns []string) (*blobManifest, error) {
+	blobs, err := s.ListBlobs(ctx, repo, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := func(hdr *tar.Header) bool { return false } // default: don't filter
+	if s.FilterTar != nil {
+		filter, err = s.FilterTar(ctx, repo, commit)
+		if err != nil {
+			return nil, errors.Errorf("error while calling FilterTar: %w", err)
+		}
+	}
+
+	oids := make([]string, 0, len(blobs))
+	for _, b := range blobs {
+		oids = append(oids, b.OID)
+	}
+	stored := s.storedBlobs(oids)
+
+	// missing is the path of a file of each blob which is not stored yet.
+	missing := map[string]string{}
+	files := make([]blobManifestFile, 0, len(blobs))
+	for _, b := range blobs {
+		if filter(&tar.Header{Name: b.Path, Size: b.Size, Typeflag: tar.TypeReg}) {
+			continue
+		}
+
+		f := blobManifestFile{Path: b.Path, OID: b.OID, Pack: -1}
+		// We do not search the content of large files unless they are
+		// allowed.
+		if b.Size > maxFileSize && !ignoreSizeMax(b.Path, largeFilePatterns) {
+			f.OID = ""
+		} else if _, ok := stored[b.OID]; !ok {
+			if _, ok := missing[b.OID]; !ok {
+				missing[b.OID] = b.Path
+			}
+		}
+		files = append(files, f)
+	}
+
+	fetched := map[string]blobLocation{}
+	if len(missing) > 0 {
+		fetched, err = s.fetchPack(ctx, repo, commit, largeFilePatterns, missing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m := &blobManifest{Files: files[:0]}
+	packIndex := map[string]int{}
+	for _, f := range files {
+		if f.OID != "" {
+			loc, ok := fetched[f.OID]
+			if !ok {
+				loc, ok = stored[f.OID]
+			}
+			if !ok {
+				// The file is not in the archive of the commit, for example
+				// because of an export-ignore git attribute.
+				continue
+			}
+			i, ok := packIndex[loc.pack]
+			if !ok {
+				i = len(m.Packs)
+				packIndex[loc.pack] = i
+				m.Packs = append(m.Packs, loc.pack)
+			}
+			f.Pack, f.Off, f.Len = i, loc.off, loc.len
+		}
+		m.Files = append(m.Files, f)
+	}
+	return m, nil
+}
+
+// storedBlobs returns the locations of the blobs with the given OIDs which are
+// stored in packs that are still in the cache.
+func (s *Store) storedBlobs(oids []string) map[string]blobLocation {
+	byPack := map[string][]string{}
+	for oid, pack := range s.blobs.lookup(oids) {
+		byPack[pack] = append(byPack[pack], oid)
+	}
+
+	stored := map[string]blobLocation{}
+	for pack, oids := range byPack {
+		// The offsets of blobs are read from the pack itself, since a pack
+		// evicted and fetched again may have a different layout.
+		locs, err := s.packBlobs(pack)
+		if err != nil {
+			s.blobs.removePack(pack)
+			continue
+		}
+		for _, oid := range oids {
+			if loc, ok := locs[oid]; ok {
+				stored[oid] = loc
+			}
+		}
+	}
+	return stored
+}
+
+// packBlobs returns the locations of the blobs in the pack with the cache key
+// pack.
+func (s *Store) packBlobs(pack string) (map[string]blobLocation, error) {
+	f, err := s.cache.Get(pack)
+	if err != nil {
+		return nil, err
+	}
+	path := f.Path
+	f.Close()
+
+	zf, err := s.ZipCache.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zf.Close()
+
+	locs := make(map[string]blobLocation, len(zf.Files))
+	for _, sf := range zf.Files {
+		locs[sf.Name] = blobLocation{pack: pack, off: sf.Off, len: sf.Len}
+	}
+	return locs, nil
+}
+
+// fetchPack fetches the blobs missing from the cache into a new pack, and
+// returns their locations. missing maps the OID of each blob to the path of a
+// file of repo at commit with its contents.
+func (s *Store) fetchPack(ctx context.Context, repo api.RepoName, commit api.CommitID, largeFilePatterns []string, missing map[string]string) (map[string]blobLocation, error) {
+	blobNames := make(map[string]string, len(missing))
+	oids := make([]string, 0, len(missing))
+	paths := make([]string, 0, len(missing))
+	for oid, path := range missing {
+		blobNames[path] = oid
+		oids = append(oids, oid)
+		paths = append(paths, path)
+	}
+	sort.Strings(oids)
+	sort.Strings(paths)
+
+	maxPaths := s.MaxBlobFetchPaths
+	if maxPaths == 0 {
+		maxPaths = defaultMaxBlobFetchPaths
+	}
+	if s.FetchTarPaths == nil || (maxPaths > 0 && len(paths) > maxPaths) {
+		// Listing too many paths is slower than fetching the full archive.
+		blobFullArchiveFetches.Inc()
+		paths = nil
+	}
+
+	// The key of a pack is derived from the OIDs of its blobs, which
+	// determine its contents, so a pack can be shared by any repository.
+	h := sha256.New()
+	for _, oid := range oids {
+		_, _ = io.WriteString(h, oid)
+	}
+	key := fmt.Sprintf("blob pack %x", h.Sum(nil))
+
+	f, err := s.cache.Open(ctx, key, func(ctx context.Context) (io.ReadCloser, error) {
+		return s.fetch(ctx, repo, commit, largeFilePatterns, paths, blobNames)
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	locs, err := s.packBlobs(key)
+	if err != nil {
+		return nil, err
+	}
+	blobsFetched.Add(float64(len(locs)))
+	stored := make([]string, 0, len(locs))
+	for oid := range locs {
+		stored = append(stored, oid)
+	}
+	s.blobs.add(key, s.cache.Path(key), stored)
+	return locs, nil
+}
+
+// openPacks returns a ZipFile of the files of m, whose contents are read from
+// the packs of m.
+func (s *Store) openPacks(m *blobManifest) (*ZipFile, error) {
+	packs := &zipPacks{}
+	zf := &ZipFile{
+		Files: make([]SrcFile, 0, len(m.Files)),
+		packs: packs,
+	}
+
+	var base int64
+	for _, key := range m.Packs {
+		f, err := s.cache.Get(key)
+		if err != nil {
+			zf.Close()
+			return nil, err
+		}
+		path := f.Path
+		f.Close()
+
+		pzf, err := s.ZipCache.Get(path)
+		if err != nil {
+			zf.Close()
+			return nil, err
+		}
+		packs.zfs = append(packs.zfs, pzf)
+		packs.bases = append(packs.bases, base)
+		base += int64(len(pzf.Data))
+	}
+
+	for _, f := range m.Files {
+		sf := SrcFile{Name: f.Path}
+		if f.Pack >= 0 {
+			if f.Pack >= len(packs.zfs) || !packHasBlob(packs.zfs[f.Pack], f) {
+				// The pack was rebuilt after the manifest was written, so
+				// its blobs may be at other offsets.
+				zf.Close()
+				return nil, errors.Errorf("blob manifest file %s is not in its pack", f.Path)
+			}
+			sf.Off = packs.bases[f.Pack] + f.Off
+			sf.Len = f.Len
+		}
+		zf.Files = append(zf.Files, sf)
+		if int(sf.Len) > zf.MaxLen {
+			zf.MaxLen = int(sf.Len)
+		}
+		packs.size += int64(sf.Len)
+	}
+
+	// We want sequential reads.
+	sort.Slice(zf.Files, func(i, j int) bool { return zf.Files[i].Off < zf.Files[j].Off })
+	return zf, nil
+}
+
+// packHasBlob reports whether the blob of f is at the offset recorded in f.
+func packHasBlob(pack *ZipFile, f blobManifestFile) bool {
+	i := sort.Search(len(pack.Files), func(i int) bool { return pack.Files[i].Off >= f.Off })
+	return i < len(pack.Files) && pack.Files[i].Off == f.Off && pack.Files[i].Len == f.Len && pack.Files[i].Name == f.OID
+}
+
+// blobIndex maps the OIDs of the blobs in the cache to the key of the pack
+// holding them. It is kept in memory, and rebuilt from the manifests in the
+// cache when the store starts.
+type blobIndex struct {
+	mu    sync.Mutex
+	blobs map[string]string
+	// packs maps the key of each pack to the OIDs it holds, and packPaths
+	// maps the path of each pack to its key, so that the blobs of a pack
+	// can be removed when it is evicted.
+	packs     map[string][]string
+	packPaths map[string]string
+}
+
+func newBlobIndex() *blobIndex {
+	return &blobIndex{
+		blobs:     map[string]string{},
+		packs:     map[string][]string{},
+		packPaths: map[string]string{},
+	}
+}
+
+// lookup returns the key of the pack of each of oids in the index.
+func (idx *blobIndex) lookup(oids []string) map[string]string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	found := map[string]string{}
+	for _, oid := range oids {
+		if pack, ok := idx.blobs[oid]; ok {
+			found[oid] = pack
+		}
+	}
+	return found
+}
+
+// add records that the pack with the cache key pack, stored at path, holds
+// the blobs with the given OIDs.
+func (idx *blobIndex) add(pack, path string, oids []string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.packPaths[path] = pack
+	for _, oid := range oids {
+		if idx.blobs[oid] == pack {
+			continue
+		}
+		idx.blobs[oid] = pack
+		idx.packs[pack] = append(idx.packs[pack], oid)
+	}
+}
+
+// removePackPath removes the blobs of the pack at path, if it is a pack.
+func (idx *blobIndex) removePackPath(path string) {
+	idx.mu.Lock()
+	pack, ok := idx.packPaths[path]
+	idx.mu.Unlock()
+	if ok {
+		idx.removePack(pack)
+	}
+}
+
+func (idx *blobIndex) removePack(pack string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, oid := range idx.packs[pack] {
+		// The blob may have been stored in another pack since.
+		if idx.blobs[oid] == pack {
+			delete(idx.blobs, oid)
+		}
+	}
+	delete(idx.packs, pack)
+	for path, p := range idx.packPaths {
+		if p == pack {
+			delete(idx.packPaths, path)
+		}
+	}
+}
+
+// loadBlobIndex adds the blobs of the manifests in the cache to the blobs
+// index, so that blobs stored before the store started are reused.
+func (s *Store) loadBlobIndex() error {
+	entries, err := os.ReadDir(s.Path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		m, err := readBlobManifest(filepath.Join(s.Path, entry.Name()))
+		if err != nil || m == nil {
+			// Archives and packs are not manifests, and manifests may be
+			// evicted while we read them.
+			continue
+		}
+
+		oids := make([][]string, len(m.Packs))
+		for _, f := range m.Files {
+			if f.Pack >= 0 && f.Pack < len(m.Packs) {
+				oids[f.Pack] = append(oids[f.Pack], f.OID)
+			}
+		}
+		for i, pack := range m.Packs {
+			s.blobs.add(pack, s.cache.Path(pack), oids[i])
+		}
+	}
+	return nil
+}
+
+// readBlobManifest returns the manifest at path, or nil if the file at path is
+// not a manifest. Manifests are JSON, while the other files in the cache are
+// zips.
+func readBlobManifest(path string) (*blobManifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if b, err := r.Peek(1); err != nil || b[0] != '{' {
+		return nil, err
+	}
+	var m blobManifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+var (
+	blobFullArchiveFetches = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_store_blob_full_archive_fetches_total",
+		Help: "The total number of packs of blobs fetched from the full archive of a commit, because too many blobs were missing.",
+	})
+	blobsFetched = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_store_blobs_fetched_total",
+		Help: "The total number of blobs fetched into packs.",
+	})
+	blobManifestsStale = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_store_blob_manifests_stale_total",
+		Help: "The total number of blob manifests rebuilt since a pack was evicted.",
+	})
+)
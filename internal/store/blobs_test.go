@@ -0,0 +1,297 @@
+package store
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestPrepareBlobs(t *testing.T) {
+	s, cleanup := tmpStore(t)
+	defer cleanup()
+
+	const (
+		commit1 = api.CommitID("1111111111111111111111111111111111111111")
+		commit2 = api.CommitID("2222222222222222222222222222222222222222")
+	)
+	trees := map[api.CommitID]map[string]string{
+		commit1: {"a.go": "a\n", "b.go": "b\n", "copy.go": "a\n", "bin": "\x00bin"},
+		commit2: {"a.go": "a\n", "b.go": "b2\n", "copy.go": "a\n", "bin": "\x00bin"},
+	}
+
+	var mu sync.Mutex
+	var fetched []string
+	s.ListBlobs = func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]Blob, error) {
+		var blobs []Blob
+		for path, body := range trees[commit] {
+			oid := sha1.Sum([]byte(body))
+			blobs = append(blobs, Blob{Path: path, OID: hex.EncodeToString(oid[:]), Size: int64(len(body))})
+		}
+		return blobs, nil
+	}
+	s.FetchTar = func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+		t.Fatal("unexpected fetch of the full archive")
+		return nil, nil
+	}
+	s.FetchTarPaths = func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, path := range paths {
+			fetched = append(fetched, string(commit[:1])+":"+path)
+		}
+		return tarOf(t, trees[commit], paths), nil
+	}
+	takeFetched := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		got := fetched
+		fetched = nil
+		sort.Strings(got)
+		return got
+	}
+
+	prepare := func(commit api.CommitID) (map[string]string, *ZipFile) {
+		t.Helper()
+		zf, err := s.PrepareBlobs(context.Background(), "foo", commit)
+		if err != nil {
+			t.Fatal("expected PrepareBlobs to succeed:", err)
+		}
+		defer zf.Close()
+		files := map[string]string{}
+		for i := range zf.Files {
+			files[zf.Files[i].Name] = string(zf.DataFor(&zf.Files[i]))
+		}
+		return files, zf
+	}
+	want := func(commit api.CommitID) map[string]string {
+		files := map[string]string{}
+		for path, body := range trees[commit] {
+			files[path] = body
+		}
+		// We only search the names of binary files.
+		files["bin"] = ""
+		return files
+	}
+
+	got, _ := prepare(commit1)
+	if !reflect.DeepEqual(got, want(commit1)) {
+		t.Fatalf("unexpected files of %s: want %q, got %q", commit1, want(commit1), got)
+	}
+	// A blob is fetched once, even if it is the content of several files.
+	if got := takeFetched(); len(got) != 3 {
+		t.Fatalf("expected 3 blobs to be fetched, got %q", got)
+	}
+
+	// Only the changed file of the second commit is fetched.
+	got, zf := prepare(commit2)
+	if !reflect.DeepEqual(got, want(commit2)) {
+		t.Fatalf("unexpected files of %s: want %q, got %q", commit2, want(commit2), got)
+	}
+	if got, want := takeFetched(), []string{"2:b.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected fetched files: want %q, got %q", want, got)
+	}
+
+	// Cached manifests do not fetch anything.
+	prepare(commit1)
+	if got := takeFetched(); len(got) != 0 {
+		t.Fatalf("expected no fetches, got %q", got)
+	}
+
+	// Once a pack of a manifest is evicted, its blobs are fetched again.
+	for _, pack := range zf.packs.zfs {
+		path := pack.f.Name()
+		s.ZipCache.delete(path)
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _ = prepare(commit2)
+	if !reflect.DeepEqual(got, want(commit2)) {
+		t.Fatalf("unexpected files of %s after eviction: want %q, got %q", commit2, want(commit2), got)
+	}
+	if got := takeFetched(); len(got) != 3 {
+		t.Fatalf("expected 3 blobs to be fetched after eviction, got %q", got)
+	}
+}
+
+// tarOf returns a tar archive of paths of files.
+func tarOf(t *testing.T, files map[string]string, paths []string) io.ReadCloser {
+	buf := new(bytes.Buffer)
+	w := tar.NewWriter(buf)
+	for _, path := range paths {
+		body := files[path]
+		if err := w.WriteHeader(&tar.Header{Name: path, Mode: 0600, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return io.NopCloser(bytes.NewReader(buf.Bytes()))
+}
+
+func TestPrepareBlobs_index(t *testing.T) {
+	s, cleanup := tmpStore(t)
+	defer cleanup()
+
+	const (
+		commit1 = api.CommitID("1111111111111111111111111111111111111111")
+		commit2 = api.CommitID("2222222222222222222222222222222222222222")
+		commit3 = api.CommitID("3333333333333333333333333333333333333333")
+	)
+	trees := map[api.CommitID]map[string]string{
+		commit1: {"a.go": "a\n", "b.go": "b\n"},
+		commit2: {"a.go": "a2\n", "b.go": "b2\n", "c.go": "c2\n"},
+		commit3: {"a.go": "a\n", "b.go": "b2\n"},
+	}
+
+	var mu sync.Mutex
+	var fetched []string
+	listBlobs := func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]Blob, error) {
+		var blobs []Blob
+		for path, body := range trees[commit] {
+			oid := sha1.Sum([]byte(body))
+			blobs = append(blobs, Blob{Path: path, OID: hex.EncodeToString(oid[:]), Size: int64(len(body))})
+		}
+		return blobs, nil
+	}
+	fetchTar := func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetched = append(fetched, string(repo)+"@"+string(commit[:1])+":full")
+		var paths []string
+		for path := range trees[commit] {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		return tarOf(t, trees[commit], paths), nil
+	}
+	fetchTarPaths := func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, path := range paths {
+			fetched = append(fetched, string(repo)+"@"+string(commit[:1])+":"+path)
+		}
+		return tarOf(t, trees[commit], paths), nil
+	}
+	setup := func(s *Store) {
+		s.ListBlobs, s.FetchTar, s.FetchTarPaths = listBlobs, fetchTar, fetchTarPaths
+	}
+	takeFetched := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		got := fetched
+		fetched = nil
+		sort.Strings(got)
+		return got
+	}
+	prepare := func(s *Store, repo api.RepoName, commit api.CommitID) {
+		t.Helper()
+		zf, err := s.PrepareBlobs(context.Background(), repo, commit)
+		if err != nil {
+			t.Fatal("expected PrepareBlobs to succeed:", err)
+		}
+		defer zf.Close()
+		files := map[string]string{}
+		for i := range zf.Files {
+			files[zf.Files[i].Name] = string(zf.DataFor(&zf.Files[i]))
+		}
+		if !reflect.DeepEqual(files, trees[commit]) {
+			t.Fatalf("unexpected files of %s@%s: want %q, got %q", repo, commit, trees[commit], files)
+		}
+	}
+	expectFetched := func(want ...string) {
+		t.Helper()
+		if got := takeFetched(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected fetched files: want %q, got %q", want, got)
+		}
+	}
+
+	setup(s)
+	prepare(s, "foo", commit1)
+	expectFetched("foo@1:a.go", "foo@1:b.go")
+	prepare(s, "foo", commit2)
+	expectFetched("foo@2:a.go", "foo@2:b.go", "foo@2:c.go")
+
+	// Blobs are reused from any earlier commit, not only the last one.
+	prepare(s, "foo", commit3)
+	expectFetched()
+
+	// Blobs are shared by repositories.
+	prepare(s, "bar", commit2)
+	expectFetched()
+
+	// The index is rebuilt from the manifests on disk after a restart.
+	restarted := &Store{Path: s.Path}
+	restarted.Start()
+	setup(restarted)
+	if err := restarted.loadBlobIndex(); err != nil {
+		t.Fatal(err)
+	}
+	trees[commit3]["d.go"] = "c2\n"
+	prepare(restarted, "baz", commit3)
+	expectFetched()
+}
+
+func TestPrepareBlobs_maxFetchPaths(t *testing.T) {
+	s, cleanup := tmpStore(t)
+	defer cleanup()
+
+	const commit = api.CommitID("1111111111111111111111111111111111111111")
+	tree := map[string]string{"a.go": "a\n", "b.go": "b\n", "c.go": "c\n"}
+
+	var fullFetches, pathFetches int
+	s.ListBlobs = func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]Blob, error) {
+		var blobs []Blob
+		for path, body := range tree {
+			oid := sha1.Sum([]byte(body))
+			blobs = append(blobs, Blob{Path: path, OID: hex.EncodeToString(oid[:]), Size: int64(len(body))})
+		}
+		return blobs, nil
+	}
+	s.FetchTar = func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+		fullFetches++
+		return tarOf(t, tree, []string{"a.go", "b.go", "c.go"}), nil
+	}
+	s.FetchTarPaths = func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+		pathFetches++
+		return tarOf(t, tree, paths), nil
+	}
+
+	// More blobs are missing than may be listed, so the full archive is
+	// fetched.
+	s.MaxBlobFetchPaths = 2
+	zf, err := s.PrepareBlobs(context.Background(), "foo", commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf.Close()
+	if fullFetches != 1 || pathFetches != 0 {
+		t.Fatalf("expected a fetch of the full archive, got %d full and %d path fetches", fullFetches, pathFetches)
+	}
+
+	// Without a limit, only the missing blobs are fetched.
+	tree["d.go"] = "d\n"
+	s.MaxBlobFetchPaths = -1
+	zf, err = s.PrepareBlobs(context.Background(), "foo", "2222222222222222222222222222222222222222")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf.Close()
+	if fullFetches != 1 || pathFetches != 1 {
+		t.Fatalf("expected a fetch of the missing blobs, got %d full and %d path fetches", fullFetches, pathFetches)
+	}
+}
@@ -52,15 +52,32 @@ const maxFileSize = 1 << 20 // 1MB; match https://sourcegraph.com/search?q=repo:
 // filter which files we cache, so we need a format that supports streaming
 // (tar). We want to be able to support random concurrent access for reading,
 // so we store as a zip.
+//
+// PrepareBlobs stores the same files deduplicated by git blob, so that
+// commits of a repository share the contents of their unchanged files.
 type Store struct {
 	// FetchTar returns an io.ReadCloser to a tar archive of a repository at the specified Git
 	// remote URL and commit ID. If the error implements "BadRequest() bool", it will be used to
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, but the archive only contains paths. It
+	// is used to fetch the blobs missing from the blob store.
+	FetchTarPaths func(ctx context.Context, repo api.RepoName, commit api.CommitID, paths []string) (io.ReadCloser, error)
+
 	// FilterTar returns a FilterFunc that filters out files we don't want to write to disk
 	FilterTar func(ctx context.Context, repo api.RepoName, commit api.CommitID) (FilterFunc, error)
 
+	// ListBlobs returns the regular files of repo at commit and their git
+	// blobs. It must be set to use PrepareBlobs.
+	ListBlobs func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]Blob, error)
+
+	// MaxBlobFetchPaths is the maximum number of blobs PrepareBlobs fetches
+	// by listing their paths. If more blobs of a commit are missing, the full
+	// archive of the commit is fetched and only the missing blobs are
+	// stored. Defaults to 1000; a negative value means no limit.
+	MaxBlobFetchPaths int
+
 	// Path is the directory to store the cache
 	Path string
 
@@ -79,6 +96,9 @@ type Store struct {
 	// fetchLimiter limits concurrent calls to FetchTar.
 	fetchLimiter *mutablelimiter.Limiter
 
+	// blobs is the index of the blobs stored by PrepareBlobs.
+	blobs *blobIndex
+
 	// ZipCache provides efficient access to repo zip files.
 	ZipCache ZipCache
 }
@@ -94,13 +114,24 @@ type FilterFunc func(hdr *tar.Header) bool
 func (s *Store) Start() {
 	s.once.Do(func() {
 		s.fetchLimiter = mutablelimiter.New(15)
+		s.blobs = newBlobIndex()
 		s.cache = &diskcache.Store{
 			Dir:               s.Path,
 			Component:         "store",
 			BackgroundTimeout: 10 * time.Minute,
-			BeforeEvict:       s.ZipCache.delete,
+			BeforeEvict: func(path string) {
+				s.ZipCache.delete(path)
+				s.blobs.removePackPath(path)
+			},
 		}
 		_ = os.MkdirAll(s.Path, 0700)
+		if s.ListBlobs != nil {
+			go func() {
+				if err := s.loadBlobIndex(); err != nil {
+					log15.Error("failed to load the blobs index", "error", err)
+				}
+			}()
+		}
 		metrics.MustRegisterDiskMonitor(s.Path)
 		go s.watchAndEvict()
 		go s.watchConfig()
@@ -149,7 +180,7 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 		// since we're just going to close it again immediately.
 		bgctx := opentracing.ContextWithSpan(context.Background(), opentracing.SpanFromContext(ctx))
 		f, err := s.cache.Open(bgctx, key, func(ctx context.Context) (io.ReadCloser, error) {
-			return s.fetch(ctx, repo, commit, largeFilePatterns)
+			return s.fetch(ctx, repo, commit, largeFilePatterns, nil, nil)
 		})
 		var path string
 		if f != nil {
@@ -179,7 +210,10 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 // fetch fetches an archive from the network and stores it on disk. It does
 // not populate the in-memory cache. You should probably be calling
 // prepareZip.
-func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitID, largeFilePatterns []string) (rc io.ReadCloser, err error) {
+//
+// If blobNames is non-nil, only the files in it are stored, named by the value
+// of their path in blobNames. It is used to write packs of blobs.
+func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitID, largeFilePatterns []string, paths []string, blobNames map[string]string) (rc io.ReadCloser, err error) {
 	fetchQueueSize.Inc()
 	ctx, releaseFetchLimiter, err := s.fetchLimiter.Acquire(ctx) // Acquire concurrent fetches semaphore
 	if err != nil {
@@ -222,13 +256,26 @@ func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitI
 		}
 	}()
 
-	r, err := s.FetchTar(ctx, repo, commit)
+	var r io.ReadCloser
+	if paths != nil {
+		r, err = s.FetchTarPaths(ctx, repo, commit, paths)
+	} else {
+		r, err = s.FetchTar(ctx, repo, commit)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	filter := func(hdr *tar.Header) bool { return false } // default: don't filter
-	if s.FilterTar != nil {
+	name := func(hdr *tar.Header) string { return hdr.Name }
+	if blobNames != nil {
+		// FilterTar was already applied when listing the blobs to fetch.
+		filter = func(hdr *tar.Header) bool {
+			_, ok := blobNames[hdr.Name]
+			return !ok
+		}
+		name = func(hdr *tar.Header) string { return blobNames[hdr.Name] }
+	} else if s.FilterTar != nil {
 		filter, err = s.FilterTar(ctx, repo, commit)
 		if err != nil {
 			return nil, errors.Errorf("error while calling FilterTar: %w", err)
@@ -247,7 +294,7 @@ func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitI
 		defer r.Close()
 		tr := tar.NewReader(r)
 		zw := zip.NewWriter(pw)
-		err := copySearchable(tr, zw, largeFilePatterns, filter)
+		err := copySearchable(tr, zw, largeFilePatterns, filter, name)
 		if err1 := zw.Close(); err == nil {
 			err = err1
 		}
@@ -261,7 +308,8 @@ func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitI
 
 // copySearchable copies searchable files from tr to zw. A searchable file is
 // any file that is under size limit, non-binary, and not matching the filter.
-func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string, filter FilterFunc) error {
+// Files are stored in zw with the name returned by name.
+func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string, filter FilterFunc, name func(*tar.Header) string) error {
 	// 32*1024 is the same size used by io.Copy
 	buf := make([]byte, 32*1024)
 	for {
@@ -292,7 +340,7 @@ func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string,
 
 		// We are happy with the file, so we can write it to zw.
 		w, err := zw.CreateHeader(&zip.FileHeader{
-			Name:   hdr.Name,
+			Name:   name(hdr),
 			Method: zip.Store,
 		})
 		if err != nil {
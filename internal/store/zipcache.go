@@ -101,6 +101,18 @@ type ZipFile struct {
 	Data   []byte
 	f      *os.File
 	wg     sync.WaitGroup // ensures underlying file is not munmap'd or closed while in use
+
+	// packs is set if the contents of Files are stored in packs of blobs,
+	// rather than in Data. See Store.PrepareBlobs.
+	packs *zipPacks
+}
+
+// zipPacks are the packs of blobs holding the contents of the files of a
+// ZipFile. The offset of a file in the i-th pack is relative to bases[i].
+type zipPacks struct {
+	zfs   []*ZipFile
+	bases []int64
+	size  int64
 }
 
 func readZipFile(path string) (*ZipFile, error) {
@@ -170,6 +182,12 @@ func (f *ZipFile) PopulateFiles(r *zip.Reader) error {
 // Contents from any SrcFile from within f MUST NOT be used after
 // Close has been called.
 func (f *ZipFile) Close() {
+	if f.packs != nil {
+		for _, zf := range f.packs.zfs {
+			zf.Close()
+		}
+		return
+	}
 	f.wg.Done()
 }
 
@@ -189,9 +207,25 @@ type SrcFile struct {
 // The contents MUST NOT be modified.
 // It is not safe to use the contents after f has been Closed.
 func (f *ZipFile) DataFor(s *SrcFile) []byte {
+	if f.packs != nil {
+		if s.Len == 0 {
+			return nil
+		}
+		i := sort.Search(len(f.packs.bases), func(i int) bool { return f.packs.bases[i] > s.Off }) - 1
+		off := s.Off - f.packs.bases[i]
+		return f.packs.zfs[i].Data[off : off+int64(s.Len)]
+	}
 	return f.Data[s.Off : s.Off+int64(s.Len)]
 }
 
+// Size returns the number of bytes of f which are searched.
+func (f *ZipFile) Size() int64 {
+	if f.packs != nil {
+		return f.packs.size
+	}
+	return int64(len(f.Data))
+}
+
 func (f *SrcFile) String() string {
 	return fmt.Sprintf("<%s: %d+%d bytes>", f.Name, f.Off, f.Len)
 }
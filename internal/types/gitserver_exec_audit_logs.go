@@ -0,0 +1,24 @@
+package types
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// GitserverExecAuditLog is an entry in the audit log of git commands gitserver
+// ran on behalf of other services.
+type GitserverExecAuditLog struct {
+	ID         int64
+	Actor      string // the X-Sourcegraph-Actor header: a user ID, "0" or "internal"
+	Client     string // the user agent of the requesting service
+	Gitserver  string // the hostname of the gitserver that ran the command
+	Repo       api.RepoName
+	Command    string
+	Args       []string
+	Status     string // the exit status of the command, or why it did not run
+	ExitStatus *int   // nil if the command did not run
+	Error      *string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
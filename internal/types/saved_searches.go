@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // SavedSearch represents a saved search
 type SavedSearch struct {
 	ID              int32 // the globally unique DB ID
@@ -7,6 +9,23 @@ type SavedSearch struct {
 	Query           string  // the literal search query to be ran
 	Notify          bool    // whether or not to notify the owner(s) of this saved search via email
 	NotifySlack     bool    // whether or not to notify the owner(s) of this saved search via Slack
+	NotifyWebhook   bool    // whether or not to post new results to WebhookURL
 	UserID          int32   // the owner of the saved search
 	SlackWebhookURL *string // if non-nil && NotifySlack == true, indicates that this Slack webhook URL should be used instead of the owners default Slack webhook.
+	WebhookURL      *string // the generic webhook URL that new results are posted to if NotifyWebhook == true.
+	WebhookSecret   *string // if non-nil, the key used to sign webhook payloads.
+}
+
+// SavedSearchDelivery is an entry in the log of outbound notifications sent
+// for a saved search.
+type SavedSearchDelivery struct {
+	ID            int64
+	SavedSearchID int32
+	Kind          string // "webhook" or "slack"
+	Event         string // the event that triggered the notification, such as "results" or "test"
+	Attempts      int
+	StatusCode    *int    // the status code of the last response, if any
+	Error         *string // the error of the last attempt, if the delivery failed
+	Duration      time.Duration
+	CreatedAt     time.Time
 }
@@ -9,12 +9,15 @@
 package types
 
 import (
+	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/fatih/structs"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/sourcegraph/jsonx"
 
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 	"github.com/sourcegraph/sourcegraph/schema"
@@ -58,7 +61,7 @@ func (e *ExternalService) RedactConfigSecrets() (string, error) {
 func redactField(buf string, paths ...[]string) (string, error) {
 	var err error
 	for _, path := range paths {
-		buf, err = jsonc.Edit(buf, RedactedSecret, path...)
+		buf, err = jsonc.EditPath(buf, RedactedSecret, jsonPath(path))
 		if err != nil {
 			return buf, err
 		}
@@ -103,6 +106,21 @@ func redactionInfo(cfg interface{}) ([]jsonStringField, error) {
 		return []jsonStringField{}, nil
 	case *schema.JVMPackagesConnection:
 		return []jsonStringField{{[]string{"maven", "credentials"}, &cfg.Maven.Credentials}}, nil
+	case *schema.NPMPackagesConnection:
+		return []jsonStringField{{[]string{"credentials"}, &cfg.Credentials}}, nil
+	case *schema.PythonPackagesConnection:
+		// Private indexes are configured with credentials in their URL.
+		fields := []jsonStringField{}
+		for i := range cfg.Urls {
+			if u, err := url.Parse(cfg.Urls[i]); err != nil || u.User != nil {
+				fields = append(fields, jsonStringField{[]string{"urls", strconv.Itoa(i)}, &cfg.Urls[i]})
+			}
+		}
+		return fields, nil
+	case *schema.MercurialConnection:
+		return []jsonStringField{{[]string{"url"}, &cfg.Url}}, nil
+	case *schema.SubversionConnection:
+		return []jsonStringField{{[]string{"password"}, &cfg.Password}}, nil
 	case *schema.OtherExternalServiceConnection:
 		return []jsonStringField{{[]string{"url"}, &cfg.Url}}, nil
 	default:
@@ -146,6 +164,20 @@ type jsonStringField struct {
 	ptr  *string
 }
 
+// jsonPath returns the JSON path of the segments of a jsonStringField path.
+// Integer segments are array indexes, all other segments are properties.
+func jsonPath(path []string) jsonx.Path {
+	components := make([]interface{}, len(path))
+	for i, segment := range path {
+		if index, err := strconv.Atoi(segment); err == nil {
+			components[i] = index
+		} else {
+			components[i] = segment
+		}
+	}
+	return jsonx.MakePath(components...)
+}
+
 func unredactFields(old, new string, cfg interface{}) (string, error) {
 	// first we zero the fields on cfg, as they will contain data we don't need from the e.Configuration() call
 	// we just want an empty struct of the correct type for marshaling into
@@ -162,7 +194,7 @@ func unredactFields(old, new string, cfg interface{}) (string, error) {
 
 	// and apply edits to update those fields in the new config
 	for _, field := range jsonStringFields {
-		v, err := jsonc.ReadProperty(new, field.path...)
+		v, err := jsonc.ReadPropertyPath(new, jsonPath(field.path))
 		if err != nil {
 			return new, err
 		}
@@ -172,14 +204,14 @@ func unredactFields(old, new string, cfg interface{}) (string, error) {
 		}
 		if stringValue != RedactedSecret {
 			// using unicode zero width space might mean the user includes it when editing still, we strip that out here
-			new, err = jsonc.Edit(new, strings.ReplaceAll(stringValue, RedactedSecret, ""), field.path...)
+			new, err = jsonc.EditPath(new, strings.ReplaceAll(stringValue, RedactedSecret, ""), jsonPath(field.path))
 			if err != nil {
 				return new, err
 			}
 			// if the field has been edited we should skip unredaction to allow edits
 			continue
 		}
-		new, err = jsonc.Edit(new, *field.ptr, field.path...)
+		new, err = jsonc.EditPath(new, *field.ptr, jsonPath(field.path))
 		if err != nil {
 			return new, err
 		}
@@ -462,7 +462,14 @@ type GitserverRepo struct {
 	LastFetched time.Time
 	// The last time a fetch updated the repository.
 	LastChanged time.Time
-	UpdatedAt   time.Time
+	// The last time gitserver checked the integrity of the repository.
+	LastFsck time.Time
+	// When the repository was found to be corrupt, or zero if it is not.
+	CorruptedAt time.Time
+	// The output of the integrity check which found the repository to be
+	// corrupt.
+	CorruptionLog string
+	UpdatedAt     time.Time
 }
 
 // ExternalService is a connection to an external service.
@@ -11,6 +11,8 @@ import (
 	"github.com/cockroachdb/errors"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
 )
@@ -22,6 +24,10 @@ type BlameOptions struct {
 
 	StartLine int `json:",omitempty" url:",omitempty"` // 1-indexed start byte (or 0 for beginning of file)
 	EndLine   int `json:",omitempty" url:",omitempty"` // 1-indexed end byte (or 0 for end of file)
+
+	// IgnoreRevs ignores the revisions listed in the repository's
+	// .git-blame-ignore-revs file. Only supported by StreamBlameFile.
+	IgnoreRevs bool `json:",omitempty" url:",omitempty"`
 }
 
 // A Hunk is a contiguous portion of a file associated with a commit.
@@ -46,6 +52,55 @@ func BlameFile(ctx context.Context, repo api.RepoName, path string, opt *BlameOp
 	return blameFileCmd(ctx, gitserverCmdFunc(repo), path, opt)
 }
 
+// StreamBlameFile returns Git blame information about a file, calling onHunks
+// with each set of hunks as gitserver computes them. Unlike BlameFile, the
+// hunks are not sent in line order and their StartByte and EndByte are not
+// set.
+func StreamBlameFile(ctx context.Context, repo api.RepoName, path string, opt *BlameOptions, onHunks func([]*Hunk)) error {
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: StreamBlameFile")
+	span.SetTag("repo", repo)
+	span.SetTag("path", path)
+	span.SetTag("opt", opt)
+	defer span.Finish()
+
+	if opt == nil {
+		opt = &BlameOptions{}
+	}
+	if opt.OldestCommit != "" {
+		return errors.Errorf("OldestCommit not implemented")
+	}
+	if err := checkSpecArgSafety(string(opt.NewestCommit)); err != nil {
+		return err
+	}
+
+	req := &protocol.BlameRequest{
+		Repo:       repo,
+		Commit:     opt.NewestCommit,
+		Path:       filepath.ToSlash(path),
+		StartLine:  opt.StartLine,
+		EndLine:    opt.EndLine,
+		IgnoreRevs: opt.IgnoreRevs,
+	}
+	return gitserver.DefaultClient.StreamBlame(ctx, req, func(blameHunks []protocol.BlameHunk) {
+		hunks := make([]*Hunk, 0, len(blameHunks))
+		for _, h := range blameHunks {
+			hunks = append(hunks, &Hunk{
+				StartLine: h.StartLine,
+				EndLine:   h.EndLine,
+				CommitID:  h.CommitID,
+				Author: gitapi.Signature{
+					Name:  h.Author.Name,
+					Email: h.Author.Email,
+					Date:  h.Author.Date,
+				},
+				Message:  h.Message,
+				Filename: h.Filename,
+			})
+		}
+		onHunks(hunks)
+	})
+}
+
 func blameFileCmd(ctx context.Context, command cmdFunc, path string, opt *BlameOptions) ([]*Hunk, error) {
 	if opt == nil {
 		opt = &BlameOptions{}
@@ -53,6 +108,9 @@ func blameFileCmd(ctx context.Context, command cmdFunc, path string, opt *BlameO
 	if opt.OldestCommit != "" {
 		return nil, errors.Errorf("OldestCommit not implemented")
 	}
+	if opt.IgnoreRevs {
+		return nil, errors.Errorf("IgnoreRevs not implemented")
+	}
 	if err := checkSpecArgSafety(string(opt.NewestCommit)); err != nil {
 		return nil, err
 	}
@@ -3,6 +3,7 @@ package git
 import (
 	"context"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -79,3 +80,56 @@ func TestRepository_BlameFile(t *testing.T) {
 		}
 	}
 }
+
+func TestRepository_StreamBlameFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	commit := "GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m foo --author='a <a@a.com>' --date 2006-01-02T15:04:05Z"
+	repo := MakeGitRepository(t,
+		"echo line1 > f",
+		"git add f",
+		commit,
+		"echo line2 >> f",
+		"git add f",
+		commit,
+		"git mv f f2",
+		"echo line3 >> f2",
+		"git add f2",
+		commit,
+		"sed -i 's/line3/LINE3/' f2",
+		"git add f2",
+		commit,
+		"git rev-parse HEAD > .git-blame-ignore-revs",
+		"git add .git-blame-ignore-revs",
+		commit,
+	)
+	author := gitapi.Signature{Name: "a", Email: "a@a.com", Date: MustParseTime(time.RFC3339, "2006-01-02T15:04:05Z")}
+
+	streamBlame := func(opt *BlameOptions) []*Hunk {
+		var hunks []*Hunk
+		if err := StreamBlameFile(ctx, repo, "f2", opt, func(h []*Hunk) {
+			hunks = append(hunks, h...)
+		}); err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(hunks, func(i, j int) bool { return hunks[i].StartLine < hunks[j].StartLine })
+		return hunks
+	}
+
+	wantHunks := []*Hunk{
+		{StartLine: 1, EndLine: 2, CommitID: "e6093374dcf5725d8517db0dccbbf69df65dbde0", Message: "foo", Author: author, Filename: "f"},
+		{StartLine: 2, EndLine: 3, CommitID: "fad406f4fe02c358a09df0d03ec7a36c2c8a20f1", Message: "foo", Author: author, Filename: "f"},
+		{StartLine: 3, EndLine: 4, CommitID: "311d75a2b414a77f5158a0ed73ec476f5469b286", Message: "foo", Author: author, Filename: "f2"},
+	}
+
+	hunks := streamBlame(&BlameOptions{NewestCommit: "master"})
+	if len(hunks) != 3 || hunks[2].CommitID == wantHunks[2].CommitID {
+		t.Fatalf("expected the last line to be blamed on the ignored commit, got:\n%s", AsJSON(hunks))
+	}
+
+	hunks = streamBlame(&BlameOptions{NewestCommit: "master", IgnoreRevs: true})
+	if !reflect.DeepEqual(hunks, wantHunks) {
+		t.Errorf("hunks != wantHunks\n\nhunks ==========\n%s\n\nwantHunks ==========\n%s", AsJSON(hunks), AsJSON(wantHunks))
+	}
+}
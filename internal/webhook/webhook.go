@@ -0,0 +1,154 @@
+// Package webhook is used to deliver signed JSON payloads to generic outbound
+// webhooks, retrying transient failures.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of the request body in
+	// the form "sha256=<hex digest>". It is only set when the client has a secret.
+	SignatureHeader = "X-Sourcegraph-Signature"
+
+	// EventHeader carries the name of the event that triggered the delivery.
+	EventHeader = "X-Sourcegraph-Event"
+)
+
+// DefaultMaxAttempts is the number of requests made for a single delivery
+// before giving up, unless the client overrides it.
+const DefaultMaxAttempts = 3
+
+// defaultDoer is used by clients that don't set a Doer. Unlike the shared
+// external doer, it neither retries nor caches so that every attempt is counted
+// in the Delivery.
+var defaultDoer, _ = httpcli.NewFactory(
+	httpcli.NewMiddleware(httpcli.ContextErrorMiddleware),
+	httpcli.ExternalTransportOpt,
+	httpcli.TracedTransportOpt,
+).Doer()
+
+// backoff returns the time to wait before the given retry (1-indexed). It is
+// replaced in tests.
+var backoff = func(retry int) time.Duration {
+	return time.Duration(1<<uint(retry-1)) * time.Second
+}
+
+// Client is capable of posting signed JSON payloads to a webhook.
+type Client struct {
+	URL         string
+	Secret      string
+	MaxAttempts int
+	Doer        httpcli.Doer
+}
+
+// New creates a new webhook client. If secret is non-empty, payloads are
+// signed with it.
+func New(url, secret string) *Client {
+	return &Client{URL: url, Secret: secret}
+}
+
+// Delivery describes the outcome of posting a payload to a webhook.
+type Delivery struct {
+	// Attempts is the number of requests that were made.
+	Attempts int
+	// StatusCode is the status code of the last response, or zero if no
+	// response was received.
+	StatusCode int
+	// Duration is the time spent on all attempts, including backoff.
+	Duration time.Duration
+}
+
+// Sign returns the signature of body for the given secret, as sent in the
+// SignatureHeader. Receivers should compute the same value over the raw request
+// body and compare it using a constant-time comparison.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Post sends the JSON encoding of payload to the webhook. Network errors, 429
+// and 5xx responses are retried with exponential backoff; other non-2xx
+// responses fail immediately. The returned Delivery is valid even if an error
+// is returned.
+func (c *Client) Post(ctx context.Context, event string, payload interface{}) (delivery Delivery, err error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return delivery, errors.Wrap(err, "webhook: marshal json")
+	}
+
+	maxAttempts := c.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	doer := c.Doer
+	if doer == nil {
+		doer = defaultDoer
+	}
+
+	start := time.Now()
+	defer func() { delivery.Duration = time.Since(start) }()
+
+	for {
+		delivery.Attempts++
+
+		var retryable bool
+		delivery.StatusCode, retryable, err = c.post(ctx, doer, event, body)
+		if err == nil || !retryable || delivery.Attempts >= maxAttempts {
+			return delivery, err
+		}
+
+		select {
+		case <-time.After(backoff(delivery.Attempts)):
+		case <-ctx.Done():
+			return delivery, errors.Wrapf(ctx.Err(), "webhook: retry after %s", err)
+		}
+	}
+}
+
+// post makes a single request to the webhook. It returns the status code of
+// the response, if any, and whether a failure may succeed when retried.
+func (c *Client) post(ctx context.Context, doer httpcli.Doer, event string, body []byte) (statusCode int, retryable bool, err error) {
+	req, err := http.NewRequest("POST", c.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, errors.Wrap(err, "webhook: create post request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sourcegraph-Webhook")
+	if event != "" {
+		req.Header.Set(EventHeader, event)
+	}
+	if c.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(c.Secret, body))
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	resp, err := doer.Do(req.WithContext(timeoutCtx))
+	if err != nil {
+		return 0, ctx.Err() == nil, errors.Wrap(err, "webhook: http request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retryable, errors.Errorf("webhook: request failed with %d %s", resp.StatusCode, string(respBody))
+}
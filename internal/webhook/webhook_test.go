@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPost(t *testing.T) {
+	old := backoff
+	backoff = func(int) time.Duration { return 0 }
+	defer func() { backoff = old }()
+
+	payload := map[string]string{"hello": "world"}
+
+	t.Run("signed", func(t *testing.T) {
+		var gotSignature, gotEvent, gotBody string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			gotBody = string(body)
+			gotSignature = r.Header.Get(SignatureHeader)
+			gotEvent = r.Header.Get(EventHeader)
+		}))
+		defer server.Close()
+
+		delivery, err := New(server.URL, "s3cr3t").Post(context.Background(), "test", payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivery.Attempts != 1 || delivery.StatusCode != http.StatusOK {
+			t.Errorf("unexpected delivery %+v", delivery)
+		}
+		if want := `{"hello":"world"}`; gotBody != want {
+			t.Errorf("unexpected body. want=%s have=%s", want, gotBody)
+		}
+		if want := Sign("s3cr3t", []byte(gotBody)); gotSignature != want {
+			t.Errorf("unexpected signature. want=%s have=%s", want, gotSignature)
+		}
+		if gotEvent != "test" {
+			t.Errorf("unexpected event. want=%s have=%s", "test", gotEvent)
+		}
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Header[SignatureHeader]; ok {
+				t.Errorf("unexpected signature header")
+			}
+		}))
+		defer server.Close()
+
+		if _, err := New(server.URL, "").Post(context.Background(), "test", payload); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer server.Close()
+
+		delivery, err := New(server.URL, "").Post(context.Background(), "test", payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivery.Attempts != 3 || delivery.StatusCode != http.StatusOK {
+			t.Errorf("unexpected delivery %+v", delivery)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := New(server.URL, "")
+		client.MaxAttempts = 2
+		delivery, err := client.Post(context.Background(), "test", payload)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if delivery.Attempts != 2 || delivery.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("unexpected delivery %+v", delivery)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		delivery, err := New(server.URL, "").Post(context.Background(), "test", payload)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if delivery.Attempts != 1 || delivery.StatusCode != http.StatusNotFound {
+			t.Errorf("unexpected delivery %+v", delivery)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS saved_search_deliveries;
+
+ALTER TABLE saved_searches
+    DROP COLUMN IF EXISTS notify_webhook,
+    DROP COLUMN IF EXISTS webhook_url,
+    DROP COLUMN IF EXISTS webhook_secret;
+
+COMMIT;
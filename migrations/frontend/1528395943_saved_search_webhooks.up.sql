@@ -0,0 +1,33 @@
+BEGIN;
+
+ALTER TABLE saved_searches
+    ADD COLUMN IF NOT EXISTS notify_webhook BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS webhook_url TEXT,
+    ADD COLUMN IF NOT EXISTS webhook_secret TEXT;
+
+COMMENT ON COLUMN saved_searches.webhook_url IS 'The URL that new results are posted to when notify_webhook is set.';
+COMMENT ON COLUMN saved_searches.webhook_secret IS 'If set, the key used to sign webhook payloads with HMAC-SHA256.';
+
+CREATE TABLE IF NOT EXISTS saved_search_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    saved_search_id INTEGER NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    event TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS saved_search_deliveries_saved_search_id_created_at ON saved_search_deliveries(saved_search_id, created_at DESC);
+
+COMMENT ON TABLE saved_search_deliveries IS 'Records outbound webhook and Slack notifications sent for saved searches.';
+COMMENT ON COLUMN saved_search_deliveries.kind IS 'The type of notification: webhook or slack.';
+COMMENT ON COLUMN saved_search_deliveries.event IS 'The event that triggered the notification, such as results or test.';
+COMMENT ON COLUMN saved_search_deliveries.attempts IS 'The number of requests made before the delivery succeeded or was abandoned.';
+COMMENT ON COLUMN saved_search_deliveries.status_code IS 'The HTTP status code of the last response, if any response was received.';
+COMMENT ON COLUMN saved_search_deliveries.error IS 'The error of the last attempt, if the delivery failed.';
+COMMENT ON COLUMN saved_search_deliveries.duration_ms IS 'The time spent on all attempts, including backoff.';
+
+COMMIT;
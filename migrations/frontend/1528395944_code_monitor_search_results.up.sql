@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE cm_trigger_jobs ADD COLUMN IF NOT EXISTS search_results JSONB;
+
+COMMENT ON COLUMN cm_trigger_jobs.search_results IS 'The new search results found by this run, as returned by the GraphQL API. Included in webhook and Slack action payloads.';
+
+COMMIT;
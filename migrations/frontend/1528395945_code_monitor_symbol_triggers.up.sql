@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS cm_symbol_trigger_states (
+    query BIGINT NOT NULL REFERENCES cm_queries(id) ON DELETE CASCADE,
+    repo_id INTEGER NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+    query_string TEXT NOT NULL,
+    commit_oid TEXT NOT NULL,
+    symbols TEXT[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (query, repo_id)
+);
+
+COMMENT ON TABLE cm_symbol_trigger_states IS 'The symbols last seen by a symbol search trigger in each repository. New symbols are detected by comparing against this state.';
+COMMENT ON COLUMN cm_symbol_trigger_states.query IS 'The trigger query this state belongs to.';
+COMMENT ON COLUMN cm_symbol_trigger_states.query_string IS 'The query that produced the state. State recorded for a different query is treated as absent.';
+COMMENT ON COLUMN cm_symbol_trigger_states.commit_oid IS 'The commit at which the symbols were last seen.';
+COMMENT ON COLUMN cm_symbol_trigger_states.symbols IS 'Keys identifying the symbols that matched the query at commit_oid.';
+
+COMMIT;
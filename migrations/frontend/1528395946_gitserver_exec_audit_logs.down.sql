@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS gitserver_exec_audit_logs;
+
+COMMIT;
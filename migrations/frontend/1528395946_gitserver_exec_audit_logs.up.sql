@@ -0,0 +1,37 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS gitserver_exec_audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,
+    client TEXT NOT NULL,
+    gitserver TEXT NOT NULL,
+    repo_name TEXT NOT NULL,
+    command TEXT NOT NULL,
+    args TEXT[] NOT NULL,
+    status TEXT NOT NULL,
+    exit_status INTEGER NULL,
+    error TEXT NULL,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS
+    gitserver_exec_audit_logs_created_at_idx
+ON
+    gitserver_exec_audit_logs (created_at);
+
+CREATE INDEX IF NOT EXISTS
+    gitserver_exec_audit_logs_repo_name_idx
+ON
+    gitserver_exec_audit_logs (repo_name);
+
+COMMENT ON TABLE gitserver_exec_audit_logs IS 'Audit log of the git commands gitserver ran on behalf of other services.';
+COMMENT ON COLUMN gitserver_exec_audit_logs.actor IS 'The actor the command was run for: a user ID, 0 for anonymous users, or internal for Sourcegraph services.';
+COMMENT ON COLUMN gitserver_exec_audit_logs.client IS 'The user agent of the service that requested the command.';
+COMMENT ON COLUMN gitserver_exec_audit_logs.gitserver IS 'The hostname of the gitserver that ran the command.';
+COMMENT ON COLUMN gitserver_exec_audit_logs.repo_name IS 'The name of the repository the command was run in. It is not a reference to repo, as commands may be run for repositories unknown to the database.';
+COMMENT ON COLUMN gitserver_exec_audit_logs.status IS 'The outcome of the request, such as the exit status of the command or why it did not run, for example repo-not-found.';
+COMMENT ON COLUMN gitserver_exec_audit_logs.exit_status IS 'The exit status of the command, if it ran.';
+COMMENT ON COLUMN gitserver_exec_audit_logs.error IS 'The error running the command, if any.';
+
+COMMIT;
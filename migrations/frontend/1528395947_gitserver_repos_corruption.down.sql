@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE gitserver_repos
+    DROP COLUMN IF EXISTS last_fsck,
+    DROP COLUMN IF EXISTS corrupted_at,
+    DROP COLUMN IF EXISTS corruption_log;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE gitserver_repos
+    ADD COLUMN IF NOT EXISTS last_fsck TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN IF NOT EXISTS corrupted_at TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN IF NOT EXISTS corruption_log TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS gitserver_repos_corrupted_idx ON gitserver_repos (repo_id) WHERE corrupted_at IS NOT NULL;
+
+COMMENT ON COLUMN gitserver_repos.last_fsck IS 'The last time gitserver checked the connectivity of the repository with git fsck.';
+COMMENT ON COLUMN gitserver_repos.corrupted_at IS 'When git fsck found the repository to be corrupt. It is reset once a check succeeds, which is usually after the repository has been recloned.';
+COMMENT ON COLUMN gitserver_repos.corruption_log IS 'The output of git fsck for a corrupt repository.';
+
+COMMIT;
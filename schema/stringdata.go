@@ -38,6 +38,14 @@ var GitoliteSchemaJSON string
 //go:embed jvm-packages.schema.json
 var JVMPackagesSchemaJSON string
 
+// MercurialSchemaJSON is the content of the file "mercurial.schema.json".
+//go:embed mercurial.schema.json
+var MercurialSchemaJSON string
+
+// NPMPackagesSchemaJSON is the content of the file "npm-packages.schema.json".
+//go:embed npm-packages.schema.json
+var NPMPackagesSchemaJSON string
+
 // OtherExternalServiceSchemaJSON is the content of the file "other_external_service.schema.json".
 //go:embed other_external_service.schema.json
 var OtherExternalServiceSchemaJSON string
@@ -50,6 +58,10 @@ var PerforceSchemaJSON string
 //go:embed phabricator.schema.json
 var PhabricatorSchemaJSON string
 
+// PythonPackagesSchemaJSON is the content of the file "python-packages.schema.json".
+//go:embed python-packages.schema.json
+var PythonPackagesSchemaJSON string
+
 // SettingsSchemaJSON is the content of the file "settings.schema.json".
 //go:embed settings.schema.json
 var SettingsSchemaJSON string
@@ -57,3 +69,7 @@ var SettingsSchemaJSON string
 // SiteSchemaJSON is the content of the file "site.schema.json".
 //go:embed site.schema.json
 var SiteSchemaJSON string
+
+// SubversionSchemaJSON is the content of the file "subversion.schema.json".
+//go:embed subversion.schema.json
+var SubversionSchemaJSON string